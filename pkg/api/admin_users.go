@@ -32,7 +32,7 @@ func AdminCreateUser(c *models.ReqContext, form dtos.AdminCreateUserForm) respon
 		return response.Error(400, "Password is missing or too short", nil)
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, models.ErrOrgNotFound) {
 			return response.Error(400, err.Error(), nil)
 		}
@@ -79,7 +79,7 @@ func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPass
 		NewPassword: passwordHashed,
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return response.Error(500, "Failed to update user password", err)
 	}
 
@@ -95,7 +95,7 @@ func AdminUpdateUserPermissions(c *models.ReqContext, form dtos.AdminUpdateUserP
 		IsGrafanaAdmin: form.IsGrafanaAdmin,
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, models.ErrLastGrafanaAdmin) {
 			return response.Error(400, models.ErrLastGrafanaAdmin.Error(), nil)
 		}
@@ -111,7 +111,7 @@ func AdminDeleteUser(c *models.ReqContext) response.Response {
 
 	cmd := models.DeleteUserCommand{UserId: userID}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			return response.Error(404, models.ErrUserNotFound.Error(), nil)
 		}
@@ -132,14 +132,15 @@ func (hs *HTTPServer) AdminDisableUser(c *models.ReqContext) response.Response {
 	}
 
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: true}
-	if err := bus.Dispatch(&disableCmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &disableCmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			return response.Error(404, models.ErrUserNotFound.Error(), nil)
 		}
 		return response.Error(500, "Failed to disable user", err)
 	}
 
-	err := hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), userID)
+	ctx := models.WithUserTokenRevocation(c.Req.Context(), c.UserId, models.UserTokenRevokedByUserDisabled)
+	err := hs.AuthTokenService.RevokeAllUserTokens(ctx, userID)
 	if err != nil {
 		return response.Error(500, "Failed to disable user", err)
 	}
@@ -158,7 +159,7 @@ func AdminEnableUser(c *models.ReqContext) response.Response {
 	}
 
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: false}
-	if err := bus.Dispatch(&disableCmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &disableCmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			return response.Error(404, models.ErrUserNotFound.Error(), nil)
 		}
@@ -168,6 +169,21 @@ func AdminEnableUser(c *models.ReqContext) response.Response {
 	return response.Success("User enabled")
 }
 
+// POST /api/admin/users/:id/unlock
+func AdminUnlockUser(c *models.ReqContext) response.Response {
+	userID := c.ParamsInt64(":id")
+
+	unlockCmd := models.UnlockUserCommand{UserId: userID}
+	if err := bus.DispatchCtx(c.Req.Context(), &unlockCmd); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return response.Error(404, models.ErrUserNotFound.Error(), nil)
+		}
+		return response.Error(500, "Failed to unlock user", err)
+	}
+
+	return response.Success("User unlocked")
+}
+
 // POST /api/admin/users/:id/logout
 func (hs *HTTPServer) AdminLogoutUser(c *models.ReqContext) response.Response {
 	userID := c.ParamsInt64(":id")
@@ -176,7 +192,8 @@ func (hs *HTTPServer) AdminLogoutUser(c *models.ReqContext) response.Response {
 		return response.Error(400, "You cannot logout yourself", nil)
 	}
 
-	return hs.logoutUserFromAllDevicesInternal(c.Req.Context(), userID)
+	ctx := models.WithUserTokenRevocation(c.Req.Context(), c.UserId, models.UserTokenRevokedByAdmin)
+	return hs.logoutUserFromAllDevicesInternal(ctx, userID)
 }
 
 // GET /api/admin/users/:id/auth-tokens
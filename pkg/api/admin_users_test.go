@@ -100,6 +100,31 @@ func TestAdminAPIEndpoint(t *testing.T) {
 			})
 	})
 
+	t.Run("When a server admin unlocks a user", func(t *testing.T) {
+		adminUnlockUserScenario(t, "Should unlock the user on a POST request",
+			"/api/admin/users/42/unlock", "/api/admin/users/:id/unlock", func(sc *scenarioContext) {
+				var userID int64
+				bus.AddHandler("test", func(cmd *models.UnlockUserCommand) error {
+					userID = cmd.UserId
+					return nil
+				})
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, int64(42), userID)
+			})
+
+		adminUnlockUserScenario(t, "Should return user not found on a POST request",
+			"/api/admin/users/42/unlock", "/api/admin/users/:id/unlock", func(sc *scenarioContext) {
+				bus.AddHandler("test", func(cmd *models.UnlockUserCommand) error {
+					return models.ErrUserNotFound
+				})
+
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 404, sc.resp.Code)
+			})
+	})
+
 	t.Run("When a server admin attempts to enable/disable a nonexistent user", func(t *testing.T) {
 		adminDisableUserScenario(t, "Should return user not found on a POST request", "enable",
 			"/api/admin/users/42/enable", "/api/admin/users/:id/enable", func(sc *scenarioContext) {
@@ -484,6 +509,24 @@ func adminDisableUserScenario(t *testing.T, desc string, action string, url stri
 	})
 }
 
+func adminUnlockUserScenario(t *testing.T, desc string, url string, routePattern string, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+
+			return AdminUnlockUser(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func adminDeleteUserScenario(t *testing.T, desc string, url string, routePattern string, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		t.Cleanup(bus.ClearBusHandlers)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -186,6 +187,20 @@ func GetAlert(c *models.ReqContext) response.Response {
 	return response.JSON(200, &query.Result)
 }
 
+// GET /api/alerts/:id/evaluations
+func GetAlertEvaluations(c *models.ReqContext) response.Response {
+	query := models.GetAlertEvaluationsQuery{
+		AlertId: c.ParamsInt64(":alertId"),
+		OrgId:   c.OrgId,
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get alert evaluations", err)
+	}
+
+	return response.JSON(200, query.Result)
+}
+
 func GetAlertNotifiers(c *models.ReqContext) response.Response {
 	return response.JSON(200, alerting.GetNotifiers())
 }
@@ -275,6 +290,10 @@ func GetAlertNotificationByUID(c *models.ReqContext) response.Response {
 func CreateAlertNotification(c *models.ReqContext, cmd models.CreateAlertNotificationCommand) response.Response {
 	cmd.OrgId = c.OrgId
 
+	if _, err := alerting.ParseNotificationTemplates(cmd.Settings); err != nil {
+		return response.Error(422, err.Error(), nil)
+	}
+
 	if err := bus.Dispatch(&cmd); err != nil {
 		if errors.Is(err, models.ErrAlertNotificationWithSameNameExists) || errors.Is(err, models.ErrAlertNotificationWithSameUIDExists) {
 			return response.Error(409, "Failed to create alert notification", err)
@@ -288,6 +307,10 @@ func CreateAlertNotification(c *models.ReqContext, cmd models.CreateAlertNotific
 func UpdateAlertNotification(c *models.ReqContext, cmd models.UpdateAlertNotificationCommand) response.Response {
 	cmd.OrgId = c.OrgId
 
+	if _, err := alerting.ParseNotificationTemplates(cmd.Settings); err != nil {
+		return response.Error(422, err.Error(), nil)
+	}
+
 	err := fillWithSecureSettingsData(&cmd)
 	if err != nil {
 		return response.Error(500, "Failed to update alert notification", err)
@@ -316,6 +339,10 @@ func UpdateAlertNotificationByUID(c *models.ReqContext, cmd models.UpdateAlertNo
 	cmd.OrgId = c.OrgId
 	cmd.Uid = c.Params("uid")
 
+	if _, err := alerting.ParseNotificationTemplates(cmd.Settings); err != nil {
+		return response.Error(422, err.Error(), nil)
+	}
+
 	err := fillWithSecureSettingsDataByUID(&cmd)
 	if err != nil {
 		return response.Error(500, "Failed to update alert notification", err)
@@ -438,12 +465,94 @@ func NotificationTest(c *models.ReqContext, dto dtos.NotificationTestCommand) re
 		if errors.Is(err, models.ErrSmtpNotEnabled) {
 			return response.Error(412, err.Error(), err)
 		}
+		var validationErr alerting.ValidationError
+		if errors.As(err, &validationErr) {
+			return response.Error(422, validationErr.Error(), nil)
+		}
 		return response.Error(500, "Failed to send alert notifications", err)
 	}
 
 	return response.Success("Test notification sent")
 }
 
+// POST /api/alert-notifications/test/preview
+func NotificationPreview(c *models.ReqContext, dto dtos.NotificationTestCommand) response.Response {
+	cmd := &alerting.NotificationPreviewCommand{
+		Settings: dto.Settings,
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), cmd); err != nil {
+		var validationErr alerting.ValidationError
+		if errors.As(err, &validationErr) {
+			return response.Error(422, validationErr.Error(), nil)
+		}
+		return response.Error(500, "Failed to preview alert notification", err)
+	}
+
+	return response.JSON(200, cmd.Result)
+}
+
+// GET /api/alert-notifications/deliveries
+func GetAlertNotificationDeliveries(c *models.ReqContext) response.Response {
+	query := models.GetAlertNotificationDeliveriesQuery{
+		OrgId: c.OrgId,
+		State: models.AlertNotificationDeliveryState(c.Query("state")),
+		Limit: c.QueryInt("limit"),
+	}
+	if query.State != "" && !query.State.IsValid() {
+		return response.Error(400, "Invalid state, should be pending or dead", nil)
+	}
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get alert notification deliveries", err)
+	}
+
+	result := make([]*dtos.AlertNotificationDelivery, 0, len(query.Result))
+	for _, delivery := range query.Result {
+		result = append(result, dtos.NewAlertNotificationDelivery(delivery))
+	}
+
+	return response.JSON(200, result)
+}
+
+// POST /api/alert-notifications/deliveries/:deliveryId/replay
+func ReplayAlertNotificationDelivery(c *models.ReqContext) response.Response {
+	cmd := models.ReplayAlertNotificationDeliveryCommand{
+		Id:    c.ParamsInt64(":deliveryId"),
+		OrgId: c.OrgId,
+		Now:   time.Now(),
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrAlertNotificationDeliveryNotFound) {
+			return response.Error(404, "Dead alert notification delivery not found", nil)
+		}
+		return response.Error(500, "Failed to replay alert notification delivery", err)
+	}
+
+	return response.Success("Notification delivery queued for replay")
+}
+
+// DELETE /api/alert-notifications/deliveries/:deliveryId
+func DeleteAlertNotificationDelivery(c *models.ReqContext) response.Response {
+	cmd := models.DeleteAlertNotificationDeliveryCommand{
+		Id:    c.ParamsInt64(":deliveryId"),
+		OrgId: c.OrgId,
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrAlertNotificationDeliveryNotFound) {
+			return response.Error(404, err.Error(), nil)
+		}
+		return response.Error(500, "Failed to delete alert notification delivery", err)
+	}
+
+	return response.Success("Notification delivery deleted")
+}
+
 // POST /api/alerts/:alertId/pause
 func PauseAlert(c *models.ReqContext, dto dtos.PauseAlertCommand) response.Response {
 	alertID := c.ParamsInt64("alertId")
@@ -522,3 +631,17 @@ func PauseAllAlerts(c *models.ReqContext, dto dtos.PauseAllAlertsCommand) respon
 
 	return response.JSON(200, result)
 }
+
+// GetAlertingClusterState returns the live alerting nodes and the alert rules they own
+// GET /api/admin/alerting/cluster
+func (hs *HTTPServer) GetAlertingClusterState(c *models.ReqContext) response.Response {
+	state, err := hs.AlertEngine.ClusterState(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, alerting.ErrHADisabled) {
+			return response.Error(400, "Alerting HA is not enabled", err)
+		}
+		return response.Error(500, "Failed to get alerting cluster state", err)
+	}
+
+	return response.JSON(200, state)
+}
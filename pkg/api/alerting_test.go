@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -9,7 +11,9 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -141,6 +145,34 @@ func TestAlertingAPIEndpoint(t *testing.T) {
 			assert.Equal(t, "alertQuery", getAlertsQuery.Query)
 		})
 
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/alerts/1/evaluations",
+		"/api/alerts/:alertId/evaluations", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			setUp()
+
+			var evaluationsQuery *models.GetAlertEvaluationsQuery
+			bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertEvaluationsQuery) error {
+				evaluationsQuery = query
+				query.Result = []*models.AlertEvaluation{
+					{Id: 2, AlertId: 1, State: models.AlertStateAlerting, Created: 2000},
+					{Id: 1, AlertId: 1, State: models.AlertStateOK, Created: 1000},
+				}
+				return nil
+			})
+
+			sc.handlerFunc = GetAlertEvaluations
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, 200, sc.resp.Code)
+			require.NotNil(t, evaluationsQuery)
+			assert.Equal(t, int64(1), evaluationsQuery.AlertId)
+			assert.Equal(t, testOrgID, evaluationsQuery.OrgId)
+
+			var evaluations []*models.AlertEvaluation
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &evaluations))
+			require.Len(t, evaluations, 2)
+			assert.Equal(t, models.AlertStateAlerting, evaluations[0].State)
+		})
+
 	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/alert-notifications/1",
 		"/alert-notifications/:notificationId", models.ROLE_ADMIN, func(sc *scenarioContext) {
 			setUp()
@@ -149,6 +181,112 @@ func TestAlertingAPIEndpoint(t *testing.T) {
 			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
 			assert.Equal(t, 404, sc.resp.Code)
 		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/alert-notifications/deliveries?state=dead",
+		"/api/alert-notifications/deliveries", models.ROLE_EDITOR, func(sc *scenarioContext) {
+			setUp()
+
+			var deliveriesQuery *models.GetAlertNotificationDeliveriesQuery
+			bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationDeliveriesQuery) error {
+				deliveriesQuery = query
+				query.Result = []*models.AlertNotificationDelivery{
+					{Id: 1, AlertId: 2, NotifierUid: "slack", State: models.AlertNotificationDeliveryDead, Attempts: 5, LastError: "slack is down"},
+				}
+				return nil
+			})
+
+			sc.handlerFunc = GetAlertNotificationDeliveries
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"state": "dead"}).exec()
+
+			require.Equal(t, 200, sc.resp.Code)
+			require.NotNil(t, deliveriesQuery)
+			assert.Equal(t, testOrgID, deliveriesQuery.OrgId)
+			assert.Equal(t, models.AlertNotificationDeliveryDead, deliveriesQuery.State)
+			assert.Equal(t, 100, deliveriesQuery.Limit)
+
+			var deliveries []*dtos.AlertNotificationDelivery
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &deliveries))
+			require.Len(t, deliveries, 1)
+			assert.Equal(t, "slack is down", deliveries[0].LastError)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/alert-notifications/deliveries?state=sent",
+		"/api/alert-notifications/deliveries", models.ROLE_EDITOR, func(sc *scenarioContext) {
+			setUp()
+
+			sc.handlerFunc = GetAlertNotificationDeliveries
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"state": "sent"}).exec()
+
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE on", "DELETE", "/api/alert-notifications/deliveries/1",
+		"/api/alert-notifications/deliveries/:deliveryId", models.ROLE_EDITOR, func(sc *scenarioContext) {
+			setUp()
+
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.DeleteAlertNotificationDeliveryCommand) error {
+				return models.ErrAlertNotificationDeliveryNotFound
+			})
+
+			sc.handlerFunc = DeleteAlertNotificationDelivery
+			sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+			assert.Equal(t, 404, sc.resp.Code)
+		})
+}
+
+func TestNotificationPreviewAPIEndpoint(t *testing.T) {
+	settings := simplejson.NewFromAny(map[string]interface{}{"titleTemplate": "{{ .RuleName }}"})
+
+	notificationPreviewScenario(t, "When previewing valid templates", settings, func(sc *scenarioContext) {
+		var previewCmd *alerting.NotificationPreviewCommand
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *alerting.NotificationPreviewCommand) error {
+			previewCmd = cmd
+			cmd.Result = &alerting.NotificationPreview{Title: "Test notification", Message: "Someone is testing"}
+			return nil
+		})
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+		require.NotNil(t, previewCmd)
+		assert.Equal(t, settings, previewCmd.Settings)
+
+		var preview alerting.NotificationPreview
+		require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &preview))
+		assert.Equal(t, "Test notification", preview.Title)
+	})
+
+	notificationPreviewScenario(t, "When previewing invalid templates", settings, func(sc *scenarioContext) {
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *alerting.NotificationPreviewCommand) error {
+			return alerting.ValidationError{Reason: "invalid titleTemplate"}
+		})
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+		require.Equal(t, 422, sc.resp.Code)
+	})
+}
+
+func notificationPreviewScenario(t *testing.T, desc string, settings *simplejson.Json, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		defer bus.ClearBusHandlers()
+
+		url := "/api/alert-notifications/test/preview"
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+			sc.context.OrgId = testOrgID
+			sc.context.OrgRole = models.ROLE_EDITOR
+
+			return NotificationPreview(c, dtos.NotificationTestCommand{Type: "slack", Settings: settings})
+		})
+
+		sc.m.Post(url, sc.defaultHandler)
+
+		fn(sc)
+	})
 }
 
 func callPauseAlert(sc *scenarioContext) {
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+// SearchAuditLog returns the entries of the audit log
+// GET /api/admin/audit
+func (hs *HTTPServer) SearchAuditLog(c *models.ReqContext) response.Response {
+	query := &audit.SearchQuery{
+		OrgId:        c.QueryInt64("orgId"),
+		ActorId:      c.QueryInt64("actorId"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resourceType"),
+		ResourceUid:  c.Query("resourceUid"),
+		Page:         c.QueryInt("page"),
+		PerPage:      c.QueryInt("perpage"),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	result, err := hs.AuditService.Search(c.Req.Context(), query)
+	if err != nil {
+		if errors.Is(err, audit.ErrSearchNotAvailable) {
+			return response.Error(400, "Audit log search requires the sql sink to be enabled", err)
+		}
+		return response.Error(500, "Failed to search audit log", err)
+	}
+
+	return response.JSON(200, result)
+}
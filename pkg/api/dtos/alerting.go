@@ -90,6 +90,36 @@ type AlertNotificationLookup struct {
 	IsDefault bool   `json:"isDefault"`
 }
 
+func NewAlertNotificationDelivery(delivery *models.AlertNotificationDelivery) *AlertNotificationDelivery {
+	return &AlertNotificationDelivery{
+		Id:           delivery.Id,
+		AlertId:      delivery.AlertId,
+		NotifierId:   delivery.NotifierId,
+		NotifierUid:  delivery.NotifierUid,
+		NotifierType: delivery.NotifierType,
+		State:        delivery.State,
+		Attempts:     delivery.Attempts,
+		NextAttempt:  time.Unix(delivery.NextAttempt, 0),
+		LastError:    delivery.LastError,
+		Created:      time.Unix(delivery.Created, 0),
+		Updated:      time.Unix(delivery.Updated, 0),
+	}
+}
+
+type AlertNotificationDelivery struct {
+	Id           int64                                 `json:"id"`
+	AlertId      int64                                 `json:"alertId"`
+	NotifierId   int64                                 `json:"notifierId"`
+	NotifierUid  string                                `json:"notifierUid"`
+	NotifierType string                                `json:"notifierType"`
+	State        models.AlertNotificationDeliveryState `json:"state"`
+	Attempts     int                                   `json:"attempts"`
+	NextAttempt  time.Time                             `json:"nextAttempt"`
+	LastError    string                                `json:"lastError"`
+	Created      time.Time                             `json:"created"`
+	Updated      time.Time                             `json:"updated"`
+}
+
 type AlertTestCommand struct {
 	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
 	PanelId   int64            `json:"panelId" binding:"Required"`
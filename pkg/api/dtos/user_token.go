@@ -4,6 +4,8 @@ import "time"
 
 type UserToken struct {
 	Id                     int64     `json:"id"`
+	UserId                 int64     `json:"userId"`
+	Label                  string    `json:"label"`
 	IsActive               bool      `json:"isActive"`
 	ClientIp               string    `json:"clientIp"`
 	Device                 string    `json:"device"`
@@ -14,3 +16,13 @@ type UserToken struct {
 	CreatedAt              time.Time `json:"createdAt"`
 	SeenAt                 time.Time `json:"seenAt"`
 }
+
+// RevokeOrgAuthTokensForm selects the sessions of the users of the current
+// organization to revoke. SeenAfter and SeenBefore are epoch milliseconds,
+// IPRange is an IP address or a CIDR range.
+type RevokeOrgAuthTokensForm struct {
+	UserId     int64  `json:"userId"`
+	SeenAfter  int64  `json:"seenAfter"`
+	SeenBefore int64  `json:"seenBefore"`
+	IPRange    string `json:"ipRange"`
+}
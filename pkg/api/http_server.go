@@ -30,6 +30,8 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/hooks"
@@ -81,6 +83,8 @@ type HTTPServer struct {
 	ContextHandler       *contexthandler.ContextHandler     `inject:""`
 	SQLStore             *sqlstore.SQLStore                 `inject:""`
 	LibraryPanelService  *librarypanels.LibraryPanelService `inject:""`
+	AuditService         *audit.AuditService                `inject:""`
+	AlertEngine          *alerting.AlertEngine              `inject:""`
 	Listener             net.Listener
 }
 
@@ -344,6 +348,9 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m.Use(middleware.OrgRedirect(hs.Cfg))
 
 	// needs to be after context handler
+	if hs.Cfg.Audit.Enabled {
+		m.Use(audit.Middleware)
+	}
 	if setting.EnforceDomain {
 		m.Use(middleware.ValidateHostHeader(hs.Cfg))
 	}
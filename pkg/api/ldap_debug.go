@@ -199,7 +199,8 @@ func (hs *HTTPServer) PostSyncUserWithLDAP(c *models.ReqContext) response.Respon
 				return response.Error(http.StatusInternalServerError, "Failed to disable the user", err)
 			}
 
-			err = hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), userId)
+			ctx := models.WithUserTokenRevocation(c.Req.Context(), c.UserId, models.UserTokenRevokedByUserDisabled)
+			err = hs.AuthTokenService.RevokeAllUserTokens(ctx, userId)
 			if err != nil {
 				return response.Error(http.StatusInternalServerError, "Failed to remove session tokens for the user", err)
 			}
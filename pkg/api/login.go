@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/network"
@@ -204,9 +206,11 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext, cmd dtos.LoginCommand) res
 	err := bus.Dispatch(authQuery)
 	authModule = authQuery.AuthModule
 	if err != nil {
+		hs.publishUserLoginFailed(c, cmd.User, err)
+
 		resp = response.Error(401, "Invalid username or password", err)
-		if errors.Is(err, login.ErrInvalidCredentials) || errors.Is(err, login.ErrTooManyLoginAttempts) || errors.Is(err,
-			models.ErrUserNotFound) {
+		if errors.Is(err, login.ErrInvalidCredentials) || errors.Is(err, login.ErrTooManyLoginAttempts) ||
+			errors.Is(err, login.ErrUserLockedOut) || errors.Is(err, models.ErrUserNotFound) {
 			return resp
 		}
 
@@ -268,17 +272,41 @@ func (hs *HTTPServer) loginUserWithUser(user *models.User, c *models.ReqContext)
 	c.UserToken = userToken
 
 	hs.log.Info("Successful Login", "User", user.Email)
+	if err := bus.Publish(&events.UserLoggedIn{
+		Timestamp: time.Now(),
+		UserId:    user.Id,
+		Login:     user.Login,
+		ClientIp:  addr,
+		UserAgent: c.Req.UserAgent(),
+	}); err != nil {
+		hs.log.Error("Failed to publish user logged in event", "userId", user.Id, "error", err)
+	}
 	cookies.WriteSessionCookie(c, hs.Cfg, userToken.UnhashedToken, hs.Cfg.LoginMaxLifetime)
 	return nil
 }
 
+// publishUserLoginFailed publishes a UserLoginFailed event, which is recorded
+// by the audit log, whether brute-force login protection is enabled or not.
+func (hs *HTTPServer) publishUserLoginFailed(c *models.ReqContext, login string, loginErr error) {
+	if err := bus.Publish(&events.UserLoginFailed{
+		Timestamp: time.Now(),
+		Login:     login,
+		ClientIp:  c.RemoteAddr(),
+		UserAgent: c.Req.UserAgent(),
+		Error:     loginErr.Error(),
+	}); err != nil {
+		hs.log.Error("Failed to publish user login failed event", "login", login, "error", err)
+	}
+}
+
 func (hs *HTTPServer) Logout(c *models.ReqContext) {
 	if hs.Cfg.SAMLEnabled && hs.Cfg.SAMLSingleLogoutEnabled {
 		c.Redirect(setting.AppSubUrl + "/logout/saml")
 		return
 	}
 
-	err := hs.AuthTokenService.RevokeToken(c.Req.Context(), c.UserToken)
+	ctx := models.WithUserTokenRevocation(c.Req.Context(), c.UserId, models.UserTokenRevokedByLogout)
+	err := hs.AuthTokenService.RevokeToken(ctx, c.UserToken)
 	if err != nil && !errors.Is(err, models.ErrUserTokenNotFound) {
 		hs.log.Error("failed to revoke auth token", "error", err)
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/login"
 	"github.com/grafana/grafana/pkg/models"
@@ -690,10 +691,23 @@ func TestLoginPostRunLokingHook(t *testing.T) {
 				query.AuthModule = c.authModule
 				return c.authErr
 			})
+			var failed *events.UserLoginFailed
+			bus.AddEventListener(func(event *events.UserLoginFailed) error {
+				failed = event
+				return nil
+			})
 
 			sc.m.Post(sc.url, sc.defaultHandler)
 			sc.fakeReqNoAssertions("POST", sc.url).exec()
 
+			if c.authErr != nil {
+				require.NotNil(t, failed)
+				assert.Equal(t, "admin", failed.Login)
+				assert.Equal(t, c.authErr.Error(), failed.Error)
+			} else {
+				assert.Nil(t, failed)
+			}
+
 			info := testHook.info
 			assert.Equal(t, c.info.AuthModule, info.AuthModule)
 			assert.Equal(t, "admin", info.LoginUsername)
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -13,16 +14,16 @@ import (
 // POST /api/org/users
 func AddOrgUserToCurrentOrg(c *models.ReqContext, cmd models.AddOrgUserCommand) response.Response {
 	cmd.OrgId = c.OrgId
-	return addOrgUserHelper(cmd)
+	return addOrgUserHelper(c.Req.Context(), cmd)
 }
 
 // POST /api/orgs/:orgId/users
 func AddOrgUser(c *models.ReqContext, cmd models.AddOrgUserCommand) response.Response {
 	cmd.OrgId = c.ParamsInt64(":orgId")
-	return addOrgUserHelper(cmd)
+	return addOrgUserHelper(c.Req.Context(), cmd)
 }
 
-func addOrgUserHelper(cmd models.AddOrgUserCommand) response.Response {
+func addOrgUserHelper(ctx context.Context, cmd models.AddOrgUserCommand) response.Response {
 	if !cmd.Role.IsValid() {
 		return response.Error(400, "Invalid role specified", nil)
 	}
@@ -37,7 +38,7 @@ func addOrgUserHelper(cmd models.AddOrgUserCommand) response.Response {
 
 	cmd.UserId = userToAdd.Id
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(ctx, &cmd); err != nil {
 		if errors.Is(err, models.ErrOrgUserAlreadyAdded) {
 			return response.JSON(409, util.DynMap{
 				"message": "User is already member of this organization",
@@ -161,22 +162,22 @@ func (hs *HTTPServer) getOrgUsersHelper(query *models.GetOrgUsersQuery, signedIn
 func UpdateOrgUserForCurrentOrg(c *models.ReqContext, cmd models.UpdateOrgUserCommand) response.Response {
 	cmd.OrgId = c.OrgId
 	cmd.UserId = c.ParamsInt64(":userId")
-	return updateOrgUserHelper(cmd)
+	return updateOrgUserHelper(c.Req.Context(), cmd)
 }
 
 // PATCH /api/orgs/:orgId/users/:userId
 func UpdateOrgUser(c *models.ReqContext, cmd models.UpdateOrgUserCommand) response.Response {
 	cmd.OrgId = c.ParamsInt64(":orgId")
 	cmd.UserId = c.ParamsInt64(":userId")
-	return updateOrgUserHelper(cmd)
+	return updateOrgUserHelper(c.Req.Context(), cmd)
 }
 
-func updateOrgUserHelper(cmd models.UpdateOrgUserCommand) response.Response {
+func updateOrgUserHelper(ctx context.Context, cmd models.UpdateOrgUserCommand) response.Response {
 	if !cmd.Role.IsValid() {
 		return response.Error(400, "Invalid role specified", nil)
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(ctx, &cmd); err != nil {
 		if errors.Is(err, models.ErrLastOrgAdmin) {
 			return response.Error(400, "Cannot change role so that there is no organization admin left", nil)
 		}
@@ -188,7 +189,7 @@ func updateOrgUserHelper(cmd models.UpdateOrgUserCommand) response.Response {
 
 // DELETE /api/org/users/:userId
 func RemoveOrgUserForCurrentOrg(c *models.ReqContext) response.Response {
-	return removeOrgUserHelper(&models.RemoveOrgUserCommand{
+	return removeOrgUserHelper(c.Req.Context(), &models.RemoveOrgUserCommand{
 		UserId:                   c.ParamsInt64(":userId"),
 		OrgId:                    c.OrgId,
 		ShouldDeleteOrphanedUser: true,
@@ -197,14 +198,14 @@ func RemoveOrgUserForCurrentOrg(c *models.ReqContext) response.Response {
 
 // DELETE /api/orgs/:orgId/users/:userId
 func RemoveOrgUser(c *models.ReqContext) response.Response {
-	return removeOrgUserHelper(&models.RemoveOrgUserCommand{
+	return removeOrgUserHelper(c.Req.Context(), &models.RemoveOrgUserCommand{
 		UserId: c.ParamsInt64(":userId"),
 		OrgId:  c.ParamsInt64(":orgId"),
 	})
 }
 
-func removeOrgUserHelper(cmd *models.RemoveOrgUserCommand) response.Response {
-	if err := bus.Dispatch(cmd); err != nil {
+func removeOrgUserHelper(ctx context.Context, cmd *models.RemoveOrgUserCommand) response.Response {
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
 		if errors.Is(err, models.ErrLastOrgAdmin) {
 			return response.Error(400, "Cannot remove last organization admin", nil)
 		}
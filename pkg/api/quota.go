@@ -7,27 +7,32 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func GetOrgQuotas(c *models.ReqContext) response.Response {
+// GET /api/org/quotas and /api/orgs/:orgId/quotas
+func (hs *HTTPServer) GetOrgQuotas(c *models.ReqContext) response.Response {
 	if !setting.Quota.Enabled {
 		return response.Error(404, "Quotas not enabled", nil)
 	}
-	query := models.GetOrgQuotasQuery{OrgId: c.ParamsInt64(":orgId")}
+	orgID := c.ParamsInt64(":orgId")
+	if orgID == 0 {
+		orgID = c.OrgId
+	}
 
-	if err := bus.Dispatch(&query); err != nil {
+	quotas, err := hs.QuotaService.GetOrgQuotas(c.Req.Context(), orgID)
+	if err != nil {
 		return response.Error(500, "Failed to get org quotas", err)
 	}
 
-	return response.JSON(200, query.Result)
+	return response.JSON(200, quotas)
 }
 
-func UpdateOrgQuota(c *models.ReqContext, cmd models.UpdateOrgQuotaCmd) response.Response {
+func (hs *HTTPServer) UpdateOrgQuota(c *models.ReqContext, cmd models.UpdateOrgQuotaCmd) response.Response {
 	if !setting.Quota.Enabled {
 		return response.Error(404, "Quotas not enabled", nil)
 	}
 	cmd.OrgId = c.ParamsInt64(":orgId")
 	cmd.Target = c.Params(":target")
 
-	if _, ok := setting.Quota.Org.ToMap()[cmd.Target]; !ok {
+	if !hs.QuotaService.IsOrgQuotaTarget(cmd.Target) {
 		return response.Error(404, "Invalid quota target", nil)
 	}
 
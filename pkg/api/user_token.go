@@ -3,6 +3,9 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -58,53 +61,9 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 		return response.Error(500, "Failed to get user auth tokens", err)
 	}
 
-	result := []*dtos.UserToken{}
+	result := make([]*dtos.UserToken, 0, len(tokens))
 	for _, token := range tokens {
-		isActive := false
-		if c.UserToken != nil && c.UserToken.Id == token.Id {
-			isActive = true
-		}
-
-		parser := uaparser.NewFromSaved()
-		client := parser.Parse(token.UserAgent)
-
-		osVersion := ""
-		if client.Os.Major != "" {
-			osVersion = client.Os.Major
-
-			if client.Os.Minor != "" {
-				osVersion = osVersion + "." + client.Os.Minor
-			}
-		}
-
-		browserVersion := ""
-		if client.UserAgent.Major != "" {
-			browserVersion = client.UserAgent.Major
-
-			if client.UserAgent.Minor != "" {
-				browserVersion = browserVersion + "." + client.UserAgent.Minor
-			}
-		}
-
-		createdAt := time.Unix(token.CreatedAt, 0)
-		seenAt := time.Unix(token.SeenAt, 0)
-
-		if token.SeenAt == 0 {
-			seenAt = createdAt
-		}
-
-		result = append(result, &dtos.UserToken{
-			Id:                     token.Id,
-			IsActive:               isActive,
-			ClientIp:               token.ClientIp,
-			Device:                 client.Device.ToString(),
-			OperatingSystem:        client.Os.Family,
-			OperatingSystemVersion: osVersion,
-			Browser:                client.UserAgent.Family,
-			BrowserVersion:         browserVersion,
-			CreatedAt:              createdAt,
-			SeenAt:                 seenAt,
-		})
+		result = append(result, userTokenToDTO(c, token))
 	}
 
 	return response.JSON(200, result)
@@ -132,7 +91,13 @@ func (hs *HTTPServer) revokeUserAuthTokenInternal(c *models.ReqContext, userID i
 		return response.Error(400, "Cannot revoke active user auth token", nil)
 	}
 
-	err = hs.AuthTokenService.RevokeToken(c.Req.Context(), token)
+	reason := models.UserTokenRevokedByUser
+	if userID != c.UserId {
+		reason = models.UserTokenRevokedByAdmin
+	}
+	ctx := models.WithUserTokenRevocation(c.Req.Context(), c.UserId, reason)
+
+	err = hs.AuthTokenService.RevokeToken(ctx, token)
 	if err != nil {
 		if errors.Is(err, models.ErrUserTokenNotFound) {
 			return response.Error(404, "User auth token not found", err)
@@ -144,3 +109,179 @@ func (hs *HTTPServer) revokeUserAuthTokenInternal(c *models.ReqContext, userID i
 		"message": "User auth token revoked",
 	})
 }
+
+// PUT /api/user/auth-tokens/:authTokenId/label
+func (hs *HTTPServer) UpdateUserAuthTokenLabel(c *models.ReqContext, cmd models.UpdateAuthTokenLabelCmd) response.Response {
+	token, err := hs.AuthTokenService.GetUserToken(c.Req.Context(), c.UserId, c.ParamsInt64(":authTokenId"))
+	if err != nil {
+		if errors.Is(err, models.ErrUserTokenNotFound) {
+			return response.Error(404, "User auth token not found", err)
+		}
+		return response.Error(500, "Failed to get user auth token", err)
+	}
+
+	if err := hs.AuthTokenService.SetUserTokenLabel(c.Req.Context(), token, strings.TrimSpace(cmd.Label)); err != nil {
+		if errors.Is(err, models.ErrUserTokenNotFound) {
+			return response.Error(404, "User auth token not found", err)
+		}
+		return response.Error(500, "Failed to update user auth token", err)
+	}
+
+	return response.JSON(200, util.DynMap{
+		"message": "User auth token updated",
+	})
+}
+
+// GET /api/org/auth-tokens
+func (hs *HTTPServer) GetOrgAuthTokens(c *models.ReqContext) response.Response {
+	query, err := orgAuthTokensQuery(c, dtos.RevokeOrgAuthTokensForm{
+		UserId:     c.QueryInt64("userId"),
+		SeenAfter:  c.QueryInt64("seenAfter"),
+		SeenBefore: c.QueryInt64("seenBefore"),
+		IPRange:    c.Query("ipRange"),
+	})
+	if err != nil {
+		return response.Error(400, err.Error(), err)
+	}
+
+	query.Limit = c.QueryInt("limit")
+	if query.Limit <= 0 || query.Limit > maxOrgAuthTokens {
+		query.Limit = maxOrgAuthTokens
+	}
+
+	tokens, err := hs.AuthTokenService.SearchUserTokens(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(500, "Failed to get user auth tokens", err)
+	}
+
+	result := make([]*dtos.UserToken, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, userTokenToDTO(c, token))
+	}
+
+	return response.JSON(200, result)
+}
+
+// POST /api/org/auth-tokens/revoke
+func (hs *HTTPServer) RevokeOrgAuthTokens(c *models.ReqContext, form dtos.RevokeOrgAuthTokensForm) response.Response {
+	if form == (dtos.RevokeOrgAuthTokensForm{}) {
+		return response.Error(400, "At least one filter is required to revoke user auth tokens", nil)
+	}
+
+	query, err := orgAuthTokensQuery(c, form)
+	if err != nil {
+		return response.Error(400, err.Error(), err)
+	}
+	if c.UserToken != nil {
+		query.ExcludeTokens = []int64{c.UserToken.Id}
+	}
+
+	tokens, err := hs.AuthTokenService.RevokeUserTokens(c.Req.Context(), &models.RevokeUserTokensCmd{
+		Query:     *query,
+		RevokedBy: c.UserId,
+		Reason:    models.UserTokenRevokedByAdmin,
+	})
+	if err != nil {
+		return response.Error(500, "Failed to revoke user auth tokens", err)
+	}
+
+	return response.JSON(200, util.DynMap{
+		"message": "User auth tokens revoked",
+		"count":   len(tokens),
+	})
+}
+
+// maxOrgAuthTokens is the maximum number of sessions listed at once
+const maxOrgAuthTokens = 1000
+
+// orgAuthTokensQuery builds the query of the sessions of the users of
+// the current organization. Org admins can't manage the sessions of
+// Grafana server admins.
+func orgAuthTokensQuery(c *models.ReqContext, form dtos.RevokeOrgAuthTokensForm) (*models.SearchUserTokensQuery, error) {
+	query := &models.SearchUserTokensQuery{
+		OrgId:                c.OrgId,
+		UserId:               form.UserId,
+		ExcludeGrafanaAdmins: !c.IsGrafanaAdmin,
+	}
+
+	if form.SeenAfter > 0 {
+		query.SeenAfter = time.Unix(0, form.SeenAfter*int64(time.Millisecond))
+	}
+	if form.SeenBefore > 0 {
+		query.SeenBefore = time.Unix(0, form.SeenBefore*int64(time.Millisecond))
+	}
+
+	if form.IPRange != "" {
+		ipRange, err := parseIPRange(form.IPRange)
+		if err != nil {
+			return nil, err
+		}
+		query.IPRange = ipRange
+	}
+
+	return query, nil
+}
+
+// parseIPRange parses a CIDR range or a single IP address
+func parseIPRange(value string) (*net.IPNet, error) {
+	if _, ipRange, err := net.ParseCIDR(value); err == nil {
+		return ipRange, nil
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP range %q", value)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func userTokenToDTO(c *models.ReqContext, token *models.UserToken) *dtos.UserToken {
+	isActive := c.UserToken != nil && c.UserToken.Id == token.Id
+
+	parser := uaparser.NewFromSaved()
+	client := parser.Parse(token.UserAgent)
+
+	osVersion := ""
+	if client.Os.Major != "" {
+		osVersion = client.Os.Major
+
+		if client.Os.Minor != "" {
+			osVersion = osVersion + "." + client.Os.Minor
+		}
+	}
+
+	browserVersion := ""
+	if client.UserAgent.Major != "" {
+		browserVersion = client.UserAgent.Major
+
+		if client.UserAgent.Minor != "" {
+			browserVersion = browserVersion + "." + client.UserAgent.Minor
+		}
+	}
+
+	createdAt := time.Unix(token.CreatedAt, 0)
+	seenAt := time.Unix(token.SeenAt, 0)
+
+	if token.SeenAt == 0 {
+		seenAt = createdAt
+	}
+
+	return &dtos.UserToken{
+		Id:                     token.Id,
+		UserId:                 token.UserId,
+		Label:                  token.Label,
+		IsActive:               isActive,
+		ClientIp:               token.ClientIp,
+		Device:                 client.Device.ToString(),
+		OperatingSystem:        client.Os.Family,
+		OperatingSystemVersion: osVersion,
+		Browser:                client.UserAgent.Family,
+		BrowserVersion:         browserVersion,
+		CreatedAt:              createdAt,
+		SeenAt:                 seenAt,
+	}
+}
@@ -3,15 +3,18 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserTokenAPIEndpoint(t *testing.T) {
@@ -83,8 +86,14 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 			sc.userAuthTokenService.GetUserTokenProvider = func(ctx context.Context, userId, userTokenId int64) (*models.UserToken, error) {
 				return &models.UserToken{Id: 2}, nil
 			}
+			var revocation models.UserTokenRevocation
+			sc.userAuthTokenService.RevokeTokenProvider = func(ctx context.Context, token *models.UserToken) error {
+				revocation, _ = models.UserTokenRevocationFromContext(ctx)
+				return nil
+			}
 			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
 			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, models.UserTokenRevocation{RevokedBy: testUserID, Reason: models.UserTokenRevokedByAdmin}, revocation)
 		})
 	})
 
@@ -309,3 +318,74 @@ func getUserAuthTokensInternalScenario(t *testing.T, desc string, token *models.
 		fn(sc)
 	})
 }
+
+func TestOrgAuthTokensAPIEndpoint(t *testing.T) {
+	revokeOrgAuthTokensScenario(t, "Should require a filter to revoke org auth tokens", dtos.RevokeOrgAuthTokensForm{},
+		func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+
+	revokeOrgAuthTokensScenario(t, "Should reject an invalid IP range", dtos.RevokeOrgAuthTokensForm{IPRange: "10.0.0.0/33"},
+		func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+
+	revokeOrgAuthTokensScenario(t, "Should revoke the org auth tokens matching the filter", dtos.RevokeOrgAuthTokensForm{
+		UserId:    3,
+		SeenAfter: 1600000000000,
+		IPRange:   "10.0.0.1",
+	}, func(sc *scenarioContext) {
+		var revokeCmd *models.RevokeUserTokensCmd
+		sc.userAuthTokenService.RevokeUserTokensProvider = func(ctx context.Context, cmd *models.RevokeUserTokensCmd) ([]*models.UserToken, error) {
+			revokeCmd = cmd
+			return []*models.UserToken{{Id: 4, UserId: 3}}, nil
+		}
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+		assert.Equal(t, 1, sc.ToJSON().Get("count").MustInt())
+
+		require.NotNil(t, revokeCmd)
+		assert.Equal(t, testUserID, revokeCmd.RevokedBy)
+		assert.Equal(t, models.UserTokenRevokedByAdmin, revokeCmd.Reason)
+		assert.Equal(t, testOrgID, revokeCmd.Query.OrgId)
+		assert.Equal(t, int64(3), revokeCmd.Query.UserId)
+		assert.Equal(t, int64(1600000000), revokeCmd.Query.SeenAfter.Unix())
+		assert.True(t, revokeCmd.Query.SeenBefore.IsZero())
+		assert.Equal(t, []int64{2}, revokeCmd.Query.ExcludeTokens)
+		assert.True(t, revokeCmd.Query.ExcludeGrafanaAdmins)
+		assert.True(t, revokeCmd.Query.IPRange.Contains(net.ParseIP("10.0.0.1")))
+		assert.False(t, revokeCmd.Query.IPRange.Contains(net.ParseIP("10.0.0.2")))
+	})
+}
+
+func revokeOrgAuthTokensScenario(t *testing.T, desc string, form dtos.RevokeOrgAuthTokensForm, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		fakeAuthTokenService := auth.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			Bus:              bus.GetBus(),
+			AuthTokenService: fakeAuthTokenService,
+		}
+
+		sc := setupScenarioContext(t, "/api/org/auth-tokens/revoke")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+			sc.context.OrgId = testOrgID
+			sc.context.OrgRole = models.ROLE_ADMIN
+			sc.context.UserToken = &models.UserToken{Id: 2, UserId: testUserID}
+
+			return hs.RevokeOrgAuthTokens(c, form)
+		})
+
+		sc.m.Post(sc.url, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
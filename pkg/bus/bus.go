@@ -18,6 +18,10 @@ type Msg interface{}
 // ErrHandlerNotFound defines an error if a handler is not found
 var ErrHandlerNotFound = errors.New("handler not found")
 
+// DispatchHook is called before a message is dispatched to its handler. The
+// returned function, if not nil, is called with the error returned by the handler.
+type DispatchHook func(ctx context.Context, msg Msg) func(err error)
+
 // TransactionManager defines a transaction interface
 type TransactionManager interface {
 	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
@@ -38,6 +42,7 @@ type Bus interface {
 	AddHandler(handler HandlerFunc)
 	AddHandlerCtx(handler HandlerFunc)
 	AddEventListener(handler HandlerFunc)
+	AddDispatchHook(hook DispatchHook)
 
 	// SetTransactionManager allows the user to replace the internal
 	// noop TransactionManager that is responsible for managing
@@ -55,6 +60,7 @@ type InProcBus struct {
 	handlers        map[string]HandlerFunc
 	handlersWithCtx map[string]HandlerFunc
 	listeners       map[string][]HandlerFunc
+	hooks           []DispatchHook
 	txMng           TransactionManager
 }
 
@@ -83,6 +89,7 @@ func (b *InProcBus) SetTransactionManager(tm TransactionManager) {
 }
 
 // DispatchCtx function dispatch a message to the bus context.
+// Handlers without context are used if no handler with context is registered.
 func (b *InProcBus) DispatchCtx(ctx context.Context, msg Msg) error {
 	var msgName = reflect.TypeOf(msg).Elem().Name()
 
@@ -91,21 +98,23 @@ func (b *InProcBus) DispatchCtx(ctx context.Context, msg Msg) error {
 
 	span.SetTag("msg", msgName)
 
-	var handler = b.handlersWithCtx[msgName]
+	withCtx := true
+	handler := b.handlersWithCtx[msgName]
 	if handler == nil {
-		return ErrHandlerNotFound
+		withCtx = false
+		handler = b.handlers[msgName]
+		if handler == nil {
+			return ErrHandlerNotFound
+		}
 	}
 
 	var params = []reflect.Value{}
-	params = append(params, reflect.ValueOf(ctx))
+	if withCtx {
+		params = append(params, reflect.ValueOf(ctx))
+	}
 	params = append(params, reflect.ValueOf(msg))
 
-	ret := reflect.ValueOf(handler).Call(params)
-	err := ret[0].Interface()
-	if err == nil {
-		return nil
-	}
-	return err.(error)
+	return b.callHandler(ctx, handler, msg, params)
 }
 
 // Dispatch function dispatch a message to the bus.
@@ -128,12 +137,29 @@ func (b *InProcBus) Dispatch(msg Msg) error {
 	}
 	params = append(params, reflect.ValueOf(msg))
 
+	return b.callHandler(context.Background(), handler, msg, params)
+}
+
+// callHandler calls the handler of the message between the dispatch hooks
+func (b *InProcBus) callHandler(ctx context.Context, handler HandlerFunc, msg Msg, params []reflect.Value) error {
+	var done []func(err error)
+	for _, hook := range b.hooks {
+		if fn := hook(ctx, msg); fn != nil {
+			done = append(done, fn)
+		}
+	}
+
+	var err error
 	ret := reflect.ValueOf(handler).Call(params)
-	err := ret[0].Interface()
-	if err == nil {
-		return nil
+	if e := ret[0].Interface(); e != nil {
+		err = e.(error)
+	}
+
+	for _, fn := range done {
+		fn(err)
 	}
-	return err.(error)
+
+	return err
 }
 
 // Publish function publish a message to the bus listener.
@@ -181,6 +207,11 @@ func (b *InProcBus) AddEventListener(handler HandlerFunc) {
 	b.listeners[eventName] = append(b.listeners[eventName], handler)
 }
 
+// AddDispatchHook adds a hook called around the handler of every dispatched message.
+func (b *InProcBus) AddDispatchHook(hook DispatchHook) {
+	b.hooks = append(b.hooks, hook)
+}
+
 // AddHandler attaches a handler function to the global bus.
 // Package level function.
 func AddHandler(implName string, handler HandlerFunc) {
@@ -199,6 +230,12 @@ func AddEventListener(handler HandlerFunc) {
 	globalBus.AddEventListener(handler)
 }
 
+// AddDispatchHook adds a dispatch hook to the global bus.
+// Package level function.
+func AddDispatchHook(hook DispatchHook) {
+	globalBus.AddDispatchHook(hook)
+}
+
 func Dispatch(msg Msg) error {
 	return globalBus.Dispatch(msg)
 }
@@ -3,6 +3,7 @@ package bus
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -77,6 +78,60 @@ func TestDispatchCtx_NoRegisteredHandler(t *testing.T) {
 		"expected bus to return HandlerNotFound since no handler is registered")
 }
 
+func TestDispatchCtx_HandlerWithoutContext(t *testing.T) {
+	bus := New()
+
+	var invoked bool
+
+	bus.AddHandler(func(query *testQuery) error {
+		invoked = true
+		return nil
+	})
+
+	err := bus.DispatchCtx(context.Background(), &testQuery{})
+	require.NoError(t, err)
+
+	require.True(t, invoked, "expected handler to be called")
+}
+
+func TestDispatchHook(t *testing.T) {
+	bus := New()
+
+	type ctxKey struct{}
+	handlerErr := errors.New("handler error")
+
+	bus.AddHandler(func(query *testQuery) error {
+		query.Resp = "handled"
+		return handlerErr
+	})
+
+	var calls []string
+	bus.AddDispatchHook(func(ctx context.Context, msg Msg) func(err error) {
+		query := msg.(*testQuery)
+		calls = append(calls, "before:"+query.Resp+":"+fmt.Sprint(ctx.Value(ctxKey{})))
+
+		return func(err error) {
+			require.Equal(t, handlerErr, err)
+			calls = append(calls, "after:"+query.Resp)
+		}
+	})
+	bus.AddDispatchHook(func(ctx context.Context, msg Msg) func(err error) {
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	err := bus.DispatchCtx(ctx, &testQuery{})
+	require.Equal(t, handlerErr, err)
+
+	err = bus.Dispatch(&testQuery{})
+	require.Equal(t, handlerErr, err)
+
+	require.Equal(t, []string{
+		"before::value", "after:handled",
+		"before::<nil>", "after:handled",
+	}, calls)
+}
+
 func TestQuery(t *testing.T) {
 	bus := New()
 
@@ -130,6 +130,24 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "secrets",
+		Usage: "Manage the encryption of the secrets stored in your db",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "rotate",
+				Usage:  "Re-encrypts all the secrets with the current encryption key. Safe to execute multiple times.",
+				Action: runDbCommand(rotateSecretsCommand),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "batch-size",
+						Usage: "Number of rows re-encrypted per transaction",
+						Value: defaultRotateSecretsBatchSize,
+					},
+				},
+			},
+		},
+	},
 }
 
 var Commands = []*cli.Command{
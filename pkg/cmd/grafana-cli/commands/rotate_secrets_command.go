@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/components/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const defaultRotateSecretsBatchSize = 100
+
+func rotateSecretsCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	batchSize := c.Int("batch-size")
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size should be positive")
+	}
+
+	provider, err := secrets.CurrentKeyProvider()
+	if err != nil {
+		return fmt.Errorf("failed to load the encryption keys: %w", err)
+	}
+	logger.Infof("Re-encrypting secrets with encryption key %q\n", provider.CurrentKeyID())
+
+	result, err := sqlStore.ReencryptSecrets(context.Background(), int64(batchSize), func(r sqlstore.ReencryptedSecrets) {
+		logger.Debugf("%s.%s: %d rows re-encrypted\n", r.Table, r.Column, r.Rows)
+	})
+
+	logger.Info("\n")
+	for _, r := range result {
+		logger.Infof("%s Re-encrypted %s.%s for %d rows\n", color.GreenString("✔"), r.Table, r.Column, r.Rows)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("\n")
+	logger.Infof("Secrets re-encrypted successfully %s", color.GreenString("✔"))
+	return nil
+}
@@ -0,0 +1,172 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// envelopePrefix starts the payloads of envelope encrypted secrets. Legacy
+// payloads start with an alphanumeric salt, so they never start with it.
+const envelopePrefix = '*'
+
+var errInvalidEnvelope = errors.New("invalid envelope encrypted payload")
+
+// envelope is a secret encrypted with a random data key, which is itself
+// encrypted (wrapped) with a key encryption key.
+//
+// It's encoded as:
+//
+//	*<key id>*<wrapped data key length: uint16><wrapped data key><ciphertext>
+//
+// where both the wrapped data key and the ciphertext are AES-GCM sealed and
+// prefixed with their nonce.
+type envelope struct {
+	keyID      string
+	wrappedKey []byte
+	ciphertext []byte
+}
+
+func isEnvelope(payload []byte) bool {
+	return len(payload) > 0 && payload[0] == envelopePrefix
+}
+
+func (e *envelope) encode() []byte {
+	buf := make([]byte, 0, len(e.keyID)+4+len(e.wrappedKey)+len(e.ciphertext))
+	buf = append(buf, envelopePrefix)
+	buf = append(buf, e.keyID...)
+	buf = append(buf, envelopePrefix)
+	buf = append(buf, byte(len(e.wrappedKey)>>8), byte(len(e.wrappedKey)))
+	buf = append(buf, e.wrappedKey...)
+	return append(buf, e.ciphertext...)
+}
+
+func decodeEnvelope(payload []byte) (*envelope, error) {
+	if !isEnvelope(payload) {
+		return nil, errInvalidEnvelope
+	}
+
+	payload = payload[1:]
+	end := bytes.IndexByte(payload, envelopePrefix)
+	if end < 1 {
+		return nil, errInvalidEnvelope
+	}
+	keyID := string(payload[:end])
+	payload = payload[end+1:]
+
+	if len(payload) < 2 {
+		return nil, errInvalidEnvelope
+	}
+	wrappedKeyLength := int(binary.BigEndian.Uint16(payload))
+	payload = payload[2:]
+	if len(payload) < wrappedKeyLength {
+		return nil, errInvalidEnvelope
+	}
+
+	return &envelope{
+		keyID:      keyID,
+		wrappedKey: payload[:wrappedKeyLength],
+		ciphertext: payload[wrappedKeyLength:],
+	}, nil
+}
+
+// seal encrypts the plaintext with a new random data key wrapped with the
+// current key of the provider.
+func seal(provider KeyProvider, plaintext []byte) (*envelope, error) {
+	dataKey := make([]byte, keyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := gcmSeal(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &envelope{ciphertext: ciphertext}
+	if err := e.wrap(provider, dataKey); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// open decrypts the ciphertext of the envelope.
+func (e *envelope) open(provider KeyProvider) ([]byte, error) {
+	dataKey, err := e.unwrap(provider)
+	if err != nil {
+		return nil, err
+	}
+	return gcmOpen(dataKey, e.ciphertext)
+}
+
+// wrap encrypts the data key with the current key of the provider.
+func (e *envelope) wrap(provider KeyProvider, dataKey []byte) error {
+	keyID := provider.CurrentKeyID()
+	if keyID == "" || bytes.IndexByte([]byte(keyID), envelopePrefix) >= 0 {
+		return fmt.Errorf("invalid encryption key id %q", keyID)
+	}
+
+	kek, err := provider.Key(keyID)
+	if err != nil {
+		return err
+	}
+	wrappedKey, err := gcmSeal(kek, dataKey)
+	if err != nil {
+		return err
+	}
+
+	e.keyID = keyID
+	e.wrappedKey = wrappedKey
+	return nil
+}
+
+// unwrap decrypts the data key of the envelope.
+func (e *envelope) unwrap(provider KeyProvider) ([]byte, error) {
+	kek, err := provider.Key(e.keyID)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := gcmOpen(kek, e.wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key with encryption key %q: %w", e.keyID, err)
+	}
+	return dataKey, nil
+}
+
+func gcmSeal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func gcmOpen(key, payload []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < gcm.NonceSize() {
+		return nil, errInvalidEnvelope
+	}
+	return gcm.Open(nil, payload[:gcm.NonceSize()], payload[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
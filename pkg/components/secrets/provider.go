@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// DefaultKeyID is the ID of the key encryption key derived from the
+// secret_key setting.
+const DefaultKeyID = "default"
+
+const keyLength = 32
+
+// KeyProvider provides the key encryption keys that wrap the data keys of
+// the secrets. Every key has an ID that is stored alongside the ciphertext,
+// so that secrets encrypted with older keys can still be decrypted after the
+// current key has been rotated.
+type KeyProvider interface {
+	// CurrentKeyID returns the ID of the key used to encrypt new secrets.
+	CurrentKeyID() string
+	// Key returns the 32 bytes key encryption key with the given ID.
+	Key(id string) ([]byte, error)
+}
+
+type staticKeyProvider struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewStaticKeyProvider returns a key provider for the given secrets by key ID.
+// The key encryption keys are derived from the secrets.
+func NewStaticKeyProvider(currentID string, secrets map[string]string) (KeyProvider, error) {
+	if _, ok := secrets[currentID]; !ok {
+		return nil, fmt.Errorf("encryption key %q not found", currentID)
+	}
+
+	keys := make(map[string][]byte, len(secrets))
+	for id, secret := range secrets {
+		if secret == "" {
+			return nil, fmt.Errorf("encryption key %q is empty", id)
+		}
+		keys[id] = deriveKey(secret, id)
+	}
+	return &staticKeyProvider{currentID: currentID, keys: keys}, nil
+}
+
+// NewSecretKeyProvider returns a key provider with a single key derived from
+// the secret_key setting.
+func NewSecretKeyProvider(secretKey string) KeyProvider {
+	return &staticKeyProvider{
+		currentID: DefaultKeyID,
+		keys:      map[string][]byte{DefaultKeyID: deriveKey(secretKey, DefaultKeyID)},
+	}
+}
+
+// NewFileKeyProvider returns a key provider for the keys of a file with one
+// "<id> = <secret>" line per key. Empty lines and lines starting with # are
+// ignored.
+func NewFileKeyProvider(path string, currentID string) (KeyProvider, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes from the configuration.
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open encryption keys file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	secrets := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid encryption key on line %d of %s", lineNumber, path)
+		}
+		secrets[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read encryption keys file: %w", err)
+	}
+
+	return NewStaticKeyProvider(currentID, secrets)
+}
+
+// NewEnvKeyProvider returns a key provider for the keys set in environment
+// variables named GF_ENCRYPTION_KEY_<ID>, where the key ID is case insensitive.
+// environ is a list of "key=value" strings as returned by os.Environ.
+func NewEnvKeyProvider(environ []string, currentID string) (KeyProvider, error) {
+	const prefix = "GF_ENCRYPTION_KEY_"
+
+	secrets := map[string]string{}
+	for _, env := range environ {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], prefix) {
+			continue
+		}
+		secrets[strings.ToLower(strings.TrimPrefix(parts[0], prefix))] = parts[1]
+	}
+
+	return NewStaticKeyProvider(strings.ToLower(currentID), secrets)
+}
+
+func (p *staticKeyProvider) CurrentKeyID() string {
+	return p.currentID
+}
+
+func (p *staticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q not found", id)
+	}
+	return key, nil
+}
+
+// fallbackKeyProvider looks up the keys that are missing from the primary
+// provider in the fallback provider, so that secrets encrypted before the
+// key provider was changed can still be decrypted.
+type fallbackKeyProvider struct {
+	KeyProvider
+	fallback KeyProvider
+}
+
+func (p *fallbackKeyProvider) Key(id string) ([]byte, error) {
+	key, err := p.KeyProvider.Key(id)
+	if err != nil {
+		if fallbackKey, fallbackErr := p.fallback.Key(id); fallbackErr == nil {
+			return fallbackKey, nil
+		}
+	}
+	return key, err
+}
+
+func deriveKey(secret, id string) []byte {
+	return pbkdf2.Key([]byte(secret), []byte(id), 10000, keyLength, sha256.New)
+}
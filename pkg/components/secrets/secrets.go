@@ -0,0 +1,178 @@
+// Package secrets implements the envelope encryption of the secrets stored
+// in the database.
+//
+// Every secret is encrypted with its own random data key, which is wrapped
+// with a key encryption key of the configured KeyProvider. The ID of the key
+// encryption key is stored alongside the ciphertext, so the key encryption key
+// can be rotated without losing access to the existing secrets, and the
+// secrets can be re-encrypted with the new key by only re-wrapping their data
+// keys. Secrets encrypted with util.Encrypt and the secret_key setting, as
+// done by older versions, can still be decrypted.
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type providerConfig struct {
+	provider  string
+	keysFile  string
+	keyID     string
+	secretKey string
+}
+
+var (
+	mu             sync.Mutex
+	customProvider KeyProvider
+	cachedProvider KeyProvider
+	cachedConfig   providerConfig
+)
+
+// SetKeyProvider overrides the key provider configured in the [security]
+// section of the settings. Passing nil restores the configured provider.
+func SetKeyProvider(provider KeyProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	customProvider = provider
+}
+
+// CurrentKeyProvider returns the key provider used to encrypt and decrypt
+// the secrets.
+func CurrentKeyProvider() (KeyProvider, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if customProvider != nil {
+		return customProvider, nil
+	}
+
+	cfg := providerConfig{
+		provider:  setting.EncryptionProvider,
+		keysFile:  setting.EncryptionKeysFile,
+		keyID:     setting.EncryptionKeyID,
+		secretKey: setting.SecretKey,
+	}
+	if cachedProvider != nil && cachedConfig == cfg {
+		return cachedProvider, nil
+	}
+
+	provider, err := newKeyProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cachedProvider, cachedConfig = provider, cfg
+	return provider, nil
+}
+
+func newKeyProvider(cfg providerConfig) (KeyProvider, error) {
+	secretKeyProvider := NewSecretKeyProvider(cfg.secretKey)
+
+	var provider KeyProvider
+	var err error
+	switch cfg.provider {
+	case "", "secret_key":
+		return secretKeyProvider, nil
+	case "file":
+		provider, err = NewFileKeyProvider(cfg.keysFile, cfg.keyID)
+	case "env":
+		provider, err = NewEnvKeyProvider(os.Environ(), cfg.keyID)
+	default:
+		return nil, fmt.Errorf("unknown encryption provider %q", cfg.provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &fallbackKeyProvider{KeyProvider: provider, fallback: secretKeyProvider}, nil
+}
+
+// Encrypt encrypts the payload with a new data key wrapped with the current
+// key encryption key.
+func Encrypt(payload []byte) ([]byte, error) {
+	provider, err := CurrentKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	return EncryptWithProvider(provider, payload)
+}
+
+// EncryptWithProvider encrypts the payload with a new data key wrapped with
+// the current key encryption key of the provider.
+func EncryptWithProvider(provider KeyProvider, payload []byte) ([]byte, error) {
+	e, err := seal(provider, payload)
+	if err != nil {
+		return nil, err
+	}
+	return e.encode(), nil
+}
+
+// Decrypt decrypts a payload encrypted by Encrypt, or by util.Encrypt with
+// the secret_key setting.
+func Decrypt(payload []byte) ([]byte, error) {
+	provider, err := CurrentKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	return DecryptWithProvider(provider, payload)
+}
+
+// DecryptWithProvider decrypts a payload encrypted by EncryptWithProvider, or
+// by util.Encrypt with the secret_key setting.
+func DecryptWithProvider(provider KeyProvider, payload []byte) ([]byte, error) {
+	if !isEnvelope(payload) {
+		return util.Decrypt(payload, setting.SecretKey)
+	}
+
+	e, err := decodeEnvelope(payload)
+	if err != nil {
+		return nil, err
+	}
+	return e.open(provider)
+}
+
+// Reencrypt returns the payload encrypted with the current key encryption
+// key. The data keys of envelope encrypted payloads are re-wrapped, while
+// legacy payloads are encrypted again. The returned bool is false if the
+// payload is already encrypted with the current key.
+func Reencrypt(payload []byte) ([]byte, bool, error) {
+	provider, err := CurrentKeyProvider()
+	if err != nil {
+		return nil, false, err
+	}
+	return ReencryptWithProvider(provider, payload)
+}
+
+// ReencryptWithProvider is like Reencrypt, using the given key provider.
+func ReencryptWithProvider(provider KeyProvider, payload []byte) ([]byte, bool, error) {
+	if !isEnvelope(payload) {
+		decrypted, err := util.Decrypt(payload, setting.SecretKey)
+		if err != nil {
+			return nil, false, err
+		}
+		encrypted, err := EncryptWithProvider(provider, decrypted)
+		return encrypted, err == nil, err
+	}
+
+	e, err := decodeEnvelope(payload)
+	if err != nil {
+		return nil, false, err
+	}
+	if e.keyID == provider.CurrentKeyID() {
+		return payload, false, nil
+	}
+
+	dataKey, err := e.unwrap(provider)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := e.wrap(provider, dataKey); err != nil {
+		return nil, false, err
+	}
+	return e.encode(), true, nil
+}
@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeEncryption(t *testing.T) {
+	provider, err := NewStaticKeyProvider("v1", map[string]string{"v1": "first secret"})
+	require.NoError(t, err)
+
+	t.Run("encrypted payloads can be decrypted", func(t *testing.T) {
+		encrypted, err := EncryptWithProvider(provider, []byte("grafana"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(encrypted), "grafana")
+
+		decrypted, err := DecryptWithProvider(provider, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("every payload has its own data key", func(t *testing.T) {
+		first, err := EncryptWithProvider(provider, []byte("grafana"))
+		require.NoError(t, err)
+		second, err := EncryptWithProvider(provider, []byte("grafana"))
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("tampered payloads are not decrypted", func(t *testing.T) {
+		encrypted, err := EncryptWithProvider(provider, []byte("grafana"))
+		require.NoError(t, err)
+		encrypted[len(encrypted)-1] ^= 1
+
+		_, err = DecryptWithProvider(provider, encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("payloads encrypted with unknown keys are not decrypted", func(t *testing.T) {
+		other, err := NewStaticKeyProvider("v9", map[string]string{"v9": "other secret"})
+		require.NoError(t, err)
+		encrypted, err := EncryptWithProvider(other, []byte("grafana"))
+		require.NoError(t, err)
+
+		_, err = DecryptWithProvider(provider, encrypted)
+		require.EqualError(t, err, `encryption key "v9" not found`)
+	})
+
+	t.Run("legacy payloads are decrypted with the secret key", func(t *testing.T) {
+		encrypted, err := util.Encrypt([]byte("grafana"), setting.SecretKey)
+		require.NoError(t, err)
+
+		decrypted, err := DecryptWithProvider(provider, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+}
+
+func TestReencrypt(t *testing.T) {
+	v1, err := NewStaticKeyProvider("v1", map[string]string{"v1": "first secret"})
+	require.NoError(t, err)
+	v2, err := NewStaticKeyProvider("v2", map[string]string{"v1": "first secret", "v2": "second secret"})
+	require.NoError(t, err)
+	v2Only, err := NewStaticKeyProvider("v2", map[string]string{"v2": "second secret"})
+	require.NoError(t, err)
+
+	t.Run("data keys are wrapped with the current key", func(t *testing.T) {
+		encrypted, err := EncryptWithProvider(v1, []byte("grafana"))
+		require.NoError(t, err)
+
+		reencrypted, changed, err := ReencryptWithProvider(v2, encrypted)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		decrypted, err := DecryptWithProvider(v2Only, reencrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+
+		_, changed, err = ReencryptWithProvider(v2, reencrypted)
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("legacy payloads are encrypted with the current key", func(t *testing.T) {
+		encrypted, err := util.Encrypt([]byte("grafana"), setting.SecretKey)
+		require.NoError(t, err)
+
+		reencrypted, changed, err := ReencryptWithProvider(v2, encrypted)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.True(t, isEnvelope(reencrypted))
+
+		decrypted, err := DecryptWithProvider(v2Only, reencrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+}
+
+func TestKeyProviders(t *testing.T) {
+	t.Run("file key provider", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys")
+		content := "# encryption keys\nv1 = first secret\n\nv2=second secret\n"
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+		provider, err := NewFileKeyProvider(path, "v2")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", provider.CurrentKeyID())
+
+		key, err := provider.Key("v1")
+		require.NoError(t, err)
+		assert.Equal(t, deriveKey("first secret", "v1"), key)
+
+		_, err = NewFileKeyProvider(path, "v3")
+		require.EqualError(t, err, `encryption key "v3" not found`)
+
+		require.NoError(t, ioutil.WriteFile(path, []byte("v1 first secret\n"), 0600))
+		_, err = NewFileKeyProvider(path, "v1")
+		require.Error(t, err)
+	})
+
+	t.Run("env key provider", func(t *testing.T) {
+		provider, err := NewEnvKeyProvider([]string{
+			"GF_ENCRYPTION_KEY_V1=first secret",
+			"GF_ENCRYPTION_KEY_V2=second=secret",
+			"HOME=/home/grafana",
+		}, "V2")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", provider.CurrentKeyID())
+
+		key, err := provider.Key("v2")
+		require.NoError(t, err)
+		assert.Equal(t, deriveKey("second=secret", "v2"), key)
+	})
+
+	t.Run("configured key provider falls back to the secret key", func(t *testing.T) {
+		defaultProvider, err := newKeyProvider(providerConfig{provider: "secret_key", secretKey: "secret"})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultKeyID, defaultProvider.CurrentKeyID())
+		encrypted, err := EncryptWithProvider(defaultProvider, []byte("grafana"))
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "keys")
+		require.NoError(t, ioutil.WriteFile(path, []byte("v1 = first secret\n"), 0600))
+		provider, err := newKeyProvider(providerConfig{provider: "file", keysFile: path, keyID: "v1", secretKey: "secret"})
+		require.NoError(t, err)
+
+		decrypted, err := DecryptWithProvider(provider, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+}
+
+func TestSetKeyProvider(t *testing.T) {
+	provider, err := NewStaticKeyProvider("v1", map[string]string{"v1": "first secret"})
+	require.NoError(t, err)
+
+	SetKeyProvider(provider)
+	t.Cleanup(func() {
+		SetKeyProvider(nil)
+	})
+
+	encrypted, err := Encrypt([]byte("grafana"))
+	require.NoError(t, err)
+	e, err := decodeEnvelope(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", e.keyID)
+
+	decrypted, err := Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("grafana"), decrypted)
+}
+
+func TestMain(m *testing.M) {
+	setting.SecretKey = "test secret key"
+	os.Exit(m.Run())
+}
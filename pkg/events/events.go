@@ -46,3 +46,52 @@ type UserUpdated struct {
 	Login     string    `json:"login"`
 	Email     string    `json:"email"`
 }
+
+type DataSourceUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	OrgId     int64     `json:"org_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+}
+
+type DataSourceDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	OrgId     int64     `json:"org_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+}
+
+// UserTokenRevoked is published when a session of a user is revoked
+type UserTokenRevoked struct {
+	Timestamp time.Time `json:"timestamp"`
+	TokenId   int64     `json:"token_id"`
+	UserId    int64     `json:"user_id"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	RevokedBy int64     `json:"revoked_by"`
+	Reason    string    `json:"reason"`
+}
+
+// UserLoggedIn is published when a user logs in
+type UserLoggedIn struct {
+	Timestamp time.Time `json:"timestamp"`
+	UserId    int64     `json:"user_id"`
+	Login     string    `json:"login"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+}
+
+// UserLoginFailed is published when a user fails to log in with
+// a username and password
+type UserLoginFailed struct {
+	Timestamp time.Time `json:"timestamp"`
+	Login     string    `json:"login"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	Error     string    `json:"error"`
+}
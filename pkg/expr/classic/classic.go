@@ -0,0 +1,206 @@
+// Package classic implements the classic_conditions expression command, which
+// evaluates the conditions of legacy dashboard alerts with the same semantics
+// as services/alerting/conditions.
+package classic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ConditionsCmd is command for the classic conditions
+// expression operation.
+type ConditionsCmd struct {
+	Conditions []condition
+	refID      string
+}
+
+// ClassicConditionJSON is the JSON model for a single condition.
+// It is based on services/alerting/conditions/query.go's newQueryCondition().
+type ClassicConditionJSON struct {
+	Evaluator ConditionEvalJSON `json:"evaluator"`
+
+	Operator struct {
+		Type string `json:"type"`
+	} `json:"operator"`
+
+	Query struct {
+		Params []string `json:"params"`
+	} `json:"query"`
+
+	Reducer struct {
+		Type string `json:"type"`
+	} `json:"reducer"`
+}
+
+// ConditionEvalJSON is the JSON model for the evaluator of a condition.
+type ConditionEvalJSON struct {
+	Params []float64 `json:"params"`
+	Type   string    `json:"type"` // e.g. "gt"
+}
+
+// EvalMatch represents the series violating the threshold of a condition.
+// It goes into the metadata of the result frame.
+type EvalMatch struct {
+	Value  *float64    `json:"value"`
+	Metric string      `json:"metric"`
+	Labels data.Labels `json:"labels"`
+}
+
+// condition is a single condition within the ConditionsCmd.
+type condition struct {
+	QueryRefID string
+	Reducer    classicReducer
+	Evaluator  evaluator
+	Operator   string
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ccc *ConditionsCmd) NeedsVars() []string {
+	vars := []string{}
+	for _, c := range ccc.Conditions {
+		vars = append(vars, c.QueryRefID)
+	}
+	return vars
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. The result is a single number that is 1 when the
+// conditions are firing, 0 when they are not and null when no data was found,
+// like the Firing and NoDataFound of legacy alerting where firing has
+// precedence over no data.
+func (ccc *ConditionsCmd) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	firing := true
+	noDataFound := true
+	matches := []EvalMatch{}
+
+	for i, c := range ccc.Conditions {
+		querySeriesSet := vars[c.QueryRefID]
+		nilReducedCount := 0
+		firingCount := 0
+
+		for _, val := range querySeriesSet.Values {
+			series, ok := val.(mathexp.Series)
+			if !ok {
+				return mathexp.Results{}, fmt.Errorf("can only reduce type series, got type %v", val.Type())
+			}
+
+			reducedNum := c.Reducer.Reduce(series)
+			if reducedNum == nil {
+				nilReducedCount++
+			}
+
+			if c.Evaluator.Eval(reducedNum) {
+				match := EvalMatch{
+					Value:  reducedNum,
+					Metric: series.GetName(),
+				}
+				if labels := series.GetLabels(); labels != nil {
+					match.Labels = labels.Copy()
+				}
+				matches = append(matches, match)
+				firingCount++
+			}
+		}
+
+		// handle no series special case by evaluating the condition for a null value
+		if len(querySeriesSet.Values) == 0 && c.Evaluator.Eval(nil) {
+			matches = append(matches, EvalMatch{Metric: "NoData"})
+			firingCount++
+		}
+
+		thisCondFiring := firingCount > 0
+		thisCondNoData := len(querySeriesSet.Values) == nilReducedCount
+
+		// the first condition seeds the result, its operator then combining
+		// the result with itself, exactly like the eval handler of legacy
+		// alerting
+		if i == 0 {
+			firing = thisCondFiring
+			noDataFound = thisCondNoData
+		}
+
+		if c.Operator == "or" {
+			firing = firing || thisCondFiring
+			noDataFound = noDataFound || thisCondNoData
+		} else {
+			firing = firing && thisCondFiring
+			noDataFound = noDataFound && thisCondNoData
+		}
+	}
+
+	num := mathexp.NewNumber("", nil)
+	num.Frame.SetMeta(&data.FrameMeta{
+		Custom: matches,
+	})
+
+	var v float64
+	switch {
+	case firing:
+		v = 1
+		num.SetValue(&v)
+	case noDataFound:
+		num.SetValue(nil)
+	default:
+		num.SetValue(&v)
+	}
+
+	return mathexp.Results{Values: mathexp.Values{num}}, nil
+}
+
+// UnmarshalConditionsCmd creates a new ConditionsCmd from the model of a
+// classic_conditions expression query.
+func UnmarshalConditionsCmd(rawQuery map[string]interface{}, refID string) (*ConditionsCmd, error) {
+	jsonFromM, err := json.Marshal(rawQuery["conditions"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal classic condition body: %w", err)
+	}
+	var ccj []ClassicConditionJSON
+	if err = json.Unmarshal(jsonFromM, &ccj); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled classic condition body: %w", err)
+	}
+	if len(ccj) == 0 {
+		return nil, fmt.Errorf("no conditions specified for classic condition refId %v", refID)
+	}
+
+	c := &ConditionsCmd{
+		refID: refID,
+	}
+
+	for i, cj := range ccj {
+		cond := condition{}
+
+		// like legacy alerting, a missing operator is an "and"
+		cond.Operator = cj.Operator.Type
+		if cond.Operator == "" {
+			cond.Operator = "and"
+		}
+		if i > 0 && cond.Operator != "and" && cond.Operator != "or" {
+			return nil, fmt.Errorf("condition %v operator must be `and` or `or`", i+1)
+		}
+
+		if len(cj.Query.Params) == 0 || cj.Query.Params[0] == "" {
+			return nil, fmt.Errorf("condition %v is missing the query refID argument", i+1)
+		}
+		cond.QueryRefID = cj.Query.Params[0]
+
+		cond.Reducer = classicReducer(cj.Reducer.Type)
+		if !cond.Reducer.ValidReduceFunc() {
+			return nil, fmt.Errorf("reducer '%v' in condition %v is not a valid reducer", cond.Reducer, i+1)
+		}
+
+		cond.Evaluator, err = newAlertEvaluator(cj.Evaluator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid evaluator in condition %v: %w", i+1, err)
+		}
+
+		c.Conditions = append(c.Conditions, cond)
+	}
+
+	return c, nil
+}
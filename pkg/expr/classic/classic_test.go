@@ -0,0 +1,420 @@
+package classic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalConditionsCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   map[string]interface{}
+		errIs   require.ErrorAssertionFunc
+		refIDs  []string
+		numCond int
+	}{
+		{
+			name: "two conditions",
+			query: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator": map[string]interface{}{"params": []interface{}{2.0}, "type": "gt"},
+						"operator":  map[string]interface{}{"type": "and"},
+						"query":     map[string]interface{}{"params": []interface{}{"A", "5m", "now"}},
+						"reducer":   map[string]interface{}{"params": []interface{}{}, "type": "avg"},
+						"type":      "query",
+					},
+					map[string]interface{}{
+						"evaluator": map[string]interface{}{"params": []interface{}{1.0, 3.0}, "type": "within_range"},
+						"operator":  map[string]interface{}{"type": "or"},
+						"query":     map[string]interface{}{"params": []interface{}{"B"}},
+						"reducer":   map[string]interface{}{"type": "percent_diff"},
+						"type":      "query",
+					},
+				},
+			},
+			errIs:   require.NoError,
+			refIDs:  []string{"A", "B"},
+			numCond: 2,
+		},
+		{
+			name:  "no conditions",
+			query: map[string]interface{}{},
+			errIs: require.Error,
+		},
+		{
+			name: "invalid reducer",
+			query: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator": map[string]interface{}{"params": []interface{}{2.0}, "type": "gt"},
+						"query":     map[string]interface{}{"params": []interface{}{"A"}},
+						"reducer":   map[string]interface{}{"type": "nope"},
+					},
+				},
+			},
+			errIs: require.Error,
+		},
+		{
+			name: "ranged evaluator with one param",
+			query: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator": map[string]interface{}{"params": []interface{}{2.0}, "type": "outside_range"},
+						"query":     map[string]interface{}{"params": []interface{}{"A"}},
+						"reducer":   map[string]interface{}{"type": "avg"},
+					},
+				},
+			},
+			errIs: require.Error,
+		},
+		{
+			name: "missing query refId",
+			query: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator": map[string]interface{}{"params": []interface{}{2.0}, "type": "gt"},
+						"reducer":   map[string]interface{}{"type": "avg"},
+					},
+				},
+			},
+			errIs: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := UnmarshalConditionsCmd(tt.query, "C")
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Len(t, cmd.Conditions, tt.numCond)
+			require.Equal(t, tt.refIDs, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestConditionsCmdExecute(t *testing.T) {
+	tests := []struct {
+		name          string
+		vars          mathexp.Vars
+		conditionsCmd *ConditionsCmd
+		resultNumber  func() mathexp.Number
+		matches       []EvalMatch
+	}{
+		{
+			name: "single query and single condition",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeries(ptr(30), ptr(40)),
+					},
+				},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(1)) },
+			matches:      []EvalMatch{{Value: ptr(35)}},
+		},
+		{
+			name: "single query and single condition not firing",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeries(ptr(30), ptr(40)),
+					},
+				},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("max"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 40},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(0)) },
+			matches:      []EvalMatch{},
+		},
+		{
+			name: "single query with no data",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeries(nil, nil),
+					},
+				},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(nil) },
+			matches:      []EvalMatch{},
+		},
+		{
+			name: "no series firing a no_value condition",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &noValueEvaluator{},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(1)) },
+			matches:      []EvalMatch{{Metric: "NoData"}},
+		},
+		{
+			name: "multiple series with labels",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeriesWithLabels(data.Labels{"h": "1"}, ptr(1), ptr(2)),
+						valBasedSeriesWithLabels(data.Labels{"h": "2"}, ptr(10), ptr(20)),
+					},
+				},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("last"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 5},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(1)) },
+			matches:      []EvalMatch{{Value: ptr(20), Labels: data.Labels{"h": "2"}}},
+		},
+		{
+			name: "two conditions and'd together with the second not firing",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeries(ptr(30), ptr(40)),
+					},
+				},
+				"B": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeries(ptr(1), ptr(2)),
+					},
+				},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+					{
+						QueryRefID: "B",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(0)) },
+			matches:      []EvalMatch{{Value: ptr(35)}},
+		},
+		{
+			name: "two conditions or'd together with the second having no data",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeries(ptr(30), ptr(40)),
+					},
+				},
+				"B": mathexp.Results{
+					Values: []mathexp.Value{
+						valBasedSeries(nil, nil),
+					},
+				},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 50},
+					},
+					{
+						QueryRefID: "B",
+						Reducer:    classicReducer("avg"),
+						Operator:   "or",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(nil) },
+			matches:      []EvalMatch{},
+		},
+		{
+			name: "leading or condition firing and'd with a condition not firing",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{Values: []mathexp.Value{valBasedSeries(ptr(30), ptr(40))}},
+				"B": mathexp.Results{Values: []mathexp.Value{valBasedSeries(ptr(1), ptr(2))}},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "or",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+					{
+						QueryRefID: "B",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(0)) },
+			matches:      []EvalMatch{{Value: ptr(35)}},
+		},
+		{
+			name: "leading or condition not firing or'd with a firing condition",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{Values: []mathexp.Value{valBasedSeries(ptr(1), ptr(2))}},
+				"B": mathexp.Results{Values: []mathexp.Value{valBasedSeries(ptr(30), ptr(40))}},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "or",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+					{
+						QueryRefID: "B",
+						Reducer:    classicReducer("avg"),
+						Operator:   "or",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(1)) },
+			matches:      []EvalMatch{{Value: ptr(35)}},
+		},
+		{
+			name: "leading or condition not firing and'd with a firing condition",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{Values: []mathexp.Value{valBasedSeries(ptr(1), ptr(2))}},
+				"B": mathexp.Results{Values: []mathexp.Value{valBasedSeries(ptr(30), ptr(40))}},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "or",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+					{
+						QueryRefID: "B",
+						Reducer:    classicReducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(ptr(0)) },
+			matches:      []EvalMatch{{Value: ptr(35)}},
+		},
+		{
+			name: "leading or condition with no data",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{Values: []mathexp.Value{valBasedSeries(nil, nil)}},
+			},
+			conditionsCmd: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						QueryRefID: "A",
+						Reducer:    classicReducer("avg"),
+						Operator:   "or",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 34},
+					},
+				},
+			},
+			resultNumber: func() mathexp.Number { return valBasedNumber(nil) },
+			matches:      []EvalMatch{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.conditionsCmd.Execute(context.Background(), tt.vars)
+			require.NoError(t, err)
+
+			require.Len(t, res.Values, 1)
+			num := res.Values[0].(mathexp.Number)
+			require.Equal(t, tt.resultNumber().GetFloat64Value(), num.GetFloat64Value())
+			require.Equal(t, tt.matches, num.Frame.Meta.Custom)
+		})
+	}
+}
+
+func valBasedSeries(vals ...*float64) mathexp.Series {
+	return valBasedSeriesWithLabels(nil, vals...)
+}
+
+func valBasedSeriesWithLabels(l data.Labels, vals ...*float64) mathexp.Series {
+	newSeries := mathexp.NewSeries("", l, 0, false, 1, true, len(vals))
+	for idx, f := range vals {
+		err := newSeries.SetPoint(idx, unixTimePointer(int64(idx), 0), f)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return newSeries
+}
+
+func valBasedNumber(f *float64) mathexp.Number {
+	newNumber := mathexp.NewNumber("", nil)
+	newNumber.SetValue(f)
+	return newNumber
+}
+
+func unixTimePointer(sec, nsec int64) *time.Time {
+	t := time.Unix(sec, nsec)
+	return &t
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
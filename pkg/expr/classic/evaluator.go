@@ -0,0 +1,100 @@
+package classic
+
+import (
+	"fmt"
+)
+
+// evaluator evaluates the reduced value of a series.
+// Returning true if a series is violating the condition.
+type evaluator interface {
+	Eval(reducedValue *float64) bool
+}
+
+type noValueEvaluator struct{}
+
+type thresholdEvaluator struct {
+	Type      string
+	Threshold float64
+}
+
+type rangedEvaluator struct {
+	Type  string
+	Lower float64
+	Upper float64
+}
+
+// newAlertEvaluator is a factory function for returning
+// an evaluator depending on evaluator JSON model.
+func newAlertEvaluator(model ConditionEvalJSON) (evaluator, error) {
+	switch model.Type {
+	case "gt", "lt":
+		return newThresholdEvaluator(model)
+	case "within_range", "outside_range":
+		return newRangedEvaluator(model)
+	case "no_value":
+		return &noValueEvaluator{}, nil
+	case "":
+		return nil, fmt.Errorf("evaluator missing type property")
+	}
+
+	return nil, fmt.Errorf("evaluator invalid evaluator type: %s", model.Type)
+}
+
+func (e *noValueEvaluator) Eval(reducedValue *float64) bool {
+	return reducedValue == nil
+}
+
+func newThresholdEvaluator(model ConditionEvalJSON) (*thresholdEvaluator, error) {
+	if len(model.Params) == 0 {
+		return nil, fmt.Errorf("evaluator '%v' is missing the threshold parameter", model.Type)
+	}
+
+	return &thresholdEvaluator{
+		Type:      model.Type,
+		Threshold: model.Params[0],
+	}, nil
+}
+
+func (e *thresholdEvaluator) Eval(reducedValue *float64) bool {
+	if reducedValue == nil {
+		return false
+	}
+
+	switch e.Type {
+	case "gt":
+		return *reducedValue > e.Threshold
+	case "lt":
+		return *reducedValue < e.Threshold
+	}
+
+	return false
+}
+
+func newRangedEvaluator(model ConditionEvalJSON) (*rangedEvaluator, error) {
+	if len(model.Params) != 2 {
+		return nil, fmt.Errorf("evaluator '%v' requires 2 parameters, got %v", model.Type, len(model.Params))
+	}
+
+	return &rangedEvaluator{
+		Type:  model.Type,
+		Lower: model.Params[0],
+		Upper: model.Params[1],
+	}, nil
+}
+
+func (e *rangedEvaluator) Eval(reducedValue *float64) bool {
+	if reducedValue == nil {
+		return false
+	}
+
+	floatValue := *reducedValue
+
+	switch e.Type {
+	case "within_range":
+		return (e.Lower < floatValue && e.Upper > floatValue) || (e.Upper < floatValue && e.Lower > floatValue)
+	case "outside_range":
+		return (e.Upper < floatValue && e.Lower < floatValue) || (e.Upper > floatValue && e.Lower > floatValue)
+	}
+
+	return false
+}
@@ -0,0 +1,175 @@
+package classic
+
+import (
+	"math"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// classicReducer reduces a series to a nullable float with the semantics of
+// the query reducer of legacy alerting.
+type classicReducer string
+
+func nilOrNaN(f *float64) bool {
+	return f == nil || math.IsNaN(*f)
+}
+
+// ValidReduceFunc returns true if the reducer is supported.
+func (cr classicReducer) ValidReduceFunc() bool {
+	switch cr {
+	case "avg", "sum", "min", "max", "count", "last", "median":
+		return true
+	case "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
+		return true
+	}
+	return false
+}
+
+// Reduce reduces the series to a single value, or nil when the series has no
+// valid values.
+// nolint: gocyclo
+func (cr classicReducer) Reduce(series mathexp.Series) *float64 {
+	if series.Len() == 0 {
+		return nil
+	}
+
+	value := float64(0)
+	allNull := true
+
+	switch cr {
+	case "avg":
+		validPointsCount := 0
+		for i := 0; i < series.Len(); i++ {
+			if f := series.GetValue(i); !nilOrNaN(f) {
+				value += *f
+				validPointsCount++
+				allNull = false
+			}
+		}
+		if validPointsCount > 0 {
+			value /= float64(validPointsCount)
+		}
+	case "sum":
+		for i := 0; i < series.Len(); i++ {
+			if f := series.GetValue(i); !nilOrNaN(f) {
+				value += *f
+				allNull = false
+			}
+		}
+	case "min":
+		value = math.MaxFloat64
+		for i := 0; i < series.Len(); i++ {
+			if f := series.GetValue(i); !nilOrNaN(f) {
+				allNull = false
+				if value > *f {
+					value = *f
+				}
+			}
+		}
+	case "max":
+		value = -math.MaxFloat64
+		for i := 0; i < series.Len(); i++ {
+			if f := series.GetValue(i); !nilOrNaN(f) {
+				allNull = false
+				if value < *f {
+					value = *f
+				}
+			}
+		}
+	case "count":
+		value = float64(series.Len())
+		allNull = false
+	case "last":
+		for i := series.Len() - 1; i >= 0; i-- {
+			if f := series.GetValue(i); !nilOrNaN(f) {
+				value = *f
+				allNull = false
+				break
+			}
+		}
+	case "median":
+		var values []float64
+		for i := 0; i < series.Len(); i++ {
+			if f := series.GetValue(i); !nilOrNaN(f) {
+				allNull = false
+				values = append(values, *f)
+			}
+		}
+		if len(values) >= 1 {
+			sort.Float64s(values)
+			length := len(values)
+			if length%2 == 1 {
+				value = values[(length-1)/2]
+			} else {
+				value = (values[(length/2)-1] + values[length/2]) / 2
+			}
+		}
+	case "diff":
+		allNull, value = calculateDiff(series, allNull, value, diff)
+	case "diff_abs":
+		allNull, value = calculateDiff(series, allNull, value, diffAbs)
+	case "percent_diff":
+		allNull, value = calculateDiff(series, allNull, value, percentDiff)
+	case "percent_diff_abs":
+		allNull, value = calculateDiff(series, allNull, value, percentDiffAbs)
+	case "count_non_null":
+		for i := 0; i < series.Len(); i++ {
+			if f := series.GetValue(i); !nilOrNaN(f) {
+				value++
+			}
+		}
+
+		if value > 0 {
+			allNull = false
+		}
+	}
+
+	if allNull {
+		return nil
+	}
+
+	return &value
+}
+
+func calculateDiff(series mathexp.Series, allNull bool, value float64, fn func(float64, float64) float64) (bool, float64) {
+	var (
+		first float64
+		i     int
+	)
+	// get the newest point
+	for i = series.Len() - 1; i >= 0; i-- {
+		if f := series.GetValue(i); !nilOrNaN(f) {
+			allNull = false
+			first = *f
+			break
+		}
+	}
+	if i >= 1 {
+		// get the oldest point
+		for j := 0; j < i; j++ {
+			if f := series.GetValue(j); !nilOrNaN(f) {
+				allNull = false
+				value = fn(first, *f)
+				break
+			}
+		}
+	}
+	return allNull, value
+}
+
+var diff = func(newest, oldest float64) float64 {
+	return newest - oldest
+}
+
+var diffAbs = func(newest, oldest float64) float64 {
+	return math.Abs(newest - oldest)
+}
+
+var percentDiff = func(newest, oldest float64) float64 {
+	return (newest - oldest) / math.Abs(oldest) * 100
+}
+
+var percentDiffAbs = func(newest, oldest float64) float64 {
+	return math.Abs((newest - oldest) / oldest * 100)
+}
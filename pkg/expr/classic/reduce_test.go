@@ -0,0 +1,80 @@
+package classic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReducer(t *testing.T) {
+	tests := []struct {
+		name     string
+		reducer  classicReducer
+		values   []*float64
+		expected *float64
+	}{
+		{name: "sum", reducer: "sum", values: []*float64{ptr(1), ptr(2), ptr(3)}, expected: ptr(6)},
+		{name: "min", reducer: "min", values: []*float64{ptr(3), ptr(2), ptr(4)}, expected: ptr(2)},
+		{name: "max", reducer: "max", values: []*float64{ptr(1), ptr(2), ptr(3)}, expected: ptr(3)},
+		{name: "avg", reducer: "avg", values: []*float64{ptr(1), ptr(2), ptr(3)}, expected: ptr(2)},
+		{name: "avg ignores nulls and NaN", reducer: "avg", values: []*float64{ptr(2), nil, ptr(math.NaN()), ptr(4)}, expected: ptr(3)},
+		{name: "count includes nulls", reducer: "count", values: []*float64{ptr(1), nil, ptr(3)}, expected: ptr(3)},
+		{name: "count_non_null", reducer: "count_non_null", values: []*float64{ptr(1), nil, ptr(math.NaN()), ptr(3)}, expected: ptr(2)},
+		{name: "count_non_null with only nulls", reducer: "count_non_null", values: []*float64{nil, nil}, expected: nil},
+		{name: "last skips trailing nulls", reducer: "last", values: []*float64{ptr(1), ptr(2), nil}, expected: ptr(2)},
+		{name: "median of odd length", reducer: "median", values: []*float64{ptr(1), ptr(3), ptr(2)}, expected: ptr(2)},
+		{name: "median of even length", reducer: "median", values: []*float64{ptr(1), ptr(2), ptr(3), ptr(4)}, expected: ptr(2.5)},
+		{name: "diff", reducer: "diff", values: []*float64{ptr(30), ptr(40), ptr(40)}, expected: ptr(10)},
+		{name: "diff of a single value", reducer: "diff", values: []*float64{ptr(30)}, expected: ptr(0)},
+		{name: "diff_abs", reducer: "diff_abs", values: []*float64{ptr(30), ptr(20)}, expected: ptr(10)},
+		{name: "percent_diff", reducer: "percent_diff", values: []*float64{ptr(-10), ptr(20)}, expected: ptr(300)},
+		{name: "percent_diff_abs", reducer: "percent_diff_abs", values: []*float64{ptr(10), ptr(5)}, expected: ptr(50)},
+		{name: "all nulls", reducer: "sum", values: []*float64{nil, nil}, expected: nil},
+		{name: "empty series", reducer: "avg", values: []*float64{}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.True(t, tt.reducer.ValidReduceFunc())
+			require.Equal(t, tt.expected, tt.reducer.Reduce(valBasedSeries(tt.values...)))
+		})
+	}
+}
+
+func TestEvaluators(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    ConditionEvalJSON
+		value    *float64
+		expected bool
+	}{
+		{name: "gt", model: ConditionEvalJSON{Type: "gt", Params: []float64{1}}, value: ptr(2), expected: true},
+		{name: "gt with null", model: ConditionEvalJSON{Type: "gt", Params: []float64{1}}, value: nil, expected: false},
+		{name: "lt", model: ConditionEvalJSON{Type: "lt", Params: []float64{1}}, value: ptr(2), expected: false},
+		{name: "within_range", model: ConditionEvalJSON{Type: "within_range", Params: []float64{1, 3}}, value: ptr(2), expected: true},
+		{name: "within_range with inverted params", model: ConditionEvalJSON{Type: "within_range", Params: []float64{3, 1}}, value: ptr(2), expected: true},
+		{name: "outside_range", model: ConditionEvalJSON{Type: "outside_range", Params: []float64{1, 3}}, value: ptr(2), expected: false},
+		{name: "outside_range above", model: ConditionEvalJSON{Type: "outside_range", Params: []float64{1, 3}}, value: ptr(4), expected: true},
+		{name: "no_value with null", model: ConditionEvalJSON{Type: "no_value"}, value: nil, expected: true},
+		{name: "no_value with a value", model: ConditionEvalJSON{Type: "no_value"}, value: ptr(1), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newAlertEvaluator(tt.model)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, e.Eval(tt.value))
+		})
+	}
+
+	t.Run("invalid type", func(t *testing.T) {
+		_, err := newAlertEvaluator(ConditionEvalJSON{Type: "nope"})
+		require.Error(t, err)
+	})
+
+	t.Run("threshold without params", func(t *testing.T) {
+		_, err := newAlertEvaluator(ConditionEvalJSON{Type: "gt"})
+		require.Error(t, err)
+	})
+}
@@ -2,11 +2,13 @@ package expr
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
@@ -72,15 +74,18 @@ func (gm *MathCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.
 type ReduceCommand struct {
 	Reducer     string
 	VarToReduce string
+	Params      []float64
 	refID       string
 }
 
-// NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID, reducer, varToReduce string) *ReduceCommand {
+// NewReduceCommand creates a new ReduceCMD. Params are passed to reducers
+// that take arguments, such as the percentile of the percentile reducer.
+func NewReduceCommand(refID, reducer, varToReduce string, params ...float64) *ReduceCommand {
 	// TODO: validate reducer here, before execution
 	return &ReduceCommand{
 		Reducer:     reducer,
 		VarToReduce: varToReduce,
+		Params:      params,
 		refID:       refID,
 	}
 }
@@ -106,7 +111,20 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 		return nil, fmt.Errorf("expected reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
 	}
 
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce), nil
+	var params []float64
+	if redFunc == "percentile" {
+		rawPercentile, ok := rn.Query["percentile"]
+		if !ok {
+			return nil, fmt.Errorf("no percentile specified for percentile reducer for refId %v", rn.RefID)
+		}
+		percentile, ok := rawPercentile.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected percentile to be a number, got %T for refId %v", rawPercentile, rn.RefID)
+		}
+		params = append(params, percentile)
+	}
+
+	return NewReduceCommand(rn.RefID, redFunc, varToReduce, params...), nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -124,7 +142,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathex
 		if !ok {
 			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 		}
-		num, err := series.Reduce(gr.refID, gr.Reducer)
+		num, err := series.Reduce(gr.refID, gr.Reducer, gr.Params...)
 		if err != nil {
 			return newRes, err
 		}
@@ -227,6 +245,201 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// ThresholdCommand is an expression command for comparing the values of a
+// number or series against one or two thresholds. Each value becomes 1 when
+// the threshold is crossed and 0 when it is not.
+type ThresholdCommand struct {
+	ReferenceVar  string
+	ThresholdFunc string
+	Conditions    []float64
+	Hysteresis    float64
+	refID         string
+}
+
+// ThresholdMeta is the frame metadata of the results of a ThresholdCommand.
+type ThresholdMeta struct {
+	Type       string    `json:"type"`
+	Conditions []float64 `json:"conditions"`
+	Hysteresis float64   `json:"hysteresis,omitempty"`
+	// Fired holds the thresholds crossed by the last firing value, and is empty
+	// when no value fired.
+	Fired []float64 `json:"fired,omitempty"`
+}
+
+// NewThresholdCommand creates a new ThresholdCMD. Hysteresis is the margin
+// by which the values of a series must come back from the thresholds once
+// they fired to stop firing.
+func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions []float64, hysteresis float64) (*ThresholdCommand, error) {
+	switch thresholdFunc {
+	case "gt", "lt":
+		if len(conditions) != 1 {
+			return nil, fmt.Errorf("threshold function %q requires 1 threshold, got %v", thresholdFunc, len(conditions))
+		}
+	case "within_range", "outside_range":
+		if len(conditions) != 2 {
+			return nil, fmt.Errorf("threshold function %q requires 2 thresholds, got %v", thresholdFunc, len(conditions))
+		}
+		if conditions[0] > conditions[1] {
+			return nil, fmt.Errorf("the lower threshold %v of %q is greater than the upper threshold %v", conditions[0], thresholdFunc, conditions[1])
+		}
+	default:
+		return nil, fmt.Errorf("expected threshold function to be one of gt, lt, within_range, outside_range, got %q", thresholdFunc)
+	}
+	if hysteresis < 0 {
+		return nil, fmt.Errorf("expected hysteresis to be positive, got %v", hysteresis)
+	}
+
+	return &ThresholdCommand{
+		ReferenceVar:  referenceVar,
+		ThresholdFunc: thresholdFunc,
+		Conditions:    conditions,
+		Hysteresis:    hysteresis,
+		refID:         refID,
+	}, nil
+}
+
+type thresholdConditionJSON struct {
+	Evaluator struct {
+		Type   string    `json:"type"`
+		Params []float64 `json:"params"`
+	} `json:"evaluator"`
+	Hysteresis float64 `json:"hysteresis"`
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCMD from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to threshold for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	jsonFromM, err := json.Marshal(rn.Query["conditions"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal threshold conditions for refId %v: %w", rn.RefID, err)
+	}
+	var conditions []thresholdConditionJSON
+	if err := json.Unmarshal(jsonFromM, &conditions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal threshold conditions for refId %v: %w", rn.RefID, err)
+	}
+	if len(conditions) != 1 {
+		return nil, fmt.Errorf("expected exactly one threshold condition for refId %v, got %v", rn.RefID, len(conditions))
+	}
+	c := conditions[0]
+
+	return NewThresholdCommand(rn.RefID, referenceVar, c.Evaluator.Type, c.Evaluator.Params, c.Hysteresis)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			newRes.Values = append(newRes.Values, tc.seriesThreshold(v))
+		case mathexp.Number:
+			newRes.Values = append(newRes.Values, tc.numberThreshold(v))
+		default:
+			return newRes, fmt.Errorf("can only apply a threshold to type series or number, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (tc *ThresholdCommand) numberThreshold(n mathexp.Number) mathexp.Number {
+	newNumber := mathexp.NewNumber(tc.refID, n.GetLabels())
+	meta := tc.meta()
+	f := n.GetFloat64Value()
+	if f != nil {
+		fired := tc.fired(*f, false)
+		newNumber.SetValue(boolToFloat64Pointer(fired != nil))
+		meta.Fired = fired
+	}
+	newNumber.Frame.SetMeta(&data.FrameMeta{Custom: meta})
+	return newNumber
+}
+
+func (tc *ThresholdCommand) seriesThreshold(s mathexp.Series) mathexp.Series {
+	newSeries := mathexp.NewSeries(tc.refID, s.GetLabels(), s.TimeIdx, s.TimeIsNullable, s.ValueIdx, true, s.Len())
+	meta := tc.meta()
+	firing := false
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f == nil {
+			_ = newSeries.SetPoint(i, t, nil)
+			continue
+		}
+		fired := tc.fired(*f, firing)
+		firing = fired != nil
+		if firing {
+			meta.Fired = fired
+		}
+		_ = newSeries.SetPoint(i, t, boolToFloat64Pointer(firing))
+	}
+	newSeries.Frame.SetMeta(&data.FrameMeta{Custom: meta})
+	return newSeries
+}
+
+// fired returns the thresholds crossed by the value, or nil if the value is
+// not crossing them. When the previous value was firing the thresholds are
+// moved back by the hysteresis.
+func (tc *ThresholdCommand) fired(f float64, firing bool) []float64 {
+	h := 0.0
+	if firing {
+		h = tc.Hysteresis
+	}
+
+	switch tc.ThresholdFunc {
+	case "gt":
+		if f > tc.Conditions[0]-h {
+			return tc.Conditions[:1]
+		}
+	case "lt":
+		if f < tc.Conditions[0]+h {
+			return tc.Conditions[:1]
+		}
+	case "within_range":
+		if f > tc.Conditions[0]-h && f < tc.Conditions[1]+h {
+			return tc.Conditions
+		}
+	case "outside_range":
+		if f < tc.Conditions[0]+h {
+			return tc.Conditions[:1]
+		}
+		if f > tc.Conditions[1]-h {
+			return tc.Conditions[1:]
+		}
+	}
+	return nil
+}
+
+func (tc *ThresholdCommand) meta() ThresholdMeta {
+	return ThresholdMeta{
+		Type:       tc.ThresholdFunc,
+		Conditions: tc.Conditions,
+		Hysteresis: tc.Hysteresis,
+	}
+}
+
+func boolToFloat64Pointer(b bool) *float64 {
+	var f float64
+	if b {
+		f = 1
+	}
+	return &f
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -239,6 +452,10 @@ const (
 	TypeReduce
 	// TypeResample is the CMDType for a resampling expression.
 	TypeResample
+	// TypeClassicConditions is the CMDType for the classic condition operation.
+	TypeClassicConditions
+	// TypeThreshold is the CMDType for a threshold expression.
+	TypeThreshold
 )
 
 func (gt CommandType) String() string {
@@ -249,6 +466,10 @@ func (gt CommandType) String() string {
 		return "reduce"
 	case TypeResample:
 		return "resample"
+	case TypeClassicConditions:
+		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
 	default:
 		return "unknown"
 	}
@@ -263,6 +484,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeReduce, nil
 	case "resample":
 		return TypeResample, nil
+	case "classic_conditions":
+		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,190 @@
+package expr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalThresholdCommand(t *testing.T) {
+	t.Run("Should unmarshal a threshold with hysteresis", func(t *testing.T) {
+		cmd, err := UnmarshalThresholdCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"type":       "threshold",
+				"expression": "$A",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator":  map[string]interface{}{"type": "within_range", "params": []interface{}{1.0, 5.0}},
+						"hysteresis": 0.5,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		require.Equal(t, "within_range", cmd.ThresholdFunc)
+		require.Equal(t, []float64{1, 5}, cmd.Conditions)
+		require.Equal(t, 0.5, cmd.Hysteresis)
+	})
+
+	t.Run("Should fail without conditions", func(t *testing.T) {
+		_, err := UnmarshalThresholdCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{"type": "threshold", "expression": "$A"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestNewThresholdCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		thresholdFunc string
+		conditions    []float64
+		hysteresis    float64
+	}{
+		{name: "unknown function", thresholdFunc: "eq", conditions: []float64{1}},
+		{name: "gt with two thresholds", thresholdFunc: "gt", conditions: []float64{1, 2}},
+		{name: "range with one threshold", thresholdFunc: "within_range", conditions: []float64{1}},
+		{name: "range with inverted thresholds", thresholdFunc: "outside_range", conditions: []float64{2, 1}},
+		{name: "negative hysteresis", thresholdFunc: "lt", conditions: []float64{1}, hysteresis: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewThresholdCommand("B", "A", tt.thresholdFunc, tt.conditions, tt.hysteresis)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestThresholdCommandExecute(t *testing.T) {
+	series := func(values ...*float64) mathexp.Series {
+		s := mathexp.NewSeries("A", data.Labels{"host": "a"}, 0, true, 1, true, len(values))
+		for i, v := range values {
+			require.NoError(t, s.SetPoint(i, utp(int64(i)), v))
+		}
+		return s
+	}
+
+	seriesValues := func(t *testing.T, v mathexp.Value) []*float64 {
+		s, ok := v.(mathexp.Series)
+		require.True(t, ok)
+		values := make([]*float64, s.Len())
+		for i := range values {
+			values[i] = s.GetValue(i)
+		}
+		return values
+	}
+
+	tests := []struct {
+		name          string
+		thresholdFunc string
+		conditions    []float64
+		hysteresis    float64
+		input         mathexp.Value
+		expected      []*float64
+		fired         []float64
+	}{
+		{
+			name:          "gt on a series",
+			thresholdFunc: "gt",
+			conditions:    []float64{5},
+			input:         series(fp(1), fp(6), nil, fp(5)),
+			expected:      []*float64{fp(0), fp(1), nil, fp(0)},
+			fired:         []float64{5},
+		},
+		{
+			name:          "lt on a series that never fires",
+			thresholdFunc: "lt",
+			conditions:    []float64{0},
+			input:         series(fp(1), fp(2)),
+			expected:      []*float64{fp(0), fp(0)},
+		},
+		{
+			name:          "within_range on a series",
+			thresholdFunc: "within_range",
+			conditions:    []float64{1, 5},
+			input:         series(fp(1), fp(3), fp(5)),
+			expected:      []*float64{fp(0), fp(1), fp(0)},
+			fired:         []float64{1, 5},
+		},
+		{
+			name:          "outside_range on a series fires on the crossed threshold",
+			thresholdFunc: "outside_range",
+			conditions:    []float64{1, 5},
+			input:         series(fp(0), fp(3), fp(6)),
+			expected:      []*float64{fp(1), fp(0), fp(1)},
+			fired:         []float64{5},
+		},
+		{
+			name:          "gt with hysteresis keeps firing until the value recovers past the margin",
+			thresholdFunc: "gt",
+			conditions:    []float64{5},
+			hysteresis:    2,
+			input:         series(fp(4), fp(6), fp(4), fp(3), fp(4)),
+			expected:      []*float64{fp(0), fp(1), fp(1), fp(0), fp(0)},
+			fired:         []float64{5},
+		},
+		{
+			name:          "gt on a number",
+			thresholdFunc: "gt",
+			conditions:    []float64{5},
+			input:         makeNumber(fp(6)),
+			expected:      []*float64{fp(1)},
+			fired:         []float64{5},
+		},
+		{
+			name:          "gt on a null number",
+			thresholdFunc: "gt",
+			conditions:    []float64{5},
+			input:         makeNumber(nil),
+			expected:      []*float64{nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := NewThresholdCommand("B", "A", tt.thresholdFunc, tt.conditions, tt.hysteresis)
+			require.NoError(t, err)
+
+			res, err := cmd.Execute(context.Background(), mathexp.Vars{
+				"A": mathexp.Results{Values: mathexp.Values{tt.input}},
+			})
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+
+			var values []*float64
+			if n, ok := res.Values[0].(mathexp.Number); ok {
+				values = []*float64{n.GetFloat64Value()}
+			} else {
+				values = seriesValues(t, res.Values[0])
+				require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+			}
+			require.Equal(t, tt.expected, values)
+
+			meta := res.Values[0].AsDataFrame().Meta.Custom.(ThresholdMeta)
+			require.Equal(t, tt.thresholdFunc, meta.Type)
+			require.Equal(t, tt.fired, meta.Fired)
+		})
+	}
+
+	t.Run("Should fail on a scalar", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("B", "A", "gt", []float64{5}, 0)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), mathexp.Vars{
+			"A": mathexp.NewScalarResults("A", fp(1)),
+		})
+		require.Error(t, err)
+	})
+}
+
+func makeNumber(f *float64) mathexp.Number {
+	n := mathexp.NewNumber("A", nil)
+	n.SetValue(f)
+	return n
+}
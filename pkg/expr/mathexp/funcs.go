@@ -1,6 +1,7 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
@@ -17,6 +18,51 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             log,
 	},
+	"round": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             round,
+	},
+	"ceil": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             ceil,
+	},
+	"floor": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             floor,
+	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"is_nan": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isNaN,
+	},
+	"is_null": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isNull,
+	},
 	"nan": {
 		Return: parse.TypeScalar,
 		F:      nan,
@@ -33,28 +79,69 @@ var builtins = map[string]parse.Func{
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
 func abs(e *State, varSet Results) (Results, error) {
-	newRes := Results{}
-	for _, res := range varSet.Values {
-		newVal, err := perFloat(e, res, math.Abs)
-		if err != nil {
-			return newRes, err
-		}
-		newRes.Values = append(newRes.Values, newVal)
-	}
-	return newRes, nil
+	return perFloatResults(e, varSet, math.Abs)
 }
 
 // log returns the natural logarithm value for each result in NumberSet, SeriesSet, or Scalar
 func log(e *State, varSet Results) (Results, error) {
-	newRes := Results{}
-	for _, res := range varSet.Values {
-		newVal, err := perFloat(e, res, math.Log)
-		if err != nil {
-			return newRes, err
+	return perFloatResults(e, varSet, math.Log)
+}
+
+// round returns the nearest integer, rounding half away from zero, for each result in NumberSet, SeriesSet, or Scalar
+func round(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Round)
+}
+
+// ceil returns the least integer value greater than or equal to each result in NumberSet, SeriesSet, or Scalar
+func ceil(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Ceil)
+}
+
+// floor returns the greatest integer value less than or equal to each result in NumberSet, SeriesSet, or Scalar
+func floor(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Floor)
+}
+
+// sqrt returns the square root for each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Sqrt)
+}
+
+// exp returns e**x for each result x in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Exp)
+}
+
+// clampMin returns the greater of each pair of results, using the same unions as binary operations
+func clampMin(e *State, varSet Results, minSet Results) (Results, error) {
+	return e.biOp("clamp_min", varSet, minSet)
+}
+
+// clampMax returns the lesser of each pair of results, using the same unions as binary operations
+func clampMax(e *State, varSet Results, maxSet Results) (Results, error) {
+	return e.biOp("clamp_max", varSet, maxSet)
+}
+
+// isNaN returns 1 for each result in NumberSet, SeriesSet, or Scalar that is NaN, and 0 otherwise
+func isNaN(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, func(f *float64) *float64 {
+		r := 0.0
+		if f != nil && math.IsNaN(*f) {
+			r = 1
 		}
-		newRes.Values = append(newRes.Values, newVal)
-	}
-	return newRes, nil
+		return &r
+	})
+}
+
+// isNull returns 1 for each result in NumberSet, SeriesSet, or Scalar that is null, and 0 otherwise
+func isNull(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, func(f *float64) *float64 {
+		r := 0.0
+		if f == nil {
+			r = 1
+		}
+		return &r
+	})
 }
 
 // nan returns a scalar nan value
@@ -116,3 +203,58 @@ func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error
 
 	return newVal, nil
 }
+
+func perFloatResults(e *State, varSet Results, floatF func(x float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+func perNullableFloatResults(e *State, varSet Results, floatF func(x *float64) *float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perNullableFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perNullableFloat is like perFloat, but passes null values to floatF instead
+// of turning them into NaN.
+func perNullableFloat(e *State, val Value, floatF func(x *float64) *float64) (Value, error) {
+	var newVal Value
+	switch val.Type() {
+	case parse.TypeNumberSet:
+		n := NewNumber(e.RefID, val.GetLabels())
+		n.SetValue(floatF(val.(Number).GetFloat64Value()))
+		newVal = n
+	case parse.TypeScalar:
+		newVal = NewScalar(e.RefID, floatF(val.(Scalar).GetFloat64Value()))
+	case parse.TypeSeriesSet:
+		resSeries := val.(Series)
+		newSeries := NewSeries(
+			e.RefID, resSeries.GetLabels(), resSeries.TimeIdx, resSeries.TimeIsNullable, resSeries.ValueIdx,
+			resSeries.ValueIsNullable, resSeries.Len(),
+		)
+		for i := 0; i < resSeries.Len(); i++ {
+			t, f := resSeries.GetPoint(i)
+			if err := newSeries.SetPoint(i, t, floatF(f)); err != nil {
+				return newSeries, err
+			}
+		}
+		newVal = newSeries
+	default:
+		return nil, fmt.Errorf("unsupported type %v", val.Type())
+	}
+
+	return newVal, nil
+}
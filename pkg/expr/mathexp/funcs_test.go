@@ -3,6 +3,7 @@ package mathexp
 import (
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -67,6 +68,181 @@ func TestFunc(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "round on scalar",
+			expr:      "round(2.5)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(3))}},
+		},
+		{
+			name: "ceil and floor on number",
+			expr: "ceil($A) + floor($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(1.5)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name: "sqrt on series",
+			expr: "sqrt($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeriesNullableTime("", nil, nullTimeTP{
+							unixTimePointer(5, 0), float64Pointer(4),
+						}, nullTimeTP{
+							unixTimePointer(10, 0), float64Pointer(9),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeriesNullableTime("", nil, nullTimeTP{
+						unixTimePointer(5, 0), float64Pointer(2),
+					}, nullTimeTP{
+						unixTimePointer(10, 0), float64Pointer(3),
+					}),
+				},
+			},
+		},
+		{
+			name:      "exp on scalar",
+			expr:      "exp(0)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(1))}},
+		},
+		{
+			name: "clamp_min on series and scalar",
+			expr: "clamp_min($A, 2)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeriesNullableTime("", nil, nullTimeTP{
+							unixTimePointer(5, 0), float64Pointer(1),
+						}, nullTimeTP{
+							unixTimePointer(10, 0), float64Pointer(3),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeriesNullableTime("", nil, nullTimeTP{
+						unixTimePointer(5, 0), float64Pointer(2),
+					}, nullTimeTP{
+						unixTimePointer(10, 0), float64Pointer(3),
+					}),
+				},
+			},
+		},
+		{
+			name: "clamp_max on numbers uses unions",
+			expr: "clamp_max($A, $B)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", data.Labels{"host": "a"}, float64Pointer(5)),
+						makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+					},
+				},
+				"B": Results{
+					[]Value{
+						makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+						makeNumber("", data.Labels{"host": "b"}, float64Pointer(3)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name: "clamp_max with scalar first returns the number",
+			expr: "clamp_max(3, $A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(5)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name: "is_null and is_nan on series",
+			expr: "is_null($A) + 2 * is_nan($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeriesNullableTime("", nil, nullTimeTP{
+							unixTimePointer(5, 0), nil,
+						}, nullTimeTP{
+							unixTimePointer(10, 0), NaN,
+						}, nullTimeTP{
+							unixTimePointer(15, 0), float64Pointer(1),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeriesNullableTime("", nil, nullTimeTP{
+						unixTimePointer(5, 0), float64Pointer(1),
+					}, nullTimeTP{
+						unixTimePointer(10, 0), float64Pointer(2),
+					}, nullTimeTP{
+						unixTimePointer(15, 0), float64Pointer(0),
+					}),
+				},
+			},
+		},
+		{
+			name:      "is_null on null scalar",
+			expr:      "is_null(null())",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(1))}},
+		},
+		{
+			name:     "clamp_min with one argument - should error",
+			expr:     "clamp_min($A)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
 		{
 			name:     "abs on string - should error",
 			expr:     `abs("hi")`,
@@ -320,7 +320,9 @@ func (t *Tree) Func() (f *FuncNode) {
 			t.backup()
 			node := t.O()
 			f.append(node)
-			if len(f.Args) == 1 && f.F.VariantReturn {
+			// functions with variant arguments return the most
+			// complex type of their arguments, like binary operators
+			if f.F.VariantReturn && (len(f.Args) == 1 || node.Return() > f.F.Return) {
 				f.F.Return = node.Return()
 			}
 		case itemString:
@@ -329,6 +331,11 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			// separates arguments
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		case itemRightParen:
 			return
 		}
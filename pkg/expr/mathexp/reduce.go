@@ -3,6 +3,7 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -72,8 +73,110 @@ func Count(fv *data.Field) *float64 {
 	return &f
 }
 
-// Reduce turns the Series into a Number based on the given reduction function
-func (s Series) Reduce(refID, rFunc string) (Number, error) {
+// Last returns the last value, or nil if it is null.
+func Last(fv *data.Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	v, ok := fv.At(fv.Len() - 1).(*float64)
+	if !ok || v == nil {
+		return nil
+	}
+	f := *v
+	return &f
+}
+
+// Median returns the median of the values.
+func Median(fv *data.Field) *float64 {
+	return Percentile(fv, 50)
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *data.Field) *float64 {
+	vals, ok := validFloats(fv)
+	if !ok || len(vals) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var mean float64
+	for _, v := range vals {
+		mean += v
+	}
+	mean /= float64(len(vals))
+	var variance float64
+	for _, v := range vals {
+		variance += (v - mean) * (v - mean)
+	}
+	f := math.Sqrt(variance / float64(len(vals)))
+	return &f
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of the values,
+// interpolating linearly between the closest ranks.
+func Percentile(fv *data.Field, p float64) *float64 {
+	vals, ok := validFloats(fv)
+	if !ok || len(vals) == 0 || p < 0 || p > 100 {
+		nan := math.NaN()
+		return &nan
+	}
+	sort.Float64s(vals)
+	rank := p / 100 * float64(len(vals)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+	return &f
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *data.Field) *float64 {
+	nan := math.NaN()
+	if fv.Len() == 0 {
+		return &nan
+	}
+	first, ok := fv.At(0).(*float64)
+	if !ok || first == nil {
+		return &nan
+	}
+	last, ok := fv.At(fv.Len() - 1).(*float64)
+	if !ok || last == nil {
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *data.Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if v, ok := fv.At(i).(*float64); ok && v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// validFloats returns a copy of the values of the field. ok is false if
+// any of the values is null or NaN.
+func validFloats(fv *data.Field) ([]float64, bool) {
+	vals := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v, ok := fv.At(i).(*float64)
+		if !ok {
+			continue
+		}
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		vals = append(vals, *v)
+	}
+	return vals, true
+}
+
+// Reduce turns the Series into a Number based on the given reduction function.
+// The percentile reduction takes the percentile as its only parameter.
+func (s Series) Reduce(refID, rFunc string, params ...float64) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -92,6 +195,24 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		f = Max(fVec)
 	case "count":
 		f = Count(fVec)
+	case "last":
+		f = Last(fVec)
+	case "median":
+		f = Median(fVec)
+	case "stddev":
+		f = StdDev(fVec)
+	case "percentile":
+		if len(params) != 1 {
+			return number, fmt.Errorf("reduction percentile expects a percentile parameter")
+		}
+		if params[0] < 0 || params[0] > 100 {
+			return number, fmt.Errorf("reduction percentile expects a percentile between 0 and 100, got %v", params[0])
+		}
+		f = Percentile(fVec, params[0])
+	case "diff":
+		f = Diff(fVec)
+	case "count_non_null":
+		f = CountNonNull(fVec)
 	default:
 		return number, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
@@ -22,6 +22,24 @@ var seriesWithNil = Vars{
 	},
 }
 
+var seriesOfFive = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("temp", nil, tp{
+				time.Unix(5, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(25, 0), float64Pointer(2),
+			}),
+		},
+	},
+}
+
 var seriesEmpty = Vars{
 	"A": Results{
 		[]Value{
@@ -34,6 +52,7 @@ func TestSeriesReduce(t *testing.T) {
 	var tests = []struct {
 		name        string
 		red         string
+		params      []float64
 		vars        Vars
 		varToReduce string
 		errIs       require.ErrorAssertionFunc
@@ -214,6 +233,124 @@ func TestSeriesReduce(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "last series",
+			red:         "last",
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(2))}},
+		},
+		{
+			name:        "last series with a nil value",
+			red:         "last",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, nil)}},
+		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name:        "median empty series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(math.Sqrt(2)))}},
+		},
+		{
+			name:        "stddev series with a nil value",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:        "percentile series",
+			red:         "percentile",
+			params:      []float64{75},
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(4))}},
+		},
+		{
+			name:        "percentile series interpolates between values",
+			red:         "percentile",
+			params:      []float64{90},
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(4.6))}},
+		},
+		{
+			name:        "percentile without a percentile will error",
+			red:         "percentile",
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
+		{
+			name:        "percentile out of range will error",
+			red:         "percentile",
+			params:      []float64{101},
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
+		{
+			name:        "diff series",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        seriesOfFive,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(-2))}},
+		},
+		{
+			name:        "diff series with a nil value",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:        "count_non_null series with a nil value",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -221,7 +358,7 @@ func TestSeriesReduce(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red)
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.params...)
 				tt.errIs(t, err)
 				if err != nil {
 					return
@@ -84,6 +84,12 @@ var (
 	// MAlertingNotificationSent is a metric counter for how many alert notifications that failed
 	MAlertingNotificationFailed *prometheus.CounterVec
 
+	// MAlertingNotificationRetried is a metric counter for how many alert notifications have been queued for retry
+	MAlertingNotificationRetried *prometheus.CounterVec
+
+	// MAlertingNotificationDeadLetters is a metric counter for how many alert notifications failed all their attempts
+	MAlertingNotificationDeadLetters *prometheus.CounterVec
+
 	// MAwsCloudWatchGetMetricStatistics is a metric counter for getting metric statistics from aws
 	MAwsCloudWatchGetMetricStatistics prometheus.Counter
 
@@ -104,6 +110,30 @@ var (
 
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
+
+	// MDataSourceConnectionsInUse is a metric gauge for the connections in use by SQL data sources
+	MDataSourceConnectionsInUse *prometheus.GaugeVec
+
+	// MDataSourceConnectionsIdle is a metric gauge for the idle connections of SQL data sources
+	MDataSourceConnectionsIdle *prometheus.GaugeVec
+
+	// MDataSourceConnectionsWaitCount is a metric counter for the number of times SQL data source queries waited for a connection
+	MDataSourceConnectionsWaitCount *prometheus.CounterVec
+
+	// MCleanupDeletedRows is a metric counter for the rows deleted by the cleanup service
+	MCleanupDeletedRows *prometheus.CounterVec
+
+	// MCleanupLastRunRows is a metric gauge for the rows deleted, or that would be deleted in dry run mode, by the last cleanup run
+	MCleanupLastRunRows *prometheus.GaugeVec
+
+	// MQuotaUsed is a metric gauge for the usage of the quotas, as of the last check
+	MQuotaUsed *prometheus.GaugeVec
+
+	// MQuotaLimit is a metric gauge for the limit of the quotas, as of the last check
+	MQuotaLimit *prometheus.GaugeVec
+
+	// MQuotaReached is a metric counter for the requests rejected because a quota was reached
+	MQuotaReached *prometheus.CounterVec
 )
 
 // Timers
@@ -116,6 +146,9 @@ var (
 
 	// MRenderingSummary is a metric summary for image rendering request duration
 	MRenderingSummary *prometheus.SummaryVec
+
+	// MAlertingNotificationDeliveryLatency is a metric summary of the time taken to deliver alert notifications, retries included
+	MAlertingNotificationDeliveryLatency *prometheus.SummaryVec
 )
 
 // StatTotals
@@ -330,6 +363,18 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"type"})
 
+	MAlertingNotificationRetried = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "alerting_notification_retried_total",
+		Help:      "counter for how many failed alert notifications have been queued for retry",
+		Namespace: ExporterName,
+	}, []string{"type"})
+
+	MAlertingNotificationDeadLetters = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "alerting_notification_dead_letters_total",
+		Help:      "counter for how many alert notifications have failed all their attempts",
+		Namespace: ExporterName,
+	}, []string{"type"})
+
 	MAwsCloudWatchGetMetricStatistics = newCounterStartingAtZero(prometheus.CounterOpts{
 		Name:      "aws_cloudwatch_get_metric_statistics_total",
 		Help:      "counter for getting metric statistics from aws",
@@ -386,6 +431,54 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MDataSourceConnectionsInUse = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "datasource_connections_in_use",
+		Help:      "number of connections in use by a SQL data source",
+		Namespace: ExporterName,
+	}, []string{"datasource_id", "plugin_id"})
+
+	MDataSourceConnectionsIdle = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "datasource_connections_idle",
+		Help:      "number of idle connections of a SQL data source",
+		Namespace: ExporterName,
+	}, []string{"datasource_id", "plugin_id"})
+
+	MDataSourceConnectionsWaitCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "datasource_connections_wait_total",
+		Help:      "total number of times a SQL data source query waited for a connection",
+		Namespace: ExporterName,
+	}, []string{"datasource_id", "plugin_id"})
+
+	MCleanupDeletedRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "cleanup_deleted_rows_total",
+		Help:      "counter for the rows deleted by the cleanup service",
+		Namespace: ExporterName,
+	}, []string{"type"})
+
+	MCleanupLastRunRows = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "cleanup_last_run_rows",
+		Help:      "number of rows deleted, or that would be deleted in dry run mode, by the last cleanup run",
+		Namespace: ExporterName,
+	}, []string{"type", "dry_run"})
+
+	MQuotaUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "quota_used",
+		Help:      "usage of the quotas, as of the last check",
+		Namespace: ExporterName,
+	}, []string{"scope", "target", "org_id"})
+
+	MQuotaLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "quota_limit",
+		Help:      "limit of the quotas, as of the last check",
+		Namespace: ExporterName,
+	}, []string{"scope", "target", "org_id"})
+
+	MQuotaReached = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "quota_reached_total",
+		Help:      "counter for the requests rejected because a quota was reached",
+		Namespace: ExporterName,
+	}, []string{"scope", "target"})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -393,6 +486,16 @@ func init() {
 		Namespace:  ExporterName,
 	})
 
+	MAlertingNotificationDeliveryLatency = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "alerting_notification_delivery_latency_seconds",
+			Help:       "summary of the time from the first attempt to the delivery of alert notifications",
+			Objectives: objectiveMap,
+			Namespace:  ExporterName,
+		},
+		[]string{"type"},
+	)
+
 	MAlertingExecutionTime = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "alerting_execution_time_milliseconds",
 		Help:       "summary of alert execution duration",
@@ -565,6 +668,9 @@ func initMetricVars() {
 		MAlertingResultState,
 		MAlertingNotificationSent,
 		MAlertingNotificationFailed,
+		MAlertingNotificationRetried,
+		MAlertingNotificationDeadLetters,
+		MAlertingNotificationDeliveryLatency,
 		MAwsCloudWatchGetMetricStatistics,
 		MAwsCloudWatchListMetrics,
 		MAwsCloudWatchGetMetricData,
@@ -573,6 +679,14 @@ func initMetricVars() {
 		MRenderingRequestTotal,
 		MRenderingSummary,
 		MRenderingQueue,
+		MDataSourceConnectionsInUse,
+		MDataSourceConnectionsIdle,
+		MDataSourceConnectionsWaitCount,
+		MCleanupDeletedRows,
+		MCleanupLastRunRows,
+		MQuotaUsed,
+		MQuotaLimit,
+		MQuotaReached,
 		MAlertingActiveAlerts,
 		MStatTotalDashboards,
 		MStatTotalUsers,
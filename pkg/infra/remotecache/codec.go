@@ -0,0 +1,147 @@
+package remotecache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+const (
+	gobEncoding  = "gob"
+	jsonEncoding = "json"
+)
+
+// ErrInvalidCacheEncoding is returned if the encoding is invalid
+var ErrInvalidCacheEncoding = errors.New("invalid remote cache encoding")
+
+// codec marshals the values stored in the cache
+type codec interface {
+	encode(value interface{}) ([]byte, error)
+	decode(data []byte) (interface{}, error)
+}
+
+func newCodec(encoding string) (codec, error) {
+	switch encoding {
+	case "", gobEncoding:
+		return gobCodec{}, nil
+	case jsonEncoding:
+		return jsonCodec{}, nil
+	}
+
+	return nil, ErrInvalidCacheEncoding
+}
+
+var (
+	registeredTypesMu sync.RWMutex
+	registeredTypes   = map[string]reflect.Type{}
+)
+
+func init() {
+	for _, value := range []interface{}{"", false, int(0), int64(0), float64(0), []byte(nil)} {
+		registerType(value)
+	}
+}
+
+func registerType(value interface{}) {
+	t := reflect.TypeOf(value)
+
+	registeredTypesMu.Lock()
+	defer registeredTypesMu.Unlock()
+	registeredTypes[typeName(t)] = t
+}
+
+func lookupType(name string) (reflect.Type, bool) {
+	registeredTypesMu.RLock()
+	defer registeredTypesMu.RUnlock()
+	t, ok := registeredTypes[name]
+	return t, ok
+}
+
+// typeName returns the name a value of type t is stored with by the JSON codec
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + typeName(t.Elem())
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+type cachedItem struct {
+	Val interface{}
+}
+
+// gobCodec stores values using "encoding/gob", which requires the types of
+// the values to be registered using `remotecache.Register`
+type gobCodec struct{}
+
+func (gobCodec) encode(value interface{}) ([]byte, error) {
+	return encodeGob(&cachedItem{Val: value})
+}
+
+func (gobCodec) decode(data []byte) (interface{}, error) {
+	item := &cachedItem{}
+	if err := decodeGob(data, item); err != nil {
+		return nil, err
+	}
+	return item.Val, nil
+}
+
+func encodeGob(item *cachedItem) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	err := gob.NewEncoder(buf).Encode(item)
+	return buf.Bytes(), err
+}
+
+func decodeGob(data []byte, out *cachedItem) error {
+	buf := bytes.NewBuffer(data)
+	return gob.NewDecoder(buf).Decode(&out)
+}
+
+// jsonCodec stores values as JSON objects holding the type name and the value,
+// so that entries can be read by tools not written in Go. Values of types
+// registered using `remotecache.Register` are decoded into their type, other
+// values are decoded the way "encoding/json" decodes into an interface{}.
+type jsonCodec struct{}
+
+type jsonItem struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (jsonCodec) encode(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	item := jsonItem{Value: raw}
+	if value != nil {
+		item.Type = typeName(reflect.TypeOf(value))
+	}
+	return json.Marshal(item)
+}
+
+func (jsonCodec) decode(data []byte) (interface{}, error) {
+	item := jsonItem{}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+
+	t, ok := lookupType(item.Type)
+	if !ok {
+		var value interface{}
+		err := json.Unmarshal(item.Value, &value)
+		return value, err
+	}
+
+	value := reflect.New(t)
+	if err := json.Unmarshal(item.Value, value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface(), nil
+}
@@ -2,6 +2,9 @@ package remotecache
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -12,14 +15,20 @@ var getTime = time.Now
 
 const databaseCacheType = "database"
 
+// maxIncrementAttempts is the number of times an increment is retried
+// when the counter is concurrently modified
+const maxIncrementAttempts = 10
+
 type databaseCache struct {
 	SQLStore *sqlstore.SQLStore
+	codec    codec
 	log      log.Logger
 }
 
-func newDatabaseCache(sqlstore *sqlstore.SQLStore) *databaseCache {
+func newDatabaseCache(sqlstore *sqlstore.SQLStore, codec codec) *databaseCache {
 	dc := &databaseCache{
 		SQLStore: sqlstore,
+		codec:    codec,
 		log:      log.New("remotecache.database"),
 	}
 
@@ -67,28 +76,49 @@ func (dc *databaseCache) Get(key string) (interface{}, error) {
 		return nil, ErrCacheItemNotFound
 	}
 
-	if cacheHit.Expires > 0 {
-		existedButExpired := getTime().Unix()-cacheHit.CreatedAt >= cacheHit.Expires
-		if existedButExpired {
-			err = dc.Delete(key) // ignore this error since we will return `ErrCacheItemNotFound` anyway
-			if err != nil {
-				dc.log.Debug("Deletion of expired key failed: %v", err)
-			}
-			return nil, ErrCacheItemNotFound
+	if cacheHit.isExpired() {
+		err = dc.Delete(key) // ignore this error since we will return `ErrCacheItemNotFound` anyway
+		if err != nil {
+			dc.log.Debug("Deletion of expired key failed: %v", err)
 		}
+		return nil, ErrCacheItemNotFound
 	}
 
-	item := &cachedItem{}
-	if err = decodeGob(cacheHit.Data, item); err != nil {
+	return dc.codec.decode(cacheHit.Data)
+}
+
+func (dc *databaseCache) GetMany(keys []string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	var cacheHits []CacheData
+	session := dc.SQLStore.NewSession()
+	defer session.Close()
+
+	if err := session.In("cache_key", keys).Find(&cacheHits); err != nil {
 		return nil, err
 	}
 
-	return item.Val, nil
+	for _, cacheHit := range cacheHits {
+		// expired items are left for the garbage collection
+		if cacheHit.isExpired() {
+			continue
+		}
+
+		value, err := dc.codec.decode(cacheHit.Data)
+		if err != nil {
+			return nil, err
+		}
+		result[cacheHit.CacheKey] = value
+	}
+
+	return result, nil
 }
 
 func (dc *databaseCache) Set(key string, value interface{}, expire time.Duration) error {
-	item := &cachedItem{Val: value}
-	data, err := encodeGob(item)
+	data, err := dc.codec.encode(value)
 	if err != nil {
 		return err
 	}
@@ -96,10 +126,7 @@ func (dc *databaseCache) Set(key string, value interface{}, expire time.Duration
 	session := dc.SQLStore.NewSession()
 	defer session.Close()
 
-	var expiresInSeconds int64
-	if expire != 0 {
-		expiresInSeconds = int64(expire) / int64(time.Second)
-	}
+	expiresInSeconds := expiresInSeconds(expire)
 
 	// attempt to insert the key
 	sql := `INSERT INTO cache_data (cache_key,data,created_at,expires) VALUES(?,?,?,?)`
@@ -123,6 +150,16 @@ func (dc *databaseCache) Set(key string, value interface{}, expire time.Duration
 	return err
 }
 
+func (dc *databaseCache) SetMany(items map[string]interface{}, expire time.Duration) error {
+	for key, value := range items {
+		if err := dc.Set(key, value, expire); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (dc *databaseCache) Delete(key string) error {
 	return dc.SQLStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
 		sql := "DELETE FROM cache_data WHERE cache_key=?"
@@ -132,6 +169,104 @@ func (dc *databaseCache) Delete(key string) error {
 	})
 }
 
+func (dc *databaseCache) DeleteByPrefix(prefix string) error {
+	return dc.SQLStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+		// '!' is used as escape character since the backslash needs escaping itself in MySQL
+		escaper := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+		sql := "DELETE FROM cache_data WHERE cache_key LIKE ? ESCAPE '!'"
+		_, err := session.Exec(sql, escaper.Replace(prefix)+"%")
+
+		return err
+	})
+}
+
+// GetCounter returns the current value of the counter at key, or
+// ErrCacheItemNotFound if it doesn't exist.
+func (dc *databaseCache) GetCounter(key string) (int64, error) {
+	cacheHit := CacheData{}
+	session := dc.SQLStore.NewSession()
+	defer session.Close()
+
+	exist, err := session.Where("cache_key= ?", key).Get(&cacheHit)
+	if err != nil {
+		return 0, err
+	}
+
+	if !exist || cacheHit.isExpired() {
+		return 0, ErrCacheItemNotFound
+	}
+
+	value, err := strconv.ParseInt(string(cacheHit.Data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cache item %q is not a counter", key)
+	}
+	return value, nil
+}
+
+// Increment updates the counter using optimistic locking, retrying
+// when the counter is modified between reading and updating it
+func (dc *databaseCache) Increment(key string, delta int64, expire time.Duration) (int64, error) {
+	session := dc.SQLStore.NewSession()
+	defer session.Close()
+
+	for attempt := 0; attempt < maxIncrementAttempts; attempt++ {
+		cacheHit := CacheData{}
+		exist, err := session.Where("cache_key= ?", key).Get(&cacheHit)
+		if err != nil {
+			return 0, err
+		}
+
+		now := getTime().Unix()
+		data := []byte(strconv.FormatInt(delta, 10))
+
+		if !exist {
+			sql := `INSERT INTO cache_data (cache_key,data,created_at,expires) VALUES(?,?,?,?)`
+			_, err = session.Exec(sql, key, data, now, expiresInSeconds(expire))
+			if err != nil && (dc.SQLStore.Dialect.IsUniqueConstraintViolation(err) || dc.SQLStore.Dialect.IsDeadlock(err)) {
+				continue
+			}
+			return delta, err
+		}
+
+		value := delta
+		createdAt := now
+		expires := expiresInSeconds(expire)
+		if !cacheHit.isExpired() {
+			current, err := strconv.ParseInt(string(cacheHit.Data), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("cache item %q is not a counter", key)
+			}
+			if delta == 0 {
+				return current, nil
+			}
+
+			value = current + delta
+			data = []byte(strconv.FormatInt(value, 10))
+			createdAt = cacheHit.CreatedAt
+			expires = cacheHit.Expires
+		}
+
+		sql := `UPDATE cache_data SET data=?, created_at=?, expires=? WHERE cache_key=? AND data=? AND created_at=?`
+		res, err := session.Exec(sql, data, createdAt, expires, key, cacheHit.Data, cacheHit.CreatedAt)
+		if err != nil {
+			if dc.SQLStore.Dialect.IsDeadlock(err) {
+				continue
+			}
+			return 0, err
+		}
+
+		updated, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		if updated == 1 {
+			return value, nil
+		}
+	}
+
+	return 0, fmt.Errorf("failed to increment cache item %q: too many concurrent updates", key)
+}
+
 // CacheData is the struct representing the table in the database
 type CacheData struct {
 	CacheKey  string
@@ -139,3 +274,7 @@ type CacheData struct {
 	Expires   int64
 	CreatedAt int64
 }
+
+func (cd CacheData) isExpired() bool {
+	return cd.Expires > 0 && getTime().Unix()-cd.CreatedAt >= cd.Expires
+}
@@ -14,6 +14,7 @@ func TestDatabaseStorageGarbageCollection(t *testing.T) {
 
 	db := &databaseCache{
 		SQLStore: sqlstore,
+		codec:    gobCodec{},
 		log:      log.New("remotecache.database"),
 	}
 
@@ -62,6 +63,7 @@ func TestSecondSet(t *testing.T) {
 
 	db := &databaseCache{
 		SQLStore: sqlstore,
+		codec:    gobCodec{},
 		log:      log.New("remotecache.database"),
 	}
 
@@ -1,6 +1,10 @@
 package remotecache
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bradfitz/gomemcache/memcache"
@@ -10,12 +14,14 @@ import (
 const memcachedCacheType = "memcached"
 
 type memcachedStorage struct {
-	c *memcache.Client
+	c     *memcache.Client
+	codec codec
 }
 
-func newMemcachedStorage(opts *setting.RemoteCacheOptions) *memcachedStorage {
+func newMemcachedStorage(opts *setting.RemoteCacheOptions, codec codec) *memcachedStorage {
 	return &memcachedStorage{
-		c: memcache.New(opts.ConnStr),
+		c:     memcache.New(opts.ConnStr),
+		codec: codec,
 	}
 }
 
@@ -29,25 +35,30 @@ func newItem(sid string, data []byte, expire int32) *memcache.Item {
 
 // Set sets value to given key in the cache.
 func (s *memcachedStorage) Set(key string, val interface{}, expires time.Duration) error {
-	item := &cachedItem{Val: val}
-	bytes, err := encodeGob(item)
+	bytes, err := s.codec.encode(val)
 	if err != nil {
 		return err
 	}
 
-	var expiresInSeconds int64
-	if expires != 0 {
-		expiresInSeconds = int64(expires) / int64(time.Second)
+	memcachedItem := newItem(key, bytes, int32(expiresInSeconds(expires)))
+	return s.c.Set(memcachedItem)
+}
+
+// SetMany sets the values to the given keys in the cache.
+func (s *memcachedStorage) SetMany(items map[string]interface{}, expires time.Duration) error {
+	for key, val := range items {
+		if err := s.Set(key, val, expires); err != nil {
+			return err
+		}
 	}
 
-	memcachedItem := newItem(key, bytes, int32(expiresInSeconds))
-	return s.c.Set(memcachedItem)
+	return nil
 }
 
 // Get gets value by given key in the cache.
 func (s *memcachedStorage) Get(key string) (interface{}, error) {
 	memcachedItem, err := s.c.Get(key)
-	if err != nil && err.Error() == "memcache: cache miss" {
+	if errors.Is(err, memcache.ErrCacheMiss) {
 		return nil, ErrCacheItemNotFound
 	}
 
@@ -55,17 +66,89 @@ func (s *memcachedStorage) Get(key string) (interface{}, error) {
 		return nil, err
 	}
 
-	item := &cachedItem{}
+	return s.codec.decode(memcachedItem.Value)
+}
 
-	err = decodeGob(memcachedItem.Value, item)
+// GetMany gets the values of the given keys found in the cache.
+func (s *memcachedStorage) GetMany(keys []string) (map[string]interface{}, error) {
+	memcachedItems, err := s.c.GetMulti(keys)
 	if err != nil {
 		return nil, err
 	}
 
-	return item.Val, nil
+	result := make(map[string]interface{}, len(memcachedItems))
+	for key, memcachedItem := range memcachedItems {
+		value, err := s.codec.decode(memcachedItem.Value)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+
+	return result, nil
 }
 
 // Delete delete a key from the cache
 func (s *memcachedStorage) Delete(key string) error {
 	return s.c.Delete(key)
 }
+
+// DeleteByPrefix is not supported since memcached cannot list its keys
+func (s *memcachedStorage) DeleteByPrefix(prefix string) error {
+	return ErrDeleteByPrefixNotSupported
+}
+
+// GetCounter returns the current value of the counter at key, or
+// ErrCacheItemNotFound if it doesn't exist.
+func (s *memcachedStorage) GetCounter(key string) (int64, error) {
+	memcachedItem, err := s.c.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, ErrCacheItemNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// memcached pads counters with spaces when they get shorter
+	value, err := strconv.ParseInt(strings.TrimSpace(string(memcachedItem.Value)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cache item %q is not a counter", key)
+	}
+	return value, nil
+}
+
+// Increment adds delta to the counter at key, creating the counter with
+// the expiration if it doesn't exist. Memcached counters are unsigned,
+// decrementing below zero results in zero.
+func (s *memcachedStorage) Increment(key string, delta int64, expires time.Duration) (int64, error) {
+	for attempt := 0; attempt < maxIncrementAttempts; attempt++ {
+		var value uint64
+		var err error
+		if delta < 0 {
+			value, err = s.c.Decrement(key, uint64(-delta))
+		} else {
+			value, err = s.c.Increment(key, uint64(delta))
+		}
+		if err == nil {
+			return int64(value), nil
+		}
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			return 0, err
+		}
+
+		initial := delta
+		if initial < 0 {
+			initial = 0
+		}
+		err = s.c.Add(newItem(key, []byte(strconv.FormatInt(initial, 10)), int32(expiresInSeconds(expires))))
+		if err == nil {
+			return initial, nil
+		}
+		// somebody else created the counter in the meantime
+		if !errors.Is(err, memcache.ErrNotStored) {
+			return 0, err
+		}
+	}
+
+	return 0, fmt.Errorf("failed to increment cache item %q: too many concurrent updates", key)
+}
@@ -0,0 +1,16 @@
+// +build redis_cluster
+
+package remotecache
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRedisClusterCacheStorage(t *testing.T) {
+
+	opts := &setting.RemoteCacheOptions{Name: redisCacheType, ConnStr: "addr=localhost:7000,addr=localhost:7001,addr=localhost:7002,cluster=true"}
+	client := createTestClient(t, opts, nil)
+	runTestsForClient(t, client)
+}
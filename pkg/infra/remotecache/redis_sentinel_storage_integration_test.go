@@ -0,0 +1,16 @@
+// +build redis_sentinel
+
+package remotecache
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRedisSentinelCacheStorage(t *testing.T) {
+
+	opts := &setting.RemoteCacheOptions{Name: redisCacheType, ConnStr: "addr=localhost:26379,master_name=mymaster"}
+	client := createTestClient(t, opts, nil)
+	runTestsForClient(t, client)
+}
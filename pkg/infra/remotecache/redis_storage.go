@@ -1,32 +1,52 @@
 package remotecache
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
-	redis "gopkg.in/redis.v5"
 )
 
 const redisCacheType = "redis"
 
+// redisScanCount is the number of keys scanned per iteration when deleting by prefix
+const redisScanCount = 1000
+
 type redisStorage struct {
-	c *redis.Client
+	c     redis.UniversalClient
+	codec codec
+}
+
+// redisConnOptions are the options parsed from the redis connection string
+type redisConnOptions struct {
+	redis.UniversalOptions
+
+	// IsCluster is set if the addresses are seed nodes of a redis cluster
+	IsCluster bool
 }
 
-// parseRedisConnStr parses k=v pairs in csv and builds a redis Options object
-func parseRedisConnStr(connStr string) (*redis.Options, error) {
+// parseRedisConnStr parses k=v pairs in csv and builds a redis options object.
+// The addr key may be repeated to list the sentinels or the cluster seed nodes.
+func parseRedisConnStr(connStr string) (*redisConnOptions, error) {
 	keyValueCSV := strings.Split(connStr, ",")
-	options := &redis.Options{Network: "tcp"}
+	options := &redisConnOptions{}
 	setTLSIsTrue := false
+	dbIsSet := false
+	var caCertPath, certPath, keyPath string
 	for _, rawKeyValue := range keyValueCSV {
 		keyValueTuple := strings.SplitN(rawKeyValue, "=", 2)
 		if len(keyValueTuple) != 2 {
-			if strings.HasPrefix(rawKeyValue, "password") {
+			if strings.HasPrefix(rawKeyValue, "password") || strings.HasPrefix(rawKeyValue, "sentinel_password") {
 				// don't log the password
 				rawKeyValue = "password******"
 			}
@@ -36,7 +56,9 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 		connVal := keyValueTuple[1]
 		switch connKey {
 		case "addr":
-			options.Addr = connVal
+			options.Addrs = append(options.Addrs, connVal)
+		case "username":
+			options.Username = connVal
 		case "password":
 			options.Password = connVal
 		case "db":
@@ -45,12 +67,23 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 				return nil, errutil.Wrap("value for db in redis connection string must be a number", err)
 			}
 			options.DB = i
+			dbIsSet = true
 		case "pool_size":
 			i, err := strconv.Atoi(connVal)
 			if err != nil {
 				return nil, errutil.Wrap("value for pool_size in redis connection string must be a number", err)
 			}
 			options.PoolSize = i
+		case "master_name":
+			options.MasterName = connVal
+		case "sentinel_password":
+			options.SentinelPassword = connVal
+		case "cluster":
+			b, err := strconv.ParseBool(connVal)
+			if err != nil {
+				return nil, errutil.Wrap("value for cluster in redis connection string must be true or false", err)
+			}
+			options.IsCluster = b
 		case "ssl":
 			if connVal != "true" && connVal != "false" && connVal != "insecure" {
 				return nil, fmt.Errorf("ssl must be set to 'true', 'false', or 'insecure' when present")
@@ -61,58 +94,293 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 			if connVal == "insecure" {
 				options.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 			}
+		case "ssl_ca_cert":
+			caCertPath = connVal
+		case "ssl_cert":
+			certPath = connVal
+		case "ssl_key":
+			keyPath = connVal
 		default:
 			return nil, fmt.Errorf("unrecognized option '%v' in redis connection string", connKey)
 		}
 	}
+
+	if len(options.Addrs) == 0 {
+		return nil, fmt.Errorf("addr is required in redis connection string")
+	}
+	if options.MasterName != "" && options.IsCluster {
+		return nil, fmt.Errorf("master_name and cluster cannot both be set in redis connection string")
+	}
+	if options.IsCluster && dbIsSet {
+		return nil, fmt.Errorf("db cannot be set for a redis cluster")
+	}
+	if options.MasterName == "" && !options.IsCluster && len(options.Addrs) > 1 {
+		return nil, fmt.Errorf("multiple addr require either master_name or cluster to be set in redis connection string")
+	}
+
 	if setTLSIsTrue {
-		// Get hostname from the Addr property and set it on the configuration for TLS
-		sp := strings.Split(options.Addr, ":")
-		if len(sp) < 1 {
-			return nil, fmt.Errorf("unable to get hostname from the addr field, expected host:port, got '%v'", options.Addr)
+		options.TLSConfig = &tls.Config{}
+		// Get hostname from the address and set it on the configuration for TLS.
+		// With multiple addresses it's set per connection
+		if len(options.Addrs) == 1 {
+			sp := strings.Split(options.Addrs[0], ":")
+			if len(sp) < 1 {
+				return nil, fmt.Errorf("unable to get hostname from the addr field, expected host:port, got '%v'", options.Addrs[0])
+			}
+			options.TLSConfig.ServerName = sp[0]
+		}
+	}
+
+	if caCertPath != "" || certPath != "" || keyPath != "" {
+		if options.TLSConfig == nil {
+			return nil, fmt.Errorf("ssl_ca_cert, ssl_cert and ssl_key require ssl to be set to 'true' or 'insecure'")
+		}
+		if err := loadRedisTLSCertificates(options.TLSConfig, caCertPath, certPath, keyPath); err != nil {
+			return nil, err
 		}
-		options.TLSConfig = &tls.Config{ServerName: sp[0]}
 	}
+
 	return options, nil
 }
 
-func newRedisStorage(opts *setting.RemoteCacheOptions) (*redisStorage, error) {
+// loadRedisTLSCertificates adds the CA certificate used to verify the redis
+// server and the client certificate to the TLS configuration
+func loadRedisTLSCertificates(config *tls.Config, caCertPath, certPath, keyPath string) error {
+	if caCertPath != "" {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `caCertPath` comes from grafana configuration file
+		caCert, err := ioutil.ReadFile(caCertPath)
+		if err != nil {
+			return errutil.Wrap("failed to read redis CA certificate", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("failed to parse redis CA certificate %q", caCertPath)
+		}
+	}
+
+	if certPath != "" || keyPath != "" {
+		if certPath == "" || keyPath == "" {
+			return fmt.Errorf("ssl_cert and ssl_key must both be set in redis connection string")
+		}
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return errutil.Wrap("failed to load redis client certificate", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return nil
+}
+
+// redisTLSDialer returns a dialer that sets the server name of the TLS
+// configuration to the host of each address dialed, unless configured,
+// since the sentinels and cluster nodes each have their own address.
+func redisTLSDialer(config *tls.Config, timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		connConfig := config.Clone()
+		if connConfig.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			connConfig.ServerName = host
+		}
+
+		dialer := &tls.Dialer{
+			NetDialer: &net.Dialer{Timeout: timeout, KeepAlive: 5 * time.Minute},
+			Config:    connConfig,
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+func newRedisStorage(opts *setting.RemoteCacheOptions, codec codec) (*redisStorage, error) {
 	opt, err := parseRedisConnStr(opts.ConnStr)
 	if err != nil {
 		return nil, err
 	}
-	return &redisStorage{c: redis.NewClient(opt)}, nil
+
+	if opt.TLSConfig != nil {
+		opt.Dialer = redisTLSDialer(opt.TLSConfig, opt.DialTimeout)
+	}
+
+	var c redis.UniversalClient
+	switch {
+	case opt.MasterName != "":
+		c = redis.NewFailoverClient(opt.Failover())
+	case opt.IsCluster:
+		c = redis.NewClusterClient(opt.Cluster())
+	default:
+		c = redis.NewClient(opt.Simple())
+	}
+
+	return &redisStorage{c: c, codec: codec}, nil
 }
 
 // Set sets value to given key in session.
 func (s *redisStorage) Set(key string, val interface{}, expires time.Duration) error {
-	item := &cachedItem{Val: val}
-	value, err := encodeGob(item)
+	value, err := s.codec.encode(val)
 	if err != nil {
 		return err
 	}
-	status := s.c.Set(key, string(value), expires)
+	status := s.c.Set(context.Background(), key, string(value), expires)
 	return status.Err()
 }
 
+// SetMany sets the values to the given keys in one pipeline.
+func (s *redisStorage) SetMany(items map[string]interface{}, expires time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	pipe := s.c.Pipeline()
+	defer pipe.Close()
+
+	ctx := context.Background()
+	for key, val := range items {
+		value, err := s.codec.encode(val)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, key, string(value), expires)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
 // Get gets value by given key in session.
 func (s *redisStorage) Get(key string) (interface{}, error) {
-	v := s.c.Get(key)
+	v, err := s.c.Get(context.Background(), key).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	item := &cachedItem{}
-	err := decodeGob([]byte(v.Val()), item)
+	return s.codec.decode([]byte(v))
+}
 
-	if err == nil {
-		return item.Val, nil
+// GetMany gets the values of the given keys found in one pipeline.
+// MGET isn't used since the keys may belong to different cluster slots.
+func (s *redisStorage) GetMany(keys []string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if len(keys) == 0 {
+		return result, nil
 	}
-	if err.Error() == "EOF" {
-		return nil, ErrCacheItemNotFound
+
+	pipe := s.c.Pipeline()
+	defer pipe.Close()
+
+	ctx := context.Background()
+	cmds := make([]*redis.StringCmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.Get(ctx, key))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		v, err := cmd.Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := s.codec.decode([]byte(v))
+		if err != nil {
+			return nil, err
+		}
+		result[keys[i]] = value
 	}
-	return nil, err
+
+	return result, nil
 }
 
 // Delete delete a key from session.
 func (s *redisStorage) Delete(key string) error {
-	cmd := s.c.Del(key)
+	cmd := s.c.Del(context.Background(), key)
 	return cmd.Err()
 }
+
+// DeleteByPrefix deletes all keys starting with prefix. The keys are
+// looked up using SCAN so that the server isn't blocked. In a cluster
+// the keys of every master node are scanned.
+func (s *redisStorage) DeleteByPrefix(prefix string) error {
+	ctx := context.Background()
+	if cluster, ok := s.c.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return redisDeleteByPrefix(ctx, client, prefix)
+		})
+	}
+
+	return redisDeleteByPrefix(ctx, s.c, prefix)
+}
+
+func redisDeleteByPrefix(ctx context.Context, c redis.UniversalClient, prefix string) error {
+	escaper := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+	match := escaper.Replace(prefix) + "*"
+
+	var cursor uint64
+	for {
+		keys, next, err := c.Scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			// the keys are deleted one by one since they may belong to different cluster slots
+			pipe := c.Pipeline()
+			for _, key := range keys {
+				pipe.Del(ctx, key)
+			}
+			_, err := pipe.Exec(ctx)
+			pipe.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// GetCounter returns the current value of the counter at key, or
+// ErrCacheItemNotFound if it doesn't exist.
+func (s *redisStorage) GetCounter(key string) (int64, error) {
+	value, err := s.c.Get(context.Background(), key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrCacheItemNotFound
+	}
+	return value, err
+}
+
+// Increment adds delta to the counter at key, creating the
+// counter with the expiration if it doesn't exist.
+func (s *redisStorage) Increment(key string, delta int64, expires time.Duration) (int64, error) {
+	ctx := context.Background()
+	var incr *redis.IntCmd
+	_, err := s.c.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, expires)
+		incr = pipe.IncrBy(ctx, key, delta)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Result()
+}
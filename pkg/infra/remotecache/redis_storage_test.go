@@ -1,59 +1,136 @@
 package remotecache
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
-	redis "gopkg.in/redis.v5"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_parseRedisConnStr(t *testing.T) {
 	cases := map[string]struct {
 		InputConnStr  string
-		OutputOptions *redis.Options
+		OutputOptions *redisConnOptions
 		ShouldErr     bool
 	}{
 		"all redis options should parse": {
 			"addr=127.0.0.1:6379,pool_size=100,db=1,password=grafanaRocks,ssl=false",
-			&redis.Options{
-				Addr:      "127.0.0.1:6379",
+			&redisConnOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:     []string{"127.0.0.1:6379"},
 				PoolSize:  100,
 				DB:        1,
 				Password:  "grafanaRocks",
-				Network:   "tcp",
 				TLSConfig: nil,
-			},
+			}},
 			false,
 		},
 		"subset of redis options should parse": {
 			"addr=127.0.0.1:6379,pool_size=100",
-			&redis.Options{
-				Addr:     "127.0.0.1:6379",
+			&redisConnOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:    []string{"127.0.0.1:6379"},
 				PoolSize: 100,
-				Network:  "tcp",
-			},
+			}},
 			false,
 		},
 		"ssl set to true should result in default TLS configuration with tls set to addr's host": {
 			"addr=grafana.com:6379,ssl=true",
-			&redis.Options{
-				Addr:      "grafana.com:6379",
-				Network:   "tcp",
+			&redisConnOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:     []string{"grafana.com:6379"},
 				TLSConfig: &tls.Config{ServerName: "grafana.com"},
-			},
+			}},
 			false,
 		},
 		"ssl to insecure should result in TLS configuration with InsecureSkipVerify": {
 			"addr=127.0.0.1:6379,ssl=insecure",
-			&redis.Options{
-				Addr:      "127.0.0.1:6379",
-				Network:   "tcp",
+			&redisConnOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:     []string{"127.0.0.1:6379"},
 				TLSConfig: &tls.Config{InsecureSkipVerify: true},
+			}},
+			false,
+		},
+		"username and password should parse for ACL authentication": {
+			"addr=127.0.0.1:6379,username=grafana,password=grafanaRocks",
+			&redisConnOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:    []string{"127.0.0.1:6379"},
+				Username: "grafana",
+				Password: "grafanaRocks",
+			}},
+			false,
+		},
+		"master_name should parse with sentinel addresses": {
+			"addr=10.0.0.1:26379,addr=10.0.0.2:26379,master_name=mymaster,sentinel_password=sentinelRocks,db=2",
+			&redisConnOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:            []string{"10.0.0.1:26379", "10.0.0.2:26379"},
+				MasterName:       "mymaster",
+				SentinelPassword: "sentinelRocks",
+				DB:               2,
+			}},
+			false,
+		},
+		"cluster should parse with seed nodes and tls set per connection": {
+			"addr=10.0.0.1:7000,addr=10.0.0.2:7000,cluster=true,ssl=true",
+			&redisConnOptions{
+				UniversalOptions: redis.UniversalOptions{
+					Addrs:     []string{"10.0.0.1:7000", "10.0.0.2:7000"},
+					TLSConfig: &tls.Config{},
+				},
+				IsCluster: true,
 			},
 			false,
 		},
+		"multiple addresses without master_name or cluster should err": {
+			"addr=10.0.0.1:6379,addr=10.0.0.2:6379",
+			nil,
+			true,
+		},
+		"master_name and cluster should err": {
+			"addr=10.0.0.1:6379,master_name=mymaster,cluster=true",
+			nil,
+			true,
+		},
+		"db for cluster should err": {
+			"addr=10.0.0.1:7000,cluster=true,db=1",
+			nil,
+			true,
+		},
+		"invalid cluster value should err": {
+			"addr=10.0.0.1:7000,cluster=maybe",
+			nil,
+			true,
+		},
+		"ssl_ca_cert without ssl should err": {
+			"addr=127.0.0.1:6379,ssl_ca_cert=/tmp/ca.pem",
+			nil,
+			true,
+		},
+		"missing ssl_ca_cert file should err": {
+			"addr=127.0.0.1:6379,ssl=true,ssl_ca_cert=/does/not/exist.pem",
+			nil,
+			true,
+		},
+		"ssl_cert without ssl_key should err": {
+			"addr=127.0.0.1:6379,ssl=true,ssl_cert=/tmp/cert.pem",
+			nil,
+			true,
+		},
+		"missing addr should err": {
+			"pool_size=100",
+			nil,
+			true,
+		},
 		"invalid SSL option should err": {
 			"addr=127.0.0.1:6379,ssl=dragons",
 			nil,
@@ -97,3 +174,47 @@ func Test_parseRedisConnStr(t *testing.T) {
 		assert.EqualValues(t, testCase.OutputOptions, options, reason)
 	}
 }
+
+func Test_parseRedisConnStrWithCertificates(t *testing.T) {
+	certPath, keyPath := createTestCertificate(t)
+
+	options, err := parseRedisConnStr(fmt.Sprintf("addr=redis.grafana.com:6379,ssl=true,ssl_ca_cert=%s,ssl_cert=%s,ssl_key=%s", certPath, certPath, keyPath))
+	require.NoError(t, err)
+
+	require.NotNil(t, options.TLSConfig)
+	assert.Equal(t, "redis.grafana.com", options.TLSConfig.ServerName)
+	assert.NotNil(t, options.TLSConfig.RootCAs)
+	assert.Len(t, options.TLSConfig.Certificates, 1)
+}
+
+// createTestCertificate writes a self-signed certificate and its key to a temporary directory
+func createTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis.grafana.com"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	err = ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	require.NoError(t, err)
+
+	return certPath, keyPath
+}
@@ -1,10 +1,10 @@
 package remotecache
 
 import (
-	"bytes"
 	"context"
 	"encoding/gob"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -20,6 +20,9 @@ var (
 	// ErrInvalidCacheType is returned if the type is invalid
 	ErrInvalidCacheType = errors.New("invalid remote cache name")
 
+	// ErrDeleteByPrefixNotSupported is returned if the cache cannot list its keys
+	ErrDeleteByPrefixNotSupported = errors.New("remote cache does not support deleting by prefix")
+
 	defaultMaxCacheExpiration = time.Hour * 24
 )
 
@@ -38,30 +41,74 @@ func init() {
 
 // CacheStorage allows the caller to set, get and delete items in the cache.
 // Cached items are stored as byte arrays and marshalled using "encoding/gob"
-// so any struct added to the cache needs to be registered with `remotecache.Register`
+// or "encoding/json", depending on the configured encoding. Any struct added
+// to the cache needs to be registered with `remotecache.Register`
 // ex `remotecache.Register(CacheableStruct{})``
 type CacheStorage interface {
 	// Get reads object from Cache
 	Get(key string) (interface{}, error)
 
+	// GetMany reads the objects of the keys from Cache. Keys that
+	// are not found are left out of the result
+	GetMany(keys []string) (map[string]interface{}, error)
+
 	// Set sets an object into the cache. if `expire` is set to zero it will default to 24h
 	Set(key string, value interface{}, expire time.Duration) error
 
+	// SetMany sets the objects into the cache. if `expire` is set to zero it will default to 24h
+	SetMany(items map[string]interface{}, expire time.Duration) error
+
 	// Delete object from cache
 	Delete(key string) error
+
+	// DeleteByPrefix deletes all objects with keys starting with prefix
+	// from cache. Returns ErrDeleteByPrefixNotSupported for memcached
+	DeleteByPrefix(prefix string) error
+
+	// Increment atomically adds delta to the counter stored at key and
+	// returns the new value. Missing counters are created with the value
+	// delta and expire after `expire`, which will default to 24h if zero.
+	// Counters are stored as plain integers and can only be read using
+	// Increment or GetCounter
+	Increment(key string, delta int64, expire time.Duration) (int64, error)
+
+	// GetCounter reads the counter stored at key without creating it.
+	// Returns ErrCacheItemNotFound if the counter does not exist
+	GetCounter(key string) (int64, error)
 }
 
 // RemoteCache allows Grafana to cache data outside its own process
 type RemoteCache struct {
 	log      log.Logger
 	client   CacheStorage
+	prefix   string
 	SQLStore *sqlstore.SQLStore `inject:""`
 	Cfg      *setting.Cfg       `inject:""`
 }
 
 // Get reads object from Cache
 func (ds *RemoteCache) Get(key string) (interface{}, error) {
-	return ds.client.Get(key)
+	return ds.client.Get(ds.prefix + key)
+}
+
+// GetMany reads the objects of the keys from Cache. Keys that
+// are not found are left out of the result
+func (ds *RemoteCache) GetMany(keys []string) (map[string]interface{}, error) {
+	prefixedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixedKeys = append(prefixedKeys, ds.prefix+key)
+	}
+
+	items, err := ds.client.GetMany(prefixedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(items))
+	for key, value := range items {
+		result[strings.TrimPrefix(key, ds.prefix)] = value
+	}
+	return result, nil
 }
 
 // Set sets an object into the cache. if `expire` is set to zero it will default to 24h
@@ -70,17 +117,51 @@ func (ds *RemoteCache) Set(key string, value interface{}, expire time.Duration)
 		expire = defaultMaxCacheExpiration
 	}
 
-	return ds.client.Set(key, value, expire)
+	return ds.client.Set(ds.prefix+key, value, expire)
+}
+
+// SetMany sets the objects into the cache. if `expire` is set to zero it will default to 24h
+func (ds *RemoteCache) SetMany(items map[string]interface{}, expire time.Duration) error {
+	if expire == 0 {
+		expire = defaultMaxCacheExpiration
+	}
+
+	prefixedItems := make(map[string]interface{}, len(items))
+	for key, value := range items {
+		prefixedItems[ds.prefix+key] = value
+	}
+	return ds.client.SetMany(prefixedItems, expire)
 }
 
 // Delete object from cache
 func (ds *RemoteCache) Delete(key string) error {
-	return ds.client.Delete(key)
+	return ds.client.Delete(ds.prefix + key)
+}
+
+// DeleteByPrefix deletes all objects with keys starting with prefix from cache
+func (ds *RemoteCache) DeleteByPrefix(prefix string) error {
+	return ds.client.DeleteByPrefix(ds.prefix + prefix)
+}
+
+// Increment atomically adds delta to the counter stored at key and returns the new value.
+// if `expire` is set to zero it will default to 24h
+func (ds *RemoteCache) Increment(key string, delta int64, expire time.Duration) (int64, error) {
+	if expire == 0 {
+		expire = defaultMaxCacheExpiration
+	}
+
+	return ds.client.Increment(ds.prefix+key, delta, expire)
+}
+
+// GetCounter reads the counter stored at key without creating it.
+func (ds *RemoteCache) GetCounter(key string) (int64, error) {
+	return ds.client.GetCounter(ds.prefix + key)
 }
 
 // Init initializes the service
 func (ds *RemoteCache) Init() error {
 	ds.log = log.New("cache.remote")
+	ds.prefix = ds.Cfg.RemoteCacheOptions.Prefix
 	var err error
 	ds.client, err = createClient(ds.Cfg.RemoteCacheOptions, ds.SQLStore)
 	return err
@@ -99,16 +180,21 @@ func (ds *RemoteCache) Run(ctx context.Context) error {
 }
 
 func createClient(opts *setting.RemoteCacheOptions, sqlstore *sqlstore.SQLStore) (CacheStorage, error) {
+	codec, err := newCodec(opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
 	if opts.Name == redisCacheType {
-		return newRedisStorage(opts)
+		return newRedisStorage(opts, codec)
 	}
 
 	if opts.Name == memcachedCacheType {
-		return newMemcachedStorage(opts), nil
+		return newMemcachedStorage(opts, codec), nil
 	}
 
 	if opts.Name == databaseCacheType {
-		return newDatabaseCache(sqlstore), nil
+		return newDatabaseCache(sqlstore, codec), nil
 	}
 
 	return nil, ErrInvalidCacheType
@@ -122,19 +208,10 @@ func createClient(opts *setting.RemoteCacheOptions, sqlstore *sqlstore.SQLStore)
 // between types and names is not a bijection.
 func Register(value interface{}) {
 	gob.Register(value)
+	registerType(value)
 }
 
-type cachedItem struct {
-	Val interface{}
-}
-
-func encodeGob(item *cachedItem) ([]byte, error) {
-	buf := bytes.NewBuffer(nil)
-	err := gob.NewEncoder(buf).Encode(item)
-	return buf.Bytes(), err
-}
-
-func decodeGob(data []byte, out *cachedItem) error {
-	buf := bytes.NewBuffer(data)
-	return gob.NewDecoder(buf).Decode(&out)
+// expiresInSeconds returns the expiration in whole seconds, zero meaning no expiration
+func expiresInSeconds(expire time.Duration) int64 {
+	return int64(expire / time.Second)
 }
@@ -1,6 +1,7 @@
 package remotecache
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -51,9 +52,46 @@ func TestInvalidCacheTypeReturnsError(t *testing.T) {
 	assert.Equal(t, err, ErrInvalidCacheType)
 }
 
+func TestInvalidCacheEncodingReturnsError(t *testing.T) {
+	_, err := createClient(&setting.RemoteCacheOptions{Name: databaseCacheType, Encoding: "xml"}, nil)
+	assert.Equal(t, err, ErrInvalidCacheEncoding)
+}
+
+func TestCacheWithJSONEncoding(t *testing.T) {
+	opts := &setting.RemoteCacheOptions{Name: databaseCacheType, Encoding: jsonEncoding}
+	client := createTestClient(t, opts, sqlstore.InitTestDB(t))
+	runTestsForClient(t, client)
+}
+
+func TestCacheWithKeyPrefix(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	prefixed := createTestClient(t, &setting.RemoteCacheOptions{Name: databaseCacheType, Prefix: "grafana:"}, sqlStore)
+	unprefixed := createTestClient(t, &setting.RemoteCacheOptions{Name: databaseCacheType}, sqlStore)
+
+	err := prefixed.Set("key1", "value", 0)
+	require.NoError(t, err)
+
+	data, err := unprefixed.Get("grafana:key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value", data)
+
+	items, err := prefixed.GetMany([]string{"key1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"key1": "value"}, items)
+
+	err = prefixed.DeleteByPrefix("")
+	require.NoError(t, err)
+
+	_, err = unprefixed.Get("grafana:key1")
+	assert.Equal(t, ErrCacheItemNotFound, err)
+}
+
 func runTestsForClient(t *testing.T, client CacheStorage) {
 	canPutGetAndDeleteCachedObjects(t, client)
 	canNotFetchExpiredItems(t, client)
+	canPutAndGetManyCachedObjects(t, client)
+	canDeleteCachedObjectsByPrefix(t, client)
+	canIncrementCounters(t, client)
 }
 
 func canPutGetAndDeleteCachedObjects(t *testing.T, client CacheStorage) {
@@ -90,3 +128,79 @@ func canNotFetchExpiredItems(t *testing.T, client CacheStorage) {
 	_, err = client.Get("key1")
 	assert.Equal(t, err, ErrCacheItemNotFound)
 }
+
+func canPutAndGetManyCachedObjects(t *testing.T, client CacheStorage) {
+	err := client.SetMany(map[string]interface{}{
+		"many1": CacheableStruct{String: "hej", Int64: 1},
+		"many2": CacheableStruct{String: "hopp", Int64: 2},
+	}, 0)
+	require.NoError(t, err)
+
+	items, err := client.GetMany([]string{"many1", "many2", "many3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"many1": CacheableStruct{String: "hej", Int64: 1},
+		"many2": CacheableStruct{String: "hopp", Int64: 2},
+	}, items)
+
+	require.NoError(t, client.Delete("many1"))
+	require.NoError(t, client.Delete("many2"))
+}
+
+func canDeleteCachedObjectsByPrefix(t *testing.T, client CacheStorage) {
+	err := client.SetMany(map[string]interface{}{
+		"prefix:a_1": "a",
+		"prefix:a_2": "b",
+		"prefix:ab":  "c",
+	}, 0)
+	require.NoError(t, err)
+
+	err = client.DeleteByPrefix("prefix:a_")
+	if errors.Is(err, ErrDeleteByPrefixNotSupported) {
+		t.Log("cache does not support deleting by prefix")
+		return
+	}
+	require.NoError(t, err)
+
+	items, err := client.GetMany([]string{"prefix:a_1", "prefix:a_2", "prefix:ab"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"prefix:ab": "c"}, items)
+
+	require.NoError(t, client.DeleteByPrefix("prefix:"))
+}
+
+func canIncrementCounters(t *testing.T, client CacheStorage) {
+	_, err := client.GetCounter("counter")
+	require.Equal(t, ErrCacheItemNotFound, err)
+
+	value, err := client.Increment("counter", 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value)
+
+	value, err = client.Increment("counter", 3, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	value, err = client.Increment("counter", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	value, err = client.GetCounter("counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	require.NoError(t, client.Delete("counter"))
+
+	value, err = client.Increment("counter", 1, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	<-time.After(time.Second + time.Millisecond)
+
+	// the expired counter should start over
+	value, err = client.Increment("counter", 1, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	require.NoError(t, client.Delete("counter"))
+}
@@ -0,0 +1,269 @@
+package serverlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	// ErrLeaseNotAcquired is returned when the lease is held by another server
+	ErrLeaseNotAcquired = errors.New("lease is held by another server")
+
+	// ErrLeaseLost is returned when the lease expired or was acquired by another server
+	ErrLeaseLost = errors.New("lease has been lost")
+)
+
+// minLeaseTTL is the shortest TTL a lease can be acquired for, leaving the
+// heartbeat enough time to renew it.
+const minLeaseTTL = 100 * time.Millisecond
+
+// Lease is a lock on an operation held by this server until it's released or
+// expires. While held, the lease is renewed in the background every third of
+// its TTL. The context of the lease is cancelled when the lease is lost.
+// Expiration is based on the clocks of the servers, which should be in sync.
+type Lease struct {
+	ActionName string
+	// FencingToken increases every time the lease of the operation is acquired.
+	// Storing it along with the result of the operation lets readers reject
+	// writes of servers that lost the lease in the meantime.
+	FencingToken int64
+
+	holder string
+	ttl    time.Duration
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	expiresAt time.Time
+	released  bool
+}
+
+// Context returns a context that is cancelled when the lease is lost or released
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// ExpiresAt returns the time the lease expires unless renewed
+func (l *Lease) ExpiresAt() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.expiresAt
+}
+
+type serverLease struct {
+	// nolint:stylecheck
+	Id           int64
+	OperationUID string `xorm:"operation_uid"`
+	Holder       string
+	ExpiresAt    int64
+	FencingToken int64
+}
+
+// ExecuteWithLease acquires the lease of the operation and executes `fn` while
+// holding it, releasing the lease afterwards. `fn` is not executed if the lease
+// is held by another server. The context passed to `fn` is cancelled if the
+// lease is lost, so that `fn` can stop before another server takes over.
+func (sl *ServerLockService) ExecuteWithLease(ctx context.Context, actionName string, ttl time.Duration, fn func(ctx context.Context, fencingToken int64)) error {
+	lease, err := sl.Acquire(ctx, actionName, ttl)
+	if errors.Is(err, ErrLeaseNotAcquired) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fn(lease.Context(), lease.FencingToken)
+
+	return sl.Release(context.Background(), lease)
+}
+
+// Acquire tries to acquire the lease of the operation for `ttl`, returning
+// ErrLeaseNotAcquired if it's held by another server. The lease is renewed in
+// the background until it's released or `ctx` is cancelled.
+func (sl *ServerLockService) Acquire(ctx context.Context, actionName string, ttl time.Duration) (*Lease, error) {
+	if ttl < minLeaseTTL {
+		return nil, fmt.Errorf("lease TTL of %s for %q is shorter than the minimum of %s", ttl, actionName, minLeaseTTL)
+	}
+
+	if err := sl.getOrCreateLease(ctx, actionName); err != nil {
+		return nil, err
+	}
+
+	holder := util.GenerateShortUID()
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	var fencingToken int64
+	err := sl.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sql := `UPDATE server_lease SET
+			holder = ?,
+			expires_at = ?,
+			fencing_token = fencing_token + 1
+		WHERE
+			operation_uid = ? AND (holder = '' OR expires_at <= ?)`
+
+		res, err := dbSession.Exec(sql, holder, expiresAt.UnixNano(), actionName, now.UnixNano())
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return ErrLeaseNotAcquired
+		}
+
+		row := serverLease{}
+		if _, err := dbSession.Where("operation_uid = ? AND holder = ?", actionName, holder).Get(&row); err != nil {
+			return err
+		}
+		fencingToken = row.FencingToken
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+	lease := &Lease{
+		ActionName:   actionName,
+		FencingToken: fencingToken,
+		holder:       holder,
+		ttl:          ttl,
+		ctx:          leaseCtx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+		expiresAt:    expiresAt,
+	}
+
+	go sl.heartbeat(lease)
+
+	return lease, nil
+}
+
+// Renew extends the lease by its TTL, returning ErrLeaseLost if the lease
+// expired or was acquired by another server in the meantime.
+func (sl *ServerLockService) Renew(ctx context.Context, lease *Lease) error {
+	now := time.Now()
+	expiresAt := now.Add(lease.ttl)
+
+	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sql := `UPDATE server_lease SET
+			expires_at = ?
+		WHERE
+			operation_uid = ? AND holder = ? AND fencing_token = ? AND expires_at > ?`
+
+		res, err := dbSession.Exec(sql, expiresAt.UnixNano(), lease.ActionName, lease.holder, lease.FencingToken, now.UnixNano())
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return ErrLeaseLost
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			lease.cancel()
+		}
+		return err
+	}
+
+	lease.mu.Lock()
+	lease.expiresAt = expiresAt
+	lease.mu.Unlock()
+
+	return nil
+}
+
+// Release stops renewing the lease and releases it so that
+// other servers can acquire it before it expires.
+func (sl *ServerLockService) Release(ctx context.Context, lease *Lease) error {
+	lease.mu.Lock()
+	if lease.released {
+		lease.mu.Unlock()
+		return nil
+	}
+	lease.released = true
+	lease.mu.Unlock()
+
+	lease.cancel()
+	<-lease.done
+
+	return sl.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sql := `UPDATE server_lease SET
+			holder = '',
+			expires_at = 0
+		WHERE
+			operation_uid = ? AND holder = ? AND fencing_token = ?`
+
+		_, err := dbSession.Exec(sql, lease.ActionName, lease.holder, lease.FencingToken)
+		return err
+	})
+}
+
+// heartbeat renews the lease every third of its TTL until the lease is
+// released or lost. Failed renewals are retried until the lease expires.
+func (sl *ServerLockService) heartbeat(lease *Lease) {
+	defer close(lease.done)
+
+	ticker := time.NewTicker(lease.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lease.ctx.Done():
+			return
+		case <-ticker.C:
+			err := sl.Renew(lease.ctx, lease)
+			if err == nil {
+				continue
+			}
+
+			if errors.Is(err, ErrLeaseLost) {
+				sl.log.Warn("Lease lost", "operation", lease.ActionName, "fencingToken", lease.FencingToken)
+				return
+			}
+
+			if !time.Now().Before(lease.ExpiresAt()) {
+				sl.log.Warn("Lease expired before it could be renewed", "operation", lease.ActionName, "error", err)
+				lease.cancel()
+				return
+			}
+
+			sl.log.Debug("Failed to renew lease", "operation", lease.ActionName, "error", err)
+		}
+	}
+}
+
+func (sl *ServerLockService) getOrCreateLease(ctx context.Context, actionName string) error {
+	return sl.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		exists, err := dbSession.Where("operation_uid = ?", actionName).Exist(&serverLease{})
+		if err != nil || exists {
+			return err
+		}
+
+		_, err = dbSession.Insert(&serverLease{OperationUID: actionName})
+		if err != nil && sl.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+			// the lease was created by another server in the meantime
+			return nil
+		}
+		return err
+	})
+}
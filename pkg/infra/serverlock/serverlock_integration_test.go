@@ -4,6 +4,7 @@ package serverlock
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,3 +34,31 @@ func TestServerLok(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, counter, 2)
 }
+
+func TestServerLeaseConcurrentAcquire(t *testing.T) {
+	sl := createTestableServerLock(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	leases := make(chan *Lease, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := sl.Acquire(ctx, "concurrent-lease", time.Minute)
+			if err == nil {
+				leases <- lease
+			}
+		}()
+	}
+	wg.Wait()
+	close(leases)
+
+	acquired := 0
+	for lease := range leases {
+		acquired++
+		assert.Equal(t, int64(1), lease.FencingToken)
+		assert.Nil(t, sl.Release(ctx, lease))
+	}
+	assert.Equal(t, 1, acquired, "exactly one server should acquire the lease")
+}
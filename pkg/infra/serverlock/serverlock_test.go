@@ -3,6 +3,7 @@ package serverlock
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,3 +57,114 @@ func TestServerLock(t *testing.T) {
 		assert.False(t, gotLock)
 	})
 }
+
+func TestServerLease(t *testing.T) {
+	sl := createTestableServerLock(t)
+	ctx := context.Background()
+
+	t.Run("acquiring a held lease should fail until it's released", func(t *testing.T) {
+		lease, err := sl.Acquire(ctx, "test-lease", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), lease.FencingToken)
+
+		_, err = sl.Acquire(ctx, "test-lease", time.Minute)
+		require.Equal(t, ErrLeaseNotAcquired, err)
+
+		require.NoError(t, sl.Release(ctx, lease))
+		assert.Error(t, lease.Context().Err())
+
+		next, err := sl.Acquire(ctx, "test-lease", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), next.FencingToken)
+		require.NoError(t, sl.Release(ctx, next))
+	})
+
+	t.Run("acquiring a lease with a TTL that's too short should fail", func(t *testing.T) {
+		for _, ttl := range []time.Duration{-time.Second, 0, 2 * time.Nanosecond, 50 * time.Millisecond} {
+			_, err := sl.Acquire(ctx, "short-lease", ttl)
+			require.Error(t, err)
+		}
+
+		require.Error(t, sl.ExecuteWithLease(ctx, "short-lease", 0, func(ctx context.Context, fencingToken int64) {
+			t.Fatal("should not execute without a lease")
+		}))
+	})
+
+	t.Run("expired lease can be acquired by another server", func(t *testing.T) {
+		lease, err := sl.Acquire(ctx, "expiring-lease", time.Minute)
+		require.NoError(t, err)
+		expireLease(t, sl, "expiring-lease")
+
+		next, err := sl.Acquire(ctx, "expiring-lease", time.Minute)
+		require.NoError(t, err)
+		assert.Greater(t, next.FencingToken, lease.FencingToken)
+
+		err = sl.Renew(ctx, lease)
+		require.Equal(t, ErrLeaseLost, err)
+		assert.Error(t, lease.Context().Err())
+
+		require.NoError(t, sl.Release(ctx, lease))
+		require.NoError(t, sl.Renew(ctx, next), "releasing a lost lease should not release the new holder's lease")
+		require.NoError(t, sl.Release(ctx, next))
+	})
+
+	t.Run("heartbeat should renew the lease", func(t *testing.T) {
+		lease, err := sl.Acquire(ctx, "renewed-lease", 300*time.Millisecond)
+		require.NoError(t, err)
+		firstExpiry := lease.ExpiresAt()
+
+		<-time.After(time.Second)
+
+		_, err = sl.Acquire(ctx, "renewed-lease", time.Minute)
+		require.Equal(t, ErrLeaseNotAcquired, err)
+		assert.True(t, lease.ExpiresAt().After(firstExpiry))
+		assert.NoError(t, lease.Context().Err())
+
+		require.NoError(t, sl.Release(ctx, lease))
+	})
+
+	t.Run("lease context should be cancelled when the lease is lost", func(t *testing.T) {
+		lease, err := sl.Acquire(ctx, "lost-lease", 300*time.Millisecond)
+		require.NoError(t, err)
+
+		err = sl.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+			_, err := dbSession.Exec("UPDATE server_lease SET holder = ?, fencing_token = fencing_token + 1 WHERE operation_uid = ?", "other-server", "lost-lease")
+			return err
+		})
+		require.NoError(t, err)
+
+		select {
+		case <-lease.Context().Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected lease context to be cancelled")
+		}
+		require.NoError(t, sl.Release(ctx, lease))
+	})
+
+	t.Run("execute with lease should only execute when the lease is acquired", func(t *testing.T) {
+		var tokens []int64
+		fn := func(ctx context.Context, fencingToken int64) {
+			tokens = append(tokens, fencingToken)
+		}
+
+		lease, err := sl.Acquire(ctx, "executed-lease", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, sl.ExecuteWithLease(ctx, "executed-lease", time.Minute, fn))
+		assert.Empty(t, tokens)
+
+		require.NoError(t, sl.Release(ctx, lease))
+		require.NoError(t, sl.ExecuteWithLease(ctx, "executed-lease", time.Minute, fn))
+		require.NoError(t, sl.ExecuteWithLease(ctx, "executed-lease", time.Minute, fn))
+		assert.Equal(t, []int64{lease.FencingToken + 1, lease.FencingToken + 2}, tokens)
+	})
+}
+
+func expireLease(t *testing.T, sl *ServerLockService, actionName string) {
+	t.Helper()
+
+	err := sl.SQLStore.WithDbSession(context.Background(), func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.Exec("UPDATE server_lease SET expires_at = ? WHERE operation_uid = ?", time.Now().Add(-time.Second).UnixNano(), actionName)
+		return err
+	})
+	require.NoError(t, err)
+}
@@ -15,6 +15,7 @@ var (
 	ErrNoEmail               = errors.New("login provider didn't return an email address")
 	ErrProviderDeniedRequest = errors.New("login provider denied login request")
 	ErrTooManyLoginAttempts  = errors.New("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
+	ErrUserLockedOut         = errors.New("too many incorrect login attempts for user - user account temporarily locked")
 	ErrPasswordEmpty         = errors.New("no password provided")
 	ErrUserDisabled          = errors.New("user is disabled")
 	ErrAbsoluteRedirectTo    = errors.New("absolute URLs are not allowed for redirect_to cookie value")
@@ -1,9 +1,11 @@
 package login
 
 import (
+	"errors"
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/models"
 )
 
@@ -12,14 +14,27 @@ var (
 	loginAttemptsWindow           = time.Minute * 5
 )
 
+var timeNow = time.Now
+
 var validateLoginAttempts = func(query *models.LoginUserQuery) error {
 	if query.Cfg.DisableBruteForceLoginProtection {
 		return nil
 	}
+	protection := query.Cfg.BruteForceLoginProtection
+	now := timeNow()
+	since := now.Add(-loginAttemptsWindow)
+
+	lockedUntil, err := getUserLockout(query)
+	if err != nil {
+		return err
+	}
+	if now.Before(lockedUntil) {
+		return ErrUserLockedOut
+	}
 
 	loginAttemptCountQuery := models.GetUserLoginAttemptCountQuery{
 		Username: query.Username,
-		Since:    time.Now().Add(-loginAttemptsWindow),
+		Since:    since,
 	}
 
 	if err := bus.Dispatch(&loginAttemptCountQuery); err != nil {
@@ -30,6 +45,46 @@ var validateLoginAttempts = func(query *models.LoginUserQuery) error {
 		return ErrTooManyLoginAttempts
 	}
 
+	ip := clientIP(query.IpAddress)
+	if ip == "" {
+		return nil
+	}
+
+	if protection.MaxAttemptsPerIP > 0 {
+		ipAttemptCountQuery := models.GetIPLoginAttemptCountQuery{
+			IpAddress: ip,
+			Since:     since,
+		}
+
+		if err := bus.Dispatch(&ipAttemptCountQuery); err != nil {
+			return err
+		}
+
+		if ipAttemptCountQuery.Result >= protection.MaxAttemptsPerIP {
+			return ErrTooManyLoginAttempts
+		}
+	}
+
+	if protection.BackoffAttempts > 0 {
+		userIPAttemptCountQuery := models.GetUserLoginAttemptCountQuery{
+			Username:  query.Username,
+			IpAddress: ip,
+			Since:     since,
+		}
+
+		if err := bus.Dispatch(&userIPAttemptCountQuery); err != nil {
+			return err
+		}
+
+		attempts := userIPAttemptCountQuery.Result
+		if attempts >= protection.BackoffAttempts {
+			delay := backoffDelay(attempts-protection.BackoffAttempts, protection.BackoffBase, protection.BackoffMax)
+			if now.Before(userIPAttemptCountQuery.LastAttempt.Add(delay)) {
+				return ErrTooManyLoginAttempts
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -40,8 +95,117 @@ var saveInvalidLoginAttempt = func(query *models.LoginUserQuery) error {
 
 	loginAttemptCommand := models.CreateLoginAttemptCommand{
 		Username:  query.Username,
-		IpAddress: query.IpAddress,
+		IpAddress: clientIP(query.IpAddress),
+	}
+
+	if err := bus.Dispatch(&loginAttemptCommand); err != nil {
+		return err
+	}
+
+	if query.Cfg.BruteForceLoginProtection.LockoutDuration > 0 {
+		return lockUserIfTooManyAttempts(query)
+	}
+
+	return nil
+}
+
+// lockUserIfTooManyAttempts locks the account of the user once the limit
+// of failed login attempts for the username is reached.
+func lockUserIfTooManyAttempts(query *models.LoginUserQuery) error {
+	protection := query.Cfg.BruteForceLoginProtection
+	now := timeNow()
+
+	loginAttemptCountQuery := models.GetUserLoginAttemptCountQuery{
+		Username: query.Username,
+		Since:    now.Add(-loginAttemptsWindow),
+	}
+
+	if err := bus.Dispatch(&loginAttemptCountQuery); err != nil {
+		return err
+	}
+
+	if loginAttemptCountQuery.Result < maxInvalidLoginAttempts {
+		return nil
+	}
+
+	userQuery := models.GetUserByLoginQuery{LoginOrEmail: query.Username}
+	if err := bus.Dispatch(&userQuery); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	lockCmd := models.LockUserCommand{
+		UserId: userQuery.Result.Id,
+		Until:  now.Add(protection.LockoutDuration),
+	}
+	if err := bus.Dispatch(&lockCmd); err != nil {
+		return err
+	}
+
+	loginLogger.Info("User account locked after too many failed login attempts", "user", userQuery.Result.Login, "until", lockCmd.Until)
+
+	if protection.LockoutNotification && userQuery.Result.Email != "" {
+		emailCmd := models.SendUserLockedOutEmailCommand{
+			User:        userQuery.Result,
+			LockedUntil: lockCmd.Until,
+		}
+		if err := bus.Dispatch(&emailCmd); err != nil {
+			loginLogger.Error("Failed to send user locked out email", "user", userQuery.Result.Login, "err", err)
+		}
+	}
+
+	return nil
+}
+
+// getUserLockout returns the time until which the account of the user
+// logging in is locked. The user isn't looked up when account lockout is
+// disabled.
+func getUserLockout(query *models.LoginUserQuery) (time.Time, error) {
+	if query.Cfg.BruteForceLoginProtection.LockoutDuration <= 0 {
+		return time.Time{}, nil
+	}
+
+	userQuery := models.GetUserByLoginQuery{LoginOrEmail: query.Username}
+	if err := bus.Dispatch(&userQuery); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	lockoutQuery := models.GetUserLockoutQuery{UserId: userQuery.Result.Id}
+	if err := bus.Dispatch(&lockoutQuery); err != nil {
+		return time.Time{}, err
+	}
+
+	return lockoutQuery.Result, nil
+}
+
+// backoffDelay returns the delay before the next login attempt is allowed,
+// doubling base with every attempt up to max.
+func backoffDelay(attempts int64, base, max time.Duration) time.Duration {
+	delay := base
+	for i := int64(0); i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
+
+// clientIP returns the IP address of a client address with an optional
+// port. Addresses that aren't IP addresses are returned as is.
+func clientIP(addr string) string {
+	if addr == "" {
+		return ""
 	}
 
-	return bus.Dispatch(&loginAttemptCommand)
+	ip, err := network.GetIPFromAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return ip.String()
 }
@@ -2,6 +2,7 @@ package login
 
 import (
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
@@ -87,7 +88,7 @@ func TestSaveInvalidLoginAttempt(t *testing.T) {
 
 		require.NotNil(t, createLoginAttemptCmd)
 		assert.Equal(t, "user", createLoginAttemptCmd.Username)
-		assert.Equal(t, "192.168.1.1:56433", createLoginAttemptCmd.IpAddress)
+		assert.Equal(t, "192.168.1.1", createLoginAttemptCmd.IpAddress)
 	})
 
 	t.Run("When brute force protection disabled", func(t *testing.T) {
@@ -122,6 +123,12 @@ func cfgWithBruteForceLoginProtectionEnabled(t *testing.T) *setting.Cfg {
 	t.Helper()
 	cfg := setting.NewCfg()
 	require.False(t, cfg.DisableBruteForceLoginProtection)
+	cfg.BruteForceLoginProtection = setting.BruteForceLoginProtection{
+		MaxAttemptsPerIP: 100,
+		BackoffAttempts:  3,
+		BackoffBase:      time.Second,
+		BackoffMax:       time.Minute,
+	}
 	return cfg
 }
 
@@ -132,3 +139,184 @@ func withLoginAttempts(t *testing.T, loginAttempts int64) {
 		return nil
 	})
 }
+
+func TestValidateLoginAttemptsByIP(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	testCases := []struct {
+		name           string
+		ipAttempts     int64
+		userIPAttempts int64
+		lastAttempt    time.Time
+		expected       error
+	}{
+		{
+			name:       "When IP login attempt count is less than max",
+			ipAttempts: 99,
+			expected:   nil,
+		},
+		{
+			name:       "When IP login attempt count equals max",
+			ipAttempts: 100,
+			expected:   ErrTooManyLoginAttempts,
+		},
+		{
+			name:           "When user and IP login attempt count is less than back-off attempts",
+			userIPAttempts: 2,
+			lastAttempt:    now,
+			expected:       nil,
+		},
+		{
+			name:           "When user and IP login attempt count equals back-off attempts and last attempt is within back-off",
+			userIPAttempts: 3,
+			lastAttempt:    now.Add(-500 * time.Millisecond),
+			expected:       ErrTooManyLoginAttempts,
+		},
+		{
+			name:           "When user and IP login attempt count equals back-off attempts and last attempt is before back-off",
+			userIPAttempts: 3,
+			lastAttempt:    now.Add(-time.Second),
+			expected:       nil,
+		},
+		{
+			name:           "When user and IP login attempt count is greater than back-off attempts back-off is doubled",
+			userIPAttempts: 4,
+			lastAttempt:    now.Add(-time.Second),
+			expected:       ErrTooManyLoginAttempts,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() { bus.ClearBusHandlers() })
+			withLoginAttemptCounts(t, 0, tc.ipAttempts, tc.userIPAttempts, tc.lastAttempt)
+
+			query := &models.LoginUserQuery{Username: "user", IpAddress: "192.168.1.1:56433", Cfg: cfgWithBruteForceLoginProtectionEnabled(t)}
+			err := validateLoginAttempts(query)
+			require.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestValidateLoginAttemptsWithLockout(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		timeNow = time.Now
+		bus.ClearBusHandlers()
+	})
+
+	lockedUntil := time.Time{}
+	withLoginAttemptCounts(t, 0, 0, 0, time.Time{})
+	bus.AddHandler("test", func(query *models.GetUserByLoginQuery) error {
+		if query.LoginOrEmail != "user" {
+			return models.ErrUserNotFound
+		}
+		query.Result = &models.User{Id: 1, Login: "user"}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetUserLockoutQuery) error {
+		query.Result = lockedUntil
+		return nil
+	})
+
+	cfg := cfgWithBruteForceLoginProtectionEnabled(t)
+	cfg.BruteForceLoginProtection.LockoutDuration = time.Hour
+
+	require.NoError(t, validateLoginAttempts(&models.LoginUserQuery{Username: "user", Cfg: cfg}))
+
+	lockedUntil = now.Add(time.Minute)
+	require.Equal(t, ErrUserLockedOut, validateLoginAttempts(&models.LoginUserQuery{Username: "user", Cfg: cfg}))
+	require.NoError(t, validateLoginAttempts(&models.LoginUserQuery{Username: "other", Cfg: cfg}))
+
+	lockedUntil = now
+	require.NoError(t, validateLoginAttempts(&models.LoginUserQuery{Username: "user", Cfg: cfg}))
+}
+
+func TestGetUserLockoutWithLockoutDisabled(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	bus.AddHandler("test", func(query *models.GetUserByLoginQuery) error {
+		t.Fatal("user shouldn't be looked up when account lockout is disabled")
+		return nil
+	})
+
+	lockedUntil, err := getUserLockout(&models.LoginUserQuery{Username: "user", Cfg: cfgWithBruteForceLoginProtectionEnabled(t)})
+	require.NoError(t, err)
+	require.True(t, lockedUntil.IsZero())
+}
+
+func TestSaveInvalidLoginAttemptWithLockout(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		timeNow = time.Now
+		bus.ClearBusHandlers()
+	})
+
+	var lockCmd *models.LockUserCommand
+	var emailCmd *models.SendUserLockedOutEmailCommand
+	bus.AddHandler("test", func(cmd *models.CreateLoginAttemptCommand) error {
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetUserByLoginQuery) error {
+		query.Result = &models.User{Id: 1, Login: "user", Email: "user@test.com"}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.LockUserCommand) error {
+		lockCmd = cmd
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.SendUserLockedOutEmailCommand) error {
+		emailCmd = cmd
+		return nil
+	})
+
+	cfg := cfgWithBruteForceLoginProtectionEnabled(t)
+	cfg.BruteForceLoginProtection.LockoutDuration = time.Hour
+	cfg.BruteForceLoginProtection.LockoutNotification = true
+	query := &models.LoginUserQuery{Username: "user", IpAddress: "192.168.1.1:56433", Cfg: cfg}
+
+	withLoginAttempts(t, maxInvalidLoginAttempts-1)
+	require.NoError(t, saveInvalidLoginAttempt(query))
+	require.Nil(t, lockCmd)
+	require.Nil(t, emailCmd)
+
+	withLoginAttempts(t, maxInvalidLoginAttempts)
+	require.NoError(t, saveInvalidLoginAttempt(query))
+	require.NotNil(t, lockCmd)
+	assert.Equal(t, int64(1), lockCmd.UserId)
+	assert.Equal(t, now.Add(time.Hour), lockCmd.Until)
+	require.NotNil(t, emailCmd)
+	assert.Equal(t, "user@test.com", emailCmd.User.Email)
+	assert.Equal(t, now.Add(time.Hour), emailCmd.LockedUntil)
+}
+
+func TestBackoffDelay(t *testing.T) {
+	assert.Equal(t, time.Second, backoffDelay(0, time.Second, time.Minute))
+	assert.Equal(t, 2*time.Second, backoffDelay(1, time.Second, time.Minute))
+	assert.Equal(t, 32*time.Second, backoffDelay(5, time.Second, time.Minute))
+	assert.Equal(t, time.Minute, backoffDelay(6, time.Second, time.Minute))
+	assert.Equal(t, time.Minute, backoffDelay(1000, time.Second, time.Minute))
+}
+
+func withLoginAttemptCounts(t *testing.T, userAttempts, ipAttempts, userIPAttempts int64, lastAttempt time.Time) {
+	t.Helper()
+	bus.AddHandler("test", func(query *models.GetUserLoginAttemptCountQuery) error {
+		if query.IpAddress == "" {
+			query.Result = userAttempts
+			return nil
+		}
+		require.Equal(t, "192.168.1.1", query.IpAddress)
+		query.Result = userIPAttempts
+		query.LastAttempt = lastAttempt
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetIPLoginAttemptCountQuery) error {
+		require.Equal(t, "192.168.1.1", query.IpAddress)
+		query.Result = ipAttempts
+		return nil
+	})
+}
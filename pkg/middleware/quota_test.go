@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -127,6 +130,11 @@ func TestMiddlewareQuota(t *testing.T) {
 				}
 				return nil
 			})
+
+			bus.AddHandler("orgQuotaLimit", func(query *models.GetOrgQuotaLimitQuery) error {
+				query.Result = query.Default
+				return nil
+			})
 		}
 
 		middlewareScenario(t, "global datasource quota reached", func(t *testing.T, sc *scenarioContext) {
@@ -195,6 +203,66 @@ func TestMiddlewareQuota(t *testing.T) {
 			cfg.Quota.Org.Dashboard = quotaUsed
 		})
 
+		middlewareScenario(t, "org library panel quota not reached", func(t *testing.T, sc *scenarioContext) {
+			setUp(sc)
+
+			quotaHandler := getQuotaHandler(sc, "library_panel")
+			sc.m.Get("/library-panels", quotaHandler, sc.defaultHandler)
+			sc.fakeReq("GET", "/library-panels").exec()
+			assert.Equal(t, 200, sc.resp.Code)
+		}, func(cfg *setting.Cfg) {
+			configure(cfg)
+
+			cfg.Quota.Org.LibraryPanel = quotaUsed + 1
+		})
+
+		middlewareScenario(t, "org library panel quota reached", func(t *testing.T, sc *scenarioContext) {
+			setUp(sc)
+
+			quotaHandler := getQuotaHandler(sc, "library_panel")
+			sc.m.Get("/library-panels", quotaHandler, sc.defaultHandler)
+			sc.fakeReq("GET", "/library-panels").exec()
+			assert.Equal(t, 403, sc.resp.Code)
+		}, func(cfg *setting.Cfg) {
+			configure(cfg)
+
+			cfg.Quota.Org.LibraryPanel = quotaUsed
+		})
+
+		middlewareScenario(t, "org query rate quota reached within the interval", func(t *testing.T, sc *scenarioContext) {
+			setUp(sc)
+
+			quotaHandler := getQuotaHandler(sc, "query")
+			sc.m.Get("/query", quotaHandler, sc.defaultHandler)
+			for i := 0; i < 2; i++ {
+				sc.fakeReq("GET", "/query").exec()
+				assert.Equal(t, 200, sc.resp.Code)
+			}
+
+			sc.fakeReq("GET", "/query").exec()
+			assert.Equal(t, 403, sc.resp.Code)
+		}, func(cfg *setting.Cfg) {
+			configure(cfg)
+
+			cfg.Quota.OrgRate.Query = 2
+		})
+
+		middlewareScenario(t, "org render rate quota unlimited", func(t *testing.T, sc *scenarioContext) {
+			setUp(sc)
+
+			quotaHandler := getQuotaHandler(sc, "render")
+			sc.m.Get("/render", quotaHandler, sc.defaultHandler)
+			for i := 0; i < 3; i++ {
+				sc.fakeReq("GET", "/render").exec()
+				assert.Equal(t, 200, sc.resp.Code)
+			}
+
+			// requests of unlimited rate quotas aren't counted
+			key := fmt.Sprintf("quota:render:org:2:%d", time.Now().Truncate(time.Hour).Unix())
+			_, err := sc.remoteCacheService.GetCounter(key)
+			assert.Equal(t, remotecache.ErrCacheItemNotFound, err)
+		}, configure)
+
 		middlewareScenario(t, "org dashboard quota reached, but quotas disabled", func(t *testing.T, sc *scenarioContext) {
 			setUp(sc)
 
@@ -216,6 +284,7 @@ func getQuotaHandler(sc *scenarioContext, target string) macaron.Handler {
 	qs := &quota.QuotaService{
 		AuthTokenService: fakeAuthTokenService,
 		Cfg:              sc.cfg,
+		RemoteCache:      sc.remoteCacheService,
 	}
 
 	return Quota(qs)(target)
@@ -230,6 +299,12 @@ func configure(cfg *setting.Cfg) {
 			Dashboard:  5,
 			DataSource: 5,
 			ApiKey:     5,
+
+			LibraryPanel: 5,
+		},
+		OrgRate: &setting.OrgRateQuota{
+			Query:  -1,
+			Render: -1,
 		},
 		User: &setting.UserQuota{
 			Org: 5,
@@ -241,6 +316,8 @@ func configure(cfg *setting.Cfg) {
 			DataSource: 5,
 			ApiKey:     5,
 			Session:    5,
+
+			LibraryPanel: 5,
 		},
 	}
 }
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var rateLimitLogger = log.New("rate-limit")
+
+// RemoteRateLimit returns a function that returns a rate limiting handler for
+// a route group configured in the [rate_limiting.<group>] sections.
+// Requests are counted per fixed interval in the remote cache, so that the
+// limits hold across Grafana instances. Requests of route groups without rate
+// limit are not counted.
+// getTime should return the current time. For non-testing purposes use time.Now
+func RemoteRateLimit(cfg *setting.Cfg, store remotecache.CacheStorage, getTime getTimeFn) func(group string) macaron.Handler {
+	return func(group string) macaron.Handler {
+		settings, ok := cfg.RateLimits[group]
+		if !ok {
+			return func(c *models.ReqContext) {}
+		}
+
+		return func(c *models.ReqContext) {
+			now := getTime()
+			windowStart := now.Truncate(settings.Interval)
+			windowEnd := windowStart.Add(settings.Interval)
+			key := fmt.Sprintf("rate-limit:%s:%s:%d", group, rateLimitKey(c, settings.KeyBy), windowStart.Unix())
+
+			count, err := store.Increment(key, 1, settings.Interval)
+			if err != nil {
+				// the rate limit is not enforced while the remote cache is unavailable
+				rateLimitLogger.Warn("Failed to increment rate limit count", "group", group, "error", err)
+				return
+			}
+
+			header := c.Resp.Header()
+			header.Set("X-RateLimit-Limit", strconv.FormatInt(settings.Limit, 10))
+			header.Set("X-RateLimit-Reset", strconv.FormatInt(windowEnd.Unix(), 10))
+
+			if count > settings.Limit {
+				header.Set("X-RateLimit-Remaining", "0")
+				header.Set("Retry-After", strconv.FormatInt(int64(math.Ceil(windowEnd.Sub(now).Seconds())), 10))
+				c.JsonApiErr(429, "Rate limit reached", nil)
+				return
+			}
+
+			header.Set("X-RateLimit-Remaining", strconv.FormatInt(settings.Limit-count, 10))
+		}
+	}
+}
+
+// rateLimitKey returns the key the request is counted by. Requests without
+// the user, organization or API key they should be counted by, such as
+// anonymous requests, are counted by the IP address of the connection. The
+// X-Forwarded-For and X-Real-IP headers are ignored, as clients can set them
+// to get a new count with every request.
+func rateLimitKey(c *models.ReqContext, keyBy string) string {
+	if c.SignedInUser != nil {
+		switch {
+		case keyBy == setting.RateLimitKeyUser && c.UserId > 0:
+			return fmt.Sprintf("user:%d", c.UserId)
+		case keyBy == setting.RateLimitKeyOrg && c.OrgId > 0:
+			return fmt.Sprintf("org:%d", c.OrgId)
+		case keyBy == setting.RateLimitKeyAPIKey && c.ApiKeyId > 0:
+			return fmt.Sprintf("api_key:%d", c.ApiKeyId)
+		}
+	}
+
+	addr := c.Req.RemoteAddr
+	if ip, err := network.GetIPFromAddress(addr); err == nil {
+		return "ip:" + ip.String()
+	}
+	return "ip:" + addr
+}
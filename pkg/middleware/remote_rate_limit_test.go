@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+type remoteRateLimitEnv struct {
+	t           *testing.T
+	cfg         *setting.Cfg
+	store       remotecache.CacheStorage
+	currentTime time.Time
+}
+
+// newServer returns a server with a rate limited route. The request is made by
+// the user whose ID is set in the X-User-Id header, if any.
+func (env *remoteRateLimitEnv) newServer(group string) *macaron.Macaron {
+	m := macaron.New()
+	m.Use(macaron.Renderer(macaron.RenderOptions{
+		Directory: "",
+		Delims:    macaron.Delims{Left: "[[", Right: "]]"},
+	}))
+	m.Use(getContextHandler(env.t, env.cfg).Middleware)
+	m.Use(func(c *models.ReqContext) {
+		if id := c.Req.Header.Get("X-User-Id"); id != "" {
+			userID, err := strconv.ParseInt(id, 10, 64)
+			require.NoError(env.t, err)
+			c.SignedInUser = &models.SignedInUser{UserId: userID, OrgId: 1}
+			c.IsSignedIn = true
+		}
+	})
+	rateLimit := RemoteRateLimit(env.cfg, env.store, func() time.Time { return env.currentTime })
+	m.Get("/foo", rateLimit(group), func(c *models.ReqContext) {
+		c.JSON(200, map[string]interface{}{"message": "OK"})
+	})
+	return m
+}
+
+func (env *remoteRateLimitEnv) doReq(m *macaron.Macaron, userID string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/foo", nil)
+	require.NoError(env.t, err)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if userID != "" {
+		req.Header.Set("X-User-Id", userID)
+	}
+	m.ServeHTTP(resp, req)
+	return resp
+}
+
+func newRemoteRateLimitEnv(t *testing.T, keyBy string) *remoteRateLimitEnv {
+	cfg := setting.NewCfg()
+	cfg.RateLimits = map[string]setting.RateLimitSettings{
+		"query": {Limit: 2, Interval: time.Minute, KeyBy: keyBy},
+	}
+	return &remoteRateLimitEnv{
+		t:           t,
+		cfg:         cfg,
+		store:       remotecache.NewFakeStore(t),
+		currentTime: time.Unix(1200, 0),
+	}
+}
+
+func TestRemoteRateLimitMiddleware(t *testing.T) {
+	t.Run("requests over the limit are rejected until the next interval", func(t *testing.T) {
+		env := newRemoteRateLimitEnv(t, setting.RateLimitKeyIP)
+		m := env.newServer("query")
+
+		resp := env.doReq(m, "")
+		require.Equal(t, 200, resp.Code)
+		assert.Equal(t, "2", resp.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "1", resp.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "1260", resp.Header().Get("X-RateLimit-Reset"))
+
+		env.currentTime = env.currentTime.Add(20 * time.Second)
+		resp = env.doReq(m, "")
+		require.Equal(t, 200, resp.Code)
+		assert.Equal(t, "0", resp.Header().Get("X-RateLimit-Remaining"))
+
+		resp = env.doReq(m, "")
+		require.Equal(t, 429, resp.Code)
+		assert.Equal(t, "0", resp.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "40", resp.Header().Get("Retry-After"))
+
+		env.currentTime = env.currentTime.Add(40 * time.Second)
+		resp = env.doReq(m, "")
+		require.Equal(t, 200, resp.Code)
+		assert.Equal(t, "1", resp.Header().Get("X-RateLimit-Remaining"))
+	})
+
+	t.Run("requests are counted across instances", func(t *testing.T) {
+		env := newRemoteRateLimitEnv(t, setting.RateLimitKeyIP)
+		first, second := env.newServer("query"), env.newServer("query")
+
+		require.Equal(t, 200, env.doReq(first, "").Code)
+		require.Equal(t, 200, env.doReq(second, "").Code)
+		require.Equal(t, 429, env.doReq(first, "").Code)
+		require.Equal(t, 429, env.doReq(second, "").Code)
+	})
+
+	t.Run("requests are counted by user", func(t *testing.T) {
+		env := newRemoteRateLimitEnv(t, setting.RateLimitKeyUser)
+		m := env.newServer("query")
+
+		require.Equal(t, 200, env.doReq(m, "1").Code)
+		require.Equal(t, 200, env.doReq(m, "1").Code)
+		require.Equal(t, 429, env.doReq(m, "1").Code)
+
+		require.Equal(t, 200, env.doReq(m, "2").Code)
+		// anonymous requests are counted by client IP
+		require.Equal(t, 200, env.doReq(m, "").Code)
+	})
+
+	t.Run("requests are counted by the address of the connection", func(t *testing.T) {
+		env := newRemoteRateLimitEnv(t, setting.RateLimitKeyIP)
+		m := env.newServer("query")
+
+		for i, forwardedFor := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/foo", nil)
+			require.NoError(t, err)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			req.Header.Set("X-Real-IP", forwardedFor)
+			m.ServeHTTP(resp, req)
+
+			if i < 2 {
+				require.Equal(t, 200, resp.Code)
+			} else {
+				require.Equal(t, 429, resp.Code)
+			}
+		}
+	})
+
+	t.Run("requests of route groups without rate limit are not counted", func(t *testing.T) {
+		env := newRemoteRateLimitEnv(t, setting.RateLimitKeyIP)
+		m := env.newServer("render")
+
+		for i := 0; i < 3; i++ {
+			resp := env.doReq(m, "")
+			require.Equal(t, 200, resp.Code)
+			assert.Empty(t, resp.Header().Get("X-RateLimit-Limit"))
+		}
+	})
+}
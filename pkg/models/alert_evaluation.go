@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// AlertEvaluation is a recent evaluation of an alert rule, kept to explain
+// why the alert fired or stayed silent
+type AlertEvaluation struct {
+	Id      int64            `json:"id"`
+	AlertId int64            `json:"alertId"`
+	OrgId   int64            `json:"-"`
+	State   AlertStateType   `json:"state"`
+	Data    *simplejson.Json `json:"data"`
+	Created int64            `json:"created"` // epoch milliseconds
+}
+
+// Commands
+
+// SaveAlertEvaluationCommand stores an evaluation of an alert rule, only
+// keeping the Limit most recent evaluations of the rule
+type SaveAlertEvaluationCommand struct {
+	AlertId int64
+	OrgId   int64
+	State   AlertStateType
+	Data    *simplejson.Json
+	Created time.Time
+	Limit   int
+}
+
+// Queries
+
+// GetAlertEvaluationsQuery returns the evaluations of an alert rule, most recent first
+type GetAlertEvaluationsQuery struct {
+	AlertId int64
+	OrgId   int64
+	Result  []*AlertEvaluation
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// AlertNode is a Grafana instance evaluating the alert rules it owns when
+// the rules are sharded across the instances of a HA setup
+type AlertNode struct {
+	Id        int64
+	NodeId    string
+	Address   string
+	Started   int64
+	Heartbeat int64
+}
+
+// Commands
+
+// HeartbeatAlertNodeCommand registers the node as alive, adding it if it's new
+type HeartbeatAlertNodeCommand struct {
+	NodeId  string
+	Address string
+	Started time.Time
+	Now     time.Time
+}
+
+// RemoveAlertNodeCommand removes the node when it stops, so that its rules are
+// rebalanced without waiting for the node to time out
+type RemoveAlertNodeCommand struct {
+	NodeId string
+}
+
+// DeleteExpiredAlertNodesCommand deletes the nodes that stopped sending heartbeats
+type DeleteExpiredAlertNodesCommand struct {
+	HeartbeatBefore time.Time
+	DeletedRows     int64
+}
+
+// Queries
+
+// GetAlertNodesQuery returns the nodes that sent a heartbeat since ActiveSince,
+// ordered by node ID
+type GetAlertNodesQuery struct {
+	ActiveSince time.Time
+	Result      []*AlertNode
+}
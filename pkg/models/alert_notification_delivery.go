@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrAlertNotificationDeliveryNotFound = errors.New("alert notification delivery not found")
+
+type AlertNotificationDeliveryState string
+
+const (
+	// AlertNotificationDeliveryPending is a delivery waiting for its next attempt
+	AlertNotificationDeliveryPending AlertNotificationDeliveryState = "pending"
+	// AlertNotificationDeliveryDead is a delivery that failed all its attempts,
+	// kept until it's replayed or deleted
+	AlertNotificationDeliveryDead AlertNotificationDeliveryState = "dead"
+)
+
+func (s AlertNotificationDeliveryState) IsValid() bool {
+	return s == AlertNotificationDeliveryPending || s == AlertNotificationDeliveryDead
+}
+
+// AlertNotificationDelivery is an alert notification that failed to be sent
+// and is retried by the delivery queue
+type AlertNotificationDelivery struct {
+	Id           int64
+	OrgId        int64
+	AlertId      int64
+	NotifierId   int64
+	NotifierUid  string
+	NotifierType string
+
+	// NotificationStateId and NotificationStateVersion identify the alert
+	// notification state set as pending when the notification was first sent.
+	// The delivery is dropped when a newer notification has been sent since,
+	// unless it has been replayed, which resets the version to 0.
+	NotificationStateId      int64
+	NotificationStateVersion int64
+
+	State       AlertNotificationDeliveryState
+	Attempts    int
+	NextAttempt int64 // epoch seconds
+	LastError   string
+	Payload     string
+	Created     int64 // epoch seconds
+	Updated     int64 // epoch seconds
+}
+
+// Commands
+
+// EnqueueAlertNotificationDeliveryCommand adds a notification to the delivery queue
+type EnqueueAlertNotificationDeliveryCommand struct {
+	OrgId                    int64
+	AlertId                  int64
+	NotifierId               int64
+	NotifierUid              string
+	NotifierType             string
+	NotificationStateId      int64
+	NotificationStateVersion int64
+	Attempts                 int
+	NextAttempt              time.Time
+	LastError                string
+	Payload                  string
+	Created                  time.Time
+
+	Result *AlertNotificationDelivery
+}
+
+// ClaimAlertNotificationDeliveryCommand counts a new attempt of a pending
+// delivery and postpones its next attempt to Until, so that no other instance
+// picks it up meanwhile. Claimed is false when the delivery has been claimed
+// by someone else since it was read.
+type ClaimAlertNotificationDeliveryCommand struct {
+	Id       int64
+	Attempts int
+	Until    time.Time
+
+	Claimed bool
+}
+
+// FailAlertNotificationDeliveryCommand records a failed attempt of a delivery,
+// which is retried at NextAttempt unless Dead is set
+type FailAlertNotificationDeliveryCommand struct {
+	Id          int64
+	Error       string
+	NextAttempt time.Time
+	Dead        bool
+}
+
+// ReplayAlertNotificationDeliveryCommand sends a dead delivery again
+type ReplayAlertNotificationDeliveryCommand struct {
+	Id    int64
+	OrgId int64
+	Now   time.Time
+}
+
+// DeleteAlertNotificationDeliveryCommand removes a delivery from the queue.
+// OrgId is optional, a zero value deletes the delivery regardless of its org.
+type DeleteAlertNotificationDeliveryCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+// Queries
+
+// GetDueAlertNotificationDeliveriesQuery returns the pending deliveries due at
+// Now, oldest attempt first
+type GetDueAlertNotificationDeliveriesQuery struct {
+	Now   time.Time
+	Limit int
+
+	Result []*AlertNotificationDelivery
+}
+
+// GetAlertNotificationDeliveriesQuery returns the deliveries of an org, most
+// recent first
+type GetAlertNotificationDeliveriesQuery struct {
+	OrgId int64
+	State AlertNotificationDeliveryState
+	Limit int
+
+	Result []*AlertNotificationDelivery
+}
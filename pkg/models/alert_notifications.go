@@ -144,6 +144,9 @@ type AlertNotificationState struct {
 	Version                      int64
 	UpdatedAt                    int64
 	AlertRuleStateUpdatedVersion int64
+	// ThreadId is the message opening the thread in which the notifiers
+	// supporting threads reply to, until the alert rule fires again
+	ThreadId string
 }
 
 type SetAlertNotificationStateToPendingCommand struct {
@@ -159,6 +162,13 @@ type SetAlertNotificationStateToCompleteCommand struct {
 	Version int64
 }
 
+// SetAlertNotificationThreadCommand sets the thread of a notification state,
+// without changing its version
+type SetAlertNotificationThreadCommand struct {
+	Id       int64
+	ThreadId string
+}
+
 type GetOrCreateNotificationStateQuery struct {
 	OrgId      int64
 	AlertId    int64
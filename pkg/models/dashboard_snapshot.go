@@ -80,6 +80,9 @@ type DeleteDashboardSnapshotCommand struct {
 }
 
 type DeleteExpiredSnapshotsCommand struct {
+	// DryRun counts the snapshots that would be deleted without deleting them
+	DryRun bool
+
 	DeletedRows int64
 }
 
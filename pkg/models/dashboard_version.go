@@ -81,6 +81,19 @@ type GetDashboardVersionsQuery struct {
 // Commands
 //
 
+// DeleteExpiredVersionsCommand deletes the versions exceeding MaxCount per
+// dashboard and the versions older than MaxAge. The latest version of each
+// dashboard is always kept. Without MaxCount and MaxAge, the versions exceeding
+// the versions_to_keep setting are deleted.
 type DeleteExpiredVersionsCommand struct {
+	MaxCount int64
+	MaxAge   time.Duration
+	// OrgId limits the deletion to the dashboards of the organization
+	OrgId int64
+	// ExcludeOrgIds excludes the dashboards of the organizations from the deletion
+	ExcludeOrgIds []int64
+	// DryRun counts the versions that would be deleted without deleting them
+	DryRun bool
+
 	DeletedRows int64
 }
@@ -11,6 +11,15 @@ type LoginAttempt struct {
 	Created   int64
 }
 
+// LoginLockout is a temporary lockout of a user account after too many
+// failed login attempts.
+type LoginLockout struct {
+	Id          int64
+	UserId      int64
+	LockedUntil int64
+	Created     int64
+}
+
 // ---------------------
 // COMMANDS
 
@@ -22,15 +31,53 @@ type CreateLoginAttemptCommand struct {
 }
 
 type DeleteOldLoginAttemptsCommand struct {
-	OlderThan   time.Time
+	OlderThan time.Time
+	// DryRun counts the login attempts that would be deleted without deleting them
+	DryRun      bool
+	DeletedRows int64
+}
+
+type DeleteExpiredLoginLockoutsCommand struct {
+	Now time.Time
+	// DryRun counts the lockouts that would be deleted without deleting them
+	DryRun      bool
 	DeletedRows int64
 }
 
+type LockUserCommand struct {
+	UserId int64
+	Until  time.Time
+}
+
+// UnlockUserCommand removes the lockout of a user account along with the
+// failed login attempts of the user.
+type UnlockUserCommand struct {
+	UserId int64
+}
+
 // ---------------------
 // QUERIES
 
+// GetUserLoginAttemptCountQuery counts the failed login attempts for a
+// username, from IpAddress only if it's set.
 type GetUserLoginAttemptCountQuery struct {
-	Username string
-	Since    time.Time
-	Result   int64
+	Username  string
+	IpAddress string
+	Since     time.Time
+	Result    int64
+	// LastAttempt is the time of the most recent attempt counted.
+	LastAttempt time.Time
+}
+
+type GetIPLoginAttemptCountQuery struct {
+	IpAddress string
+	Since     time.Time
+	Result    int64
+}
+
+// GetUserLockoutQuery returns the time until which the user account is
+// locked. It's zero if the account isn't locked.
+type GetUserLockoutQuery struct {
+	UserId int64
+	Result time.Time
 }
@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var ErrInvalidEmailCode = errors.New("invalid or expired email code")
 var ErrSmtpNotEnabled = errors.New("SMTP not configured, check your grafana.ini config file's [smtp] section")
@@ -37,12 +40,20 @@ type SendWebhookSync struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	// Validation is called with the response of the webhook, replacing the
+	// check of the status code when set
+	Validation func(body []byte, statusCode int) error
 }
 
 type SendResetPasswordEmailCommand struct {
 	User *User
 }
 
+type SendUserLockedOutEmailCommand struct {
+	User        *User
+	LockedUntil time.Time
+}
+
 type ValidateResetPasswordCodeQuery struct {
 	Code   string
 	Result *User
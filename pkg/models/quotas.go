@@ -28,6 +28,8 @@ type OrgQuotaDTO struct {
 	Target string `json:"target"`
 	Limit  int64  `json:"limit"`
 	Used   int64  `json:"used"`
+	// Interval is the interval the requests of rate quotas are counted in
+	Interval string `json:"interval,omitempty"`
 }
 
 type UserQuotaDTO struct {
@@ -50,6 +52,13 @@ type GetOrgQuotaByTargetQuery struct {
 	Result  *OrgQuotaDTO
 }
 
+type GetOrgQuotaLimitQuery struct {
+	Target  string
+	OrgId   int64
+	Default int64
+	Result  int64
+}
+
 type GetOrgQuotasQuery struct {
 	OrgId  int64
 	Result []*OrgQuotaDTO
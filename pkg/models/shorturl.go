@@ -21,6 +21,8 @@ type ShortUrl struct {
 
 type DeleteShortUrlCommand struct {
 	OlderThan time.Time
+	// DryRun counts the short URLs that would be deleted without deleting them
+	DryRun bool
 
 	NumDeleted int64
 }
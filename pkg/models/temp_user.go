@@ -63,6 +63,8 @@ type UpdateTempUserStatusCommand struct {
 
 type ExpireTempUsersCommand struct {
 	OlderThan time.Time
+	// DryRun counts the invites that would be expired without expiring them
+	DryRun bool
 
 	NumExpired int64
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"time"
 )
 
 // Typed errors
@@ -31,6 +32,7 @@ type UserToken struct {
 	RotatedAt     int64
 	CreatedAt     int64
 	UpdatedAt     int64
+	Label         string
 	UnhashedToken string
 }
 
@@ -38,6 +40,65 @@ type RevokeAuthTokenCmd struct {
 	AuthTokenId int64 `json:"authTokenId"`
 }
 
+type UpdateAuthTokenLabelCmd struct {
+	Label string `json:"label" binding:"MaxSize(100)"`
+}
+
+// Reasons for revoking user tokens
+const (
+	UserTokenRevokedByUser          = "user"
+	UserTokenRevokedByAdmin         = "admin"
+	UserTokenRevokedBySessionsLimit = "max_concurrent_sessions"
+	UserTokenRevokedByLogout        = "logout"
+	UserTokenRevokedByUserDisabled  = "user_disabled"
+)
+
+// UserTokenRevocation is who revokes user tokens and why, published in the
+// UserTokenRevoked events of the tokens revoked with a context carrying it.
+type UserTokenRevocation struct {
+	RevokedBy int64
+	Reason    string
+}
+
+type userTokenRevocationKey struct{}
+
+// WithUserTokenRevocation returns a context carrying the user revoking tokens
+// and the reason.
+func WithUserTokenRevocation(ctx context.Context, revokedBy int64, reason string) context.Context {
+	return context.WithValue(ctx, userTokenRevocationKey{}, UserTokenRevocation{RevokedBy: revokedBy, Reason: reason})
+}
+
+// UserTokenRevocationFromContext returns the user revoking tokens and the
+// reason carried by the context, if any.
+func UserTokenRevocationFromContext(ctx context.Context) (UserTokenRevocation, bool) {
+	revocation, ok := ctx.Value(userTokenRevocationKey{}).(UserTokenRevocation)
+	return revocation, ok
+}
+
+// SearchUserTokensQuery filters the active tokens of the users of an organization.
+// Filters left empty match all tokens.
+type SearchUserTokensQuery struct {
+	OrgId         int64
+	UserId        int64
+	SeenAfter     time.Time
+	SeenBefore    time.Time
+	IPRange       *net.IPNet
+	ExcludeTokens []int64
+	// ExcludeGrafanaAdmins excludes the tokens of Grafana server admins,
+	// whose sessions can't be managed by org admins
+	ExcludeGrafanaAdmins bool
+	Limit                int
+}
+
+// RevokeUserTokensCmd revokes the tokens matching the query, for example all
+// sessions of a user from an IP range. RevokedBy is the ID of the user
+// revoking the tokens, recorded in the events published for the tokens.
+type RevokeUserTokensCmd struct {
+	Query     SearchUserTokensQuery
+	RevokedBy int64
+	Reason    string
+}
+
 // UserTokenService are used for generating and validating user tokens
 type UserTokenService interface {
 	CreateToken(ctx context.Context, user *User, clientIP net.IP, userAgent string) (*UserToken, error)
@@ -48,4 +109,7 @@ type UserTokenService interface {
 	ActiveTokenCount(ctx context.Context) (int64, error)
 	GetUserToken(ctx context.Context, userId, userTokenId int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userId int64) ([]*UserToken, error)
+	SearchUserTokens(ctx context.Context, query *SearchUserTokensQuery) ([]*UserToken, error)
+	RevokeUserTokens(ctx context.Context, cmd *RevokeUserTokensCmd) ([]*UserToken, error)
+	SetUserTokenLabel(ctx context.Context, token *UserToken, label string) error
 }
@@ -0,0 +1,248 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ErrHADisabled is returned when getting the cluster state while the alert
+// rules aren't sharded across the Grafana instances
+var ErrHADisabled = errors.New("alerting HA is not enabled")
+
+// cluster shards the alert rules across the Grafana instances sending
+// heartbeats to the alert_node table. Each rule is owned by a single live node,
+// chosen by rendezvous hashing so that only the rules of the nodes joining or
+// leaving move when the cluster changes.
+type cluster struct {
+	nodeID            string
+	address           string
+	started           time.Time
+	heartbeatInterval time.Duration
+	nodeTimeout       time.Duration
+	log               log.Logger
+
+	mu    sync.RWMutex
+	nodes []string
+}
+
+func newCluster(cfg *setting.Cfg) *cluster {
+	return &cluster{
+		nodeID:            util.GenerateShortUID(),
+		address:           setting.InstanceName,
+		started:           time.Now(),
+		heartbeatInterval: cfg.AlertingHAHeartbeatInterval,
+		nodeTimeout:       cfg.AlertingHANodeTimeout,
+		log:               log.New("alerting.cluster"),
+	}
+}
+
+// run sends the heartbeats of the node until the context is done, and then
+// removes the node so that its rules are rebalanced right away.
+func (c *cluster) run(ctx context.Context) error {
+	ticker := time.NewTicker(c.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := bus.Dispatch(&models.RemoveAlertNodeCommand{NodeId: c.nodeID}); err != nil {
+				c.log.Warn("Failed to remove alerting node", "nodeId", c.nodeID, "error", err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := c.heartbeat(ctx); err != nil {
+				c.log.Error("Failed to send alerting node heartbeat", "nodeId", c.nodeID, "error", err)
+			}
+		}
+	}
+}
+
+// heartbeat registers the node as alive and refreshes the live nodes
+func (c *cluster) heartbeat(ctx context.Context) error {
+	now := time.Now()
+	cmd := &models.HeartbeatAlertNodeCommand{NodeId: c.nodeID, Address: c.address, Started: c.started, Now: now}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return err
+	}
+
+	expiredCmd := &models.DeleteExpiredAlertNodesCommand{HeartbeatBefore: now.Add(-10 * c.nodeTimeout)}
+	if err := bus.DispatchCtx(ctx, expiredCmd); err != nil {
+		c.log.Warn("Failed to delete expired alerting nodes", "error", err)
+	}
+
+	query := &models.GetAlertNodesQuery{ActiveSince: now.Add(-c.nodeTimeout)}
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		return err
+	}
+
+	nodes := make([]string, 0, len(query.Result))
+	for _, node := range query.Result {
+		nodes = append(nodes, node.NodeId)
+	}
+
+	c.mu.Lock()
+	changed := !stringsEqual(c.nodes, nodes)
+	c.nodes = nodes
+	c.mu.Unlock()
+
+	if changed {
+		c.log.Info("Alerting nodes changed, rebalancing alert rules", "nodeId", c.nodeID, "nodes", nodes)
+	}
+	return nil
+}
+
+// members returns the live nodes, which always include this node so
+// that it keeps evaluating its rules while its heartbeats are failing
+func (c *cluster) members() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, node := range c.nodes {
+		if node == c.nodeID {
+			return c.nodes
+		}
+	}
+
+	nodes := append([]string{c.nodeID}, c.nodes...)
+	sort.Strings(nodes)
+	return nodes
+}
+
+// ownedRules returns the rules owned by this node
+func (c *cluster) ownedRules(rules []*Rule) []*Rule {
+	nodes := c.members()
+
+	owned := make([]*Rule, 0, len(rules)/len(nodes)+1)
+	for _, rule := range rules {
+		if ruleOwner(nodes, rule.ID) == c.nodeID {
+			owned = append(owned, rule)
+		}
+	}
+	return owned
+}
+
+// ruleOwner returns the node with the highest score for the rule
+func ruleOwner(nodes []string, ruleID int64) string {
+	var owner string
+	var ownerScore uint64
+	for _, node := range nodes {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(node))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(strconv.FormatInt(ruleID, 10)))
+		score := mix64(h.Sum64())
+
+		if owner == "" || score > ownerScore {
+			owner, ownerScore = node, score
+		}
+	}
+	return owner
+}
+
+// mix64 is the finalizer of MurmurHash3, which spreads the FNV hashes of
+// inputs that only differ by a few bytes across the whole range
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb3f99ce5e2ff
+	h ^= h >> 33
+	return h
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ClusterState is the live nodes of the cluster and the alert rules they own
+type ClusterState struct {
+	NodeId string              `json:"nodeId"`
+	Nodes  []*ClusterNodeState `json:"nodes"`
+}
+
+// ClusterNodeState is a live node of the cluster
+type ClusterNodeState struct {
+	NodeId        string              `json:"nodeId"`
+	Address       string              `json:"address"`
+	Started       time.Time           `json:"started"`
+	LastHeartbeat time.Time           `json:"lastHeartbeat"`
+	Rules         []*ClusterRuleState `json:"rules"`
+}
+
+// ClusterRuleState is an alert rule owned by a node of the cluster
+type ClusterRuleState struct {
+	Id          int64  `json:"id"`
+	OrgId       int64  `json:"orgId"`
+	DashboardId int64  `json:"dashboardId"`
+	PanelId     int64  `json:"panelId"`
+	Name        string `json:"name"`
+}
+
+// ClusterState returns the live nodes of the cluster with the alert rules
+// they own, as seen by this node.
+func (e *AlertEngine) ClusterState(ctx context.Context) (*ClusterState, error) {
+	if e.cluster == nil {
+		return nil, ErrHADisabled
+	}
+
+	nodesQuery := &models.GetAlertNodesQuery{ActiveSince: time.Now().Add(-e.cluster.nodeTimeout)}
+	if err := bus.DispatchCtx(ctx, nodesQuery); err != nil {
+		return nil, err
+	}
+	alertsQuery := &models.GetAllAlertsQuery{}
+	if err := bus.DispatchCtx(ctx, alertsQuery); err != nil {
+		return nil, err
+	}
+
+	state := &ClusterState{NodeId: e.cluster.nodeID, Nodes: []*ClusterNodeState{}}
+	if len(nodesQuery.Result) == 0 {
+		return state, nil
+	}
+
+	nodeIDs := make([]string, 0, len(nodesQuery.Result))
+	nodes := make(map[string]*ClusterNodeState, len(nodesQuery.Result))
+	for _, node := range nodesQuery.Result {
+		nodeState := &ClusterNodeState{
+			NodeId:        node.NodeId,
+			Address:       node.Address,
+			Started:       time.Unix(node.Started, 0),
+			LastHeartbeat: time.Unix(node.Heartbeat, 0),
+			Rules:         []*ClusterRuleState{},
+		}
+		nodeIDs = append(nodeIDs, node.NodeId)
+		nodes[node.NodeId] = nodeState
+		state.Nodes = append(state.Nodes, nodeState)
+	}
+
+	for _, alert := range alertsQuery.Result {
+		owner := nodes[ruleOwner(nodeIDs, alert.Id)]
+		owner.Rules = append(owner.Rules, &ClusterRuleState{
+			Id:          alert.Id,
+			OrgId:       alert.OrgId,
+			DashboardId: alert.DashboardId,
+			PanelId:     alert.PanelId,
+			Name:        alert.Name,
+		})
+	}
+
+	return state, nil
+}
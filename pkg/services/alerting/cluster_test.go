@@ -0,0 +1,176 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleOwner(t *testing.T) {
+	nodes := []string{"a", "b", "c"}
+
+	t.Run("should be deterministic", func(t *testing.T) {
+		for ruleID := int64(1); ruleID <= 100; ruleID++ {
+			owner := ruleOwner(nodes, ruleID)
+			assert.Equal(t, owner, ruleOwner([]string{"c", "a", "b"}, ruleID))
+		}
+	})
+
+	t.Run("should spread the rules across the nodes", func(t *testing.T) {
+		counts := map[string]int{}
+		for ruleID := int64(1); ruleID <= 300; ruleID++ {
+			counts[ruleOwner(nodes, ruleID)]++
+		}
+		for _, node := range nodes {
+			assert.Greater(t, counts[node], 50, "node %s owns too few rules", node)
+		}
+	})
+
+	t.Run("should only move the rules of a node that joins", func(t *testing.T) {
+		joined := append([]string{"d"}, nodes...)
+		for ruleID := int64(1); ruleID <= 300; ruleID++ {
+			before, after := ruleOwner(nodes, ruleID), ruleOwner(joined, ruleID)
+			if before != after {
+				assert.Equal(t, "d", after)
+			}
+		}
+	})
+
+	t.Run("should only move the rules of a node that leaves", func(t *testing.T) {
+		left := []string{"a", "c"}
+		for ruleID := int64(1); ruleID <= 300; ruleID++ {
+			before, after := ruleOwner(nodes, ruleID), ruleOwner(left, ruleID)
+			if before != after {
+				assert.Equal(t, "b", before)
+			}
+		}
+	})
+}
+
+func TestClusterOwnedRules(t *testing.T) {
+	rules := make([]*Rule, 0, 100)
+	for ruleID := int64(1); ruleID <= 100; ruleID++ {
+		rules = append(rules, &Rule{ID: ruleID})
+	}
+
+	t.Run("should partition the rules across the nodes", func(t *testing.T) {
+		nodes := []string{"a", "b", "c"}
+		owners := map[int64]string{}
+		for _, node := range nodes {
+			c := &cluster{nodeID: node, nodes: nodes}
+			for _, rule := range c.ownedRules(rules) {
+				_, exists := owners[rule.ID]
+				require.False(t, exists, "rule %d is owned by several nodes", rule.ID)
+				owners[rule.ID] = node
+			}
+		}
+		require.Len(t, owners, len(rules))
+	})
+
+	t.Run("should own all the rules before the first heartbeat", func(t *testing.T) {
+		c := &cluster{nodeID: "a"}
+		require.Len(t, c.ownedRules(rules), len(rules))
+	})
+
+	t.Run("should include itself when missing from the live nodes", func(t *testing.T) {
+		c := &cluster{nodeID: "a", nodes: []string{"b"}}
+		require.Equal(t, []string{"a", "b"}, c.members())
+	})
+}
+
+type fakeRuleReader struct {
+	rules []*Rule
+}
+
+func (r *fakeRuleReader) fetch() []*Rule {
+	return r.rules
+}
+
+func TestAlertEngineUpdateRules(t *testing.T) {
+	rules := make([]*Rule, 0, 100)
+	for ruleID := int64(1); ruleID <= 100; ruleID++ {
+		rules = append(rules, &Rule{ID: ruleID})
+	}
+
+	nodes := []string{"a", "b", "c"}
+	e := &AlertEngine{
+		ruleReader: &fakeRuleReader{rules: rules},
+		scheduler:  newScheduler(),
+		cluster:    &cluster{nodeID: "a", nodes: nodes},
+	}
+	e.updateRules()
+
+	owned := e.cluster.ownedRules(rules)
+	require.Less(t, len(owned), len(rules))
+	require.Equal(t, float64(len(owned)), testutil.ToFloat64(metrics.MAlertingActiveAlerts))
+}
+
+func TestClusterHeartbeat(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	var heartbeats []*models.HeartbeatAlertNodeCommand
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.HeartbeatAlertNodeCommand) error {
+		heartbeats = append(heartbeats, cmd)
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.DeleteExpiredAlertNodesCommand) error {
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNodesQuery) error {
+		query.Result = []*models.AlertNode{{NodeId: "a"}, {NodeId: "b"}}
+		return nil
+	})
+
+	c := &cluster{nodeID: "a", nodeTimeout: time.Minute, log: log.New("test")}
+	require.NoError(t, c.heartbeat(context.Background()))
+
+	require.Len(t, heartbeats, 1)
+	require.Equal(t, "a", heartbeats[0].NodeId)
+	require.Equal(t, []string{"a", "b"}, c.members())
+}
+
+func TestAlertEngineClusterState(t *testing.T) {
+	t.Run("should fail when HA is disabled", func(t *testing.T) {
+		e := &AlertEngine{}
+		_, err := e.ClusterState(context.Background())
+		require.ErrorIs(t, err, ErrHADisabled)
+	})
+
+	t.Run("should return the rules owned by each node", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNodesQuery) error {
+			query.Result = []*models.AlertNode{{NodeId: "a"}, {NodeId: "b"}}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAllAlertsQuery) error {
+			for id := int64(1); id <= 10; id++ {
+				query.Result = append(query.Result, &models.Alert{Id: id})
+			}
+			return nil
+		})
+
+		e := &AlertEngine{cluster: &cluster{nodeID: "a", nodeTimeout: time.Minute}}
+		state, err := e.ClusterState(context.Background())
+		require.NoError(t, err)
+
+		require.Equal(t, "a", state.NodeId)
+		require.Len(t, state.Nodes, 2)
+		total := 0
+		for _, node := range state.Nodes {
+			for _, rule := range node.Rules {
+				require.Equal(t, ruleOwner([]string{"a", "b"}, rule.Id), node.NodeId)
+			}
+			total += len(node.Rules)
+		}
+		require.Equal(t, 10, total)
+	})
+}
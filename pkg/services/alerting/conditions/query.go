@@ -58,6 +58,7 @@ func (c *QueryCondition) Eval(context *alerting.EvalContext) (*alerting.Conditio
 	emptySeriesCount := 0
 	evalMatchCount := 0
 	var matches []*alerting.EvalMatch
+	var evaluated []*alerting.SeriesEvaluation
 
 	for _, series := range seriesList {
 		reducedValue := c.Reducer.Reduce(series)
@@ -67,6 +68,13 @@ func (c *QueryCondition) Eval(context *alerting.EvalContext) (*alerting.Conditio
 			emptySeriesCount++
 		}
 
+		evaluated = append(evaluated, &alerting.SeriesEvaluation{
+			Metric: series.Name,
+			Tags:   series.Tags,
+			Value:  reducedValue,
+			Match:  evalMatch,
+		})
+
 		if context.IsTestRun {
 			context.Logs = append(context.Logs, &alerting.ResultLogEntry{
 				Message: fmt.Sprintf("Condition[%d]: Eval: %v, Metric: %s, Value: %s", c.Index, evalMatch, series.Name, reducedValue),
@@ -88,6 +96,11 @@ func (c *QueryCondition) Eval(context *alerting.EvalContext) (*alerting.Conditio
 	if len(seriesList) == 0 {
 		// eval condition for null value
 		evalMatch := c.Evaluator.Eval(null.FloatFromPtr(nil))
+		evaluated = append(evaluated, &alerting.SeriesEvaluation{
+			Metric: "NoData",
+			Value:  null.FloatFromPtr(nil),
+			Match:  evalMatch,
+		})
 
 		if context.IsTestRun {
 			context.Logs = append(context.Logs, &alerting.ResultLogEntry{
@@ -106,6 +119,7 @@ func (c *QueryCondition) Eval(context *alerting.EvalContext) (*alerting.Conditio
 		NoDataFound: emptySeriesCount == len(seriesList),
 		Operator:    c.Operator,
 		EvalMatches: matches,
+		Series:      evaluated,
 	}, nil
 }
 
@@ -107,6 +107,22 @@ func TestQueryCondition(t *testing.T) {
 				So(cr.Firing, ShouldBeTrue)
 			})
 
+			Convey("Should return the reduced value of every series", func() {
+				ctx.series = tsdb.TimeSeriesSlice{
+					&tsdb.TimeSeries{Name: "test1", Points: newTimeSeriesPointsFromArgs(120, 0)},
+					&tsdb.TimeSeries{Name: "test2", Points: newTimeSeriesPointsFromArgs(0, 0)},
+				}
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(cr.Series, ShouldHaveLength, 2)
+				So(cr.Series[0].Metric, ShouldEqual, "test1")
+				So(cr.Series[0].Value.Float64, ShouldEqual, 120)
+				So(cr.Series[0].Match, ShouldBeTrue)
+				So(cr.Series[1].Metric, ShouldEqual, "test2")
+				So(cr.Series[1].Match, ShouldBeFalse)
+			})
+
 			Convey("No series", func() {
 				Convey("Should set NoDataFound when condition is gt", func() {
 					ctx.series = tsdb.TimeSeriesSlice{}
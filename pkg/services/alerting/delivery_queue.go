@@ -0,0 +1,367 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// channelLimiter limits the number of notifications sent at the same time
+// through each notification channel.
+type channelLimiter struct {
+	limit int
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+func newChannelLimiter(limit int) *channelLimiter {
+	return &channelLimiter{limit: limit, slots: map[string]chan struct{}{}}
+}
+
+// acquire waits for a free slot of the channel, returning a function
+// releasing the slot. A limit of 0 or less disables the limiter.
+func (l *channelLimiter) acquire(ctx context.Context, uid string) (func(), error) {
+	if l == nil || l.limit <= 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	slots, ok := l.slots[uid]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.slots[uid] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// deliverySnapshot is the part of the evaluation context stored with a queued
+// delivery, from which the notification is rendered again when retried.
+type deliverySnapshot struct {
+	RuleID          int64                 `json:"ruleId"`
+	OrgID           int64                 `json:"orgId"`
+	DashboardID     int64                 `json:"dashboardId"`
+	PanelID         int64                 `json:"panelId"`
+	Name            string                `json:"name"`
+	Message         string                `json:"message"`
+	State           models.AlertStateType `json:"state"`
+	PrevState       models.AlertStateType `json:"prevState"`
+	Tags            []*models.Tag         `json:"tags"`
+	StateChanges    int64                 `json:"stateChanges"`
+	Firing          bool                  `json:"firing"`
+	NoDataFound     bool                  `json:"noDataFound"`
+	EvalMatches     []*EvalMatch          `json:"evalMatches"`
+	Error           string                `json:"error,omitempty"`
+	StartTime       time.Time             `json:"startTime"`
+	EndTime         time.Time             `json:"endTime"`
+	ImagePublicURL  string                `json:"imagePublicUrl,omitempty"`
+	ImageOnDiskPath string                `json:"imageOnDiskPath,omitempty"`
+}
+
+func newDeliverySnapshot(c *EvalContext) *deliverySnapshot {
+	snapshot := &deliverySnapshot{
+		RuleID:          c.Rule.ID,
+		OrgID:           c.Rule.OrgID,
+		DashboardID:     c.Rule.DashboardID,
+		PanelID:         c.Rule.PanelID,
+		Name:            c.Rule.Name,
+		Message:         c.Rule.Message,
+		State:           c.Rule.State,
+		PrevState:       c.PrevAlertState,
+		Tags:            c.Rule.AlertRuleTags,
+		StateChanges:    c.Rule.StateChanges,
+		Firing:          c.Firing,
+		NoDataFound:     c.NoDataFound,
+		EvalMatches:     c.EvalMatches,
+		StartTime:       c.StartTime,
+		EndTime:         c.EndTime,
+		ImagePublicURL:  c.ImagePublicURL,
+		ImageOnDiskPath: c.ImageOnDiskPath,
+	}
+	if c.Error != nil {
+		snapshot.Error = c.Error.Error()
+	}
+	return snapshot
+}
+
+func (s *deliverySnapshot) evalContext(ctx context.Context) *EvalContext {
+	evalContext := NewEvalContext(ctx, &Rule{
+		ID:            s.RuleID,
+		OrgID:         s.OrgID,
+		DashboardID:   s.DashboardID,
+		PanelID:       s.PanelID,
+		Name:          s.Name,
+		Message:       s.Message,
+		State:         s.State,
+		AlertRuleTags: s.Tags,
+		StateChanges:  s.StateChanges,
+	})
+	evalContext.PrevAlertState = s.PrevState
+	evalContext.Firing = s.Firing
+	evalContext.NoDataFound = s.NoDataFound
+	evalContext.StartTime = s.StartTime
+	evalContext.EndTime = s.EndTime
+	evalContext.ImagePublicURL = s.ImagePublicURL
+	evalContext.ImageOnDiskPath = s.ImageOnDiskPath
+	if s.EvalMatches != nil {
+		evalContext.EvalMatches = s.EvalMatches
+	}
+	if s.Error != "" {
+		evalContext.Error = errors.New(s.Error)
+	}
+	return evalContext
+}
+
+// retryBackoff returns the delay before the next attempt of a delivery,
+// doubling with each attempt up to the maximum backoff
+func retryBackoff(attempts int) time.Duration {
+	backoff := setting.AlertingNotificationRetryBackoff
+	for i := 1; i < attempts && backoff < setting.AlertingNotificationRetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > setting.AlertingNotificationRetryMaxBackoff {
+		backoff = setting.AlertingNotificationRetryMaxBackoff
+	}
+	return backoff
+}
+
+// enqueueRetry queues a notification that failed to be sent for the first
+// time, returning whether it will be retried
+func (n *notificationService) enqueueRetry(evalContext *EvalContext, notifierState *notifierState, sendErr error) bool {
+	if setting.AlertingNotificationMaxAttempts <= 1 || notifierState.state == nil {
+		return false
+	}
+
+	notifier := notifierState.notifier
+	payload, err := json.Marshal(newDeliverySnapshot(evalContext))
+	if err != nil {
+		n.log.Error("Failed to encode notification for retry", "uid", notifier.GetNotifierUID(), "error", err)
+		return false
+	}
+
+	now := time.Now()
+	cmd := &models.EnqueueAlertNotificationDeliveryCommand{
+		OrgId:                    evalContext.Rule.OrgID,
+		AlertId:                  evalContext.Rule.ID,
+		NotifierId:               notifierState.state.NotifierId,
+		NotifierUid:              notifier.GetNotifierUID(),
+		NotifierType:             notifier.GetType(),
+		NotificationStateId:      notifierState.state.Id,
+		NotificationStateVersion: notifierState.state.Version,
+		Attempts:                 1,
+		NextAttempt:              now.Add(retryBackoff(1)),
+		LastError:                sendErr.Error(),
+		Payload:                  string(payload),
+		Created:                  now,
+	}
+	// the context of the evaluation may be the reason the notification failed,
+	// so queue it regardless
+	if err := bus.Dispatch(cmd); err != nil {
+		n.log.Error("Failed to queue notification for retry", "uid", notifier.GetNotifierUID(), "error", err)
+		return false
+	}
+
+	metrics.MAlertingNotificationRetried.WithLabelValues(notifier.GetType()).Inc()
+	n.log.Info("Queued notification for retry", "uid", notifier.GetNotifierUID(), "deliveryId", cmd.Result.Id, "nextAttempt", cmd.NextAttempt)
+	return true
+}
+
+var (
+	deliveryQueuePollInterval = time.Second * 10
+	deliveryQueueBatchSize    = 100
+)
+
+// deliveryQueue retries the notifications that failed to be sent, with an
+// exponential backoff, until they're sent or fail all their attempts and are
+// kept as dead letters. The queue is stored in the database, so the
+// notifications survive restarts and are shared by the Grafana instances.
+type deliveryQueue struct {
+	notifier *notificationService
+	log      log.Logger
+}
+
+func newDeliveryQueue(notifier *notificationService) *deliveryQueue {
+	return &deliveryQueue{
+		notifier: notifier,
+		log:      log.New("alerting.deliveryQueue"),
+	}
+}
+
+func (q *deliveryQueue) run(ctx context.Context) error {
+	ticker := time.NewTicker(deliveryQueuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := q.processDue(ctx, time.Now()); err != nil {
+				q.log.Error("Failed to process notification delivery queue", "error", err)
+			}
+		}
+	}
+}
+
+// processDue retries the deliveries due at now, waiting for them to complete
+func (q *deliveryQueue) processDue(ctx context.Context, now time.Time) error {
+	query := &models.GetDueAlertNotificationDeliveriesQuery{Now: now, Limit: deliveryQueueBatchSize}
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range query.Result {
+		wg.Add(1)
+		go func(delivery *models.AlertNotificationDelivery) {
+			defer wg.Done()
+			defer func() {
+				if err := recover(); err != nil {
+					q.log.Error("Notification delivery panic", "deliveryId", delivery.Id, "error", err, "stack", log.Stack(1))
+				}
+			}()
+			q.claimAndDeliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// claimAndDeliver sends the delivery once a slot of its notification channel
+// is free, unless another instance claimed it in the meantime. The slot is
+// taken before claiming, so that the claim only has to cover the send.
+func (q *deliveryQueue) claimAndDeliver(ctx context.Context, delivery *models.AlertNotificationDelivery) {
+	release, err := q.notifier.limiter.acquire(ctx, delivery.NotifierUid)
+	if err != nil {
+		return
+	}
+	defer release()
+
+	// postpone the next attempt while this one runs, so that the delivery is
+	// picked up again if this instance goes away before recording the result
+	claim := &models.ClaimAlertNotificationDeliveryCommand{
+		Id:       delivery.Id,
+		Attempts: delivery.Attempts,
+		Until:    time.Now().Add(2 * setting.AlertingNotificationTimeout),
+	}
+	if err := bus.DispatchCtx(ctx, claim); err != nil {
+		q.log.Error("Failed to claim notification delivery", "deliveryId", delivery.Id, "error", err)
+		return
+	}
+	if !claim.Claimed {
+		return
+	}
+	delivery.Attempts++
+
+	q.deliver(ctx, delivery)
+}
+
+func (q *deliveryQueue) deliver(ctx context.Context, delivery *models.AlertNotificationDelivery) {
+	logger := q.log.New("deliveryId", delivery.Id, "uid", delivery.NotifierUid, "attempt", delivery.Attempts)
+
+	notificationQuery := &models.GetAlertNotificationsQuery{OrgId: delivery.OrgId, Id: delivery.NotifierId}
+	if err := bus.DispatchCtx(ctx, notificationQuery); err != nil {
+		q.fail(ctx, logger, delivery, err)
+		return
+	}
+	if notificationQuery.Result == nil {
+		logger.Info("Dropping notification of a deleted notification channel")
+		q.remove(ctx, logger, delivery)
+		return
+	}
+
+	if delivery.NotificationStateVersion > 0 {
+		stateQuery := &models.GetOrCreateNotificationStateQuery{
+			OrgId:      delivery.OrgId,
+			AlertId:    delivery.AlertId,
+			NotifierId: delivery.NotifierId,
+		}
+		if err := bus.DispatchCtx(ctx, stateQuery); err != nil {
+			q.fail(ctx, logger, delivery, err)
+			return
+		}
+		if stateQuery.Result.Version != delivery.NotificationStateVersion {
+			logger.Info("Dropping notification superseded by a newer notification")
+			q.remove(ctx, logger, delivery)
+			return
+		}
+	}
+
+	notifier, err := InitNotifier(notificationQuery.Result)
+	if err != nil {
+		q.fail(ctx, logger, delivery, err)
+		return
+	}
+
+	var snapshot deliverySnapshot
+	if err := json.Unmarshal([]byte(delivery.Payload), &snapshot); err != nil {
+		q.fail(ctx, logger, delivery, err)
+		return
+	}
+
+	notifyCtx, cancel := context.WithTimeout(ctx, setting.AlertingNotificationTimeout)
+	defer cancel()
+	if err := q.notifier.send(snapshot.evalContext(notifyCtx), notifier); err != nil {
+		q.fail(ctx, logger, delivery, err)
+		return
+	}
+
+	logger.Info("Sent queued notification")
+	metrics.MAlertingNotificationDeliveryLatency.WithLabelValues(delivery.NotifierType).Observe(time.Since(time.Unix(delivery.Created, 0)).Seconds())
+	q.remove(ctx, logger, delivery)
+
+	if delivery.NotificationStateVersion > 0 {
+		cmd := &models.SetAlertNotificationStateToCompleteCommand{
+			Id:      delivery.NotificationStateId,
+			Version: delivery.NotificationStateVersion,
+		}
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			logger.Error("Failed to mark notification as complete", "error", err)
+		}
+	}
+}
+
+// fail records a failed attempt, the delivery becoming a dead letter after its
+// last attempt
+func (q *deliveryQueue) fail(ctx context.Context, logger log.Logger, delivery *models.AlertNotificationDelivery, sendErr error) {
+	cmd := &models.FailAlertNotificationDeliveryCommand{
+		Id:          delivery.Id,
+		Error:       sendErr.Error(),
+		NextAttempt: time.Now().Add(retryBackoff(delivery.Attempts)),
+		Dead:        delivery.Attempts >= setting.AlertingNotificationMaxAttempts,
+	}
+
+	if cmd.Dead {
+		logger.Error("Notification failed all its attempts", "error", sendErr)
+		metrics.MAlertingNotificationDeadLetters.WithLabelValues(delivery.NotifierType).Inc()
+	} else {
+		logger.Warn("Failed to send queued notification", "error", sendErr, "nextAttempt", cmd.NextAttempt)
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		logger.Error("Failed to record notification failure", "error", err)
+	}
+}
+
+func (q *deliveryQueue) remove(ctx context.Context, logger log.Logger, delivery *models.AlertNotificationDelivery) {
+	cmd := &models.DeleteAlertNotificationDeliveryCommand{Id: delivery.Id}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil && !errors.Is(err, models.ErrAlertNotificationDeliveryNotFound) {
+		logger.Error("Failed to remove notification from the delivery queue", "error", err)
+	}
+}
@@ -0,0 +1,344 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelLimiter(t *testing.T) {
+	limiter := newChannelLimiter(1)
+
+	release, err := limiter.acquire(context.Background(), "a")
+	require.NoError(t, err)
+
+	t.Run("should wait for a free slot of the channel", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := limiter.acquire(ctx, "a")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should not limit the other channels", func(t *testing.T) {
+		releaseB, err := limiter.acquire(context.Background(), "b")
+		require.NoError(t, err)
+		releaseB()
+	})
+
+	t.Run("should free the slot once released", func(t *testing.T) {
+		release()
+		release, err := limiter.acquire(context.Background(), "a")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("should not limit when disabled", func(t *testing.T) {
+		limiter := newChannelLimiter(0)
+		for i := 0; i < 3; i++ {
+			_, err := limiter.acquire(context.Background(), "a")
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	origBackoff, origMaxBackoff := setting.AlertingNotificationRetryBackoff, setting.AlertingNotificationRetryMaxBackoff
+	t.Cleanup(func() {
+		setting.AlertingNotificationRetryBackoff, setting.AlertingNotificationRetryMaxBackoff = origBackoff, origMaxBackoff
+	})
+	setting.AlertingNotificationRetryBackoff = 30 * time.Second
+	setting.AlertingNotificationRetryMaxBackoff = 5 * time.Minute
+
+	assert.Equal(t, 30*time.Second, retryBackoff(1))
+	assert.Equal(t, time.Minute, retryBackoff(2))
+	assert.Equal(t, 4*time.Minute, retryBackoff(4))
+	assert.Equal(t, 5*time.Minute, retryBackoff(5))
+	assert.Equal(t, 5*time.Minute, retryBackoff(100))
+}
+
+func TestDeliverySnapshot(t *testing.T) {
+	evalContext := NewEvalContext(context.Background(), &Rule{
+		ID:            1,
+		OrgID:         2,
+		DashboardID:   3,
+		PanelID:       4,
+		Name:          "High CPU",
+		Message:       "CPU is too high",
+		State:         models.AlertStateAlerting,
+		AlertRuleTags: []*models.Tag{{Key: "team", Value: "backend"}},
+	})
+	evalContext.PrevAlertState = models.AlertStateOK
+	evalContext.Firing = true
+	evalContext.EvalMatches = []*EvalMatch{{Metric: "cpu", Value: null.FloatFrom(95)}}
+	evalContext.Error = errors.New("timeout")
+	evalContext.ImagePublicURL = "https://example.com/image.png"
+
+	payload, err := json.Marshal(newDeliverySnapshot(evalContext))
+	require.NoError(t, err)
+	var snapshot deliverySnapshot
+	require.NoError(t, json.Unmarshal(payload, &snapshot))
+
+	restored := snapshot.evalContext(context.Background())
+	assert.Equal(t, evalContext.Rule.ID, restored.Rule.ID)
+	assert.Equal(t, evalContext.Rule.DashboardID, restored.Rule.DashboardID)
+	assert.Equal(t, evalContext.GetNotificationTitle(), restored.GetNotificationTitle())
+	assert.Equal(t, evalContext.Rule.Message, restored.Rule.Message)
+	assert.Equal(t, evalContext.Rule.AlertRuleTags[0].Value, restored.Rule.AlertRuleTags[0].Value)
+	assert.Equal(t, models.AlertStateOK, restored.PrevAlertState)
+	assert.True(t, restored.Firing)
+	assert.Equal(t, "cpu", restored.EvalMatches[0].Metric)
+	assert.Equal(t, 95.0, restored.EvalMatches[0].Value.Float64)
+	assert.EqualError(t, restored.Error, "timeout")
+	assert.Equal(t, evalContext.ImagePublicURL, restored.ImagePublicURL)
+}
+
+type failingNotifier struct {
+	testNotifier
+	err      error
+	notified *int
+}
+
+func (n *failingNotifier) Notify(evalCtx *EvalContext) error {
+	if n.notified != nil {
+		*n.notified++
+	}
+	return n.err
+}
+
+func TestSendNotificationEnqueuesRetry(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	origMaxAttempts := setting.AlertingNotificationMaxAttempts
+	t.Cleanup(func() { setting.AlertingNotificationMaxAttempts = origMaxAttempts })
+
+	var enqueued *models.EnqueueAlertNotificationDeliveryCommand
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.EnqueueAlertNotificationDeliveryCommand) error {
+		enqueued = cmd
+		cmd.Result = &models.AlertNotificationDelivery{Id: 1}
+		return nil
+	})
+
+	newState := func() *notifierState {
+		return &notifierState{
+			notifier: &failingNotifier{testNotifier: testNotifier{UID: "slack", Type: "slack"}, err: errors.New("slack is down")},
+			state:    &models.AlertNotificationState{Id: 5, NotifierId: 6, Version: 2},
+		}
+	}
+	evalContext := NewEvalContext(context.Background(), &Rule{ID: 1, OrgID: 2, Name: "High CPU", State: models.AlertStateAlerting})
+
+	t.Run("should queue a failed notification for retry", func(t *testing.T) {
+		setting.AlertingNotificationMaxAttempts = 5
+		n := newNotificationService(nil)
+		err := n.sendAndMarkAsComplete(evalContext, newState())
+		require.Error(t, err)
+
+		require.NotNil(t, enqueued)
+		assert.Equal(t, int64(1), enqueued.AlertId)
+		assert.Equal(t, int64(6), enqueued.NotifierId)
+		assert.Equal(t, int64(5), enqueued.NotificationStateId)
+		assert.Equal(t, int64(2), enqueued.NotificationStateVersion)
+		assert.Equal(t, 1, enqueued.Attempts)
+		assert.Equal(t, "slack is down", enqueued.LastError)
+		assert.Equal(t, NotificationRetrying, evalContext.Notifications[len(evalContext.Notifications)-1].Result)
+	})
+
+	t.Run("should not queue when retries are disabled", func(t *testing.T) {
+		setting.AlertingNotificationMaxAttempts = 1
+		enqueued = nil
+		n := newNotificationService(nil)
+		err := n.sendAndMarkAsComplete(evalContext, newState())
+		require.Error(t, err)
+
+		require.Nil(t, enqueued)
+		assert.Equal(t, NotificationFailed, evalContext.Notifications[len(evalContext.Notifications)-1].Result)
+	})
+}
+
+func TestDeliveryQueue(t *testing.T) {
+	origMaxAttempts, origBackoff, origMaxBackoff := setting.AlertingNotificationMaxAttempts, setting.AlertingNotificationRetryBackoff, setting.AlertingNotificationRetryMaxBackoff
+	origConcurrency := setting.AlertingNotificationChannelConcurrency
+	t.Cleanup(func() {
+		setting.AlertingNotificationMaxAttempts = origMaxAttempts
+		setting.AlertingNotificationRetryBackoff, setting.AlertingNotificationRetryMaxBackoff = origBackoff, origMaxBackoff
+		setting.AlertingNotificationChannelConcurrency = origConcurrency
+	})
+	setting.AlertingNotificationChannelConcurrency = 1
+	setting.AlertingNotificationMaxAttempts = 3
+	setting.AlertingNotificationRetryBackoff = 30 * time.Second
+	setting.AlertingNotificationRetryMaxBackoff = time.Hour
+
+	var sendErr, claimErr error
+	sent := 0
+	RegisterNotifier(&NotifierPlugin{
+		Type: "queued",
+		Name: "Queued",
+		Factory: func(model *models.AlertNotification) (Notifier, error) {
+			return &failingNotifier{testNotifier: testNotifier{UID: model.Uid, Type: model.Type}, err: sendErr, notified: &sent}, nil
+		},
+	})
+
+	payload, err := json.Marshal(newDeliverySnapshot(NewEvalContext(context.Background(), &Rule{
+		ID:    1,
+		OrgID: 2,
+		Name:  "High CPU",
+		State: models.AlertStateAlerting,
+	})))
+	require.NoError(t, err)
+
+	type queueResult struct {
+		claims    []*models.ClaimAlertNotificationDeliveryCommand
+		slotTaken bool
+		removed   []int64
+		failed    []*models.FailAlertNotificationDeliveryCommand
+		completed []*models.SetAlertNotificationStateToCompleteCommand
+	}
+
+	queueScenario := func(t *testing.T, delivery *models.AlertNotificationDelivery, claimed bool, stateVersion int64) *queueResult {
+		t.Cleanup(bus.ClearBusHandlers)
+		result := &queueResult{}
+		sent = 0
+		q := newDeliveryQueue(newNotificationService(nil))
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDueAlertNotificationDeliveriesQuery) error {
+			query.Result = []*models.AlertNotificationDelivery{delivery}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.ClaimAlertNotificationDeliveryCommand) error {
+			result.claims = append(result.claims, cmd)
+			result.slotTaken = len(q.notifier.limiter.slots[delivery.NotifierUid]) == 1
+			if claimErr != nil {
+				return claimErr
+			}
+			cmd.Claimed = claimed
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationsQuery) error {
+			query.Result = &models.AlertNotification{Id: query.Id, Uid: "queued", Type: "queued", Settings: simplejson.New()}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetOrCreateNotificationStateQuery) error {
+			query.Result = &models.AlertNotificationState{Id: 5, Version: stateVersion}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.DeleteAlertNotificationDeliveryCommand) error {
+			result.removed = append(result.removed, cmd.Id)
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.FailAlertNotificationDeliveryCommand) error {
+			result.failed = append(result.failed, cmd)
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToCompleteCommand) error {
+			result.completed = append(result.completed, cmd)
+			return nil
+		})
+
+		require.NoError(t, q.processDue(context.Background(), time.Now()))
+		return result
+	}
+
+	newDelivery := func(attempts int, stateVersion int64) *models.AlertNotificationDelivery {
+		return &models.AlertNotificationDelivery{
+			Id:                       10,
+			OrgId:                    2,
+			AlertId:                  1,
+			NotifierId:               3,
+			NotifierUid:              "queued",
+			NotifierType:             "queued",
+			NotificationStateId:      5,
+			NotificationStateVersion: stateVersion,
+			Attempts:                 attempts,
+			Payload:                  string(payload),
+			Created:                  time.Now().Unix(),
+		}
+	}
+
+	t.Run("should remove a delivered notification and mark it as complete", func(t *testing.T) {
+		sendErr = nil
+		result := queueScenario(t, newDelivery(1, 2), true, 2)
+
+		require.Equal(t, 1, sent)
+		require.Equal(t, []int64{10}, result.removed)
+		require.Empty(t, result.failed)
+		require.Len(t, result.completed, 1)
+		require.Equal(t, int64(5), result.completed[0].Id)
+		require.Equal(t, int64(2), result.completed[0].Version)
+	})
+
+	t.Run("should schedule another attempt of a failed notification", func(t *testing.T) {
+		sendErr = errors.New("slack is down")
+		result := queueScenario(t, newDelivery(1, 2), true, 2)
+
+		require.Empty(t, result.removed)
+		require.Len(t, result.failed, 1)
+		require.False(t, result.failed[0].Dead)
+		require.Equal(t, "slack is down", result.failed[0].Error)
+		require.True(t, result.failed[0].NextAttempt.After(time.Now()))
+	})
+
+	t.Run("should keep a notification failing its last attempt as a dead letter", func(t *testing.T) {
+		sendErr = errors.New("slack is down")
+		result := queueScenario(t, newDelivery(2, 2), true, 2)
+
+		require.Len(t, result.failed, 1)
+		require.True(t, result.failed[0].Dead)
+	})
+
+	t.Run("should drop a notification superseded by a newer one", func(t *testing.T) {
+		sendErr = nil
+		result := queueScenario(t, newDelivery(1, 2), true, 4)
+
+		require.Equal(t, 0, sent)
+		require.Equal(t, []int64{10}, result.removed)
+		require.Empty(t, result.completed)
+	})
+
+	t.Run("should send a replayed notification regardless of newer ones", func(t *testing.T) {
+		sendErr = nil
+		result := queueScenario(t, newDelivery(0, 0), true, 4)
+
+		require.Equal(t, 1, sent)
+		require.Equal(t, []int64{10}, result.removed)
+		require.Empty(t, result.completed)
+	})
+
+	t.Run("should skip a notification claimed by another instance", func(t *testing.T) {
+		sendErr = nil
+		result := queueScenario(t, newDelivery(1, 2), false, 2)
+
+		require.Equal(t, 0, sent)
+		require.Empty(t, result.removed)
+		require.Empty(t, result.failed)
+	})
+
+	t.Run("should claim a notification once the slot of its channel is taken", func(t *testing.T) {
+		sendErr = nil
+		start := time.Now()
+		result := queueScenario(t, newDelivery(1, 2), true, 2)
+
+		require.Len(t, result.claims, 1)
+		require.True(t, result.slotTaken)
+		require.False(t, result.claims[0].Until.Before(start.Add(2*setting.AlertingNotificationTimeout)))
+		require.Equal(t, 1, sent)
+	})
+
+	t.Run("should go on with the other notifications when a claim fails", func(t *testing.T) {
+		sendErr, claimErr = nil, errors.New("database is locked")
+		t.Cleanup(func() { claimErr = nil })
+		result := queueScenario(t, newDelivery(1, 2), true, 2)
+
+		require.Len(t, result.claims, 1)
+		require.Equal(t, 0, sent)
+		require.Empty(t, result.removed)
+		require.Empty(t, result.failed)
+	})
+}
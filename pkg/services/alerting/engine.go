@@ -13,6 +13,7 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/setting"
@@ -25,6 +26,7 @@ import (
 type AlertEngine struct {
 	RenderService rendering.Service `inject:""`
 	Bus           bus.Bus           `inject:""`
+	Cfg           *setting.Cfg      `inject:""`
 
 	execQueue     chan *Job
 	ticker        *Ticker
@@ -33,6 +35,8 @@ type AlertEngine struct {
 	ruleReader    ruleReader
 	log           log.Logger
 	resultHandler resultHandler
+	deliveryQueue *deliveryQueue
+	cluster       *cluster
 }
 
 func init() {
@@ -52,15 +56,32 @@ func (e *AlertEngine) Init() error {
 	e.evalHandler = NewEvalHandler()
 	e.ruleReader = newRuleReader()
 	e.log = log.New("alerting.engine")
-	e.resultHandler = newResultHandler(e.RenderService)
+	notifier := newNotificationService(e.RenderService)
+	e.resultHandler = newResultHandler(notifier)
+	e.deliveryQueue = newDeliveryQueue(notifier)
+	if e.Cfg != nil && e.Cfg.AlertingHAEnabled {
+		e.cluster = newCluster(e.Cfg)
+	}
 	return nil
 }
 
 // Run starts the alerting service background process.
 func (e *AlertEngine) Run(ctx context.Context) error {
+	if e.cluster != nil {
+		// join the cluster before loading the rules, otherwise this
+		// node would own all the rules until its first heartbeat
+		if err := e.cluster.heartbeat(ctx); err != nil {
+			e.log.Error("Failed to join alerting cluster", "error", err)
+		}
+	}
+
 	alertGroup, ctx := errgroup.WithContext(ctx)
+	if e.cluster != nil {
+		alertGroup.Go(func() error { return e.cluster.run(ctx) })
+	}
 	alertGroup.Go(func() error { return e.alertingTicker(ctx) })
 	alertGroup.Go(func() error { return e.runJobDispatcher(ctx) })
+	alertGroup.Go(func() error { return e.deliveryQueue.run(ctx) })
 
 	err := alertGroup.Wait()
 	return err
@@ -82,7 +103,7 @@ func (e *AlertEngine) alertingTicker(grafanaCtx context.Context) error {
 		case tick := <-e.ticker.C:
 			// TEMP SOLUTION update rules ever tenth tick
 			if tickIndex%10 == 0 {
-				e.scheduler.Update(e.ruleReader.fetch())
+				e.updateRules()
 			}
 
 			e.scheduler.Tick(tick, e.execQueue)
@@ -91,6 +112,17 @@ func (e *AlertEngine) alertingTicker(grafanaCtx context.Context) error {
 	}
 }
 
+// updateRules schedules the rules owned by this instance, which are the
+// active alerts it reports
+func (e *AlertEngine) updateRules() {
+	rules := e.ruleReader.fetch()
+	if e.cluster != nil {
+		rules = e.cluster.ownedRules(rules)
+	}
+	metrics.MAlertingActiveAlerts.Set(float64(len(rules)))
+	e.scheduler.Update(rules)
+}
+
 func (e *AlertEngine) runJobDispatcher(grafanaCtx context.Context) error {
 	dispatcherGroup, alertCtx := errgroup.WithContext(grafanaCtx)
 
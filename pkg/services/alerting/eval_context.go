@@ -18,6 +18,8 @@ type EvalContext struct {
 	IsTestRun      bool
 	IsDebug        bool
 	EvalMatches    []*EvalMatch
+	Conditions     []*ConditionEvaluation
+	Notifications  []*NotificationResult
 	Logs           []*ResultLogEntry
 	Error          error
 	ConditionEvals string
@@ -93,6 +95,18 @@ func (c *EvalContext) GetDurationMs() float64 {
 	return float64(c.EndTime.Nanosecond()-c.StartTime.Nanosecond()) / float64(1000000)
 }
 
+func (c *EvalContext) addNotificationResult(notifier Notifier, result string, err error) {
+	notification := &NotificationResult{
+		Uid:    notifier.GetNotifierUID(),
+		Type:   notifier.GetType(),
+		Result: result,
+	}
+	if err != nil {
+		notification.Error = err.Error()
+	}
+	c.Notifications = append(c.Notifications, notification)
+}
+
 // GetNotificationTitle returns the title of the alert rule including alert state.
 func (c *EvalContext) GetNotificationTitle() string {
 	return "[" + c.GetStateModel().Text + "] " + c.Rule.Name
@@ -116,8 +130,9 @@ func (c *EvalContext) GetDashboardUID() (*models.DashboardRef, error) {
 const urlFormat = "%s?tab=alert&viewPanel=%d&orgId=%d"
 
 // GetRuleURL returns the url to the dashboard containing the alert.
+// Alerts that are not part of a dashboard link to Grafana.
 func (c *EvalContext) GetRuleURL() (string, error) {
-	if c.IsTestRun {
+	if c.IsTestRun || c.Rule.DashboardID == 0 {
 		return setting.AppUrl, nil
 	}
 
@@ -31,7 +31,9 @@ func (e *DefaultEvalHandler) Eval(context *EvalContext) {
 
 	for i := 0; i < len(context.Rule.Conditions); i++ {
 		condition := context.Rule.Conditions[i]
+		start := time.Now()
 		cr, err := condition.Eval(context)
+		context.Conditions = append(context.Conditions, newConditionEvaluation(i, cr, err, time.Since(start)))
 		if err != nil {
 			context.Error = err
 		}
@@ -72,3 +74,35 @@ func (e *DefaultEvalHandler) Eval(context *EvalContext) {
 	elapsedTime := context.EndTime.Sub(context.StartTime).Nanoseconds() / int64(time.Millisecond)
 	metrics.MAlertingExecutionTime.Observe(float64(elapsedTime))
 }
+
+// maxEvaluatedSeries limits the series of a condition kept in the evaluation
+// history, as a query can return many series
+const maxEvaluatedSeries = 20
+
+func newConditionEvaluation(index int, cr *ConditionResult, err error, duration time.Duration) *ConditionEvaluation {
+	evaluation := &ConditionEvaluation{
+		Index:      index,
+		DurationMs: duration.Nanoseconds() / int64(time.Millisecond),
+		Series:     []*SeriesEvaluation{},
+	}
+	if err != nil {
+		evaluation.Error = err.Error()
+		return evaluation
+	}
+
+	evaluation.Operator = cr.Operator
+	evaluation.Firing = cr.Firing
+	evaluation.NoDataFound = cr.NoDataFound
+	evaluation.SeriesCount = len(cr.Series)
+
+	// keep the matching series first, as they explain why the alert fired
+	for _, match := range []bool{true, false} {
+		for _, series := range cr.Series {
+			if series.Match == match && len(evaluation.Series) < maxEvaluatedSeries {
+				evaluation.Series = append(evaluation.Series, series)
+			}
+		}
+	}
+
+	return evaluation
+}
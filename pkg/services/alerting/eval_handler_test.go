@@ -2,7 +2,10 @@ package alerting
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -202,5 +205,48 @@ func TestAlertingEvaluationHandler(t *testing.T) {
 			handler.Eval(context)
 			So(context.NoDataFound, ShouldBeTrue)
 		})
+
+		Convey("Should record the evaluation of every condition", func() {
+			context := NewEvalContext(context.TODO(), &Rule{
+				Conditions: []Condition{
+					&conditionStub{firing: true, operator: "and"},
+					&conditionStub{firing: false, operator: "or", noData: true},
+				},
+			})
+
+			handler.Eval(context)
+			So(context.Conditions, ShouldHaveLength, 2)
+			So(context.Conditions[0].Index, ShouldEqual, 0)
+			So(context.Conditions[0].Firing, ShouldBeTrue)
+			So(context.Conditions[1].Index, ShouldEqual, 1)
+			So(context.Conditions[1].Operator, ShouldEqual, "or")
+			So(context.Conditions[1].NoDataFound, ShouldBeTrue)
+		})
+	})
+}
+
+func TestNewConditionEvaluation(t *testing.T) {
+	Convey("Test condition evaluation", t, func() {
+		Convey("Should record the error of the condition", func() {
+			evaluation := newConditionEvaluation(1, nil, errors.New("query failed"), time.Second)
+			So(evaluation.Index, ShouldEqual, 1)
+			So(evaluation.DurationMs, ShouldEqual, 1000)
+			So(evaluation.Error, ShouldEqual, "query failed")
+		})
+
+		Convey("Should keep the matching series first when there are too many series", func() {
+			var series []*SeriesEvaluation
+			for i := 0; i < maxEvaluatedSeries+10; i++ {
+				series = append(series, &SeriesEvaluation{Metric: fmt.Sprintf("series%d", i), Match: i%10 == 9})
+			}
+
+			evaluation := newConditionEvaluation(0, &ConditionResult{Series: series}, nil, 0)
+			So(evaluation.SeriesCount, ShouldEqual, maxEvaluatedSeries+10)
+			So(evaluation.Series, ShouldHaveLength, maxEvaluatedSeries)
+			So(evaluation.Series[0].Metric, ShouldEqual, "series9")
+			So(evaluation.Series[1].Metric, ShouldEqual, "series19")
+			So(evaluation.Series[2].Metric, ShouldEqual, "series29")
+			So(evaluation.Series[3].Metric, ShouldEqual, "series0")
+		})
 	})
 }
@@ -55,6 +55,7 @@ type ConditionResult struct {
 	NoDataFound bool
 	Operator    string
 	EvalMatches []*EvalMatch
+	Series      []*SeriesEvaluation
 }
 
 // Condition is responsible for evaluating an alert condition.
@@ -42,3 +42,40 @@ type EvalMatch struct {
 	Metric string            `json:"metric"`
 	Tags   map[string]string `json:"tags"`
 }
+
+// SeriesEvaluation represents the reduced value of a series and whether it
+// matched the evaluator of the condition.
+type SeriesEvaluation struct {
+	Metric string            `json:"metric"`
+	Tags   map[string]string `json:"tags,omitempty"`
+	Value  null.Float        `json:"value"`
+	Match  bool              `json:"match"`
+}
+
+// ConditionEvaluation represents the evaluation of a condition of the alert rule.
+type ConditionEvaluation struct {
+	Index       int                 `json:"index"`
+	Operator    string              `json:"operator"`
+	Firing      bool                `json:"firing"`
+	NoDataFound bool                `json:"noDataFound"`
+	DurationMs  int64               `json:"durationMs"`
+	SeriesCount int                 `json:"seriesCount"`
+	Series      []*SeriesEvaluation `json:"series"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// Notification results
+const (
+	NotificationSent     = "sent"
+	NotificationFailed   = "failed"
+	NotificationRetrying = "retrying"
+	NotificationSkipped  = "skipped"
+)
+
+// NotificationResult represents the outcome of a notifier invoked by the alert evaluation.
+type NotificationResult struct {
+	Uid    string `json:"uid"`
+	Type   string `json:"type"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,164 @@
+package alerting
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Settings of a notification channel holding its templates.
+const (
+	TitleTemplateSetting   = "titleTemplate"
+	MessageTemplateSetting = "messageTemplate"
+)
+
+// NotificationTemplateData is the data available to the templates of a notification channel.
+type NotificationTemplateData struct {
+	RuleID      int64
+	RuleName    string
+	RuleURL     string
+	State       string
+	PrevState   string
+	Title       string
+	Message     string
+	Tags        map[string]string
+	EvalMatches []*EvalMatch
+	ImageURL    string
+	Error       string
+}
+
+var notificationTemplateFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"toUpper": strings.ToUpper,
+	"toLower": strings.ToLower,
+	"title":   strings.Title,
+}
+
+// NotificationTemplates renders the title and message of the notifications
+// of a channel. A template left empty keeps the default title or message.
+type NotificationTemplates struct {
+	title   *template.Template
+	message *template.Template
+}
+
+// ParseNotificationTemplates parses the templates found in the settings of
+// a notification channel, returning a ValidationError if one is invalid.
+func ParseNotificationTemplates(settings *simplejson.Json) (*NotificationTemplates, error) {
+	templates := &NotificationTemplates{}
+	if settings == nil {
+		return templates, nil
+	}
+
+	var err error
+	if templates.title, err = parseNotificationTemplate(settings, TitleTemplateSetting); err != nil {
+		return nil, err
+	}
+	if templates.message, err = parseNotificationTemplate(settings, MessageTemplateSetting); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func parseNotificationTemplate(settings *simplejson.Json, name string) (*template.Template, error) {
+	text := settings.Get(name).MustString()
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	return ParseNotificationTemplate(name, text)
+}
+
+// ParseNotificationTemplate parses the template of the name setting of a
+// notification channel, returning a ValidationError if it's invalid.
+func ParseNotificationTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(notificationTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, ValidationError{Reason: fmt.Sprintf("invalid %s: %v", name, err)}
+	}
+
+	// execute the template with sample data, so that references to fields
+	// that don't exist are caught when saving the channel
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, sampleNotificationTemplateData()); err != nil {
+		return nil, ValidationError{Reason: fmt.Sprintf("invalid %s: %v", name, err)}
+	}
+
+	return tmpl, nil
+}
+
+// Title renders the title of the notification, or returns the default title
+// when there's no title template.
+func (t *NotificationTemplates) Title(evalContext *EvalContext) (string, error) {
+	if t == nil || t.title == nil {
+		return evalContext.GetNotificationTitle(), nil
+	}
+	return ExecuteNotificationTemplate(t.title, evalContext)
+}
+
+// Message renders the message of the notification, or returns the message of
+// the rule when there's no message template.
+func (t *NotificationTemplates) Message(evalContext *EvalContext) (string, error) {
+	if t == nil || t.message == nil {
+		return evalContext.Rule.Message, nil
+	}
+	return ExecuteNotificationTemplate(t.message, evalContext)
+}
+
+// ExecuteNotificationTemplate renders a template parsed by
+// ParseNotificationTemplate with the evaluation.
+func ExecuteNotificationTemplate(tmpl *template.Template, evalContext *EvalContext) (string, error) {
+	data, err := evalContext.NotificationTemplateData()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// NotificationTemplateData returns the data of the evaluation available to the
+// templates of the notification channels.
+func (c *EvalContext) NotificationTemplateData() (*NotificationTemplateData, error) {
+	ruleURL, err := c.GetRuleURL()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &NotificationTemplateData{
+		RuleID:      c.Rule.ID,
+		RuleName:    c.Rule.Name,
+		RuleURL:     ruleURL,
+		State:       string(c.Rule.State),
+		PrevState:   string(c.PrevAlertState),
+		Title:       c.GetNotificationTitle(),
+		Message:     c.Rule.Message,
+		Tags:        make(map[string]string, len(c.Rule.AlertRuleTags)),
+		EvalMatches: c.EvalMatches,
+		ImageURL:    c.ImagePublicURL,
+	}
+	for _, tag := range c.Rule.AlertRuleTags {
+		data.Tags[tag.Key] = tag.Value
+	}
+	if c.Error != nil {
+		data.Error = c.Error.Error()
+	}
+
+	return data, nil
+}
+
+func sampleNotificationTemplateData() *NotificationTemplateData {
+	return &NotificationTemplateData{
+		RuleName:    "Sample rule",
+		State:       "alerting",
+		PrevState:   "ok",
+		Tags:        map[string]string{},
+		EvalMatches: evalMatchesBasedOnState(),
+	}
+}
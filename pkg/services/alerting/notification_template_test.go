@@ -0,0 +1,151 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotificationTemplates(t *testing.T) {
+	t.Run("should accept settings without templates", func(t *testing.T) {
+		templates, err := ParseNotificationTemplates(simplejson.New())
+		require.NoError(t, err)
+		require.Nil(t, templates.title)
+		require.Nil(t, templates.message)
+	})
+
+	t.Run("should accept valid templates", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			"titleTemplate":   "{{ .State | toUpper }}: {{ .RuleName }}",
+			"messageTemplate": "{{ range .EvalMatches }}{{ .Metric }}={{ .Value }} {{ end }}",
+		})
+		templates, err := ParseNotificationTemplates(settings)
+		require.NoError(t, err)
+		require.NotNil(t, templates.title)
+		require.NotNil(t, templates.message)
+	})
+
+	t.Run("should reject a template with a syntax error", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			"titleTemplate": "{{ .RuleName ",
+		})
+		_, err := ParseNotificationTemplates(settings)
+
+		var validationErr ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Contains(t, err.Error(), "invalid titleTemplate")
+	})
+
+	t.Run("should reject a template referencing an unknown field", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			"messageTemplate": "{{ .Unknown }}",
+		})
+		_, err := ParseNotificationTemplates(settings)
+
+		var validationErr ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Contains(t, err.Error(), "invalid messageTemplate")
+	})
+}
+
+func TestNotificationTemplates(t *testing.T) {
+	evalContext := NewEvalContext(context.Background(), &Rule{
+		ID:            3,
+		Name:          "High CPU",
+		Message:       "CPU is too high",
+		State:         models.AlertStateAlerting,
+		AlertRuleTags: []*models.Tag{{Key: "team", Value: "backend"}},
+	})
+	evalContext.IsTestRun = true
+	evalContext.ImagePublicURL = "https://example.com/image.png"
+	evalContext.EvalMatches = []*EvalMatch{{Metric: "cpu", Value: null.FloatFrom(95)}}
+
+	t.Run("should keep the default title and message without templates", func(t *testing.T) {
+		templates, err := ParseNotificationTemplates(simplejson.New())
+		require.NoError(t, err)
+
+		title, err := templates.Title(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, "[Alerting] High CPU", title)
+
+		message, err := templates.Message(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, "CPU is too high", message)
+	})
+
+	t.Run("should render the templates with the evaluation", func(t *testing.T) {
+		templates, err := ParseNotificationTemplates(simplejson.NewFromAny(map[string]interface{}{
+			"titleTemplate":   "[{{ .Tags.team }}] {{ .Title }}",
+			"messageTemplate": "{{ .Message }} on {{ .RuleURL }}:{{ range .EvalMatches }} {{ .Metric }}={{ .Value }}{{ end }} {{ .ImageURL }}",
+		}))
+		require.NoError(t, err)
+
+		title, err := templates.Title(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, "[backend] [Alerting] High CPU", title)
+
+		message, err := templates.Message(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, "CPU is too high on "+setting.AppUrl+": cpu=95.000 https://example.com/image.png", message)
+	})
+}
+
+func TestNotificationPreview(t *testing.T) {
+	t.Run("should render the templates with a test notification", func(t *testing.T) {
+		cmd := &NotificationPreviewCommand{
+			Settings: simplejson.NewFromAny(map[string]interface{}{
+				"titleTemplate":   "{{ .RuleName }} is {{ .State }}",
+				"messageTemplate": "{{ len .EvalMatches }} series: {{ .Error }}",
+			}),
+		}
+		require.NoError(t, handleNotificationPreviewCommand(context.Background(), cmd))
+
+		require.Equal(t, "Test notification is alerting", cmd.Result.Title)
+		require.Equal(t, "2 series: this is only a test", cmd.Result.Message)
+	})
+
+	t.Run("should fail for an invalid template", func(t *testing.T) {
+		cmd := &NotificationPreviewCommand{
+			Settings: simplejson.NewFromAny(map[string]interface{}{
+				"titleTemplate": "{{ .RuleName",
+			}),
+		}
+		err := handleNotificationPreviewCommand(context.Background(), cmd)
+
+		var validationErr ValidationError
+		require.True(t, errors.As(err, &validationErr))
+	})
+}
+
+func TestInitNotifierWithTemplates(t *testing.T) {
+	RegisterNotifier(&NotifierPlugin{
+		Type:    "test",
+		Name:    "Test",
+		Factory: newTestNotifier,
+	})
+
+	_, err := InitNotifier(&models.AlertNotification{
+		Type: "test",
+		Settings: simplejson.NewFromAny(map[string]interface{}{
+			"titleTemplate": "{{ .RuleName }}",
+		}),
+	})
+	require.NoError(t, err)
+
+	// channels saved before their templates were validated must still
+	// send their notifications, with the default layout
+	_, err = InitNotifier(&models.AlertNotification{
+		Type: "test",
+		Settings: simplejson.NewFromAny(map[string]interface{}{
+			"titleTemplate": "{{ .RuleName",
+		}),
+	})
+	require.NoError(t, err)
+}
@@ -87,12 +87,14 @@ func newNotificationService(renderService rendering.Service) *notificationServic
 	return &notificationService{
 		log:           log.New("alerting.notifier"),
 		renderService: renderService,
+		limiter:       newChannelLimiter(setting.AlertingNotificationChannelConcurrency),
 	}
 }
 
 type notificationService struct {
 	log           log.Logger
 	renderService rendering.Service
+	limiter       *channelLimiter
 }
 
 func (n *notificationService) SendIfNeeded(evalCtx *EvalContext) error {
@@ -125,21 +127,49 @@ func (n *notificationService) SendIfNeeded(evalCtx *EvalContext) error {
 	return n.sendNotifications(evalCtx, notifierStates)
 }
 
+// notify sends the notification once a slot of the notification channel is free
+func (n *notificationService) notify(evalContext *EvalContext, notifier Notifier) error {
+	release, err := n.limiter.acquire(evalContext.Ctx, notifier.GetNotifierUID())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return n.send(evalContext, notifier)
+}
+
+// send sends the notification, the caller holding a slot of the notification
+// channel
+func (n *notificationService) send(evalContext *EvalContext, notifier Notifier) error {
+	metrics.MAlertingNotificationSent.WithLabelValues(notifier.GetType()).Inc()
+	if err := notifier.Notify(evalContext); err != nil {
+		metrics.MAlertingNotificationFailed.WithLabelValues(notifier.GetType()).Inc()
+		return err
+	}
+	return nil
+}
+
 func (n *notificationService) sendAndMarkAsComplete(evalContext *EvalContext, notifierState *notifierState) error {
 	notifier := notifierState.notifier
 
 	n.log.Debug("Sending notification", "type", notifier.GetType(), "uid", notifier.GetNotifierUID(), "isDefault", notifier.GetIsDefault())
-	metrics.MAlertingNotificationSent.WithLabelValues(notifier.GetType()).Inc()
 
 	if err := evalContext.evaluateNotificationTemplateFields(); err != nil {
 		n.log.Error("failed trying to evaluate notification template fields", "uid", notifier.GetNotifierUID(), "error", err)
 	}
 
-	if err := notifier.Notify(evalContext); err != nil {
+	start := time.Now()
+	if err := n.notify(evalContext, notifier); err != nil {
 		n.log.Error("failed to send notification", "uid", notifier.GetNotifierUID(), "error", err)
-		metrics.MAlertingNotificationFailed.WithLabelValues(notifier.GetType()).Inc()
+		if !evalContext.IsTestRun && n.enqueueRetry(evalContext, notifierState, err) {
+			evalContext.addNotificationResult(notifier, NotificationRetrying, err)
+		} else {
+			evalContext.addNotificationResult(notifier, NotificationFailed, err)
+		}
 		return err
 	}
+	metrics.MAlertingNotificationDeliveryLatency.WithLabelValues(notifier.GetType()).Observe(time.Since(start).Seconds())
+	evalContext.addNotificationResult(notifier, NotificationSent, nil)
 
 	if evalContext.IsTestRun {
 		return nil
@@ -164,9 +194,11 @@ func (n *notificationService) sendNotification(evalContext *EvalContext, notifie
 		err := bus.DispatchCtx(evalContext.Ctx, setPendingCmd)
 		if err != nil {
 			if errors.Is(err, models.ErrAlertNotificationStateVersionConflict) {
+				evalContext.addNotificationResult(notifierState.notifier, NotificationSkipped, nil)
 				return nil
 			}
 
+			evalContext.addNotificationResult(notifierState.notifier, NotificationFailed, err)
 			return err
 		}
 
@@ -64,6 +64,17 @@ func TestNotificationService(t *testing.T) {
 			require.Truef(sc.t, evalCtx.Ctx.Value(notificationSent{}).(bool), "expected notification to be sent, but wasn't")
 		})
 
+	evalCtxRecorded := NewEvalContext(context.Background(), testRule)
+	notificationServiceScenario(t, "Given alert rule with notifier should record the notification result",
+		evalCtxRecorded, false, func(sc *scenarioContext) {
+			err := sc.notificationService.SendIfNeeded(evalCtxRecorded)
+			require.NoError(sc.t, err)
+
+			require.Len(sc.t, evalCtxRecorded.Notifications, 1)
+			require.Equal(sc.t, "test", evalCtxRecorded.Notifications[0].Type)
+			require.Equal(sc.t, NotificationSent, evalCtxRecorded.Notifications[0].Result)
+		})
+
 	notificationServiceScenario(t, "Given alert rule with upload image enabled and render times out should send notification",
 		evalCtx, true, func(sc *scenarioContext) {
 			setting.AlertingNotificationTimeout = 200 * time.Millisecond
@@ -109,10 +109,7 @@ func (am *AlertmanagerNotifier) createAlert(evalContext *alerting.EvalContext, m
 
 	// Annotations (summary and description are very commonly used).
 	alertJSON.SetPath([]string{"annotations", "summary"}, evalContext.Rule.Name)
-	description := ""
-	if evalContext.Rule.Message != "" {
-		description += evalContext.Rule.Message
-	}
+	description := am.GetNotificationMessage(evalContext)
 	if evalContext.Error != nil {
 		if description != "" {
 			description += "\n"
@@ -24,7 +24,8 @@ type NotifierBase struct {
 	DisableResolveMessage bool
 	Frequency             time.Duration
 
-	log log.Logger
+	log       log.Logger
+	templates *alerting.NotificationTemplates
 }
 
 // NewNotifierBase returns a new `NotifierBase`.
@@ -35,6 +36,14 @@ func NewNotifierBase(model *models.AlertNotification) NotifierBase {
 		uploadImage = value.MustBool()
 	}
 
+	logger := log.New("alerting.notifier." + model.Name)
+	// invalid templates are rejected when saving the channel, so this only
+	// happens for channels saved before, which keep the default layout
+	templates, err := alerting.ParseNotificationTemplates(model.Settings)
+	if err != nil {
+		logger.Error("Failed to parse notification templates", "error", err)
+	}
+
 	return NotifierBase{
 		UID:                   model.Uid,
 		Name:                  model.Name,
@@ -44,7 +53,8 @@ func NewNotifierBase(model *models.AlertNotification) NotifierBase {
 		SendReminder:          model.SendReminder,
 		DisableResolveMessage: model.DisableResolveMessage,
 		Frequency:             model.Frequency,
-		log:                   log.New("alerting.notifier." + model.Name),
+		log:                   logger,
+		templates:             templates,
 	}
 }
 
@@ -146,3 +156,25 @@ func (n *NotifierBase) GetDisableResolveMessage() bool {
 func (n *NotifierBase) GetFrequency() time.Duration {
 	return n.Frequency
 }
+
+// GetNotificationTitle returns the title of the notification, rendered from
+// the title template of the channel if it has one.
+func (n *NotifierBase) GetNotificationTitle(evalContext *alerting.EvalContext) string {
+	title, err := n.templates.Title(evalContext)
+	if err != nil {
+		n.log.Error("Failed to render notification title template", "ruleId", evalContext.Rule.ID, "error", err)
+		return evalContext.GetNotificationTitle()
+	}
+	return title
+}
+
+// GetNotificationMessage returns the message of the notification, rendered
+// from the message template of the channel if it has one.
+func (n *NotifierBase) GetNotificationMessage(evalContext *alerting.EvalContext) string {
+	message, err := n.templates.Message(evalContext)
+	if err != nil {
+		n.log.Error("Failed to render notification message template", "ruleId", evalContext.Rule.ID, "error", err)
+		return evalContext.Rule.Message
+	}
+	return message
+}
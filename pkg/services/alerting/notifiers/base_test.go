@@ -219,3 +219,43 @@ func TestBaseNotifier(t *testing.T) {
 		})
 	})
 }
+
+func TestNotifierBaseTemplates(t *testing.T) {
+	evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+		Name:    "High CPU",
+		Message: "CPU is too high",
+		State:   models.AlertStateAlerting,
+	})
+	evalContext.IsTestRun = true
+
+	t.Run("should render the templates of the channel", func(t *testing.T) {
+		base := NewNotifierBase(&models.AlertNotification{
+			Settings: simplejson.NewFromAny(map[string]interface{}{
+				"titleTemplate":   "{{ .RuleName }} ({{ .State }})",
+				"messageTemplate": "Check {{ .RuleName }}: {{ .Message }}",
+			}),
+		})
+
+		assert.Equal(t, "High CPU (alerting)", base.GetNotificationTitle(evalContext))
+		assert.Equal(t, "Check High CPU: CPU is too high", base.GetNotificationMessage(evalContext))
+	})
+
+	t.Run("should keep the default title and message without templates", func(t *testing.T) {
+		base := NewNotifierBase(&models.AlertNotification{Settings: simplejson.New()})
+
+		assert.Equal(t, "[Alerting] High CPU", base.GetNotificationTitle(evalContext))
+		assert.Equal(t, "CPU is too high", base.GetNotificationMessage(evalContext))
+	})
+
+	t.Run("should keep the default title and message for invalid templates", func(t *testing.T) {
+		base := NewNotifierBase(&models.AlertNotification{
+			Settings: simplejson.NewFromAny(map[string]interface{}{
+				"titleTemplate":   "{{ .RuleName",
+				"messageTemplate": "{{ .Unknown }}",
+			}),
+		})
+
+		assert.Equal(t, "[Alerting] High CPU", base.GetNotificationTitle(evalContext))
+		assert.Equal(t, "CPU is too high", base.GetNotificationMessage(evalContext))
+	})
+}
@@ -111,9 +111,9 @@ func (dd *DingDingNotifier) genBody(evalContext *alerting.EvalContext, messageUR
 
 	dd.log.Info("messageUrl:" + messageURL)
 
-	message := evalContext.Rule.Message
+	message := dd.GetNotificationMessage(evalContext)
 	picURL := evalContext.ImagePublicURL
-	title := evalContext.GetNotificationTitle()
+	title := dd.GetNotificationTitle(evalContext)
 	if message == "" {
 		message = title
 	}
@@ -103,11 +103,11 @@ func (dn *DiscordNotifier) Notify(evalContext *alerting.EvalContext) error {
 	color, _ := strconv.ParseInt(strings.TrimLeft(evalContext.GetStateModel().Color, "#"), 16, 0)
 
 	embed := simplejson.New()
-	embed.Set("title", evalContext.GetNotificationTitle())
+	embed.Set("title", dn.GetNotificationTitle(evalContext))
 	// Discord takes integer for color
 	embed.Set("color", color)
 	embed.Set("url", ruleURL)
-	embed.Set("description", evalContext.Rule.Message)
+	embed.Set("description", dn.GetNotificationMessage(evalContext))
 	embed.Set("type", "rich")
 	embed.Set("fields", fields)
 	embed.Set("footer", footer)
@@ -82,15 +82,16 @@ func (en *EmailNotifier) Notify(evalContext *alerting.EvalContext) error {
 		error = evalContext.Error.Error()
 	}
 
+	title := en.GetNotificationTitle(evalContext)
 	cmd := &models.SendEmailCommandSync{
 		SendEmailCommand: models.SendEmailCommand{
-			Subject: evalContext.GetNotificationTitle(),
+			Subject: title,
 			Data: map[string]interface{}{
-				"Title":         evalContext.GetNotificationTitle(),
+				"Title":         title,
 				"State":         evalContext.Rule.State,
 				"Name":          evalContext.Rule.Name,
 				"StateModel":    evalContext.GetStateModel(),
-				"Message":       evalContext.Rule.Message,
+				"Message":       en.GetNotificationMessage(evalContext),
 				"Error":         error,
 				"RuleUrl":       ruleURL,
 				"ImageLink":     "",
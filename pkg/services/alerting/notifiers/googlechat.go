@@ -131,12 +131,12 @@ func (gcn *GoogleChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 	}
 
 	widgets := []widget{}
-	if len(evalContext.Rule.Message) > 0 {
+	if message := gcn.GetNotificationMessage(evalContext); len(message) > 0 {
 		// add a text paragraph widget for the message if there is a message
 		// Google Chat API doesn't accept an empty text property
 		widgets = append(widgets, textParagraphWidget{
 			Text: text{
-				Text: evalContext.Rule.Message,
+				Text: message,
 			},
 		})
 	}
@@ -195,13 +195,14 @@ func (gcn *GoogleChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 	})
 
 	// nest the required structs
+	title := gcn.GetNotificationTitle(evalContext)
 	res1D := &outerStruct{
-		PreviewText:  evalContext.GetNotificationTitle(),
-		FallbackText: evalContext.GetNotificationTitle(),
+		PreviewText:  title,
+		FallbackText: title,
 		Cards: []card{
 			{
 				Header: header{
-					Title: evalContext.GetNotificationTitle(),
+					Title: title,
 				},
 				Sections: []section{
 					{
@@ -120,13 +120,14 @@ func (hc *HipChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 		})
 	}
 
+	title := hc.GetNotificationTitle(evalContext)
 	message := ""
 	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
-		message += " " + evalContext.Rule.Message
+		message += " " + hc.GetNotificationMessage(evalContext)
 	}
 
 	if message == "" {
-		message = evalContext.GetNotificationTitle() + " in state " + evalContext.GetStateModel().Text
+		message = title + " in state " + evalContext.GetStateModel().Text
 	}
 
 	// HipChat has a set list of colors
@@ -147,7 +148,7 @@ func (hc *HipChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 		"style":       "application",
 		"url":         ruleURL,
 		"id":          "1",
-		"title":       evalContext.GetNotificationTitle(),
+		"title":       title,
 		"description": message,
 		"icon": map[string]interface{}{
 			"url": "https://grafana.com/assets/img/fav32.png",
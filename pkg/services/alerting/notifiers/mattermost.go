@@ -0,0 +1,283 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func init() {
+	alerting.RegisterNotifier(&alerting.NotifierPlugin{
+		Type:        "mattermost",
+		Name:        "Mattermost",
+		Description: "Sends notifications to Mattermost via incoming webhooks or a bot account",
+		Heading:     "Mattermost settings",
+		Factory:     NewMattermostNotifier,
+		Options: []alerting.NotifierOption{
+			{
+				Label:        "Webhook URL",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "Mattermost incoming webhook url",
+				Description:  "Not needed when posting with a bot account",
+				PropertyName: "url",
+				Secure:       true,
+			},
+			{
+				Label:        "Recipient",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Override the channel of the incoming webhook, use channel-name or @username",
+				PropertyName: "recipient",
+			},
+			{
+				Label:        "Username",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Override the username of the incoming webhook",
+				PropertyName: "username",
+			},
+			{
+				Label:        "Icon URL",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Override the icon of the incoming webhook",
+				PropertyName: "iconUrl",
+			},
+			{
+				Label:        "Server URL",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "https://mattermost.example.com",
+				Description:  "Mattermost server to post to with a bot account, replying to the thread of the alert until it fires again",
+				PropertyName: "serverUrl",
+			},
+			{
+				Label:        "Bot token",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Access token of the bot account",
+				PropertyName: "token",
+				Secure:       true,
+			},
+			{
+				Label:        "Channel ID",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "ID of the channel the bot account posts to",
+				PropertyName: "channelId",
+			},
+		},
+	})
+}
+
+// NewMattermostNotifier is the constructor for the Mattermost notifier.
+func NewMattermostNotifier(model *models.AlertNotification) (alerting.Notifier, error) {
+	url := model.DecryptedValue("url", model.Settings.Get("url").MustString())
+	token := model.DecryptedValue("token", model.Settings.Get("token").MustString())
+	serverURL := strings.TrimSuffix(model.Settings.Get("serverUrl").MustString(), "/")
+	channelID := model.Settings.Get("channelId").MustString()
+
+	if token == "" && url == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+	if token != "" && (serverURL == "" || channelID == "") {
+		return nil, alerting.ValidationError{Reason: "Could not find serverUrl or channelId property in settings, both are needed to post with a bot token"}
+	}
+
+	return &MattermostNotifier{
+		NotifierBase: NewNotifierBase(model),
+		URL:          url,
+		Recipient:    model.Settings.Get("recipient").MustString(),
+		Username:     model.Settings.Get("username").MustString(),
+		IconURL:      model.Settings.Get("iconUrl").MustString(),
+		ServerURL:    serverURL,
+		Token:        token,
+		ChannelID:    channelID,
+		notifierID:   model.Id,
+		log:          log.New("alerting.notifier.mattermost"),
+	}, nil
+}
+
+// MattermostNotifier is responsible for sending
+// alert notifications to Mattermost.
+type MattermostNotifier struct {
+	NotifierBase
+	URL       string
+	Recipient string
+	Username  string
+	IconURL   string
+	ServerURL string
+	Token     string
+	ChannelID string
+
+	notifierID int64
+	log        log.Logger
+}
+
+// Notify sends an alert notification to Mattermost.
+func (mn *MattermostNotifier) Notify(evalContext *alerting.EvalContext) error {
+	mn.log.Info("Executing mattermost notification", "ruleId", evalContext.Rule.ID, "notification", mn.Name)
+
+	ruleURL, err := evalContext.GetRuleURL()
+	if err != nil {
+		mn.log.Error("Failed get rule link", "error", err)
+		return err
+	}
+
+	fields := make([]map[string]interface{}, 0)
+	fieldLimitCount := 4
+	for index, evt := range evalContext.EvalMatches {
+		fields = append(fields, map[string]interface{}{
+			"title": evt.Metric,
+			"value": evt.Value,
+			"short": true,
+		})
+		if index > fieldLimitCount {
+			break
+		}
+	}
+
+	if evalContext.Error != nil {
+		fields = append(fields, map[string]interface{}{
+			"title": "Error message",
+			"value": evalContext.Error.Error(),
+			"short": false,
+		})
+	}
+
+	message := ""
+	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
+		message = mn.GetNotificationMessage(evalContext)
+	}
+
+	title := mn.GetNotificationTitle(evalContext)
+	attachment := map[string]interface{}{
+		"color":       evalContext.GetStateModel().Color,
+		"title":       title,
+		"title_link":  ruleURL,
+		"text":        message,
+		"fallback":    title,
+		"fields":      fields,
+		"footer":      "Grafana v" + setting.BuildVersion,
+		"footer_icon": "https://grafana.com/assets/img/fav32.png",
+		"ts":          time.Now().Unix(),
+	}
+	if mn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		attachment["image_url"] = evalContext.ImagePublicURL
+	}
+
+	if mn.Token != "" {
+		return mn.post(evalContext, attachment)
+	}
+
+	body := map[string]interface{}{
+		"attachments": []map[string]interface{}{attachment},
+	}
+	if mn.Recipient != "" {
+		body["channel"] = mn.Recipient
+	}
+	if mn.Username != "" {
+		body["username"] = mn.Username
+	}
+	if mn.IconURL != "" {
+		body["icon_url"] = mn.IconURL
+	}
+
+	data, _ := json.Marshal(&body)
+	cmd := &models.SendWebhookSync{Url: mn.URL, Body: string(data)}
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		mn.log.Error("Failed to send mattermost notification", "error", err, "webhook", mn.Name)
+		return err
+	}
+
+	return nil
+}
+
+// post creates a post with the bot account. The first notification of an
+// alert opens a thread, which the following notifications reply to until
+// the alert fires again.
+func (mn *MattermostNotifier) post(evalContext *alerting.EvalContext, attachment map[string]interface{}) error {
+	var state *models.AlertNotificationState
+	if mn.notifierID != 0 && !evalContext.IsTestRun {
+		query := &models.GetOrCreateNotificationStateQuery{
+			OrgId:      evalContext.Rule.OrgID,
+			AlertId:    evalContext.Rule.ID,
+			NotifierId: mn.notifierID,
+		}
+		if err := bus.DispatchCtx(evalContext.Ctx, query); err != nil {
+			mn.log.Warn("Failed to get the thread of the alert, posting outside of it", "error", err)
+		} else {
+			state = query.Result
+		}
+	}
+
+	rootID := ""
+	if state != nil && !startsThread(evalContext) {
+		rootID = state.ThreadId
+	}
+
+	body := map[string]interface{}{
+		"channel_id": mn.ChannelID,
+		"root_id":    rootID,
+		"props": map[string]interface{}{
+			"attachments": []map[string]interface{}{attachment},
+		},
+	}
+
+	var postID string
+	data, _ := json.Marshal(&body)
+	cmd := &models.SendWebhookSync{
+		Url:        mn.ServerURL + "/api/v4/posts",
+		Body:       string(data),
+		HttpHeader: map[string]string{"Authorization": "Bearer " + mn.Token},
+		Validation: func(body []byte, statusCode int) error {
+			if statusCode/100 != 2 {
+				return fmt.Errorf("unexpected status code %d: %s", statusCode, body)
+			}
+
+			var post struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(body, &post); err != nil {
+				return err
+			}
+			if post.ID == "" {
+				return errors.New("missing id of the created post")
+			}
+			postID = post.ID
+			return nil
+		},
+	}
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		mn.log.Error("Failed to send mattermost notification", "error", err, "webhook", mn.Name)
+		return err
+	}
+
+	if state != nil && rootID == "" {
+		threadCmd := &models.SetAlertNotificationThreadCommand{Id: state.Id, ThreadId: postID}
+		if err := bus.DispatchCtx(evalContext.Ctx, threadCmd); err != nil {
+			mn.log.Warn("Failed to save the thread of the alert", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// startsThread returns whether the notification opens a new thread, which
+// is the case when the alert rule starts firing
+func startsThread(evalContext *alerting.EvalContext) bool {
+	switch evalContext.PrevAlertState {
+	case models.AlertStateOK, models.AlertStatePending, models.AlertStateUnknown:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/stretchr/testify/require"
+)
+
+func newMattermostNotifier(t *testing.T, settings string) (*MattermostNotifier, error) {
+	t.Helper()
+
+	settingsJSON, err := simplejson.NewJson([]byte(settings))
+	require.NoError(t, err)
+	model := &models.AlertNotification{
+		Id:       1,
+		Name:     "ops",
+		Type:     "mattermost",
+		Settings: settingsJSON,
+	}
+
+	not, err := NewMattermostNotifier(model)
+	if err != nil {
+		return nil, err
+	}
+	return not.(*MattermostNotifier), nil
+}
+
+func TestMattermostNotifier_parsingFromSettings(t *testing.T) {
+	t.Run("Empty settings should cause error", func(t *testing.T) {
+		_, err := newMattermostNotifier(t, `{}`)
+		require.EqualError(t, err, "alert validation error: Could not find url property in settings")
+	})
+
+	t.Run("Token without channel should cause error", func(t *testing.T) {
+		_, err := newMattermostNotifier(t, `{"token": "secret", "serverUrl": "http://mattermost"}`)
+		require.Error(t, err)
+	})
+
+	t.Run("Valid settings should result in a valid notifier", func(t *testing.T) {
+		not, err := newMattermostNotifier(t, `{
+			"url": "http://mattermost/hooks/1",
+			"recipient": "ops",
+			"username": "grafana",
+			"token": "secret",
+			"serverUrl": "http://mattermost/",
+			"channelId": "c1"
+		}`)
+		require.NoError(t, err)
+		require.Equal(t, "http://mattermost/hooks/1", not.URL)
+		require.Equal(t, "ops", not.Recipient)
+		require.Equal(t, "grafana", not.Username)
+		require.Equal(t, "http://mattermost", not.ServerURL)
+		require.Equal(t, "secret", not.Token)
+		require.Equal(t, "c1", not.ChannelID)
+	})
+}
+
+func TestMattermostNotifier_Notify(t *testing.T) {
+	t.Run("should post to the incoming webhook", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+
+		not, err := newMattermostNotifier(t, `{"url": "`+server.URL+`/hooks/1", "recipient": "ops", "iconUrl": "http://icon"}`)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{Name: "someRule", Message: "someMessage", State: models.AlertStateAlerting})
+		evalContext.IsTestRun = true
+		require.NoError(t, not.Notify(evalContext))
+
+		req := server.lastRequest(t)
+		require.Equal(t, "/hooks/1", req.Path)
+
+		body, err := simplejson.NewJson([]byte(req.Body))
+		require.NoError(t, err)
+		require.Equal(t, "ops", body.Get("channel").MustString())
+		require.Equal(t, "http://icon", body.Get("icon_url").MustString())
+		require.Equal(t, "[Alerting] someRule", body.Get("attachments").GetIndex(0).Get("title").MustString())
+		require.Equal(t, "someMessage", body.Get("attachments").GetIndex(0).Get("text").MustString())
+	})
+
+	t.Run("should fail when the webhook fails", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		not, err := newMattermostNotifier(t, `{"url": "`+server.URL+`/hooks/1"}`)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{Name: "someRule", State: models.AlertStateAlerting})
+		evalContext.IsTestRun = true
+		require.Error(t, not.Notify(evalContext))
+	})
+
+	t.Run("should thread the notifications of an alert with a bot account", func(t *testing.T) {
+		posts := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			posts++
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": []string{"", "root1", "reply1", "root2"}[posts]})
+		})
+
+		state := &models.AlertNotificationState{Id: 7, OrgId: 1, AlertId: 2, NotifierId: 1}
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetOrCreateNotificationStateQuery) error {
+			require.Equal(t, int64(1), query.NotifierId)
+			require.Equal(t, int64(2), query.AlertId)
+			query.Result = state
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationThreadCommand) error {
+			require.Equal(t, state.Id, cmd.Id)
+			state.ThreadId = cmd.ThreadId
+			return nil
+		})
+
+		not, err := newMattermostNotifier(t, `{"token": "secret", "serverUrl": "`+server.URL+`", "channelId": "c1"}`)
+		require.NoError(t, err)
+
+		notify := func(prev, next models.AlertStateType) map[string]interface{} {
+			evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{ID: 2, OrgID: 1, Name: "someRule", State: next})
+			evalContext.PrevAlertState = prev
+			require.NoError(t, not.Notify(evalContext))
+
+			req := server.lastRequest(t)
+			require.Equal(t, "/api/v4/posts", req.Path)
+			require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+
+			var post map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(req.Body), &post))
+			require.Equal(t, "c1", post["channel_id"])
+			return post
+		}
+
+		post := notify(models.AlertStateOK, models.AlertStateAlerting)
+		require.Equal(t, "", post["root_id"])
+		require.Equal(t, "root1", state.ThreadId)
+
+		post = notify(models.AlertStateAlerting, models.AlertStateOK)
+		require.Equal(t, "root1", post["root_id"])
+		require.Equal(t, "root1", state.ThreadId)
+
+		post = notify(models.AlertStateOK, models.AlertStateAlerting)
+		require.Equal(t, "", post["root_id"])
+		require.Equal(t, "root2", state.ThreadId)
+	})
+
+	t.Run("should fail when the post is not created", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "invalid token"}`))
+		})
+
+		not, err := newMattermostNotifier(t, `{"token": "secret", "serverUrl": "`+server.URL+`", "channelId": "c1"}`)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{Name: "someRule", State: models.AlertStateAlerting})
+		evalContext.IsTestRun = true
+		require.Error(t, not.Notify(evalContext))
+	})
+}
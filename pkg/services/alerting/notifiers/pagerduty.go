@@ -117,7 +117,7 @@ func (pn *PagerdutyNotifier) buildEventPayload(evalContext *alerting.EvalContext
 			queries[evt.Metric] = evt.Value
 		}
 		customData.Set("queries", queries)
-		customData.Set("message", evalContext.Rule.Message)
+		customData.Set("message", pn.GetNotificationMessage(evalContext))
 	} else {
 		for _, evt := range evalContext.EvalMatches {
 			customData.Set(evt.Metric, evt.Value)
@@ -170,7 +170,7 @@ func (pn *PagerdutyNotifier) buildEventPayload(evalContext *alerting.EvalContext
 	if pn.MessageInDetails {
 		summary = evalContext.Rule.Name
 	} else {
-		summary = evalContext.Rule.Name + " - " + evalContext.Rule.Message
+		summary = evalContext.Rule.Name + " - " + pn.GetNotificationMessage(evalContext)
 	}
 	if len(summary) > 1024 {
 		summary = summary[0:1024]
@@ -130,8 +130,8 @@ func (sn *SensuNotifier) Notify(evalContext *alerting.EvalContext) error {
 		bodyJSON.Set("imageUrl", evalContext.ImagePublicURL)
 	}
 
-	if evalContext.Rule.Message != "" {
-		bodyJSON.Set("output", evalContext.Rule.Message)
+	if message := sn.GetNotificationMessage(evalContext); message != "" {
+		bodyJSON.Set("output", message)
 	}
 
 	body, _ := bodyJSON.MarshalJSON()
@@ -135,8 +135,8 @@ func (sn *SensuGoNotifier) Notify(evalContext *alerting.EvalContext) error {
 		namespace = "default"
 	}
 	// Sensu Go needs check output
-	if evalContext.Rule.Message != "" {
-		bodyJSON.SetPath([]string{"check", "output"}, evalContext.Rule.Message)
+	if message := sn.GetNotificationMessage(evalContext); message != "" {
+		bodyJSON.SetPath([]string{"check", "output"}, message)
 	} else {
 		bodyJSON.SetPath([]string{"check", "output"}, "Grafana Metric Condition Met")
 	}
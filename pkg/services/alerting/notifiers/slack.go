@@ -242,7 +242,7 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 	}
 	msg := ""
 	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
-		msg = evalContext.Rule.Message
+		msg = sn.GetNotificationMessage(evalContext)
 	}
 	imageURL := ""
 	// default to file.upload API method if a token is provided
@@ -262,12 +262,13 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 			},
 		}
 	}
+	title := sn.GetNotificationTitle(evalContext)
 	attachment := map[string]interface{}{
 		"color":       evalContext.GetStateModel().Color,
-		"title":       evalContext.GetNotificationTitle(),
+		"title":       title,
 		"title_link":  ruleURL,
 		"text":        msg,
-		"fallback":    evalContext.GetNotificationTitle(),
+		"fallback":    title,
 		"fields":      fields,
 		"footer":      "Grafana v" + setting.BuildVersion,
 		"footer_icon": "https://grafana.com/assets/img/fav32.png",
@@ -277,7 +278,7 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 		attachment["image_url"] = imageURL
 	}
 	body := map[string]interface{}{
-		"text": evalContext.GetNotificationTitle(),
+		"text": title,
 		"attachments": []map[string]interface{}{
 			attachment,
 		},
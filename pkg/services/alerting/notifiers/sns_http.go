@@ -0,0 +1,234 @@
+package notifiers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+const (
+	snsHTTPDefaultTopic = "grafana"
+	// snsHTTPSignatureVersion is the signature version of the notifications
+	// signed with the shared secret. It differs from the certificate based
+	// versions of Amazon SNS, so that SNS clients don't mistake them for SNS
+	// notifications.
+	snsHTTPSignatureVersion = "HmacSHA256"
+	snsHTTPTimestampFormat  = "2006-01-02T15:04:05.000Z"
+)
+
+func init() {
+	alerting.RegisterNotifier(&alerting.NotifierPlugin{
+		Type:        "sns-http",
+		Name:        "SNS-style HTTP",
+		Description: "Sends notifications to an HTTP endpoint in the message format of Amazon SNS",
+		Heading:     "SNS-style HTTP settings",
+		Factory:     NewSNSHTTPNotifier,
+		Options: []alerting.NotifierOption{
+			{
+				Label:        "Url",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "https://subscriber.example.com/notifications",
+				PropertyName: "url",
+				Required:     true,
+			},
+			{
+				Label:        "Topic",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  snsHTTPDefaultTopic,
+				Description:  "Topic the notifications are published to, sent as their TopicArn",
+				PropertyName: "topic",
+			},
+			{
+				Label:        "Secret",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypePassword,
+				Description:  "Shared secret signing the notifications with HMAC-SHA256",
+				PropertyName: "secret",
+				Secure:       true,
+			},
+		},
+	})
+}
+
+// NewSNSHTTPNotifier is the constructor for the SNS-style HTTP notifier.
+func NewSNSHTTPNotifier(model *models.AlertNotification) (alerting.Notifier, error) {
+	endpoint := model.Settings.Get("url").MustString()
+	if endpoint == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, alerting.ValidationError{Reason: "Invalid url property in settings, must be an http or https URL"}
+	}
+
+	topic := strings.TrimSpace(model.Settings.Get("topic").MustString())
+	if topic == "" {
+		topic = snsHTTPDefaultTopic
+	}
+
+	return &SNSHTTPNotifier{
+		NotifierBase: NewNotifierBase(model),
+		URL:          endpoint,
+		Topic:        topic,
+		Secret:       model.DecryptedValue("secret", model.Settings.Get("secret").MustString()),
+		log:          log.New("alerting.notifier.sns-http"),
+	}, nil
+}
+
+// SNSHTTPNotifier is responsible for sending alert notifications to HTTP
+// endpoints in the message format of Amazon SNS, so that the subscribers of
+// SNS topics can receive them without an SNS topic or a mail server.
+type SNSHTTPNotifier struct {
+	NotifierBase
+	URL    string
+	Topic  string
+	Secret string
+	log    log.Logger
+}
+
+// snsHTTPNotification is the envelope of a notification, with the fields of
+// an Amazon SNS notification
+type snsHTTPNotification struct {
+	Type              string                           `json:"Type"`
+	MessageID         string                           `json:"MessageId"`
+	TopicArn          string                           `json:"TopicArn"`
+	Subject           string                           `json:"Subject"`
+	Message           string                           `json:"Message"`
+	Timestamp         string                           `json:"Timestamp"`
+	SignatureVersion  string                           `json:"SignatureVersion,omitempty"`
+	Signature         string                           `json:"Signature,omitempty"`
+	MessageAttributes map[string]snsHTTPAttributeValue `json:"MessageAttributes"`
+}
+
+type snsHTTPAttributeValue struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// snsHTTPMessage is the alert, sent JSON encoded as the message of the
+// notification
+type snsHTTPMessage struct {
+	Title       string                `json:"title"`
+	Message     string                `json:"message,omitempty"`
+	State       models.AlertStateType `json:"state"`
+	RuleID      int64                 `json:"ruleId"`
+	RuleName    string                `json:"ruleName"`
+	RuleURL     string                `json:"ruleUrl,omitempty"`
+	OrgID       int64                 `json:"orgId"`
+	DashboardID int64                 `json:"dashboardId"`
+	PanelID     int64                 `json:"panelId"`
+	EvalMatches []*alerting.EvalMatch `json:"evalMatches"`
+	Tags        map[string]string     `json:"tags"`
+	ImageURL    string                `json:"imageUrl,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// Notify sends an alert notification to the HTTP endpoint.
+func (sn *SNSHTTPNotifier) Notify(evalContext *alerting.EvalContext) error {
+	sn.log.Info("Sending SNS-style HTTP notification", "ruleId", evalContext.Rule.ID, "notification", sn.Name)
+
+	message := snsHTTPMessage{
+		Title:       sn.GetNotificationTitle(evalContext),
+		Message:     sn.GetNotificationMessage(evalContext),
+		State:       evalContext.Rule.State,
+		RuleID:      evalContext.Rule.ID,
+		RuleName:    evalContext.Rule.Name,
+		OrgID:       evalContext.Rule.OrgID,
+		DashboardID: evalContext.Rule.DashboardID,
+		PanelID:     evalContext.Rule.PanelID,
+		EvalMatches: evalContext.EvalMatches,
+		Tags:        make(map[string]string),
+	}
+	for _, tag := range evalContext.Rule.AlertRuleTags {
+		message.Tags[tag.Key] = tag.Value
+	}
+	if ruleURL, err := evalContext.GetRuleURL(); err == nil {
+		message.RuleURL = ruleURL
+	}
+	if sn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		message.ImageURL = evalContext.ImagePublicURL
+	}
+	if evalContext.Error != nil {
+		message.Error = evalContext.Error.Error()
+	}
+
+	messageJSON, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	notification := &snsHTTPNotification{
+		Type:      "Notification",
+		MessageID: uuid.New().String(),
+		TopicArn:  sn.Topic,
+		Subject:   message.Title,
+		Message:   string(messageJSON),
+		Timestamp: time.Now().UTC().Format(snsHTTPTimestampFormat),
+		// the attributes allow the subscribers to filter the notifications
+		// without decoding the message
+		MessageAttributes: map[string]snsHTTPAttributeValue{
+			"state":  {Type: "String", Value: string(evalContext.Rule.State)},
+			"ruleId": {Type: "Number", Value: strconv.FormatInt(evalContext.Rule.ID, 10)},
+			"orgId":  {Type: "Number", Value: strconv.FormatInt(evalContext.Rule.OrgID, 10)},
+		},
+	}
+	if sn.Secret != "" {
+		notification.SignatureVersion = snsHTTPSignatureVersion
+		notification.Signature = signSNSHTTPNotification(notification, sn.Secret)
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:  sn.URL,
+		Body: string(body),
+		HttpHeader: map[string]string{
+			"x-amz-sns-message-type": notification.Type,
+			"x-amz-sns-message-id":   notification.MessageID,
+			"x-amz-sns-topic-arn":    notification.TopicArn,
+		},
+	}
+
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		sn.log.Error("Failed to send SNS-style HTTP notification", "error", err, "notification", sn.Name)
+		return err
+	}
+
+	return nil
+}
+
+// signSNSHTTPNotification signs the string to sign of an Amazon SNS
+// notification, the name and value of its fields on separate lines in
+// alphabetical order, with the secret
+func signSNSHTTPNotification(notification *snsHTTPNotification, secret string) string {
+	var stringToSign strings.Builder
+	for _, field := range [][2]string{
+		{"Message", notification.Message},
+		{"MessageId", notification.MessageID},
+		{"Subject", notification.Subject},
+		{"Timestamp", notification.Timestamp},
+		{"TopicArn", notification.TopicArn},
+		{"Type", notification.Type},
+	} {
+		stringToSign.WriteString(field[0] + "\n" + field[1] + "\n")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stringToSign.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,141 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/stretchr/testify/require"
+)
+
+func newSNSHTTPNotifier(t *testing.T, settings string) (*SNSHTTPNotifier, error) {
+	t.Helper()
+
+	settingsJSON, err := simplejson.NewJson([]byte(settings))
+	require.NoError(t, err)
+	model := &models.AlertNotification{
+		Name:     "ops",
+		Type:     "sns-http",
+		Settings: settingsJSON,
+	}
+
+	not, err := NewSNSHTTPNotifier(model)
+	if err != nil {
+		return nil, err
+	}
+	return not.(*SNSHTTPNotifier), nil
+}
+
+func TestSNSHTTPNotifier_parsingFromSettings(t *testing.T) {
+	t.Run("Empty settings should cause error", func(t *testing.T) {
+		_, err := newSNSHTTPNotifier(t, `{}`)
+		require.EqualError(t, err, "alert validation error: Could not find url property in settings")
+	})
+
+	t.Run("Invalid url should cause error", func(t *testing.T) {
+		_, err := newSNSHTTPNotifier(t, `{"url": "ftp://subscriber"}`)
+		require.EqualError(t, err, "alert validation error: Invalid url property in settings, must be an http or https URL")
+	})
+
+	t.Run("Missing topic should default to grafana", func(t *testing.T) {
+		not, err := newSNSHTTPNotifier(t, `{"url": "http://subscriber"}`)
+		require.NoError(t, err)
+		require.Equal(t, "http://subscriber", not.URL)
+		require.Equal(t, "grafana", not.Topic)
+		require.Empty(t, not.Secret)
+	})
+
+	t.Run("Valid settings should result in a valid notifier", func(t *testing.T) {
+		not, err := newSNSHTTPNotifier(t, `{"url": "https://subscriber/notifications", "topic": "ops-alerts", "secret": "xxx"}`)
+		require.NoError(t, err)
+		require.Equal(t, "https://subscriber/notifications", not.URL)
+		require.Equal(t, "ops-alerts", not.Topic)
+		require.Equal(t, "xxx", not.Secret)
+	})
+}
+
+func TestSNSHTTPNotifier_Notify(t *testing.T) {
+	newEvalContext := func() *alerting.EvalContext {
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			ID:            7,
+			OrgID:         1,
+			Name:          "someRule",
+			Message:       "someMessage",
+			State:         models.AlertStateAlerting,
+			AlertRuleTags: []*models.Tag{{Key: "team", Value: "ops"}},
+		})
+		evalContext.IsTestRun = true
+		evalContext.EvalMatches = []*alerting.EvalMatch{{Metric: "cpu", Tags: map[string]string{}}}
+		return evalContext
+	}
+
+	t.Run("should send an SNS notification of the alert", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+		not, err := newSNSHTTPNotifier(t, `{"url": "`+server.URL+`/notifications", "topic": "ops-alerts"}`)
+		require.NoError(t, err)
+		require.NoError(t, not.Notify(newEvalContext()))
+
+		req := server.lastRequest(t)
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/notifications", req.Path)
+		require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		var notification snsHTTPNotification
+		require.NoError(t, json.Unmarshal([]byte(req.Body), &notification))
+		require.Equal(t, "Notification", notification.Type)
+		require.NotEmpty(t, notification.MessageID)
+		require.Equal(t, "ops-alerts", notification.TopicArn)
+		require.Equal(t, "[Alerting] someRule", notification.Subject)
+		require.NotEmpty(t, notification.Timestamp)
+		require.Empty(t, notification.SignatureVersion)
+		require.Empty(t, notification.Signature)
+		require.Equal(t, snsHTTPAttributeValue{Type: "String", Value: "alerting"}, notification.MessageAttributes["state"])
+		require.Equal(t, snsHTTPAttributeValue{Type: "Number", Value: "7"}, notification.MessageAttributes["ruleId"])
+
+		require.Equal(t, "Notification", req.Header.Get("x-amz-sns-message-type"))
+		require.Equal(t, notification.MessageID, req.Header.Get("x-amz-sns-message-id"))
+		require.Equal(t, "ops-alerts", req.Header.Get("x-amz-sns-topic-arn"))
+
+		var message snsHTTPMessage
+		require.NoError(t, json.Unmarshal([]byte(notification.Message), &message))
+		require.Equal(t, "[Alerting] someRule", message.Title)
+		require.Equal(t, "someMessage", message.Message)
+		require.Equal(t, models.AlertStateAlerting, message.State)
+		require.Equal(t, int64(7), message.RuleID)
+		require.Equal(t, "someRule", message.RuleName)
+		require.Equal(t, map[string]string{"team": "ops"}, message.Tags)
+		require.Len(t, message.EvalMatches, 1)
+		require.Equal(t, "cpu", message.EvalMatches[0].Metric)
+	})
+
+	t.Run("should sign the notification with the secret", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+		not, err := newSNSHTTPNotifier(t, `{"url": "`+server.URL+`", "secret": "xxx"}`)
+		require.NoError(t, err)
+		require.NoError(t, not.Notify(newEvalContext()))
+
+		var notification snsHTTPNotification
+		require.NoError(t, json.Unmarshal([]byte(server.lastRequest(t).Body), &notification))
+		require.Equal(t, "grafana", notification.TopicArn)
+		require.Equal(t, "HmacSHA256", notification.SignatureVersion)
+		require.NotEmpty(t, notification.Signature)
+		require.Equal(t, signSNSHTTPNotification(&notification, "xxx"), notification.Signature)
+		require.NotEqual(t, signSNSHTTPNotification(&notification, "yyy"), notification.Signature)
+	})
+
+	t.Run("should fail when the endpoint returns an error", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		not, err := newSNSHTTPNotifier(t, `{"url": "`+server.URL+`"}`)
+		require.NoError(t, err)
+		require.Error(t, not.Notify(newEvalContext()))
+	})
+}
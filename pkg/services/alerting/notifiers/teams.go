@@ -2,6 +2,7 @@ package notifiers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -25,10 +26,31 @@ func init() {
 				PropertyName: "url",
 				Required:     true,
 			},
+			{
+				Label:   "Card type",
+				Element: alerting.ElementTypeSelect,
+				SelectOptions: []alerting.SelectOption{
+					{
+						Value: teamsMessageCard,
+						Label: "Message card",
+					},
+					{
+						Value: teamsAdaptiveCard,
+						Label: "Adaptive card",
+					},
+				},
+				Description:  "Adaptive cards are supported by the Workflows of Teams, message cards by the legacy connectors",
+				PropertyName: "cardType",
+			},
 		},
 	})
 }
 
+const (
+	teamsMessageCard  = "messageCard"
+	teamsAdaptiveCard = "adaptiveCard"
+)
+
 // NewTeamsNotifier is the constructor for Teams notifier.
 func NewTeamsNotifier(model *models.AlertNotification) (alerting.Notifier, error) {
 	url := model.Settings.Get("url").MustString()
@@ -36,9 +58,15 @@ func NewTeamsNotifier(model *models.AlertNotification) (alerting.Notifier, error
 		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
 	}
 
+	cardType := model.Settings.Get("cardType").MustString(teamsMessageCard)
+	if cardType != teamsMessageCard && cardType != teamsAdaptiveCard {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid cardType %q, must be %s or %s", cardType, teamsMessageCard, teamsAdaptiveCard)}
+	}
+
 	return &TeamsNotifier{
 		NotifierBase: NewNotifierBase(model),
 		URL:          url,
+		CardType:     cardType,
 		log:          log.New("alerting.notifier.teams"),
 	}, nil
 }
@@ -47,8 +75,9 @@ func NewTeamsNotifier(model *models.AlertNotification) (alerting.Notifier, error
 // alert notifications to Microsoft teams.
 type TeamsNotifier struct {
 	NotifierBase
-	URL string
-	log log.Logger
+	URL      string
+	CardType string
+	log      log.Logger
 }
 
 // Notify send an alert notification to Microsoft teams.
@@ -61,6 +90,10 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 		return err
 	}
 
+	if tn.CardType == teamsAdaptiveCard {
+		return tn.send(evalContext, tn.adaptiveCard(evalContext, ruleURL))
+	}
+
 	fields := make([]map[string]interface{}, 0)
 	fieldLimitCount := 4
 	for index, evt := range evalContext.EvalMatches {
@@ -82,7 +115,7 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 
 	message := ""
 	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
-		message = evalContext.Rule.Message
+		message = tn.GetNotificationMessage(evalContext)
 	}
 
 	images := make([]map[string]interface{}, 0)
@@ -92,13 +125,14 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 		})
 	}
 
+	title := tn.GetNotificationTitle(evalContext)
 	body := map[string]interface{}{
 		"@type":    "MessageCard",
 		"@context": "http://schema.org/extensions",
 		// summary MUST not be empty or the webhook request fails
 		// summary SHOULD contain some meaningful information, since it is used for mobile notifications
-		"summary":    evalContext.GetNotificationTitle(),
-		"title":      evalContext.GetNotificationTitle(),
+		"summary":    title,
+		"title":      title,
 		"themeColor": evalContext.GetStateModel().Color,
 		"sections": []map[string]interface{}{
 			{
@@ -132,6 +166,108 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 		},
 	}
 
+	return tn.send(evalContext, body)
+}
+
+// adaptiveCard builds a message holding an adaptive card, the format expected
+// by the webhooks of the Workflows of Teams.
+func (tn *TeamsNotifier) adaptiveCard(evalContext *alerting.EvalContext, ruleURL string) map[string]interface{} {
+	color := "Default"
+	switch evalContext.Rule.State {
+	case models.AlertStateAlerting:
+		color = "Attention"
+	case models.AlertStateOK:
+		color = "Good"
+	case models.AlertStateNoData:
+		color = "Warning"
+	}
+
+	body := []map[string]interface{}{
+		{
+			"type":   "TextBlock",
+			"text":   tn.GetNotificationTitle(evalContext),
+			"weight": "Bolder",
+			"size":   "Large",
+			"color":  color,
+			"wrap":   true,
+		},
+	}
+
+	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
+		if message := tn.GetNotificationMessage(evalContext); message != "" {
+			body = append(body, map[string]interface{}{
+				"type": "TextBlock",
+				"text": message,
+				"wrap": true,
+			})
+		}
+	}
+
+	facts := make([]map[string]interface{}, 0)
+	fieldLimitCount := 4
+	for index, evt := range evalContext.EvalMatches {
+		facts = append(facts, map[string]interface{}{
+			"title": evt.Metric,
+			"value": evt.Value.String(),
+		})
+		if index > fieldLimitCount {
+			break
+		}
+	}
+	if evalContext.Error != nil {
+		facts = append(facts, map[string]interface{}{
+			"title": "Error message",
+			"value": evalContext.Error.Error(),
+		})
+	}
+	if len(facts) > 0 {
+		body = append(body, map[string]interface{}{
+			"type":  "FactSet",
+			"facts": facts,
+		})
+	}
+
+	actions := []map[string]interface{}{
+		{
+			"type":  "Action.OpenUrl",
+			"title": "View Rule",
+			"url":   ruleURL,
+		},
+	}
+
+	if tn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		body = append(body, map[string]interface{}{
+			"type": "Image",
+			"url":  evalContext.ImagePublicURL,
+		})
+		actions = append(actions, map[string]interface{}{
+			"type":  "Action.OpenUrl",
+			"title": "View Graph",
+			"url":   evalContext.ImagePublicURL,
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+					"actions": actions,
+					"msteams": map[string]interface{}{
+						"width": "Full",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (tn *TeamsNotifier) send(evalContext *alerting.EvalContext, body map[string]interface{}) error {
 	data, _ := json.Marshal(&body)
 	cmd := &models.SendWebhookSync{Url: tn.URL, Body: string(data)}
 
@@ -1,10 +1,14 @@
 package notifiers
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -68,6 +72,73 @@ func TestTeamsNotifier(t *testing.T) {
 				So(teamsNotifier.Type, ShouldEqual, "teams")
 				So(teamsNotifier.URL, ShouldEqual, "http://google.com")
 			})
+
+			Convey("from settings with invalid card type", func() {
+				json := `
+				{
+          "url": "http://google.com",
+          "cardType": "heroCard"
+				}`
+
+				settingsJSON, _ := simplejson.NewJson([]byte(json))
+				model := &models.AlertNotification{
+					Name:     "ops",
+					Type:     "teams",
+					Settings: settingsJSON,
+				}
+
+				_, err := NewTeamsNotifier(model)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("Sending an adaptive card", func() {
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			json := `
+				{
+          "url": "` + server.URL + `",
+          "cardType": "adaptiveCard"
+				}`
+
+			settingsJSON, _ := simplejson.NewJson([]byte(json))
+			model := &models.AlertNotification{
+				Name:     "ops",
+				Type:     "teams",
+				Settings: settingsJSON,
+			}
+
+			not, err := NewTeamsNotifier(model)
+			So(err, ShouldBeNil)
+			So(not.(*TeamsNotifier).CardType, ShouldEqual, "adaptiveCard")
+
+			evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+				Name:    "someRule",
+				Message: "someMessage",
+				State:   models.AlertStateAlerting,
+			})
+			evalContext.IsTestRun = true
+			evalContext.EvalMatches = []*alerting.EvalMatch{{Metric: "cpu", Value: null.FloatFrom(95)}}
+
+			err = not.Notify(evalContext)
+			So(err, ShouldBeNil)
+
+			body, err := simplejson.NewJson([]byte(server.lastRequest(t).Body))
+			So(err, ShouldBeNil)
+			So(body.Get("type").MustString(), ShouldEqual, "message")
+
+			attachment := body.Get("attachments").GetIndex(0)
+			So(attachment.Get("contentType").MustString(), ShouldEqual, "application/vnd.microsoft.card.adaptive")
+
+			card := attachment.Get("content")
+			So(card.Get("type").MustString(), ShouldEqual, "AdaptiveCard")
+			So(card.Get("body").GetIndex(0).Get("text").MustString(), ShouldEqual, "[Alerting] someRule")
+			So(card.Get("body").GetIndex(0).Get("color").MustString(), ShouldEqual, "Attention")
+			So(card.Get("body").GetIndex(1).Get("text").MustString(), ShouldEqual, "someMessage")
+			So(card.Get("body").GetIndex(2).Get("facts").GetIndex(0).Get("value").MustString(), ShouldEqual, "95.000")
+			So(card.Get("actions").GetIndex(0).Get("title").MustString(), ShouldEqual, "View Rule")
 		})
 	})
 }
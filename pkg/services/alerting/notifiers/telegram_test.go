@@ -59,7 +59,7 @@ func TestTelegramNotifier(t *testing.T) {
 						State:   models.AlertStateOK,
 					})
 
-				caption := generateImageCaption(evalContext, "http://grafa.url/abcdef", "")
+				caption := (&TelegramNotifier{}).generateImageCaption(evalContext, "http://grafa.url/abcdef", "")
 				So(len(caption), ShouldBeLessThanOrEqualTo, 1024)
 				So(caption, ShouldContainSubstring, "Some kind of message.")
 				So(caption, ShouldContainSubstring, "[OK] This is an alarm")
@@ -75,7 +75,7 @@ func TestTelegramNotifier(t *testing.T) {
 							State:   models.AlertStateOK,
 						})
 
-					caption := generateImageCaption(evalContext,
+					caption := (&TelegramNotifier{}).generateImageCaption(evalContext,
 						"http://grafa.url/abcdefaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
 						"foo bar")
 					So(len(caption), ShouldBeLessThanOrEqualTo, 1024)
@@ -93,7 +93,7 @@ func TestTelegramNotifier(t *testing.T) {
 							State:   models.AlertStateOK,
 						})
 
-					caption := generateImageCaption(evalContext,
+					caption := (&TelegramNotifier{}).generateImageCaption(evalContext,
 						"http://grafa.url/foo",
 						"")
 					So(len(caption), ShouldBeLessThanOrEqualTo, 1024)
@@ -110,7 +110,7 @@ func TestTelegramNotifier(t *testing.T) {
 							State:   models.AlertStateOK,
 						})
 
-					caption := generateImageCaption(evalContext,
+					caption := (&TelegramNotifier{}).generateImageCaption(evalContext,
 						"http://grafa.url/foo",
 						"foo bar long song")
 					So(len(caption), ShouldBeLessThanOrEqualTo, 1024)
@@ -0,0 +1,113 @@
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// testServer is a local stand-in for the service a notifier talks to,
+// recording the requests it receives.
+type testServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []testRequest
+}
+
+type testRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   string
+}
+
+// newTestServer starts a server answering with the handler, and sends the
+// webhooks of the notifiers to it over HTTP the way the notification service
+// does.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *testServer {
+	t.Helper()
+
+	ts := &testServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		ts.mu.Lock()
+		ts.requests = append(ts.requests, testRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: string(body)})
+		ts.mu.Unlock()
+
+		handler(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	bus.AddHandlerCtx("test", sendTestWebhook)
+	t.Cleanup(bus.ClearBusHandlers)
+
+	return ts
+}
+
+// lastRequest returns the last request received by the server.
+func (ts *testServer) lastRequest(t *testing.T) testRequest {
+	t.Helper()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if len(ts.requests) == 0 {
+		t.Fatal("no request received")
+	}
+	return ts.requests[len(ts.requests)-1]
+}
+
+func sendTestWebhook(ctx context.Context, cmd *models.SendWebhookSync) error {
+	method := cmd.HttpMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, cmd.Url, strings.NewReader(cmd.Body))
+	if err != nil {
+		return err
+	}
+
+	contentType := cmd.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	request.Header.Set("Content-Type", contentType)
+	if cmd.User != "" && cmd.Password != "" {
+		request.SetBasicAuth(cmd.User, cmd.Password)
+	}
+	for k, v := range cmd.HttpHeader {
+		request.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Validation != nil {
+		if err := cmd.Validation(body, resp.StatusCode); err != nil {
+			return fmt.Errorf("webhook failed validation: %w", err)
+		}
+		return nil
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook response status %v", resp.Status)
+	}
+	return nil
+}
@@ -111,10 +111,10 @@ func (vn *VictoropsNotifier) Notify(evalContext *alerting.EvalContext) error {
 	bodyJSON := simplejson.New()
 	bodyJSON.Set("message_type", messageType)
 	bodyJSON.Set("entity_id", evalContext.Rule.Name)
-	bodyJSON.Set("entity_display_name", evalContext.GetNotificationTitle())
+	bodyJSON.Set("entity_display_name", vn.GetNotificationTitle(evalContext))
 	bodyJSON.Set("timestamp", time.Now().Unix())
 	bodyJSON.Set("state_start_time", evalContext.StartTime.Unix())
-	bodyJSON.Set("state_message", evalContext.Rule.Message)
+	bodyJSON.Set("state_message", vn.GetNotificationMessage(evalContext))
 	bodyJSON.Set("monitoring_tool", "Grafana v"+setting.BuildVersion)
 	bodyJSON.Set("alert_url", ruleURL)
 	bodyJSON.Set("metrics", fields)
@@ -92,7 +92,7 @@ func (wn *WebhookNotifier) Notify(evalContext *alerting.EvalContext) error {
 	wn.log.Info("Sending webhook")
 
 	bodyJSON := simplejson.New()
-	bodyJSON.Set("title", evalContext.GetNotificationTitle())
+	bodyJSON.Set("title", wn.GetNotificationTitle(evalContext))
 	bodyJSON.Set("ruleId", evalContext.Rule.ID)
 	bodyJSON.Set("ruleName", evalContext.Rule.Name)
 	bodyJSON.Set("state", evalContext.Rule.State)
@@ -118,8 +118,8 @@ func (wn *WebhookNotifier) Notify(evalContext *alerting.EvalContext) error {
 		bodyJSON.Set("imageUrl", evalContext.ImagePublicURL)
 	}
 
-	if evalContext.Rule.Message != "" {
-		bodyJSON.Set("message", evalContext.Rule.Message)
+	if message := wn.GetNotificationMessage(evalContext); message != "" {
+		bodyJSON.Set("message", message)
 	}
 
 	body, _ := bodyJSON.MarshalJSON()
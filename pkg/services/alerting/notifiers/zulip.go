@@ -0,0 +1,219 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+const (
+	zulipDefaultTopic = "{{ .RuleName }}"
+	// zulipMaxTopicLength is the maximum length of a topic accepted by Zulip
+	zulipMaxTopicLength = 60
+)
+
+func init() {
+	alerting.RegisterNotifier(&alerting.NotifierPlugin{
+		Type:        "zulip",
+		Name:        "Zulip",
+		Description: "Sends notifications to a Zulip stream with a bot account",
+		Heading:     "Zulip settings",
+		Factory:     NewZulipNotifier,
+		Options: []alerting.NotifierOption{
+			{
+				Label:        "Server URL",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "https://example.zulipchat.com",
+				PropertyName: "url",
+				Required:     true,
+			},
+			{
+				Label:        "Bot email",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "grafana-bot@example.zulipchat.com",
+				PropertyName: "botEmail",
+				Required:     true,
+			},
+			{
+				Label:        "API key",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "API key of the bot",
+				PropertyName: "apiKey",
+				Required:     true,
+				Secure:       true,
+			},
+			{
+				Label:        "Stream",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				PropertyName: "stream",
+				Required:     true,
+			},
+			{
+				Label:        "Topic",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  zulipDefaultTopic,
+				Description:  "Template of the topic, the notifications of an alert rule are threaded in the topic of the rule by default",
+				PropertyName: "topic",
+			},
+		},
+	})
+}
+
+// NewZulipNotifier is the constructor for the Zulip notifier.
+func NewZulipNotifier(model *models.AlertNotification) (alerting.Notifier, error) {
+	serverURL := strings.TrimSuffix(model.Settings.Get("url").MustString(), "/")
+	if serverURL == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+	botEmail := model.Settings.Get("botEmail").MustString()
+	if botEmail == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find botEmail property in settings"}
+	}
+	apiKey := model.DecryptedValue("apiKey", model.Settings.Get("apiKey").MustString())
+	if apiKey == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find apiKey property in settings"}
+	}
+	stream := model.Settings.Get("stream").MustString()
+	if stream == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find stream property in settings"}
+	}
+
+	topic := model.Settings.Get("topic").MustString()
+	if strings.TrimSpace(topic) == "" {
+		topic = zulipDefaultTopic
+	}
+	topicTemplate, err := alerting.ParseNotificationTemplate("topic", topic)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZulipNotifier{
+		NotifierBase:  NewNotifierBase(model),
+		URL:           serverURL,
+		BotEmail:      botEmail,
+		APIKey:        apiKey,
+		Stream:        stream,
+		topicTemplate: topicTemplate,
+		log:           log.New("alerting.notifier.zulip"),
+	}, nil
+}
+
+// ZulipNotifier is responsible for sending
+// alert notifications to Zulip.
+type ZulipNotifier struct {
+	NotifierBase
+	URL      string
+	BotEmail string
+	APIKey   string
+	Stream   string
+
+	topicTemplate *template.Template
+	log           log.Logger
+}
+
+// Notify sends an alert notification to Zulip.
+func (zn *ZulipNotifier) Notify(evalContext *alerting.EvalContext) error {
+	zn.log.Info("Executing zulip notification", "ruleId", evalContext.Rule.ID, "notification", zn.Name)
+
+	ruleURL, err := evalContext.GetRuleURL()
+	if err != nil {
+		zn.log.Error("Failed get rule link", "error", err)
+		return err
+	}
+
+	form := url.Values{}
+	form.Set("type", "stream")
+	form.Set("to", zn.Stream)
+	form.Set("topic", zn.topic(evalContext))
+	form.Set("content", zn.content(evalContext, ruleURL))
+
+	cmd := &models.SendWebhookSync{
+		Url:         zn.URL + "/api/v1/messages",
+		User:        zn.BotEmail,
+		Password:    zn.APIKey,
+		Body:        form.Encode(),
+		ContentType: "application/x-www-form-urlencoded",
+		Validation: func(body []byte, statusCode int) error {
+			var result struct {
+				Result string `json:"result"`
+				Msg    string `json:"msg"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return fmt.Errorf("unexpected response with status code %d: %w", statusCode, err)
+			}
+			if statusCode/100 != 2 || result.Result != "success" {
+				return fmt.Errorf("zulip returned %q with status code %d: %s", result.Result, statusCode, result.Msg)
+			}
+			return nil
+		},
+	}
+
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		zn.log.Error("Failed to send zulip notification", "error", err, "webhook", zn.Name)
+		return err
+	}
+
+	return nil
+}
+
+// topic renders the topic of the notification, the notifications of a same
+// topic being threaded together
+func (zn *ZulipNotifier) topic(evalContext *alerting.EvalContext) string {
+	topic, err := alerting.ExecuteNotificationTemplate(zn.topicTemplate, evalContext)
+	if err != nil {
+		zn.log.Warn("Failed to render the topic, using the name of the rule", "error", err)
+		topic = evalContext.Rule.Name
+	}
+
+	topic = strings.TrimSpace(topic)
+	if topic == "" {
+		topic = evalContext.Rule.Name
+	}
+	if runes := []rune(topic); len(runes) > zulipMaxTopicLength {
+		topic = string(runes[:zulipMaxTopicLength-1]) + "…"
+	}
+	return topic
+}
+
+func (zn *ZulipNotifier) content(evalContext *alerting.EvalContext, ruleURL string) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "**[%s](%s)**\n", zn.GetNotificationTitle(evalContext), ruleURL)
+
+	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
+		if message := zn.GetNotificationMessage(evalContext); message != "" {
+			fmt.Fprintf(&content, "%s\n", message)
+		}
+	}
+
+	if len(evalContext.EvalMatches) > 0 {
+		content.WriteString("\n")
+		for index, evt := range evalContext.EvalMatches {
+			fmt.Fprintf(&content, "- %s: %s\n", evt.Metric, evt.Value)
+			if index > 4 {
+				break
+			}
+		}
+	}
+
+	if evalContext.Error != nil {
+		fmt.Fprintf(&content, "\n**Error message:** %s\n", evalContext.Error.Error())
+	}
+
+	if zn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		fmt.Fprintf(&content, "\n[Graph](%s)\n", evalContext.ImagePublicURL)
+	}
+
+	return content.String()
+}
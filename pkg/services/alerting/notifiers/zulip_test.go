@@ -0,0 +1,123 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/stretchr/testify/require"
+)
+
+func newZulipNotifier(t *testing.T, settings string) (*ZulipNotifier, error) {
+	t.Helper()
+
+	settingsJSON, err := simplejson.NewJson([]byte(settings))
+	require.NoError(t, err)
+	model := &models.AlertNotification{
+		Name:     "ops",
+		Type:     "zulip",
+		Settings: settingsJSON,
+	}
+
+	not, err := NewZulipNotifier(model)
+	if err != nil {
+		return nil, err
+	}
+	return not.(*ZulipNotifier), nil
+}
+
+func TestZulipNotifier_parsingFromSettings(t *testing.T) {
+	t.Run("Empty settings should cause error", func(t *testing.T) {
+		_, err := newZulipNotifier(t, `{}`)
+		require.EqualError(t, err, "alert validation error: Could not find url property in settings")
+	})
+
+	t.Run("Missing stream should cause error", func(t *testing.T) {
+		_, err := newZulipNotifier(t, `{"url": "http://zulip", "botEmail": "bot@zulip", "apiKey": "secret"}`)
+		require.EqualError(t, err, "alert validation error: Could not find stream property in settings")
+	})
+
+	t.Run("Invalid topic should cause error", func(t *testing.T) {
+		_, err := newZulipNotifier(t, `{"url": "http://zulip", "botEmail": "bot@zulip", "apiKey": "secret", "stream": "ops", "topic": "{{ .Unknown }}"}`)
+		require.Error(t, err)
+	})
+
+	t.Run("Valid settings should result in a valid notifier", func(t *testing.T) {
+		not, err := newZulipNotifier(t, `{"url": "http://zulip/", "botEmail": "bot@zulip", "apiKey": "secret", "stream": "ops"}`)
+		require.NoError(t, err)
+		require.Equal(t, "http://zulip", not.URL)
+		require.Equal(t, "bot@zulip", not.BotEmail)
+		require.Equal(t, "secret", not.APIKey)
+		require.Equal(t, "ops", not.Stream)
+	})
+}
+
+func TestZulipNotifier_Notify(t *testing.T) {
+	t.Run("should send a message to the topic of the rule", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"result": "success", "msg": "", "id": 42}`))
+		})
+
+		not, err := newZulipNotifier(t, `{"url": "`+server.URL+`", "botEmail": "bot@zulip", "apiKey": "secret", "stream": "ops"}`)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{Name: "someRule", Message: "someMessage", State: models.AlertStateAlerting})
+		evalContext.IsTestRun = true
+		require.NoError(t, not.Notify(evalContext))
+
+		req := server.lastRequest(t)
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/api/v1/messages", req.Path)
+		require.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+		require.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("bot@zulip:secret")), req.Header.Get("Authorization"))
+
+		form, err := url.ParseQuery(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, "stream", form.Get("type"))
+		require.Equal(t, "ops", form.Get("to"))
+		require.Equal(t, "someRule", form.Get("topic"))
+		require.Contains(t, form.Get("content"), "[Alerting] someRule")
+		require.Contains(t, form.Get("content"), "someMessage")
+	})
+
+	t.Run("should render and truncate the topic", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"result": "success"}`))
+		})
+
+		not, err := newZulipNotifier(t, `{"url": "`+server.URL+`", "botEmail": "bot@zulip", "apiKey": "secret", "stream": "ops", "topic": "alerts/{{ .RuleName }}"}`)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{Name: strings.Repeat("a", 80), State: models.AlertStateAlerting})
+		evalContext.IsTestRun = true
+		require.NoError(t, not.Notify(evalContext))
+
+		form, err := url.ParseQuery(server.lastRequest(t).Body)
+		require.NoError(t, err)
+		topic := form.Get("topic")
+		require.True(t, strings.HasPrefix(topic, "alerts/aaa"))
+		require.Len(t, []rune(topic), zulipMaxTopicLength)
+	})
+
+	t.Run("should fail when zulip returns an error", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"result": "error", "msg": "Stream 'ops' does not exist"}`))
+		})
+
+		not, err := newZulipNotifier(t, `{"url": "`+server.URL+`", "botEmail": "bot@zulip", "apiKey": "secret", "stream": "ops"}`)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{Name: "someRule", State: models.AlertStateAlerting})
+		evalContext.IsTestRun = true
+		err = not.Notify(evalContext)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Stream 'ops' does not exist")
+	})
+}
@@ -2,6 +2,7 @@ package alerting
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -12,7 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 
 	"github.com/grafana/grafana/pkg/services/annotations"
-	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type resultHandler interface {
@@ -24,10 +25,10 @@ type defaultResultHandler struct {
 	log      log.Logger
 }
 
-func newResultHandler(renderService rendering.Service) *defaultResultHandler {
+func newResultHandler(notifier *notificationService) *defaultResultHandler {
 	return &defaultResultHandler{
 		log:      log.New("alerting.resultHandler"),
-		notifier: newNotificationService(renderService),
+		notifier: notifier,
 	}
 }
 
@@ -46,6 +47,16 @@ func (handler *defaultResultHandler) handle(evalContext *EvalContext) error {
 		annotationData.Set("noData", true)
 	}
 
+	// every evaluation is recorded once handled, including the ones of paused
+	// alerts and of alerts already updated by another instance
+	if !evalContext.IsTestRun && setting.AlertingEvaluationHistoryLimit > 0 {
+		defer func() {
+			if err := handler.saveEvaluation(evalContext); err != nil {
+				handler.log.Error("Failed to save alert evaluation", "ruleId", evalContext.Rule.ID, "error", err)
+			}
+		}()
+	}
+
 	metrics.MAlertingResultState.WithLabelValues(string(evalContext.Rule.State)).Inc()
 	if evalContext.shouldUpdateAlertState() {
 		handler.log.Info("New state change", "ruleId", evalContext.Rule.ID, "newState", evalContext.Rule.State, "prev state", evalContext.PrevAlertState)
@@ -112,3 +123,59 @@ func (handler *defaultResultHandler) handle(evalContext *EvalContext) error {
 
 	return nil
 }
+
+// evaluationRecord is an evaluation kept in the history of the alert rule
+type evaluationRecord struct {
+	State          models.AlertStateType  `json:"state"`
+	PrevState      models.AlertStateType  `json:"prevState"`
+	Firing         bool                   `json:"firing"`
+	NoDataFound    bool                   `json:"noDataFound"`
+	ConditionEvals string                 `json:"conditionEvals"`
+	DurationMs     int64                  `json:"durationMs"`
+	Conditions     []*ConditionEvaluation `json:"conditions"`
+	Notifications  []*NotificationResult  `json:"notifications"`
+	Error          string                 `json:"error,omitempty"`
+}
+
+func (handler *defaultResultHandler) saveEvaluation(evalContext *EvalContext) error {
+	record := evaluationRecord{
+		State:          evalContext.Rule.State,
+		PrevState:      evalContext.PrevAlertState,
+		Firing:         evalContext.Firing,
+		NoDataFound:    evalContext.NoDataFound,
+		ConditionEvals: evalContext.ConditionEvals,
+		DurationMs:     evalContext.EndTime.Sub(evalContext.StartTime).Nanoseconds() / int64(time.Millisecond),
+		Conditions:     evalContext.Conditions,
+		Notifications:  evalContext.Notifications,
+	}
+	if record.Conditions == nil {
+		record.Conditions = []*ConditionEvaluation{}
+	}
+	if record.Notifications == nil {
+		record.Notifications = []*NotificationResult{}
+	}
+	if evalContext.Error != nil {
+		record.Error = evalContext.Error.Error()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	evalData, err := simplejson.NewJson(data)
+	if err != nil {
+		return err
+	}
+
+	cmd := &models.SaveAlertEvaluationCommand{
+		AlertId: evalContext.Rule.ID,
+		OrgId:   evalContext.Rule.OrgID,
+		State:   evalContext.Rule.State,
+		Data:    evalData,
+		Created: evalContext.StartTime,
+		Limit:   setting.AlertingEvaluationHistoryLimit,
+	}
+	// the context may be done when the notifications timed out, which
+	// the history should still show
+	return bus.Dispatch(cmd)
+}
@@ -0,0 +1,54 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultHandlerSavesEvaluation(t *testing.T) {
+	origLimit := setting.AlertingEvaluationHistoryLimit
+	t.Cleanup(func() { setting.AlertingEvaluationHistoryLimit = origLimit })
+	setting.AlertingEvaluationHistoryLimit = 20
+
+	handleScenario := func(t *testing.T, setStateErr error) []*models.SaveAlertEvaluationCommand {
+		// the SQL handlers are put back for the tests using the test database
+		t.Cleanup(func() {
+			bus.AddHandler("sql", sqlstore.SetAlertState)
+			bus.AddHandlerCtx("sql", sqlstore.SaveAlertEvaluation)
+		})
+
+		var saved []*models.SaveAlertEvaluationCommand
+		bus.AddHandler("test", func(cmd *models.SetAlertStateCommand) error {
+			return setStateErr
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SaveAlertEvaluationCommand) error {
+			saved = append(saved, cmd)
+			return nil
+		})
+
+		evalContext := NewEvalContext(context.Background(), &Rule{ID: 1, OrgID: 2, State: models.AlertStateOK})
+		evalContext.Rule.State = models.AlertStateAlerting
+
+		handler := newResultHandler(newNotificationService(nil))
+		_ = handler.handle(evalContext)
+		return saved
+	}
+
+	t.Run("should save the evaluation of a paused alert", func(t *testing.T) {
+		saved := handleScenario(t, models.ErrCannotChangeStateOnPausedAlert)
+		require.Len(t, saved, 1)
+		require.Equal(t, int64(1), saved[0].AlertId)
+		require.Equal(t, models.AlertStateAlerting, saved[0].State)
+	})
+
+	t.Run("should save the evaluation of an alert updated by another instance", func(t *testing.T) {
+		saved := handleScenario(t, models.ErrRequiresNewState)
+		require.Len(t, saved, 1)
+	})
+}
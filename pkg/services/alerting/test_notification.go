@@ -25,12 +25,26 @@ type NotificationTestCommand struct {
 	SecureSettings map[string]string
 }
 
+// NotificationPreviewCommand renders the title and message of a test
+// notification with the templates of a notification channel, without sending it.
+type NotificationPreviewCommand struct {
+	Settings *simplejson.Json
+	Result   *NotificationPreview
+}
+
+// NotificationPreview is the title and message of a notification.
+type NotificationPreview struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
 var (
 	logger = log.New("alerting.testnotification")
 )
 
 func init() {
 	bus.AddHandlerCtx("alerting", handleNotificationTestCommand)
+	bus.AddHandlerCtx("alerting", handleNotificationPreviewCommand)
 }
 
 func handleNotificationTestCommand(ctx context.Context, cmd *NotificationTestCommand) error {
@@ -64,6 +78,10 @@ func handleNotificationTestCommand(ctx context.Context, cmd *NotificationTestCom
 
 	model.SecureSettings = securejsondata.GetEncryptedJsonData(secureSettingsMap)
 
+	if _, err := ParseNotificationTemplates(model.Settings); err != nil {
+		return err
+	}
+
 	notifiers, err := InitNotifier(model)
 
 	if err != nil {
@@ -71,10 +89,30 @@ func handleNotificationTestCommand(ctx context.Context, cmd *NotificationTestCom
 		return err
 	}
 
-	return notifier.sendNotifications(createTestEvalContext(cmd), notifierStateSlice{{notifier: notifiers}})
+	return notifier.sendNotifications(createTestEvalContext(cmd.Settings), notifierStateSlice{{notifier: notifiers}})
+}
+
+func handleNotificationPreviewCommand(ctx context.Context, cmd *NotificationPreviewCommand) error {
+	templates, err := ParseNotificationTemplates(cmd.Settings)
+	if err != nil {
+		return err
+	}
+
+	evalContext := createTestEvalContext(cmd.Settings)
+	title, err := templates.Title(evalContext)
+	if err != nil {
+		return ValidationError{Reason: fmt.Sprintf("invalid %s: %v", TitleTemplateSetting, err)}
+	}
+	message, err := templates.Message(evalContext)
+	if err != nil {
+		return ValidationError{Reason: fmt.Sprintf("invalid %s: %v", MessageTemplateSetting, err)}
+	}
+
+	cmd.Result = &NotificationPreview{Title: title, Message: message}
+	return nil
 }
 
-func createTestEvalContext(cmd *NotificationTestCommand) *EvalContext {
+func createTestEvalContext(settings *simplejson.Json) *EvalContext {
 	testRule := &Rule{
 		DashboardID: 1,
 		PanelID:     1,
@@ -84,7 +122,7 @@ func createTestEvalContext(cmd *NotificationTestCommand) *EvalContext {
 	}
 
 	ctx := NewEvalContext(context.Background(), testRule)
-	if cmd.Settings.Get("uploadImage").MustBool(true) {
+	if settings.Get("uploadImage").MustBool(true) {
 		ctx.ImagePublicURL = "https://grafana.com/assets/img/blog/mixed_styles.png"
 	}
 	ctx.IsTestRun = true
@@ -0,0 +1,219 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var getTime = time.Now
+
+func init() {
+	registry.RegisterService(&AuditService{})
+}
+
+// AuditService records the administrative and data-changing actions by hooking
+// into the bus command handlers. The entries are written to the configured
+// sinks in the background.
+type AuditService struct {
+	Cfg      *setting.Cfg       `inject:""`
+	SQLStore *sqlstore.SQLStore `inject:""`
+	Bus      bus.Bus            `inject:""`
+
+	log   log.Logger
+	sinks []Sink
+	queue chan *Entry
+}
+
+func (s *AuditService) Init() error {
+	s.log = log.New("audit")
+	if !s.Cfg.Audit.Enabled {
+		return nil
+	}
+
+	sinks, err := s.newSinks()
+	if err != nil {
+		return err
+	}
+	s.sinks = sinks
+	s.queue = make(chan *Entry, s.Cfg.Audit.QueueSize)
+
+	s.Bus.AddDispatchHook(s.dispatchHook)
+	s.Bus.AddEventListener(s.userLoggedIn)
+	s.Bus.AddEventListener(s.userLoginFailed)
+	s.Bus.AddEventListener(s.userTokenRevoked)
+
+	return nil
+}
+
+// IsDisabled returns true if the audit log is disabled
+func (s *AuditService) IsDisabled() bool {
+	return !s.Cfg.Audit.Enabled
+}
+
+// Run writes the recorded entries to the sinks until the context is done,
+// writing the entries still queued before returning.
+func (s *AuditService) Run(ctx context.Context) error {
+	defer s.closeSinks()
+
+	for {
+		select {
+		case entry := <-s.queue:
+			s.write(ctx, entry)
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-s.queue:
+					s.write(context.Background(), entry)
+				default:
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+// Record adds the entry to the audit log, completing the actor and the
+// request metadata from the request info of the context. The entry is
+// dropped if too many entries are waiting to be written.
+func (s *AuditService) Record(ctx context.Context, entry *Entry) {
+	if s.queue == nil {
+		return
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = getTime()
+	}
+	if info := RequestInfoFromContext(ctx); info != nil {
+		if entry.OrgId == 0 {
+			entry.OrgId = info.OrgId
+		}
+		if entry.ActorId == 0 && entry.ActorLogin == "" {
+			entry.ActorId = info.UserId
+			entry.ActorLogin = info.Login
+		}
+		if entry.ClientIp == "" {
+			entry.ClientIp = info.ClientIp
+		}
+		entry.UserAgent = info.UserAgent
+		entry.RequestMethod = info.Method
+		entry.RequestPath = info.Path
+	}
+
+	select {
+	case s.queue <- entry:
+	default:
+		s.log.Error("Audit log queue is full, dropping entry", "action", entry.Action, "resourceType", entry.ResourceType,
+			"resourceUid", entry.ResourceUid, "actorId", entry.ActorId)
+	}
+}
+
+func (s *AuditService) write(ctx context.Context, entry *Entry) {
+	for _, sink := range s.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			s.log.Error("Failed to write audit log entry", "sink", sink.Name(), "action", entry.Action, "error", err)
+		}
+	}
+}
+
+func (s *AuditService) closeSinks() {
+	for _, sink := range s.sinks {
+		if err := sink.Close(); err != nil {
+			s.log.Warn("Failed to close audit log sink", "sink", sink.Name(), "error", err)
+		}
+	}
+}
+
+// dispatchHook records the entries of the audited commands handled successfully
+func (s *AuditService) dispatchHook(ctx context.Context, msg bus.Msg) func(err error) {
+	a, ok := auditors[reflect.TypeOf(msg).Elem().Name()]
+	if !ok {
+		return nil
+	}
+
+	var before *resource
+	if a.before != nil {
+		var err error
+		if before, err = a.before(ctx, msg); err != nil {
+			s.log.Warn("Failed to get resource before action", "action", a.action, "error", err)
+		}
+	}
+
+	return func(err error) {
+		if err != nil {
+			return
+		}
+
+		after := a.after(msg, before)
+		entry := &Entry{
+			OrgId:        after.orgID,
+			ActorId:      after.actorID,
+			ActorLogin:   after.actorLogin,
+			Action:       a.action,
+			ResourceType: a.resourceType,
+			ResourceUid:  after.uid,
+			ClientIp:     after.clientIP,
+		}
+		if before != nil {
+			entry.Diff = diff(before.state, after.state)
+		} else {
+			entry.Diff = diff(nil, after.state)
+		}
+
+		s.Record(ctx, entry)
+	}
+}
+
+func (s *AuditService) userLoggedIn(event *events.UserLoggedIn) error {
+	s.Record(context.Background(), &Entry{
+		Timestamp:    event.Timestamp,
+		ActorId:      event.UserId,
+		ActorLogin:   event.Login,
+		Action:       "login.success",
+		ResourceType: "user",
+		ResourceUid:  event.Login,
+		ClientIp:     event.ClientIp,
+		UserAgent:    event.UserAgent,
+	})
+	return nil
+}
+
+func (s *AuditService) userLoginFailed(event *events.UserLoginFailed) error {
+	s.Record(context.Background(), &Entry{
+		Timestamp:    event.Timestamp,
+		ActorLogin:   event.Login,
+		Action:       "login.failed",
+		ResourceType: "user",
+		ResourceUid:  event.Login,
+		Diff: map[string]*Change{
+			"error": {After: event.Error},
+		},
+		ClientIp:  event.ClientIp,
+		UserAgent: event.UserAgent,
+	})
+	return nil
+}
+
+func (s *AuditService) userTokenRevoked(event *events.UserTokenRevoked) error {
+	s.Record(context.Background(), &Entry{
+		Timestamp:    event.Timestamp,
+		ActorId:      event.RevokedBy,
+		Action:       "session.revoke",
+		ResourceType: "user",
+		ResourceUid:  formatID(event.UserId),
+		Diff: map[string]*Change{
+			"tokenId":   {Before: event.TokenId},
+			"clientIp":  {Before: event.ClientIp},
+			"userAgent": {Before: event.UserAgent},
+			"reason":    {After: event.Reason},
+		},
+	})
+	return nil
+}
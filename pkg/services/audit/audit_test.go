@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditService_DispatchHook(t *testing.T) {
+	bus.ClearBusHandlers()
+	t.Cleanup(bus.ClearBusHandlers)
+
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	origGetTime := getTime
+	getTime = func() time.Time { return now }
+	t.Cleanup(func() { getTime = origGetTime })
+
+	cfg := setting.NewCfg()
+	cfg.Audit = setting.AuditSettings{Enabled: true, QueueSize: 10}
+	s := &AuditService{Cfg: cfg, Bus: bus.GetBus()}
+	require.NoError(t, s.Init())
+
+	bus.AddHandler("test", func(cmd *models.CreateTeamCommand) error {
+		cmd.Result = models.Team{Id: 3, OrgId: cmd.OrgId, Name: cmd.Name}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetTeamByIdQuery) error {
+		query.Result = &models.TeamDTO{Id: query.Id, OrgId: query.OrgId, Name: "old name", Email: "team@example.org"}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.UpdateTeamCommand) error {
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.DeleteTeamCommand) error {
+		return models.ErrTeamNotFound
+	})
+
+	ctx := WithRequestInfo(context.Background(), &RequestInfo{
+		OrgId:     1,
+		UserId:    2,
+		Login:     "admin",
+		ClientIp:  "192.168.1.1",
+		UserAgent: "curl",
+		Method:    "POST",
+		Path:      "/api/teams",
+	})
+
+	t.Run("records the entry of a command handled with the request info", func(t *testing.T) {
+		err := bus.DispatchCtx(ctx, &models.CreateTeamCommand{OrgId: 1, Name: "team"})
+		require.NoError(t, err)
+
+		entry := nextEntry(t, s)
+		assert.Equal(t, &Entry{
+			Timestamp:     now,
+			OrgId:         1,
+			ActorId:       2,
+			ActorLogin:    "admin",
+			Action:        "team.create",
+			ResourceType:  "team",
+			ResourceUid:   "3",
+			Diff:          map[string]*Change{"name": {After: "team"}, "email": {After: ""}},
+			ClientIp:      "192.168.1.1",
+			UserAgent:     "curl",
+			RequestMethod: "POST",
+			RequestPath:   "/api/teams",
+		}, entry)
+	})
+
+	t.Run("records the fields changed by the command", func(t *testing.T) {
+		err := bus.DispatchCtx(ctx, &models.UpdateTeamCommand{Id: 3, OrgId: 1, Name: "new name", Email: "team@example.org"})
+		require.NoError(t, err)
+
+		entry := nextEntry(t, s)
+		assert.Equal(t, "team.update", entry.Action)
+		assert.Equal(t, map[string]*Change{"name": {Before: "old name", After: "new name"}}, entry.Diff)
+	})
+
+	t.Run("records commands dispatched without context", func(t *testing.T) {
+		err := bus.Dispatch(&models.CreateTeamCommand{OrgId: 4, Name: "team"})
+		require.NoError(t, err)
+
+		entry := nextEntry(t, s)
+		assert.Equal(t, int64(4), entry.OrgId)
+		assert.Zero(t, entry.ActorId)
+		assert.Empty(t, entry.RequestPath)
+	})
+
+	t.Run("does not record failed commands nor queries", func(t *testing.T) {
+		err := bus.DispatchCtx(ctx, &models.DeleteTeamCommand{Id: 3, OrgId: 1})
+		require.True(t, errors.Is(err, models.ErrTeamNotFound))
+
+		err = bus.DispatchCtx(ctx, &models.GetTeamByIdQuery{Id: 3, OrgId: 1})
+		require.NoError(t, err)
+
+		assert.Empty(t, s.queue)
+	})
+}
+
+func TestAuditService_UserLoginFailed(t *testing.T) {
+	bus.ClearBusHandlers()
+	t.Cleanup(bus.ClearBusHandlers)
+
+	cfg := setting.NewCfg()
+	cfg.Audit = setting.AuditSettings{Enabled: true, QueueSize: 10}
+	s := &AuditService{Cfg: cfg, Bus: bus.GetBus()}
+	require.NoError(t, s.Init())
+
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := bus.Publish(&events.UserLoginFailed{
+		Timestamp: now,
+		Login:     "admin",
+		ClientIp:  "192.168.1.1",
+		UserAgent: "curl",
+		Error:     "invalid username or password",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Entry{
+		Timestamp:    now,
+		ActorLogin:   "admin",
+		Action:       "login.failed",
+		ResourceType: "user",
+		ResourceUid:  "admin",
+		Diff:         map[string]*Change{"error": {After: "invalid username or password"}},
+		ClientIp:     "192.168.1.1",
+		UserAgent:    "curl",
+	}, nextEntry(t, s))
+}
+
+func nextEntry(t *testing.T, s *AuditService) *Entry {
+	t.Helper()
+
+	select {
+	case entry := <-s.queue:
+		return entry
+	default:
+		require.FailNow(t, "no audit log entry recorded")
+		return nil
+	}
+}
@@ -0,0 +1,530 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// resource is the state of the resource of an audited command
+type resource struct {
+	orgID int64
+	uid   string
+	state interface{}
+
+	// actorID, actorLogin and clientIP are set for commands carrying
+	// the actor, in case they are not dispatched with a request context
+	actorID    int64
+	actorLogin string
+	clientIP   string
+}
+
+// auditor describes how the entry of a command is recorded
+type auditor struct {
+	action       string
+	resourceType string
+	// before returns the resource before the command is handled, if any
+	before func(ctx context.Context, msg bus.Msg) (*resource, error)
+	// after returns the resource once the command has been handled
+	after func(msg bus.Msg, before *resource) *resource
+}
+
+// auditors are the auditors of the commands recorded in the audit log, by command type name
+var auditors = map[string]auditor{}
+
+func register(msg bus.Msg, a auditor) {
+	auditors[reflect.TypeOf(msg).Elem().Name()] = a
+}
+
+func init() {
+	registerDashboardAuditors()
+	registerDataSourceAuditors()
+	registerAPIKeyAuditors()
+	registerUserAuditors()
+	registerOrgAuditors()
+	registerTeamAuditors()
+}
+
+func registerDashboardAuditors() {
+	register(&models.SaveDashboardCommand{}, auditor{
+		action:       "dashboard.save",
+		resourceType: "dashboard",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.SaveDashboardCommand)
+			uid := cmd.Dashboard.Get("uid").MustString()
+			id := cmd.Dashboard.Get("id").MustInt64()
+			if uid == "" && id == 0 {
+				return nil, nil
+			}
+			return getDashboard(ctx, cmd.OrgId, id, uid)
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.SaveDashboardCommand)
+			after := &resource{orgID: cmd.OrgId, actorID: cmd.UserId, state: cmd.Dashboard}
+			if cmd.Result != nil {
+				after.uid = cmd.Result.Uid
+			}
+			return after
+		},
+	})
+
+	register(&models.DeleteDashboardCommand{}, auditor{
+		action:       "dashboard.delete",
+		resourceType: "dashboard",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.DeleteDashboardCommand)
+			return getDashboard(ctx, cmd.OrgId, cmd.Id, "")
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.DeleteDashboardCommand)
+			return &resource{orgID: cmd.OrgId, uid: uidOf(before, cmd.Id)}
+		},
+	})
+
+	register(&models.UpdateDashboardAclCommand{}, auditor{
+		action:       "dashboard.permissions.update",
+		resourceType: "dashboard",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.UpdateDashboardAclCommand)
+			orgID := aclOrgID(ctx, cmd)
+
+			dashboard, err := getDashboard(ctx, orgID, cmd.DashboardID, "")
+			if err != nil || dashboard == nil {
+				return nil, err
+			}
+
+			query := models.GetDashboardAclInfoListQuery{DashboardID: cmd.DashboardID, OrgID: orgID}
+			if err := bus.DispatchCtx(ctx, &query); err != nil {
+				return nil, err
+			}
+
+			permissions := map[string]string{}
+			for _, item := range query.Result {
+				if !item.Inherited {
+					permissions[permissionKey(item.UserId, item.TeamId, item.Role)] = item.Permission.String()
+				}
+			}
+			return &resource{orgID: orgID, uid: dashboard.uid, state: permissions}, nil
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateDashboardAclCommand)
+			permissions := map[string]string{}
+			for _, item := range cmd.Items {
+				permissions[permissionKey(item.UserID, item.TeamID, item.Role)] = item.Permission.String()
+			}
+			after := &resource{uid: uidOf(before, cmd.DashboardID), state: permissions}
+			if before != nil {
+				after.orgID = before.orgID
+			}
+			return after
+		},
+	})
+}
+
+func registerDataSourceAuditors() {
+	register(&models.AddDataSourceCommand{}, auditor{
+		action:       "datasource.create",
+		resourceType: "datasource",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.AddDataSourceCommand)
+			after := &resource{orgID: cmd.OrgId, uid: cmd.Uid, state: &dataSourceState{
+				Name: cmd.Name, Type: cmd.Type, Access: cmd.Access, Url: cmd.Url, User: cmd.User, Database: cmd.Database,
+				BasicAuth: cmd.BasicAuth, BasicAuthUser: cmd.BasicAuthUser, WithCredentials: cmd.WithCredentials,
+				IsDefault: cmd.IsDefault, JsonData: cmd.JsonData, SecureJsonFields: sortedKeys(cmd.SecureJsonData),
+			}}
+			if cmd.Result != nil {
+				after.uid = cmd.Result.Uid
+			}
+			return after
+		},
+	})
+
+	register(&models.UpdateDataSourceCommand{}, auditor{
+		action:       "datasource.update",
+		resourceType: "datasource",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.UpdateDataSourceCommand)
+			return getDataSource(ctx, &models.GetDataSourceQuery{Id: cmd.Id, OrgId: cmd.OrgId})
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateDataSourceCommand)
+			after := &resource{orgID: cmd.OrgId, uid: cmd.Uid, state: &dataSourceState{
+				Name: cmd.Name, Type: cmd.Type, Access: cmd.Access, Url: cmd.Url, User: cmd.User, Database: cmd.Database,
+				BasicAuth: cmd.BasicAuth, BasicAuthUser: cmd.BasicAuthUser, WithCredentials: cmd.WithCredentials,
+				IsDefault: cmd.IsDefault, JsonData: cmd.JsonData, SecureJsonFields: sortedKeys(cmd.SecureJsonData),
+			}}
+			if after.uid == "" {
+				after.uid = uidOf(before, cmd.Id)
+			}
+			return after
+		},
+	})
+
+	register(&models.DeleteDataSourceCommand{}, auditor{
+		action:       "datasource.delete",
+		resourceType: "datasource",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.DeleteDataSourceCommand)
+			return getDataSource(ctx, &models.GetDataSourceQuery{Id: cmd.ID, Uid: cmd.UID, Name: cmd.Name, OrgId: cmd.OrgID})
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.DeleteDataSourceCommand)
+			uid := cmd.UID
+			if uid == "" {
+				uid = uidOf(before, cmd.ID)
+			}
+			return &resource{orgID: cmd.OrgID, uid: uid}
+		},
+	})
+}
+
+func registerAPIKeyAuditors() {
+	register(&models.AddApiKeyCommand{}, auditor{
+		action:       "api_key.create",
+		resourceType: "api_key",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.AddApiKeyCommand)
+			after := &resource{orgID: cmd.OrgId, state: map[string]interface{}{
+				"name":          cmd.Name,
+				"role":          cmd.Role,
+				"secondsToLive": cmd.SecondsToLive,
+			}}
+			if cmd.Result != nil {
+				after.uid = formatID(cmd.Result.Id)
+			}
+			return after
+		},
+	})
+
+	register(&models.DeleteApiKeyCommand{}, auditor{
+		action:       "api_key.delete",
+		resourceType: "api_key",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.DeleteApiKeyCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.Id)}
+		},
+	})
+}
+
+func registerUserAuditors() {
+	register(&models.CreateUserCommand{}, auditor{
+		action:       "user.create",
+		resourceType: "user",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.CreateUserCommand)
+			return &resource{uid: formatID(cmd.Result.Id), state: map[string]interface{}{
+				"login":      cmd.Login,
+				"email":      cmd.Email,
+				"name":       cmd.Name,
+				"isAdmin":    cmd.IsAdmin,
+				"isDisabled": cmd.IsDisabled,
+			}}
+		},
+	})
+
+	register(&models.UpdateUserCommand{}, auditor{
+		action:       "user.update",
+		resourceType: "user",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.UpdateUserCommand)
+			query := models.GetUserByIdQuery{Id: cmd.UserId}
+			if err := bus.DispatchCtx(ctx, &query); err != nil {
+				return nil, err
+			}
+			return &resource{uid: formatID(cmd.UserId), state: map[string]interface{}{
+				"login": query.Result.Login,
+				"email": query.Result.Email,
+				"name":  query.Result.Name,
+			}}, nil
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateUserCommand)
+			return &resource{uid: formatID(cmd.UserId), state: map[string]interface{}{
+				"login": cmd.Login,
+				"email": cmd.Email,
+				"name":  cmd.Name,
+			}}
+		},
+	})
+
+	register(&models.DeleteUserCommand{}, auditor{
+		action:       "user.delete",
+		resourceType: "user",
+		after: func(msg bus.Msg, before *resource) *resource {
+			return &resource{uid: formatID(msg.(*models.DeleteUserCommand).UserId)}
+		},
+	})
+
+	register(&models.UpdateUserPermissionsCommand{}, auditor{
+		action:       "user.permissions.update",
+		resourceType: "user",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateUserPermissionsCommand)
+			return &resource{uid: formatID(cmd.UserId), state: map[string]interface{}{"isGrafanaAdmin": cmd.IsGrafanaAdmin}}
+		},
+	})
+
+	register(&models.DisableUserCommand{}, auditor{
+		action:       "user.disable",
+		resourceType: "user",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.DisableUserCommand)
+			return &resource{uid: formatID(cmd.UserId), state: map[string]interface{}{"isDisabled": cmd.IsDisabled}}
+		},
+	})
+}
+
+func registerOrgAuditors() {
+	register(&models.CreateOrgCommand{}, auditor{
+		action:       "org.create",
+		resourceType: "org",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.CreateOrgCommand)
+			return &resource{orgID: cmd.Result.Id, uid: formatID(cmd.Result.Id), state: map[string]interface{}{"name": cmd.Name}}
+		},
+	})
+
+	register(&models.UpdateOrgCommand{}, auditor{
+		action:       "org.update",
+		resourceType: "org",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.UpdateOrgCommand)
+			query := models.GetOrgByIdQuery{Id: cmd.OrgId}
+			if err := bus.DispatchCtx(ctx, &query); err != nil {
+				return nil, err
+			}
+			return &resource{state: map[string]interface{}{"name": query.Result.Name}}, nil
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateOrgCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.OrgId), state: map[string]interface{}{"name": cmd.Name}}
+		},
+	})
+
+	register(&models.DeleteOrgCommand{}, auditor{
+		action:       "org.delete",
+		resourceType: "org",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.DeleteOrgCommand)
+			return &resource{orgID: cmd.Id, uid: formatID(cmd.Id)}
+		},
+	})
+
+	register(&models.AddOrgUserCommand{}, auditor{
+		action:       "org.user.add",
+		resourceType: "user",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.AddOrgUserCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.UserId), state: map[string]interface{}{"role": cmd.Role}}
+		},
+	})
+
+	register(&models.UpdateOrgUserCommand{}, auditor{
+		action:       "org.user.update",
+		resourceType: "user",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.UpdateOrgUserCommand)
+			query := models.GetSignedInUserQuery{UserId: cmd.UserId, OrgId: cmd.OrgId}
+			if err := bus.DispatchCtx(ctx, &query); err != nil {
+				return nil, err
+			}
+			return &resource{state: map[string]interface{}{"role": query.Result.OrgRole}}, nil
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateOrgUserCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.UserId), state: map[string]interface{}{"role": cmd.Role}}
+		},
+	})
+
+	register(&models.RemoveOrgUserCommand{}, auditor{
+		action:       "org.user.remove",
+		resourceType: "user",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.RemoveOrgUserCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.UserId)}
+		},
+	})
+}
+
+func registerTeamAuditors() {
+	register(&models.CreateTeamCommand{}, auditor{
+		action:       "team.create",
+		resourceType: "team",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.CreateTeamCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.Result.Id), state: map[string]interface{}{
+				"name":  cmd.Name,
+				"email": cmd.Email,
+			}}
+		},
+	})
+
+	register(&models.UpdateTeamCommand{}, auditor{
+		action:       "team.update",
+		resourceType: "team",
+		before: func(ctx context.Context, msg bus.Msg) (*resource, error) {
+			cmd := msg.(*models.UpdateTeamCommand)
+			query := models.GetTeamByIdQuery{Id: cmd.Id, OrgId: cmd.OrgId}
+			if err := bus.DispatchCtx(ctx, &query); err != nil {
+				return nil, err
+			}
+			return &resource{state: map[string]interface{}{
+				"name":  query.Result.Name,
+				"email": query.Result.Email,
+			}}, nil
+		},
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateTeamCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.Id), state: map[string]interface{}{
+				"name":  cmd.Name,
+				"email": cmd.Email,
+			}}
+		},
+	})
+
+	register(&models.DeleteTeamCommand{}, auditor{
+		action:       "team.delete",
+		resourceType: "team",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.DeleteTeamCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.Id)}
+		},
+	})
+
+	register(&models.AddTeamMemberCommand{}, auditor{
+		action:       "team.member.add",
+		resourceType: "team",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.AddTeamMemberCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.TeamId), state: map[string]interface{}{
+				"userId":     cmd.UserId,
+				"permission": cmd.Permission,
+			}}
+		},
+	})
+
+	register(&models.UpdateTeamMemberCommand{}, auditor{
+		action:       "team.member.update",
+		resourceType: "team",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.UpdateTeamMemberCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.TeamId), state: map[string]interface{}{
+				"userId":     cmd.UserId,
+				"permission": cmd.Permission,
+			}}
+		},
+	})
+
+	register(&models.RemoveTeamMemberCommand{}, auditor{
+		action:       "team.member.remove",
+		resourceType: "team",
+		after: func(msg bus.Msg, before *resource) *resource {
+			cmd := msg.(*models.RemoveTeamMemberCommand)
+			return &resource{orgID: cmd.OrgId, uid: formatID(cmd.TeamId), state: map[string]interface{}{"userId": cmd.UserId}}
+		},
+	})
+}
+
+// dataSourceState is the state of a data source recorded in the audit log,
+// which includes the names of the secure fields but not their values
+type dataSourceState struct {
+	Name             string           `json:"name"`
+	Type             string           `json:"type"`
+	Access           models.DsAccess  `json:"access"`
+	Url              string           `json:"url"`
+	User             string           `json:"user"`
+	Database         string           `json:"database"`
+	BasicAuth        bool             `json:"basicAuth"`
+	BasicAuthUser    string           `json:"basicAuthUser"`
+	WithCredentials  bool             `json:"withCredentials"`
+	IsDefault        bool             `json:"isDefault"`
+	JsonData         *simplejson.Json `json:"jsonData"`
+	SecureJsonFields []string         `json:"secureJsonFields"`
+}
+
+func getDashboard(ctx context.Context, orgID, id int64, uid string) (*resource, error) {
+	query := models.GetDashboardQuery{Id: id, Uid: uid, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &resource{orgID: orgID, uid: query.Result.Uid, state: query.Result.Data}, nil
+}
+
+func getDataSource(ctx context.Context, query *models.GetDataSourceQuery) (*resource, error) {
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ds := query.Result
+	return &resource{orgID: ds.OrgId, uid: ds.Uid, state: &dataSourceState{
+		Name: ds.Name, Type: ds.Type, Access: ds.Access, Url: ds.Url, User: ds.User, Database: ds.Database,
+		BasicAuth: ds.BasicAuth, BasicAuthUser: ds.BasicAuthUser, WithCredentials: ds.WithCredentials,
+		IsDefault: ds.IsDefault, JsonData: ds.JsonData, SecureJsonFields: sortedKeys(ds.SecureJsonData),
+	}}, nil
+}
+
+// aclOrgID returns the org of the dashboard of the ACL command, which
+// is only set on the items or the request if there are no items
+func aclOrgID(ctx context.Context, cmd *models.UpdateDashboardAclCommand) int64 {
+	for _, item := range cmd.Items {
+		if item.OrgID != 0 {
+			return item.OrgID
+		}
+	}
+	if info := RequestInfoFromContext(ctx); info != nil {
+		return info.OrgId
+	}
+	return 0
+}
+
+func permissionKey(userID, teamID int64, role *models.RoleType) string {
+	switch {
+	case userID != 0:
+		return fmt.Sprintf("user:%d", userID)
+	case teamID != 0:
+		return fmt.Sprintf("team:%d", teamID)
+	case role != nil:
+		return "role:" + string(*role)
+	}
+	return "unknown"
+}
+
+// uidOf returns the UID of the resource before the command,
+// or its ID if it wasn't found
+func uidOf(before *resource, id int64) string {
+	if before != nil && before.uid != "" {
+		return before.uid
+	}
+	return formatID(id)
+}
+
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func sortedKeys(values interface{}) []string {
+	keys := []string{}
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Map {
+		return keys
+	}
+	for _, key := range v.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
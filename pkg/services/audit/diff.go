@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// maxChangeSize is the maximum size of the JSON of a value in the diff,
+// above which the value is replaced by truncatedValue
+const maxChangeSize = 8 * 1024
+
+const (
+	redactedValue  = "[redacted]"
+	truncatedValue = "[truncated]"
+)
+
+// sensitiveFields are the parts of field names whose values are never recorded
+var sensitiveFields = []string{"password", "secret", "token", "key"}
+
+// diff returns the top-level fields of the JSON representations of the states
+// that differ, with their values before and after. Nil states are empty.
+func diff(before, after interface{}) map[string]*Change {
+	beforeFields := toFields(before)
+	afterFields := toFields(after)
+
+	changes := map[string]*Change{}
+	for name, value := range afterFields {
+		if previous, ok := beforeFields[name]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[name] = &Change{Before: previous, After: value}
+		}
+	}
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = &Change{Before: value}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	for name, change := range changes {
+		if isSensitive(name) {
+			change.Before, change.After = redact(change.Before), redact(change.After)
+			continue
+		}
+		change.Before, change.After = truncate(change.Before), truncate(change.After)
+	}
+
+	return changes
+}
+
+func toFields(state interface{}) map[string]interface{} {
+	if state == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, field := range sensitiveFields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func redact(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return redactedValue
+}
+
+func truncate(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil || len(data) > maxChangeSize {
+		return truncatedValue
+	}
+	return value
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("returns the changed fields", func(t *testing.T) {
+		before := map[string]interface{}{"name": "old", "url": "http://localhost", "removed": true}
+		after := map[string]interface{}{"name": "new", "url": "http://localhost", "added": 1}
+
+		assert.Equal(t, map[string]*Change{
+			"name":    {Before: "old", After: "new"},
+			"removed": {Before: true},
+			"added":   {After: float64(1)},
+		}, diff(before, after))
+	})
+
+	t.Run("returns nil without changes", func(t *testing.T) {
+		state := map[string]interface{}{"name": "same"}
+		assert.Nil(t, diff(state, state))
+		assert.Nil(t, diff(nil, nil))
+	})
+
+	t.Run("redacts the sensitive fields", func(t *testing.T) {
+		before := map[string]interface{}{"password": "old", "apiKey": nil}
+		after := map[string]interface{}{"password": "new", "apiKey": "key"}
+
+		assert.Equal(t, map[string]*Change{
+			"password": {Before: redactedValue, After: redactedValue},
+			"apiKey":   {Before: nil, After: redactedValue},
+		}, diff(before, after))
+	})
+
+	t.Run("truncates large values", func(t *testing.T) {
+		after := map[string]interface{}{"panels": strings.Repeat("x", maxChangeSize)}
+
+		assert.Equal(t, map[string]*Change{
+			"panels": {After: truncatedValue},
+		}, diff(nil, after))
+	})
+}
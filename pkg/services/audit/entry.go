@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Entry is a record of an action in the audit log
+type Entry struct {
+	Id            int64              `json:"id,omitempty"`
+	Timestamp     time.Time          `json:"timestamp"`
+	OrgId         int64              `json:"orgId"`
+	ActorId       int64              `json:"actorId"`
+	ActorLogin    string             `json:"actorLogin"`
+	Action        string             `json:"action"`
+	ResourceType  string             `json:"resourceType"`
+	ResourceUid   string             `json:"resourceUid"`
+	Diff          map[string]*Change `json:"diff,omitempty"`
+	ClientIp      string             `json:"clientIp"`
+	UserAgent     string             `json:"userAgent"`
+	RequestMethod string             `json:"requestMethod"`
+	RequestPath   string             `json:"requestPath"`
+}
+
+// Change is the value of a field of the resource before and after the action
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// RequestInfo is the actor and the metadata of the request an action is performed with
+type RequestInfo struct {
+	OrgId     int64
+	UserId    int64
+	Login     string
+	ClientIp  string
+	UserAgent string
+	Method    string
+	Path      string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a context carrying the request info, which is
+// recorded with the entries of the commands dispatched with the context.
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the request info of the context, if any
+func RequestInfoFromContext(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*RequestInfo)
+	return info
+}
+
+// Middleware stores the actor and the metadata of the request in the context
+// of the request, so that they're recorded with the entries of the commands
+// dispatched with the request context.
+func Middleware(c *models.ReqContext) {
+	info := &RequestInfo{
+		OrgId:     c.OrgId,
+		UserId:    c.UserId,
+		Login:     c.Login,
+		ClientIp:  c.RemoteAddr(),
+		UserAgent: c.Req.UserAgent(),
+		Method:    c.Req.Method,
+		Path:      c.Req.URL.Path,
+	}
+	if c.ApiKeyId != 0 {
+		info.Login = fmt.Sprintf("api_key:%d", c.ApiKeyId)
+	}
+
+	c.Req.Request = c.Req.WithContext(WithRequestInfo(c.Req.Context(), info))
+}
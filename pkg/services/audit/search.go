@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrSearchNotAvailable is returned when searching the audit log while the entries aren't stored in the database
+var ErrSearchNotAvailable = errors.New("audit log entries are not stored in the database")
+
+// SearchQuery filters the entries of the audit log. Filters left empty match all entries.
+type SearchQuery struct {
+	OrgId        int64
+	ActorId      int64
+	Action       string
+	ResourceType string
+	ResourceUid  string
+	From         time.Time
+	To           time.Time
+	Page         int
+	PerPage      int
+}
+
+// SearchResult is a page of entries of the audit log, most recent first
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Entries    []*Entry `json:"entries"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
+
+// Search returns the entries of the audit log matching the query
+func (s *AuditService) Search(ctx context.Context, query *SearchQuery) (*SearchResult, error) {
+	if !s.Cfg.Audit.Enabled || !s.Cfg.Audit.HasSink(setting.AuditSinkSQL) {
+		return nil, ErrSearchNotAvailable
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PerPage <= 0 {
+		query.PerPage = 100
+	}
+
+	result := &SearchResult{Entries: []*Entry{}, Page: query.Page, PerPage: query.PerPage}
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var where []string
+		var params []interface{}
+		addFilter := func(cond string, param interface{}) {
+			where = append(where, cond)
+			params = append(params, param)
+		}
+		if query.OrgId != 0 {
+			addFilter("org_id = ?", query.OrgId)
+		}
+		if query.ActorId != 0 {
+			addFilter("actor_id = ?", query.ActorId)
+		}
+		if query.Action != "" {
+			addFilter("action = ?", query.Action)
+		}
+		if query.ResourceType != "" {
+			addFilter("resource_type = ?", query.ResourceType)
+		}
+		if query.ResourceUid != "" {
+			addFilter("resource_uid = ?", query.ResourceUid)
+		}
+		if !query.From.IsZero() {
+			addFilter("created >= ?", query.From.UnixNano()/int64(time.Millisecond))
+		}
+		if !query.To.IsZero() {
+			addFilter("created <= ?", query.To.UnixNano()/int64(time.Millisecond))
+		}
+		whereSQL := "1 = 1"
+		if len(where) > 0 {
+			whereSQL = strings.Join(where, " AND ")
+		}
+
+		count, err := dbSession.Table("audit_log").Where(whereSQL, params...).Count(&auditLog{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		var rows []*auditLog
+		err = dbSession.Table("audit_log").
+			Where(whereSQL, params...).
+			Desc("created", "id").
+			Limit(query.PerPage, (query.Page-1)*query.PerPage).
+			Find(&rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			entry := &Entry{
+				Id:            row.Id,
+				Timestamp:     time.Unix(0, row.Created*int64(time.Millisecond)),
+				OrgId:         row.OrgId,
+				ActorId:       row.ActorId,
+				ActorLogin:    row.ActorLogin,
+				Action:        row.Action,
+				ResourceType:  row.ResourceType,
+				ResourceUid:   row.ResourceUid,
+				ClientIp:      row.ClientIp,
+				UserAgent:     row.UserAgent,
+				RequestMethod: row.RequestMethod,
+				RequestPath:   row.RequestPath,
+			}
+			if row.Diff != "" {
+				if err := json.Unmarshal([]byte(row.Diff), &entry.Diff); err != nil {
+					return err
+				}
+			}
+			result.Entries = append(result.Entries, entry)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Sink is a destination of the audit log entries
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, entry *Entry) error
+	Close() error
+}
+
+func (s *AuditService) newSinks() ([]Sink, error) {
+	sinks := make([]Sink, 0, len(s.Cfg.Audit.Sinks))
+	for _, name := range s.Cfg.Audit.Sinks {
+		switch name {
+		case setting.AuditSinkSQL:
+			sinks = append(sinks, &sqlSink{store: s.SQLStore})
+		case setting.AuditSinkFile:
+			sink, err := newFileSink(s.Cfg.Audit.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case setting.AuditSinkWebhook:
+			sinks = append(sinks, &webhookSink{
+				url:    s.Cfg.Audit.WebhookURL,
+				client: &http.Client{Timeout: s.Cfg.Audit.WebhookTimeout},
+			})
+		}
+	}
+	return sinks, nil
+}
+
+// auditLog is the row of an entry in the audit_log table
+type auditLog struct {
+	Id            int64
+	OrgId         int64
+	ActorId       int64
+	ActorLogin    string
+	Action        string
+	ResourceType  string
+	ResourceUid   string
+	Diff          string
+	ClientIp      string
+	UserAgent     string
+	RequestMethod string
+	RequestPath   string
+	Created       int64 // epoch milliseconds
+}
+
+// sqlSink stores the entries in the audit_log table, which can be searched
+type sqlSink struct {
+	store *sqlstore.SQLStore
+}
+
+func (s *sqlSink) Name() string {
+	return setting.AuditSinkSQL
+}
+
+func (s *sqlSink) Write(ctx context.Context, entry *Entry) error {
+	row := &auditLog{
+		OrgId:         entry.OrgId,
+		ActorId:       entry.ActorId,
+		ActorLogin:    truncateString(entry.ActorLogin, 190),
+		Action:        entry.Action,
+		ResourceType:  entry.ResourceType,
+		ResourceUid:   truncateString(entry.ResourceUid, 190),
+		ClientIp:      truncateString(entry.ClientIp, 255),
+		UserAgent:     truncateString(entry.UserAgent, 255),
+		RequestMethod: entry.RequestMethod,
+		RequestPath:   truncateString(entry.RequestPath, 255),
+		Created:       entry.Timestamp.UnixNano() / int64(time.Millisecond),
+	}
+
+	if len(entry.Diff) > 0 {
+		data, err := json.Marshal(entry.Diff)
+		if err != nil {
+			return err
+		}
+		row.Diff = string(data)
+	}
+
+	return s.store.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.Insert(row)
+		entry.Id = row.Id
+		return err
+	})
+}
+
+func (s *sqlSink) Close() error {
+	return nil
+}
+
+// fileSink appends the entries to a file as JSON lines
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes from grafana configuration file
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+
+	return &fileSink{file: file}, nil
+}
+
+func (s *fileSink) Name() string {
+	return setting.AuditSinkFile
+}
+
+func (s *fileSink) Write(ctx context.Context, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// webhookSink posts the entries as JSON to a URL
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) Name() string {
+	return setting.AuditSinkWebhook
+}
+
+func (s *webhookSink) Write(ctx context.Context, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grafana")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	return nil
+}
+
+func truncateString(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	sink, err := newFileSink(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, sink.Write(ctx, &Entry{Action: "team.create", ResourceUid: "1"}))
+	require.NoError(t, sink.Write(ctx, &Entry{Action: "team.delete", ResourceUid: "1"}))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var actions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		actions = append(actions, entry.Action)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"team.create", "team.delete"}, actions)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []*Entry
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var entry Entry
+		require.NoError(t, json.Unmarshal(body, &entry))
+		received = append(received, &entry)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	sink := &webhookSink{url: server.URL, client: &http.Client{Timeout: time.Second}}
+
+	err := sink.Write(context.Background(), &Entry{Action: "datasource.update", ResourceUid: "abc"})
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Equal(t, "abc", received[0].ResourceUid)
+
+	status = http.StatusInternalServerError
+	err = sink.Write(context.Background(), &Entry{Action: "datasource.delete"})
+	require.EqualError(t, err, "webhook responded with status 500")
+}
+
+func TestSQLSinkAndSearch(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.Audit = setting.AuditSettings{Enabled: true, Sinks: []string{setting.AuditSinkSQL}}
+	s := &AuditService{Cfg: cfg, SQLStore: store}
+
+	sink := &sqlSink{store: store}
+	start := time.Now().Truncate(time.Second)
+	entries := []*Entry{
+		{Timestamp: start, OrgId: 1, ActorId: 1, Action: "dashboard.save", ResourceType: "dashboard", ResourceUid: "a",
+			Diff: map[string]*Change{"title": {Before: "old", After: "new"}}},
+		{Timestamp: start.Add(time.Minute), OrgId: 1, ActorId: 2, Action: "dashboard.delete", ResourceType: "dashboard", ResourceUid: "a"},
+		{Timestamp: start.Add(2 * time.Minute), OrgId: 2, ActorId: 2, Action: "team.create", ResourceType: "team", ResourceUid: "1"},
+	}
+	for _, entry := range entries {
+		require.NoError(t, sink.Write(context.Background(), entry))
+		require.NotZero(t, entry.Id)
+	}
+
+	search := func(t *testing.T, query *SearchQuery) *SearchResult {
+		t.Helper()
+		result, err := s.Search(context.Background(), query)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("returns the most recent entries first", func(t *testing.T) {
+		result := search(t, &SearchQuery{})
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Entries, 3)
+		assert.Equal(t, "team.create", result.Entries[0].Action)
+		assert.Equal(t, "dashboard.save", result.Entries[2].Action)
+		assert.Equal(t, map[string]*Change{"title": {Before: "old", After: "new"}}, result.Entries[2].Diff)
+	})
+
+	t.Run("filters the entries", func(t *testing.T) {
+		result := search(t, &SearchQuery{OrgId: 1, ResourceType: "dashboard", ResourceUid: "a", ActorId: 2})
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "dashboard.delete", result.Entries[0].Action)
+
+		result = search(t, &SearchQuery{From: start.Add(time.Minute), To: start.Add(time.Minute)})
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "dashboard.delete", result.Entries[0].Action)
+	})
+
+	t.Run("paginates the entries", func(t *testing.T) {
+		result := search(t, &SearchQuery{Page: 2, PerPage: 2})
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "dashboard.save", result.Entries[0].Action)
+	})
+
+	t.Run("fails without the sql sink", func(t *testing.T) {
+		cfg.Audit.Sinks = []string{setting.AuditSinkFile}
+		t.Cleanup(func() { cfg.Audit.Sinks = []string{setting.AuditSinkSQL} })
+
+		_, err := s.Search(context.Background(), &SearchQuery{})
+		require.Equal(t, ErrSearchNotAvailable, err)
+	})
+}
@@ -96,6 +96,10 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *models.Use
 		return nil, err
 	}
 
+	if err := s.enforceSessionsLimit(ctx, user.Id); err != nil {
+		return nil, err
+	}
+
 	userAuthToken.UnhashedToken = token
 
 	s.log.Debug("user auth token created", "tokenId", userAuthToken.Id, "userId", userAuthToken.UserId, "clientIP", userAuthToken.ClientIp, "userAgent", userAuthToken.UserAgent, "authToken", userAuthToken.AuthToken)
@@ -309,11 +313,18 @@ func (s *UserAuthTokenService) RevokeToken(ctx context.Context, token *models.Us
 
 	s.log.Debug("user auth token revoked", "tokenId", model.Id, "userId", model.UserId, "clientIP", model.ClientIp, "userAgent", model.UserAgent)
 
+	s.publishTokensRevoked(ctx, []*userAuthToken{model})
+
 	return nil
 }
 
 func (s *UserAuthTokenService) RevokeAllUserTokens(ctx context.Context, userId int64) error {
-	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+	var tokens []*userAuthToken
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if err := dbSession.Where("user_id = ?", userId).Find(&tokens); err != nil {
+			return err
+		}
+
 		sql := `DELETE from user_auth_token WHERE user_id = ?`
 		res, err := dbSession.Exec(sql, userId)
 		if err != nil {
@@ -329,14 +340,26 @@ func (s *UserAuthTokenService) RevokeAllUserTokens(ctx context.Context, userId i
 
 		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	s.publishTokensRevoked(ctx, tokens)
+
+	return nil
 }
 
 func (s *UserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
-	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+	var tokens []*userAuthToken
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		if len(userIds) == 0 {
 			return nil
 		}
 
+		if err := dbSession.In("user_id", userIds).Find(&tokens); err != nil {
+			return err
+		}
+
 		user_id_params := strings.Repeat(",?", len(userIds)-1)
 		sql := "DELETE from user_auth_token WHERE user_id IN (?" + user_id_params + ")"
 
@@ -359,6 +382,13 @@ func (s *UserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, use
 
 		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	s.publishTokensRevoked(ctx, tokens)
+
+	return nil
 }
 
 func (s *UserAuthTokenService) GetUserToken(ctx context.Context, userId, userTokenId int64) (*models.UserToken, error) {
@@ -7,13 +7,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserAuthToken(t *testing.T) {
@@ -510,6 +513,178 @@ func TestUserAuthToken(t *testing.T) {
 	})
 }
 
+func TestUserAuthTokenSessions(t *testing.T) {
+	ctx := createTestContext(t)
+	tokenService := ctx.tokenService
+
+	now := time.Date(2018, 12, 13, 13, 45, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	t.Cleanup(func() { getTime = time.Now })
+
+	createUser := func(t *testing.T, login string, isAdmin bool, orgID int64) *models.User {
+		t.Helper()
+
+		cmd := &models.CreateUserCommand{Login: login, IsAdmin: isAdmin, SkipOrgSetup: true}
+		require.NoError(t, sqlstore.CreateUser(context.Background(), cmd))
+		_, err := ctx.sqlstore.NewSession().Insert(&models.OrgUser{
+			OrgId: orgID, UserId: cmd.Result.Id, Role: models.ROLE_VIEWER, Created: now, Updated: now,
+		})
+		require.NoError(t, err)
+		return &cmd.Result
+	}
+
+	createToken := func(t *testing.T, user *models.User, clientIP string, seenAt time.Time) *models.UserToken {
+		t.Helper()
+
+		token, err := tokenService.CreateToken(context.Background(), user, net.ParseIP(clientIP), "some user agent")
+		require.NoError(t, err)
+		_, err = ctx.updateRotatedAt(token.Id, seenAt.Unix())
+		require.NoError(t, err)
+		return token
+	}
+
+	tokenIDs := func(tokens []*models.UserToken) []int64 {
+		ids := []int64{}
+		for _, token := range tokens {
+			ids = append(ids, token.Id)
+		}
+		return ids
+	}
+
+	editor := createUser(t, "editor", false, 1)
+	admin := createUser(t, "admin", true, 1)
+	other := createUser(t, "other", false, 2)
+
+	editorOld := createToken(t, editor, "10.0.0.1", now.Add(-2*time.Hour))
+	editorNew := createToken(t, editor, "192.168.0.1", now.Add(-time.Minute))
+	adminToken := createToken(t, admin, "10.0.0.2", now.Add(-time.Hour))
+	otherToken := createToken(t, other, "10.0.0.3", now)
+
+	t.Run("Can search tokens of org users", func(t *testing.T) {
+		tokens, err := tokenService.SearchUserTokens(context.Background(), &models.SearchUserTokensQuery{OrgId: 1})
+		require.NoError(t, err)
+		require.Equal(t, []int64{editorNew.Id, adminToken.Id, editorOld.Id}, tokenIDs(tokens))
+
+		tokens, err = tokenService.SearchUserTokens(context.Background(), &models.SearchUserTokensQuery{OrgId: 1, ExcludeGrafanaAdmins: true})
+		require.NoError(t, err)
+		require.Equal(t, []int64{editorNew.Id, editorOld.Id}, tokenIDs(tokens))
+	})
+
+	t.Run("Can search tokens by last seen and IP range", func(t *testing.T) {
+		_, ipRange, err := net.ParseCIDR("10.0.0.0/8")
+		require.NoError(t, err)
+
+		tokens, err := tokenService.SearchUserTokens(context.Background(), &models.SearchUserTokensQuery{
+			SeenAfter:  now.Add(-3 * time.Hour),
+			SeenBefore: now.Add(-30 * time.Minute),
+			IPRange:    ipRange,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{adminToken.Id, editorOld.Id}, tokenIDs(tokens))
+	})
+
+	t.Run("Can limit the tokens found", func(t *testing.T) {
+		tokens, err := tokenService.SearchUserTokens(context.Background(), &models.SearchUserTokensQuery{OrgId: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, []int64{editorNew.Id, adminToken.Id}, tokenIDs(tokens))
+
+		_, ipRange, err := net.ParseCIDR("10.0.0.0/8")
+		require.NoError(t, err)
+		tokens, err = tokenService.SearchUserTokens(context.Background(), &models.SearchUserTokensQuery{IPRange: ipRange, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, []int64{otherToken.Id, adminToken.Id}, tokenIDs(tokens))
+	})
+
+	t.Run("Can label token", func(t *testing.T) {
+		require.NoError(t, tokenService.SetUserTokenLabel(context.Background(), editorNew, "laptop"))
+
+		token, err := tokenService.GetUserToken(context.Background(), editor.Id, editorNew.Id)
+		require.NoError(t, err)
+		require.Equal(t, "laptop", token.Label)
+
+		err = tokenService.SetUserTokenLabel(context.Background(), &models.UserToken{Id: editorNew.Id, UserId: other.Id}, "stolen")
+		require.Equal(t, models.ErrUserTokenNotFound, err)
+	})
+
+	t.Run("Can revoke tokens by filter and publish events", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		var revoked []*events.UserTokenRevoked
+		bus.AddEventListener(func(event *events.UserTokenRevoked) error {
+			revoked = append(revoked, event)
+			return nil
+		})
+
+		tokens, err := tokenService.RevokeUserTokens(context.Background(), &models.RevokeUserTokensCmd{
+			Query:     models.SearchUserTokensQuery{UserId: editor.Id, ExcludeTokens: []int64{editorNew.Id}},
+			RevokedBy: admin.Id,
+			Reason:    models.UserTokenRevokedByAdmin,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{editorOld.Id}, tokenIDs(tokens))
+
+		stored, err := ctx.getAuthTokenByID(editorOld.Id)
+		require.NoError(t, err)
+		require.Nil(t, stored)
+
+		require.Len(t, revoked, 1)
+		require.Equal(t, editorOld.Id, revoked[0].TokenId)
+		require.Equal(t, editor.Id, revoked[0].UserId)
+		require.Equal(t, "10.0.0.1", revoked[0].ClientIp)
+		require.Equal(t, admin.Id, revoked[0].RevokedBy)
+		require.Equal(t, models.UserTokenRevokedByAdmin, revoked[0].Reason)
+	})
+
+	t.Run("Publishes events for revoked tokens", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		var revoked []*events.UserTokenRevoked
+		bus.AddEventListener(func(event *events.UserTokenRevoked) error {
+			revoked = append(revoked, event)
+			return nil
+		})
+
+		token := createToken(t, editor, "10.0.0.6", now)
+		require.NoError(t, tokenService.RevokeToken(context.Background(), token))
+		require.Len(t, revoked, 1)
+		require.Equal(t, token.Id, revoked[0].TokenId)
+		require.Equal(t, editor.Id, revoked[0].RevokedBy)
+		require.Equal(t, models.UserTokenRevokedByUser, revoked[0].Reason)
+
+		revoked = nil
+		revokeCtx := models.WithUserTokenRevocation(context.Background(), admin.Id, models.UserTokenRevokedByUserDisabled)
+		require.NoError(t, tokenService.RevokeAllUserTokens(revokeCtx, editor.Id))
+		require.Len(t, revoked, 1)
+		require.Equal(t, editorNew.Id, revoked[0].TokenId)
+		require.Equal(t, editor.Id, revoked[0].UserId)
+		require.Equal(t, admin.Id, revoked[0].RevokedBy)
+		require.Equal(t, models.UserTokenRevokedByUserDisabled, revoked[0].Reason)
+
+		revoked = nil
+		token = createToken(t, editor, "10.0.0.7", now)
+		require.NoError(t, tokenService.BatchRevokeAllUserTokens(revokeCtx, []int64{editor.Id}))
+		require.Len(t, revoked, 1)
+		require.Equal(t, token.Id, revoked[0].TokenId)
+	})
+
+	t.Run("Revokes least recently used tokens exceeding max concurrent sessions", func(t *testing.T) {
+		tokenService.Cfg.MaxConcurrentSessions = 2
+		t.Cleanup(func() { tokenService.Cfg.MaxConcurrentSessions = 0 })
+
+		first := createToken(t, other, "10.0.0.4", now.Add(-time.Minute))
+		second, err := tokenService.CreateToken(context.Background(), other, net.ParseIP("10.0.0.5"), "some user agent")
+		require.NoError(t, err)
+
+		tokens, err := tokenService.GetUserTokens(context.Background(), other.Id)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int64{otherToken.Id, second.Id}, tokenIDs(tokens))
+
+		stored, err := ctx.getAuthTokenByID(first.Id)
+		require.NoError(t, err)
+		require.Nil(t, stored)
+	})
+}
+
 func createTestContext(t *testing.T) *testContext {
 	t.Helper()
 	maxInactiveDurationVal, _ := time.ParseDuration("168h")
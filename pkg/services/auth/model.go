@@ -18,6 +18,7 @@ type userAuthToken struct {
 	RotatedAt     int64
 	CreatedAt     int64
 	UpdatedAt     int64
+	Label         string
 	UnhashedToken string `xorm:"-"`
 }
 
@@ -43,6 +44,7 @@ func (uat *userAuthToken) fromUserToken(ut *models.UserToken) error {
 	uat.RotatedAt = ut.RotatedAt
 	uat.CreatedAt = ut.CreatedAt
 	uat.UpdatedAt = ut.UpdatedAt
+	uat.Label = ut.Label
 	uat.UnhashedToken = ut.UnhashedToken
 
 	return nil
@@ -64,6 +66,7 @@ func (uat *userAuthToken) toUserToken(ut *models.UserToken) error {
 	ut.RotatedAt = uat.RotatedAt
 	ut.CreatedAt = uat.CreatedAt
 	ut.UpdatedAt = uat.UpdatedAt
+	ut.Label = uat.Label
 	ut.UnhashedToken = uat.UnhashedToken
 
 	return nil
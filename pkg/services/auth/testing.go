@@ -17,6 +17,9 @@ type FakeUserAuthTokenService struct {
 	GetUserTokenProvider        func(ctx context.Context, userId, userTokenId int64) (*models.UserToken, error)
 	GetUserTokensProvider       func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	BatchRevokedTokenProvider   func(ctx context.Context, userIds []int64) error
+	SearchUserTokensProvider    func(ctx context.Context, query *models.SearchUserTokensQuery) ([]*models.UserToken, error)
+	RevokeUserTokensProvider    func(ctx context.Context, cmd *models.RevokeUserTokensCmd) ([]*models.UserToken, error)
+	SetUserTokenLabelProvider   func(ctx context.Context, token *models.UserToken, label string) error
 }
 
 func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
@@ -54,6 +57,15 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 		GetUserTokensProvider: func(ctx context.Context, userId int64) ([]*models.UserToken, error) {
 			return nil, nil
 		},
+		SearchUserTokensProvider: func(ctx context.Context, query *models.SearchUserTokensQuery) ([]*models.UserToken, error) {
+			return nil, nil
+		},
+		RevokeUserTokensProvider: func(ctx context.Context, cmd *models.RevokeUserTokensCmd) ([]*models.UserToken, error) {
+			return nil, nil
+		},
+		SetUserTokenLabelProvider: func(ctx context.Context, token *models.UserToken, label string) error {
+			return nil
+		},
 	}
 }
 
@@ -77,11 +89,11 @@ func (s *FakeUserAuthTokenService) TryRotateToken(ctx context.Context, token *mo
 }
 
 func (s *FakeUserAuthTokenService) RevokeToken(ctx context.Context, token *models.UserToken) error {
-	return s.RevokeTokenProvider(context.Background(), token)
+	return s.RevokeTokenProvider(ctx, token)
 }
 
 func (s *FakeUserAuthTokenService) RevokeAllUserTokens(ctx context.Context, userId int64) error {
-	return s.RevokeAllUserTokensProvider(context.Background(), userId)
+	return s.RevokeAllUserTokensProvider(ctx, userId)
 }
 
 func (s *FakeUserAuthTokenService) ActiveTokenCount(ctx context.Context) (int64, error) {
@@ -99,3 +111,15 @@ func (s *FakeUserAuthTokenService) GetUserTokens(ctx context.Context, userId int
 func (s *FakeUserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.BatchRevokedTokenProvider(ctx, userIds)
 }
+
+func (s *FakeUserAuthTokenService) SearchUserTokens(ctx context.Context, query *models.SearchUserTokensQuery) ([]*models.UserToken, error) {
+	return s.SearchUserTokensProvider(ctx, query)
+}
+
+func (s *FakeUserAuthTokenService) RevokeUserTokens(ctx context.Context, cmd *models.RevokeUserTokensCmd) ([]*models.UserToken, error) {
+	return s.RevokeUserTokensProvider(ctx, cmd)
+}
+
+func (s *FakeUserAuthTokenService) SetUserTokenLabel(ctx context.Context, token *models.UserToken, label string) error {
+	return s.SetUserTokenLabelProvider(ctx, token, label)
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// lastSeenAtSQL is the time a token was last used: seen_at is reset when the token is rotated
+const lastSeenAtSQL = "CASE WHEN user_auth_token.seen_at > user_auth_token.rotated_at THEN user_auth_token.seen_at ELSE user_auth_token.rotated_at END"
+
+// SearchUserTokens returns the active tokens matching the query, most recently used first.
+func (s *UserAuthTokenService) SearchUserTokens(ctx context.Context, query *models.SearchUserTokensQuery) ([]*models.UserToken, error) {
+	result := []*models.UserToken{}
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sess := dbSession.Table("user_auth_token").
+			Select("user_auth_token.*").
+			Where("user_auth_token.created_at > ? AND user_auth_token.rotated_at > ?",
+				s.createdAfterParam(),
+				s.rotatedAfterParam())
+
+		if query.OrgId != 0 {
+			sess.Join("INNER", "org_user", "org_user.user_id = user_auth_token.user_id").
+				Where("org_user.org_id = ?", query.OrgId)
+		}
+		if query.ExcludeGrafanaAdmins {
+			userTable := s.SQLStore.Dialect.Quote("user")
+			sess.Join("INNER", userTable, userTable+".id = user_auth_token.user_id").
+				Where(userTable+".is_admin = ?", false)
+		}
+		if query.UserId != 0 {
+			sess.Where("user_auth_token.user_id = ?", query.UserId)
+		}
+		if !query.SeenAfter.IsZero() {
+			sess.Where(lastSeenAtSQL+" >= ?", query.SeenAfter.Unix())
+		}
+		if !query.SeenBefore.IsZero() {
+			sess.Where(lastSeenAtSQL+" < ?", query.SeenBefore.Unix())
+		}
+		if len(query.ExcludeTokens) > 0 {
+			sess.NotIn("user_auth_token.id", query.ExcludeTokens)
+		}
+
+		// the IP range is matched while reading the rows since client_ip is
+		// stored as text, so the rows are only limited without IP range
+		if query.Limit > 0 && query.IPRange == nil {
+			sess.Limit(query.Limit)
+		}
+
+		rows, err := sess.OrderBy(lastSeenAtSQL + " DESC, user_auth_token.id DESC").Rows(&userAuthToken{})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				s.log.Warn("Failed to close rows", "error", err)
+			}
+		}()
+
+		for rows.Next() {
+			if query.Limit > 0 && len(result) == query.Limit {
+				break
+			}
+
+			var token userAuthToken
+			if err := rows.Scan(&token); err != nil {
+				return err
+			}
+			if query.IPRange != nil && !query.IPRange.Contains(parseClientIP(token.ClientIp)) {
+				continue
+			}
+
+			var userToken models.UserToken
+			if err := token.toUserToken(&userToken); err != nil {
+				return err
+			}
+			result = append(result, &userToken)
+		}
+
+		// xorm reports the end of the rows as sql.ErrNoRows
+		if err := rows.Err(); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// RevokeUserTokens revokes the tokens matching the query and publishes
+// a UserTokenRevoked event for each of them.
+func (s *UserAuthTokenService) RevokeUserTokens(ctx context.Context, cmd *models.RevokeUserTokensCmd) ([]*models.UserToken, error) {
+	tokens, err := s.SearchUserTokens(ctx, &cmd.Query)
+	if err != nil || len(tokens) == 0 {
+		return tokens, err
+	}
+
+	ids := make([]int64, 0, len(tokens))
+	for _, token := range tokens {
+		ids = append(ids, token.Id)
+	}
+
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.In("id", ids).Delete(&userAuthToken{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Debug("user auth tokens revoked", "count", len(tokens), "revokedBy", cmd.RevokedBy, "reason", cmd.Reason)
+
+	for _, token := range tokens {
+		s.publishTokenRevoked(token, cmd.RevokedBy, cmd.Reason)
+	}
+
+	return tokens, nil
+}
+
+// SetUserTokenLabel sets the label the user gave the token, such as the name of the device
+func (s *UserAuthTokenService) SetUserTokenLabel(ctx context.Context, token *models.UserToken, label string) error {
+	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		affected, err := dbSession.Table("user_auth_token").
+			Where("id = ? AND user_id = ?", token.Id, token.UserId).
+			Update(map[string]interface{}{"label": label})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrUserTokenNotFound
+		}
+
+		token.Label = label
+		return nil
+	})
+}
+
+// publishTokensRevoked publishes a UserTokenRevoked event for each of the
+// tokens, revoked by the user and for the reason carried by the context.
+// Tokens revoked without them are considered revoked by their user.
+func (s *UserAuthTokenService) publishTokensRevoked(ctx context.Context, tokens []*userAuthToken) {
+	revocation, ok := models.UserTokenRevocationFromContext(ctx)
+	for _, model := range tokens {
+		var token models.UserToken
+		if err := model.toUserToken(&token); err != nil {
+			s.log.Error("Failed to publish user token revoked event", "tokenId", model.Id, "error", err)
+			continue
+		}
+
+		if ok {
+			s.publishTokenRevoked(&token, revocation.RevokedBy, revocation.Reason)
+		} else {
+			s.publishTokenRevoked(&token, token.UserId, models.UserTokenRevokedByUser)
+		}
+	}
+}
+
+// publishTokenRevoked publishes a UserTokenRevoked event, which is recorded by the audit log
+func (s *UserAuthTokenService) publishTokenRevoked(token *models.UserToken, revokedBy int64, reason string) {
+	err := bus.Publish(&events.UserTokenRevoked{
+		Timestamp: time.Now(),
+		TokenId:   token.Id,
+		UserId:    token.UserId,
+		ClientIp:  token.ClientIp,
+		UserAgent: token.UserAgent,
+		RevokedBy: revokedBy,
+		Reason:    reason,
+	})
+	if err != nil {
+		s.log.Error("Failed to publish user token revoked event", "tokenId", token.Id, "error", err)
+	}
+}
+
+// enforceSessionsLimit revokes the least recently used tokens of
+// the user exceeding the maximum number of concurrent sessions.
+func (s *UserAuthTokenService) enforceSessionsLimit(ctx context.Context, userID int64) error {
+	if s.Cfg.MaxConcurrentSessions <= 0 {
+		return nil
+	}
+
+	tokens, err := s.SearchUserTokens(ctx, &models.SearchUserTokensQuery{UserId: userID})
+	if err != nil || len(tokens) <= s.Cfg.MaxConcurrentSessions {
+		return err
+	}
+
+	exclude := make([]int64, 0, s.Cfg.MaxConcurrentSessions)
+	for _, token := range tokens[:s.Cfg.MaxConcurrentSessions] {
+		exclude = append(exclude, token.Id)
+	}
+
+	_, err = s.RevokeUserTokens(ctx, &models.RevokeUserTokensCmd{
+		Query:     models.SearchUserTokensQuery{UserId: userID, ExcludeTokens: exclude},
+		RevokedBy: userID,
+		Reason:    models.UserTokenRevokedBySessionsLimit,
+	})
+	return err
+}
+
+func parseClientIP(clientIP string) net.IP {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+	return net.ParseIP(clientIP)
+}
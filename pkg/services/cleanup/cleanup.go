@@ -5,12 +5,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/shorturls"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
@@ -18,6 +21,18 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+const (
+	// cleanupInterval is the interval at which the cleanup jobs run
+	cleanupInterval = time.Minute * 10
+	// jobLeaseTTL is the TTL of the leases of the cleanup jobs,
+	// which are renewed while the jobs are running
+	jobLeaseTTL = time.Minute
+	// jobLockInterval is the minimum interval between two runs of a cleanup
+	// job across instances. It's shorter than the cleanup interval, so that
+	// the lock of the previous run has always expired at the next tick.
+	jobLockInterval = cleanupInterval - time.Minute
+)
+
 type CleanUpService struct {
 	log               log.Logger
 	Cfg               *setting.Cfg                  `inject:""`
@@ -37,7 +52,7 @@ func (srv *CleanUpService) Init() error {
 func (srv *CleanUpService) Run(ctx context.Context) error {
 	srv.cleanUpTmpFiles()
 
-	ticker := time.NewTicker(time.Minute * 10)
+	ticker := time.NewTicker(cleanupInterval)
 	for {
 		select {
 		case <-ticker.C:
@@ -45,24 +60,47 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			defer cancelFn()
 
 			srv.cleanUpTmpFiles()
-			srv.deleteExpiredSnapshots()
-			srv.deleteExpiredDashboardVersions()
-			srv.cleanUpOldAnnotations(ctxWithTimeout)
-			srv.expireOldUserInvites()
-			srv.deleteStaleShortURLs()
-			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
-				time.Minute*10, func() {
-					srv.deleteOldLoginAttempts()
-				})
-			if err != nil {
-				srv.log.Error("failed to lock and execute cleanup of old login attempts", "error", err)
-			}
+			srv.runJob(ctxWithTimeout, "delete expired snapshots", srv.deleteExpiredSnapshots)
+			srv.runJob(ctxWithTimeout, "delete expired dashboard versions", srv.deleteExpiredDashboardVersions)
+			srv.runJob(ctxWithTimeout, "clean up old annotations", srv.cleanUpOldAnnotations)
+			srv.runJob(ctxWithTimeout, "expire old user invites", srv.expireOldUserInvites)
+			srv.runJob(ctxWithTimeout, "delete stale short urls", srv.deleteStaleShortURLs)
+			srv.runJob(ctxWithTimeout, "delete old login attempts", srv.deleteOldLoginAttempts)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
+// runJob runs the cleanup job on one Grafana instance at a time, at most
+// once per cleanup interval. The lease of the job is held while it runs,
+// so that a slow job isn't started again by another instance.
+func (srv *CleanUpService) runJob(ctx context.Context, name string, job func(ctx context.Context)) {
+	lockName := "cleanup " + name
+	err := srv.ServerLockService.ExecuteWithLease(ctx, lockName, jobLeaseTTL, func(ctx context.Context, _ int64) {
+		err := srv.ServerLockService.LockAndExecute(ctx, lockName, jobLockInterval, func() {
+			job(ctx)
+		})
+		if err != nil {
+			srv.log.Error("Failed to lock and execute cleanup job", "job", name, "error", err)
+		}
+	})
+	if err != nil {
+		srv.log.Error("Failed to acquire lease of cleanup job", "job", name, "error", err)
+	}
+}
+
+// recordRows records the number of rows of the object type deleted by the
+// cleanup job, or that would be deleted in dry run mode.
+func (srv *CleanUpService) recordRows(objectType string, rows int64, dryRun bool) {
+	metrics.MCleanupLastRunRows.WithLabelValues(objectType, strconv.FormatBool(dryRun)).Set(float64(rows))
+	if dryRun {
+		srv.log.Info("Cleanup dry run", "type", objectType, "rows to delete", rows)
+		return
+	}
+	metrics.MCleanupDeletedRows.WithLabelValues(objectType).Add(float64(rows))
+}
+
 func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) {
 	cleaner := annotations.GetAnnotationCleaner()
 	affected, affectedTags, err := cleaner.CleanAnnotations(ctx, srv.Cfg)
@@ -70,6 +108,10 @@ func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) {
 		srv.log.Error("failed to clean up old annotations", "error", err)
 	} else {
 		srv.log.Debug("Deleted excess annotations", "annotations affected", affected, "annotation tags affected", affectedTags)
+		srv.recordRows("annotations", affected, srv.Cfg.Retention.DryRun)
+		if !srv.Cfg.Retention.DryRun {
+			srv.recordRows("annotation_tags", affectedTags, false)
+		}
 	}
 }
 
@@ -84,6 +126,7 @@ func (srv *CleanUpService) cleanUpTmpFiles() {
 		return
 	}
 
+	dryRun := srv.Cfg.Retention.DryRun
 	var toDelete []os.FileInfo
 	var now = time.Now()
 
@@ -93,6 +136,11 @@ func (srv *CleanUpService) cleanUpTmpFiles() {
 		}
 	}
 
+	if dryRun {
+		srv.log.Info("Cleanup dry run", "type", "temp_files", "files to delete", len(toDelete))
+		return
+	}
+
 	for _, file := range toDelete {
 		fullPath := path.Join(srv.Cfg.ImagesDir, file.Name())
 		err := os.Remove(fullPath)
@@ -112,59 +160,113 @@ func (srv *CleanUpService) shouldCleanupTempFile(filemtime time.Time, now time.T
 	return filemtime.Add(srv.Cfg.TempDataLifetime).Before(now)
 }
 
-func (srv *CleanUpService) deleteExpiredSnapshots() {
-	cmd := models.DeleteExpiredSnapshotsCommand{}
+func (srv *CleanUpService) deleteExpiredSnapshots(ctx context.Context) {
+	cmd := models.DeleteExpiredSnapshotsCommand{DryRun: srv.Cfg.Retention.DryRun}
 	if err := bus.Dispatch(&cmd); err != nil {
 		srv.log.Error("Failed to delete expired snapshots", "error", err.Error())
 	} else {
 		srv.log.Debug("Deleted expired snapshots", "rows affected", cmd.DeletedRows)
+		srv.recordRows("snapshots", cmd.DeletedRows, cmd.DryRun)
 	}
 }
 
-func (srv *CleanUpService) deleteExpiredDashboardVersions() {
-	cmd := models.DeleteExpiredVersionsCommand{}
-	if err := bus.Dispatch(&cmd); err != nil {
-		srv.log.Error("Failed to delete expired dashboard versions", "error", err.Error())
-	} else {
-		srv.log.Debug("Deleted old/expired dashboard versions", "rows affected", cmd.DeletedRows)
+// deleteExpiredDashboardVersions applies the retention policy of each
+// organization with its own policy to its dashboard versions, and the
+// default policy to the others.
+func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) {
+	retention := srv.Cfg.Retention
+	orgPolicies := retention.Orgs[setting.RetentionDashboardVersions]
+	orgIDs := make([]int64, 0, len(orgPolicies))
+	for orgID := range orgPolicies {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	var cmds []*models.DeleteExpiredVersionsCommand
+	if retention.DashboardVersions.IsEnabled() {
+		cmds = append(cmds, &models.DeleteExpiredVersionsCommand{
+			MaxCount:      retention.DashboardVersions.MaxCount,
+			MaxAge:        retention.DashboardVersions.MaxAge,
+			ExcludeOrgIds: orgIDs,
+			DryRun:        retention.DryRun,
+		})
+	}
+	for _, orgID := range orgIDs {
+		if policy := orgPolicies[orgID]; policy.IsEnabled() {
+			cmds = append(cmds, &models.DeleteExpiredVersionsCommand{
+				MaxCount: policy.MaxCount,
+				MaxAge:   policy.MaxAge,
+				OrgId:    orgID,
+				DryRun:   retention.DryRun,
+			})
+		}
 	}
+
+	var deletedRows int64
+	for _, cmd := range cmds {
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			srv.log.Error("Failed to delete expired dashboard versions", "orgId", cmd.OrgId, "error", err.Error())
+			return
+		}
+		deletedRows += cmd.DeletedRows
+	}
+
+	srv.log.Debug("Deleted old/expired dashboard versions", "rows affected", deletedRows)
+	srv.recordRows("dashboard_versions", deletedRows, retention.DryRun)
 }
 
-func (srv *CleanUpService) deleteOldLoginAttempts() {
+func (srv *CleanUpService) deleteOldLoginAttempts(ctx context.Context) {
 	if srv.Cfg.DisableBruteForceLoginProtection {
 		return
 	}
 
 	cmd := models.DeleteOldLoginAttemptsCommand{
 		OlderThan: time.Now().Add(time.Minute * -10),
+		DryRun:    srv.Cfg.Retention.DryRun,
 	}
 	if err := bus.Dispatch(&cmd); err != nil {
 		srv.log.Error("Problem deleting expired login attempts", "error", err.Error())
 	} else {
 		srv.log.Debug("Deleted expired login attempts", "rows affected", cmd.DeletedRows)
+		srv.recordRows("login_attempts", cmd.DeletedRows, cmd.DryRun)
+	}
+
+	lockoutsCmd := models.DeleteExpiredLoginLockoutsCommand{
+		Now:    time.Now(),
+		DryRun: srv.Cfg.Retention.DryRun,
+	}
+	if err := bus.Dispatch(&lockoutsCmd); err != nil {
+		srv.log.Error("Problem deleting expired login lockouts", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired login lockouts", "rows affected", lockoutsCmd.DeletedRows)
+		srv.recordRows("login_lockouts", lockoutsCmd.DeletedRows, lockoutsCmd.DryRun)
 	}
 }
 
-func (srv *CleanUpService) expireOldUserInvites() {
+func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
 
 	cmd := models.ExpireTempUsersCommand{
 		OlderThan: time.Now().Add(-maxInviteLifetime),
+		DryRun:    srv.Cfg.Retention.DryRun,
 	}
 	if err := bus.Dispatch(&cmd); err != nil {
 		srv.log.Error("Problem expiring user invites", "error", err.Error())
 	} else {
 		srv.log.Debug("Expired user invites", "rows affected", cmd.NumExpired)
+		srv.recordRows("user_invites", cmd.NumExpired, cmd.DryRun)
 	}
 }
 
-func (srv *CleanUpService) deleteStaleShortURLs() {
+func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 	cmd := models.DeleteShortUrlCommand{
 		OlderThan: time.Now().Add(-time.Hour * 24 * 7),
+		DryRun:    srv.Cfg.Retention.DryRun,
 	}
-	if err := srv.ShortURLService.DeleteStaleShortURLs(context.Background(), &cmd); err != nil {
+	if err := srv.ShortURLService.DeleteStaleShortURLs(ctx, &cmd); err != nil {
 		srv.log.Error("Problem deleting stale short urls", "error", err.Error())
 	} else {
 		srv.log.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
+		srv.recordRows("short_urls", cmd.NumDeleted, cmd.DryRun)
 	}
 }
@@ -16,8 +16,9 @@ func (lps *LibraryPanelService) registerAPIEndpoints() {
 		return
 	}
 
+	quota := middleware.Quota(lps.QuotaService)
 	lps.RouteRegister.Group("/api/library-panels", func(libraryPanels routing.RouteRegister) {
-		libraryPanels.Post("/", middleware.ReqSignedIn, binding.Bind(createLibraryPanelCommand{}), routing.Wrap(lps.createHandler))
+		libraryPanels.Post("/", middleware.ReqSignedIn, quota("library_panel"), binding.Bind(createLibraryPanelCommand{}), routing.Wrap(lps.createHandler))
 		libraryPanels.Post("/:uid/dashboards/:dashboardId", middleware.ReqSignedIn, routing.Wrap(lps.connectHandler))
 		libraryPanels.Delete("/:uid", middleware.ReqSignedIn, routing.Wrap(lps.deleteHandler))
 		libraryPanels.Delete("/:uid/dashboards/:dashboardId", middleware.ReqSignedIn, routing.Wrap(lps.disconnectHandler))
@@ -13,13 +13,14 @@ import (
 )
 
 func (ng *AlertNG) registerAPIEndpoints() {
+	quota := middleware.Quota(ng.QuotaService)
 	ng.RouteRegister.Group("/api/alert-definitions", func(alertDefinitions routing.RouteRegister) {
 		alertDefinitions.Get("", middleware.ReqSignedIn, routing.Wrap(ng.listAlertDefinitions))
 		alertDefinitions.Get("/eval/:alertDefinitionUID", ng.validateOrgAlertDefinition, routing.Wrap(ng.alertDefinitionEvalEndpoint))
 		alertDefinitions.Post("/eval", middleware.ReqSignedIn, binding.Bind(evalAlertConditionCommand{}), routing.Wrap(ng.conditionEvalEndpoint))
 		alertDefinitions.Get("/:alertDefinitionUID", ng.validateOrgAlertDefinition, routing.Wrap(ng.getAlertDefinitionEndpoint))
 		alertDefinitions.Delete("/:alertDefinitionUID", ng.validateOrgAlertDefinition, routing.Wrap(ng.deleteAlertDefinitionEndpoint))
-		alertDefinitions.Post("/", middleware.ReqSignedIn, binding.Bind(saveAlertDefinitionCommand{}), routing.Wrap(ng.createAlertDefinitionEndpoint))
+		alertDefinitions.Post("/", middleware.ReqSignedIn, quota("alert_definition"), binding.Bind(saveAlertDefinitionCommand{}), routing.Wrap(ng.createAlertDefinitionEndpoint))
 		alertDefinitions.Put("/:alertDefinitionUID", ng.validateOrgAlertDefinition, binding.Bind(updateAlertDefinitionCommand{}), routing.Wrap(ng.updateAlertDefinitionEndpoint))
 	})
 
@@ -30,6 +31,18 @@ func (ng *AlertNG) registerAPIEndpoints() {
 
 	ng.RouteRegister.Group("/api/alert-instances", func(alertInstances routing.RouteRegister) {
 		alertInstances.Get("", middleware.ReqSignedIn, routing.Wrap(ng.listAlertInstancesEndpoint))
+		alertInstances.Get("/history", middleware.ReqSignedIn, routing.Wrap(ng.listAlertInstanceStateChangesEndpoint))
+	})
+
+	ng.RouteRegister.Group("/api/alert-notification-policy", func(notificationPolicy routing.RouteRegister) {
+		notificationPolicy.Get("", middleware.ReqSignedIn, routing.Wrap(ng.getNotificationPolicyEndpoint))
+		notificationPolicy.Put("", middleware.ReqOrgAdmin, binding.Bind(Route{}), routing.Wrap(ng.updateNotificationPolicyEndpoint))
+	})
+
+	ng.RouteRegister.Group("/api/alert-silences", func(silences routing.RouteRegister) {
+		silences.Get("", middleware.ReqSignedIn, routing.Wrap(ng.listSilencesEndpoint))
+		silences.Post("", middleware.ReqEditorRole, binding.Bind(createSilenceCommand{}), routing.Wrap(ng.createSilenceEndpoint))
+		silences.Delete("/:silenceUID", middleware.ReqEditorRole, routing.Wrap(ng.expireSilenceEndpoint))
 	})
 }
 
@@ -41,6 +41,11 @@ func (ng *AlertNG) deleteAlertDefinitionByUID(cmd *deleteAlertDefinitionByUIDCom
 		if err != nil {
 			return err
 		}
+
+		_, err = sess.Exec("DELETE FROM alert_instance_history WHERE def_org_id = ? AND def_uid = ?", cmd.OrgID, cmd.UID)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -65,6 +70,18 @@ func (ng *AlertNG) saveAlertDefinition(cmd *saveAlertDefinitionCommand) error {
 		if cmd.IntervalSeconds != nil {
 			intervalSeconds = *cmd.IntervalSeconds
 		}
+		var forSeconds int64
+		if cmd.ForSeconds != nil {
+			forSeconds = *cmd.ForSeconds
+		}
+		noDataState := cmd.NoDataState
+		if noDataState == "" {
+			noDataState = NoData
+		}
+		execErrState := cmd.ExecErrState
+		if execErrState == "" {
+			execErrState = ExecErrError
+		}
 
 		var initialVersion int64 = 1
 
@@ -81,6 +98,9 @@ func (ng *AlertNG) saveAlertDefinition(cmd *saveAlertDefinitionCommand) error {
 			IntervalSeconds: intervalSeconds,
 			Version:         initialVersion,
 			UID:             uid,
+			ForSeconds:      forSeconds,
+			NoDataState:     noDataState,
+			ExecErrState:    execErrState,
 		}
 
 		if err := ng.validateAlertDefinition(alertDefinition, false); err != nil {
@@ -107,6 +127,9 @@ func (ng *AlertNG) saveAlertDefinition(cmd *saveAlertDefinitionCommand) error {
 			Title:              alertDefinition.Title,
 			Data:               alertDefinition.Data,
 			IntervalSeconds:    alertDefinition.IntervalSeconds,
+			ForSeconds:         alertDefinition.ForSeconds,
+			NoDataState:        alertDefinition.NoDataState,
+			ExecErrState:       alertDefinition.ExecErrState,
 		}
 		if _, err := sess.Insert(alertDefVersion); err != nil {
 			return err
@@ -145,6 +168,18 @@ func (ng *AlertNG) updateAlertDefinition(cmd *updateAlertDefinitionCommand) erro
 		if intervalSeconds == nil {
 			intervalSeconds = &existingAlertDefinition.IntervalSeconds
 		}
+		forSeconds := cmd.ForSeconds
+		if forSeconds == nil {
+			forSeconds = &existingAlertDefinition.ForSeconds
+		}
+		noDataState := cmd.NoDataState
+		if noDataState == "" {
+			noDataState = existingAlertDefinition.NoDataState
+		}
+		execErrState := cmd.ExecErrState
+		if execErrState == "" {
+			execErrState = existingAlertDefinition.ExecErrState
+		}
 
 		// explicitly set all fields regardless of being provided or not
 		alertDefinition := &AlertDefinition{
@@ -155,6 +190,9 @@ func (ng *AlertNG) updateAlertDefinition(cmd *updateAlertDefinitionCommand) erro
 			OrgID:           existingAlertDefinition.OrgID,
 			IntervalSeconds: *intervalSeconds,
 			UID:             existingAlertDefinition.UID,
+			ForSeconds:      *forSeconds,
+			NoDataState:     noDataState,
+			ExecErrState:    execErrState,
 		}
 
 		if err := ng.validateAlertDefinition(alertDefinition, true); err != nil {
@@ -185,6 +223,9 @@ func (ng *AlertNG) updateAlertDefinition(cmd *updateAlertDefinitionCommand) erro
 			Title:              alertDefinition.Title,
 			Data:               alertDefinition.Data,
 			IntervalSeconds:    alertDefinition.IntervalSeconds,
+			ForSeconds:         alertDefinition.ForSeconds,
+			NoDataState:        alertDefinition.NoDataState,
+			ExecErrState:       alertDefinition.ExecErrState,
 		}
 		if _, err := sess.Insert(alertDefVersion); err != nil {
 			return err
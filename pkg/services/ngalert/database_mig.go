@@ -46,6 +46,20 @@ func addAlertDefinitionMigrations(mg *migrator.Migrator) {
 	}
 	mg.AddMigration("add unique index in alert_definition on org_id and title columns", migrator.NewAddIndexMigration(alertDefinition, uniqueIndices[0]))
 	mg.AddMigration("add unique index in alert_definition on org_id and uid columns", migrator.NewAddIndexMigration(alertDefinition, uniqueIndices[1]))
+
+	for _, col := range alertDefinitionStateColumns() {
+		mg.AddMigration(fmt.Sprintf("add %s column to alert_definition table", col.Name), migrator.NewAddColumnMigration(alertDefinition, col))
+	}
+}
+
+// alertDefinitionStateColumns returns the columns of the settings that
+// control the state of the alert instances of a definition.
+func alertDefinitionStateColumns() []*migrator.Column {
+	return []*migrator.Column{
+		{Name: "for_seconds", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+		{Name: "no_data_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false, Default: fmt.Sprintf("'%s'", NoData)},
+		{Name: "exec_err_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false, Default: fmt.Sprintf("'%s'", ExecErrError)},
+	}
 }
 
 func addAlertDefinitionVersionMigrations(mg *migrator.Migrator) {
@@ -77,6 +91,10 @@ func addAlertDefinitionVersionMigrations(mg *migrator.Migrator) {
 
 	mg.AddMigration("alter alert_definition_version table data column to mediumtext in mysql", migrator.NewRawSQLMigration("").
 		Mysql("ALTER TABLE alert_definition_version MODIFY data MEDIUMTEXT;"))
+
+	for _, col := range alertDefinitionStateColumns() {
+		mg.AddMigration(fmt.Sprintf("add %s column to alert_definition_version table", col.Name), migrator.NewAddColumnMigration(alertDefinitionVersion, col))
+	}
 }
 
 func alertInstanceMigration(mg *migrator.Migrator) {
@@ -102,4 +120,79 @@ func alertInstanceMigration(mg *migrator.Migrator) {
 	mg.AddMigration("create alert_instance table", migrator.NewAddTableMigration(alertInstance))
 	mg.AddMigration("add index in alert_instance table on def_org_id, def_uid and current_state columns", migrator.NewAddIndexMigration(alertInstance, alertInstance.Indices[0]))
 	mg.AddMigration("add index in alert_instance table on def_org_id, current_state columns", migrator.NewAddIndexMigration(alertInstance, alertInstance.Indices[1]))
+
+	mg.AddMigration("add starts_at column to alert_instance table", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "starts_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add ends_at column to alert_instance table", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "ends_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+}
+
+func alertInstanceHistoryMigration(mg *migrator.Migrator) {
+	alertInstanceHistory := migrator.Table{
+		Name: "alert_instance_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "def_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "def_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "prev_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "timestamp", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"def_org_id", "def_uid", "timestamp"}, Type: migrator.IndexType},
+			{Cols: []string{"def_org_id", "timestamp"}, Type: migrator.IndexType},
+			{Cols: []string{"timestamp"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_instance_history table", migrator.NewAddTableMigration(alertInstanceHistory))
+	mg.AddMigration("add index in alert_instance_history table on def_org_id, def_uid and timestamp columns", migrator.NewAddIndexMigration(alertInstanceHistory, alertInstanceHistory.Indices[0]))
+	mg.AddMigration("add index in alert_instance_history table on def_org_id and timestamp columns", migrator.NewAddIndexMigration(alertInstanceHistory, alertInstanceHistory.Indices[1]))
+	mg.AddMigration("add index in alert_instance_history table on timestamp column", migrator.NewAddIndexMigration(alertInstanceHistory, alertInstanceHistory.Indices[2]))
+}
+
+func alertNotificationMigrations(mg *migrator.Migrator) {
+	notificationPolicy := migrator.Table{
+		Name: "alert_notification_policy",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "route", Type: migrator.DB_Text, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_policy table", migrator.NewAddTableMigration(notificationPolicy))
+	mg.AddMigration("add unique index in alert_notification_policy on org_id column", migrator.NewAddIndexMigration(notificationPolicy, notificationPolicy.Indices[0]))
+
+	silence := migrator.Table{
+		Name: "alert_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "starts_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "ends_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "ends_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_silence table", migrator.NewAddTableMigration(silence))
+	mg.AddMigration("add unique index in alert_silence on org_id and uid columns", migrator.NewAddIndexMigration(silence, silence.Indices[0]))
+	mg.AddMigration("add index in alert_silence on org_id and ends_at columns", migrator.NewAddIndexMigration(silence, silence.Indices[1]))
 }
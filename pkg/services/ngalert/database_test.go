@@ -36,6 +36,7 @@ func TestCreatingAlertDefinition(t *testing.T) {
 		desc                 string
 		inputIntervalSeconds *int64
 		inputTitle           string
+		inputNoDataState     NoDataState
 		expectedError        error
 		expectedInterval     int64
 
@@ -67,6 +68,13 @@ func TestCreatingAlertDefinition(t *testing.T) {
 			inputTitle:           "",
 			expectedError:        errEmptyTitleError,
 		},
+		{
+			desc:                 "should fail to create an alert definition with invalid no data state",
+			inputIntervalSeconds: &customIntervalSeconds,
+			inputTitle:           "a name",
+			inputNoDataState:     "unknown",
+			expectedError:        errors.New(""),
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -97,6 +105,7 @@ func TestCreatingAlertDefinition(t *testing.T) {
 			if tc.inputIntervalSeconds != nil {
 				q.IntervalSeconds = tc.inputIntervalSeconds
 			}
+			q.NoDataState = tc.inputNoDataState
 			err := ng.saveAlertDefinition(&q)
 			switch {
 			case tc.expectedError != nil:
@@ -106,6 +115,9 @@ func TestCreatingAlertDefinition(t *testing.T) {
 				assert.Equal(t, tc.expectedUpdated, q.Result.Updated)
 				assert.Equal(t, tc.expectedInterval, q.Result.IntervalSeconds)
 				assert.Equal(t, int64(1), q.Result.Version)
+				assert.Equal(t, int64(0), q.Result.ForSeconds)
+				assert.Equal(t, NoData, q.Result.NoDataState)
+				assert.Equal(t, ExecErrError, q.Result.ExecErrState)
 
 			}
 		})
@@ -309,6 +321,8 @@ func TestUpdatingAlertDefinition(t *testing.T) {
 					assert.Equal(t, 1, len(q.Result.Data))
 					assert.Equal(t, tc.expectedUpdated, q.Result.Updated)
 					assert.Equal(t, tc.expectedIntervalSeconds, q.Result.IntervalSeconds)
+					assert.Equal(t, previousAlertDefinition.NoDataState, q.Result.NoDataState)
+					assert.Equal(t, previousAlertDefinition.ExecErrState, q.Result.ExecErrState)
 					assert.Equal(t, previousAlertDefinition.Version+1, q.Result.Version)
 					assert.Equal(t, alertDefinition.OrgID, q.Result.OrgID)
 					assert.Equal(t, alertDefinition.UID, q.Result.UID)
@@ -4,7 +4,9 @@ package eval
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -14,6 +16,10 @@ import (
 
 const alertingEvaluationTimeout = 30 * time.Second
 
+// errNoResults is returned by the execution of a condition whose
+// query or expression returned no data.
+var errNoResults = errors.New("no GEL results")
+
 // invalidEvalResultFormatError is an error for invalid format of the alert definition evaluation results.
 type invalidEvalResultFormatError struct {
 	refID  string
@@ -59,24 +65,33 @@ type Results []result
 // identified by its labels.
 type result struct {
 	Instance data.Labels
-	State    state // Enum
+	State    State // Enum
 }
 
-// state is an enum of the evaluation state for an alert instance.
-type state int
+// State is an enum of the evaluation state for an alert instance.
+type State int
 
 const (
 	// Normal is the eval state for an alert instance condition
 	// that evaluated to false.
-	Normal state = iota
+	Normal State = iota
 
 	// Alerting is the eval state for an alert instance condition
-	// that evaluated to false.
+	// that evaluated to true.
 	Alerting
+
+	// NoData is the eval state for an alert instance condition
+	// that returned no data or a null value.
+	NoData
+
+	// Error is the eval state for an alert definition condition
+	// that failed to be evaluated. It is not returned by ConditionEval,
+	// which returns the error instead.
+	Error
 )
 
-func (s state) String() string {
-	return [...]string{"Normal", "Alerting"}[s]
+func (s State) String() string {
+	return [...]string{"Normal", "Alerting", "NoData", "Error"}[s]
 }
 
 // IsValid checks the condition's validity.
@@ -146,9 +161,8 @@ func (c *Condition) execute(ctx AlertExecCtx, now time.Time) (*ExecutionResults,
 	}
 
 	if len(result.Results) == 0 {
-		err = fmt.Errorf("no GEL results")
-		result.Error = err
-		return &result, err
+		result.Error = errNoResults
+		return &result, errNoResults
 	}
 
 	return &result, nil
@@ -164,6 +178,11 @@ func evaluateExecutionResult(results *ExecutionResults) (Results, error) {
 		if err != nil {
 			return nil, &invalidEvalResultFormatError{refID: f.RefID, reason: "unable to get frame row length", err: err}
 		}
+		if len(f.Fields) == 0 {
+			// an empty frame has no labels to identify an instance
+			evalResults = append(evalResults, result{Instance: data.Labels{}, State: NoData})
+			continue
+		}
 		if rowLen > 1 {
 			return nil, &invalidEvalResultFormatError{refID: f.RefID, reason: fmt.Sprintf("unexpected row length: %d instead of 1", rowLen)}
 		}
@@ -183,9 +202,16 @@ func evaluateExecutionResult(results *ExecutionResults) (Results, error) {
 		}
 		labels[labelsStr] = true
 
+		var val *float64
+		if rowLen == 1 {
+			val = f.Fields[0].At(0).(*float64)
+		}
+
 		state := Normal
-		val, err := f.Fields[0].FloatAt(0)
-		if err != nil || val != 0 {
+		switch {
+		case val == nil || math.IsNaN(*val):
+			state = NoData
+		case *val != 0:
 			state = Alerting
 		}
 
@@ -203,7 +229,7 @@ func evaluateExecutionResult(results *ExecutionResults) (Results, error) {
 func (evalResults Results) AsDataFrame() data.Frame {
 	fields := make([]*data.Field, 0)
 	for _, evalResult := range evalResults {
-		fields = append(fields, data.NewField("", evalResult.Instance, []bool{evalResult.State != Normal}))
+		fields = append(fields, data.NewField("", evalResult.Instance, []bool{evalResult.State == Alerting}))
 	}
 	f := data.NewFrame("", fields...)
 	return *f
@@ -217,6 +243,9 @@ func ConditionEval(condition *Condition, now time.Time) (Results, error) {
 	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx}
 
 	execResult, err := condition.execute(alertExecCtx, now)
+	if errors.Is(err, errNoResults) {
+		return Results{{Instance: data.Labels{}, State: NoData}}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute conditions: %w", err)
 	}
@@ -14,6 +14,12 @@ type AlertInstance struct {
 	CurrentState      InstanceStateType
 	CurrentStateSince time.Time
 	LastEvalTime      time.Time
+	// StartsAt is when the instance last started alerting.
+	StartsAt time.Time
+	// EndsAt is when the instance stopped alerting. While the instance is
+	// alerting, it is the time after which the alert is considered resolved
+	// unless it is evaluated again.
+	EndsAt time.Time
 }
 
 // InstanceStateType is an enum for instance states.
@@ -24,13 +30,23 @@ const (
 	InstanceStateFiring InstanceStateType = "Alerting"
 	// InstanceStateNormal is for a normal alert.
 	InstanceStateNormal InstanceStateType = "Normal"
+	// InstanceStatePending is for an alert whose condition is true for
+	// less than the for duration of its definition.
+	InstanceStatePending InstanceStateType = "Pending"
+	// InstanceStateNoData is for an alert whose condition returned no data.
+	InstanceStateNoData InstanceStateType = "NoData"
+	// InstanceStateError is for an alert whose condition failed to be evaluated.
+	InstanceStateError InstanceStateType = "Error"
 )
 
 // IsValid checks that the value of InstanceStateType is a valid
 // string.
 func (i InstanceStateType) IsValid() bool {
 	return i == InstanceStateFiring ||
-		i == InstanceStateNormal
+		i == InstanceStateNormal ||
+		i == InstanceStatePending ||
+		i == InstanceStateNoData ||
+		i == InstanceStateError
 }
 
 // saveAlertInstanceCommand is the query for saving a new alert instance.
@@ -39,7 +55,12 @@ type saveAlertInstanceCommand struct {
 	DefinitionUID   string
 	Labels          InstanceLabels
 	State           InstanceStateType
-	LastEvalTime    time.Time
+	// StateSince is when the instance entered its state.
+	// It defaults to the current time.
+	StateSince   time.Time
+	LastEvalTime time.Time
+	StartsAt     time.Time
+	EndsAt       time.Time
 }
 
 // getAlertDefinitionByIDQuery is the query for retrieving/deleting an alert definition by ID.
@@ -71,6 +92,8 @@ type listAlertInstancesQueryResult struct {
 	CurrentState      InstanceStateType `json:"currentState"`
 	CurrentStateSince time.Time         `json:"currentStateSince"`
 	LastEvalTime      time.Time         `json:"lastEvalTime"`
+	StartsAt          time.Time         `json:"startsAt"`
+	EndsAt            time.Time         `json:"endsAt"`
 }
 
 // validateAlertInstance validates that the alert instance contains an alert definition id,
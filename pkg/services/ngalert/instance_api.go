@@ -1,6 +1,9 @@
 package ngalert
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 )
@@ -15,3 +18,32 @@ func (ng *AlertNG) listAlertInstancesEndpoint(c *models.ReqContext) response.Res
 
 	return response.JSON(200, cmd.Result)
 }
+
+// listAlertInstanceStateChangesEndpoint handles GET /api/alert-instances/history.
+func (ng *AlertNG) listAlertInstanceStateChangesEndpoint(c *models.ReqContext) response.Response {
+	query := listAlertInstanceStateChangesQuery{
+		DefinitionOrgID: c.SignedInUser.OrgId,
+		DefinitionUID:   c.Query("definitionUid"),
+		LabelsHash:      c.Query("labelsHash"),
+		State:           InstanceStateType(c.Query("state")),
+		Limit:           c.QueryInt("limit"),
+	}
+
+	if query.State != "" && !query.State.IsValid() {
+		return response.Error(400, fmt.Sprintf("Invalid state %q", query.State), nil)
+	}
+
+	// from and to are epoch milliseconds
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	if err := ng.listAlertInstanceStateChanges(&query); err != nil {
+		return response.Error(500, "Failed to list alert instance state history", err)
+	}
+
+	return response.JSON(200, query.Result)
+}
@@ -76,6 +76,28 @@ func (ng *AlertNG) listAlertInstances(cmd *listAlertInstancesQuery) error {
 	})
 }
 
+// getAlertDefinitionInstances returns the alert instances of an alert definition.
+func (ng *AlertNG) getAlertDefinitionInstances(key alertDefinitionKey) ([]*AlertInstance, error) {
+	alertInstances := make([]*AlertInstance, 0)
+	err := ng.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.SQL("SELECT * FROM alert_instance WHERE def_org_id = ? AND def_uid = ?", key.orgID, key.definitionUID).Find(&alertInstances)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, instance := range alertInstances {
+		// unset times are stored as zero
+		if instance.StartsAt.Unix() == 0 {
+			instance.StartsAt = time.Time{}
+		}
+		if instance.EndsAt.Unix() == 0 {
+			instance.EndsAt = time.Time{}
+		}
+	}
+	return alertInstances, nil
+}
+
 // saveAlertDefinition is a handler for saving a new alert definition.
 // nolint:unused
 func (ng *AlertNG) saveAlertInstance(cmd *saveAlertInstanceCommand) error {
@@ -85,26 +107,33 @@ func (ng *AlertNG) saveAlertInstance(cmd *saveAlertInstanceCommand) error {
 			return err
 		}
 
+		stateSince := cmd.StateSince
+		if stateSince.IsZero() {
+			stateSince = timeNow()
+		}
+
 		alertInstance := &AlertInstance{
 			DefinitionOrgID:   cmd.DefinitionOrgID,
 			DefinitionUID:     cmd.DefinitionUID,
 			Labels:            cmd.Labels,
 			LabelsHash:        labelsHash,
 			CurrentState:      cmd.State,
-			CurrentStateSince: time.Now(),
+			CurrentStateSince: stateSince,
 			LastEvalTime:      cmd.LastEvalTime,
+			StartsAt:          cmd.StartsAt,
+			EndsAt:            cmd.EndsAt,
 		}
 
 		if err := validateAlertInstance(alertInstance); err != nil {
 			return err
 		}
 
-		params := append(make([]interface{}, 0), alertInstance.DefinitionOrgID, alertInstance.DefinitionUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentStateSince.Unix(), alertInstance.LastEvalTime.Unix())
+		params := append(make([]interface{}, 0), alertInstance.DefinitionOrgID, alertInstance.DefinitionUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentStateSince.Unix(), alertInstance.LastEvalTime.Unix(), unixOrZero(alertInstance.StartsAt), unixOrZero(alertInstance.EndsAt))
 
 		upsertSQL := ng.SQLStore.Dialect.UpsertSQL(
 			"alert_instance",
 			[]string{"def_org_id", "def_uid", "labels_hash"},
-			[]string{"def_org_id", "def_uid", "labels", "labels_hash", "current_state", "current_state_since", "last_eval_time"})
+			[]string{"def_org_id", "def_uid", "labels", "labels_hash", "current_state", "current_state_since", "last_eval_time", "starts_at", "ends_at"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
@@ -113,3 +142,11 @@ func (ng *AlertNG) saveAlertInstance(cmd *saveAlertInstanceCommand) error {
 		return nil
 	})
 }
+
+// unixOrZero returns the unix time of t, or zero if t is not set.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,99 @@
+package ngalert
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultAlertInstanceHistoryLimit = 100
+	// stateHistoryCleanupInterval is how often the state changes that are
+	// older than the retention of the state history are deleted.
+	stateHistoryCleanupInterval = time.Hour
+)
+
+// AlertInstanceStateChange is an entry of the state history of an alert
+// instance.
+type AlertInstanceStateChange struct {
+	ID              int64             `xorm:"pk autoincr 'id'" json:"id"`
+	DefinitionOrgID int64             `xorm:"def_org_id" json:"definitionOrgId"`
+	DefinitionUID   string            `xorm:"def_uid" json:"definitionUid"`
+	Labels          InstanceLabels    `json:"labels"`
+	LabelsHash      string            `json:"labelsHash"`
+	PrevState       InstanceStateType `json:"prevState"`
+	State           InstanceStateType `json:"state"`
+	Error           string            `json:"error,omitempty"`
+	Timestamp       time.Time         `json:"timestamp"`
+}
+
+// saveAlertInstanceStateChangesCommand is the command for appending state
+// changes to the state history of alert instances.
+type saveAlertInstanceStateChangesCommand struct {
+	Changes []*AlertInstanceStateChange
+}
+
+// listAlertInstanceStateChangesQuery is the query for the state history of
+// the alert instances of an organisation. The state changes are returned
+// most recent first.
+type listAlertInstanceStateChangesQuery struct {
+	DefinitionOrgID int64
+	DefinitionUID   string
+	LabelsHash      string
+	State           InstanceStateType
+	From            time.Time
+	To              time.Time
+	Limit           int
+
+	Result []*AlertInstanceStateChange
+}
+
+// deleteAlertInstanceStateChangesCommand is the command for deleting the
+// state changes older than a given time.
+type deleteAlertInstanceStateChangesCommand struct {
+	Before time.Time
+
+	Result int64
+}
+
+// newAlertInstanceStateChange returns the state change of a state update.
+func newAlertInstanceStateChange(u *stateUpdate) *AlertInstanceStateChange {
+	change := &AlertInstanceStateChange{
+		DefinitionOrgID: u.Instance.DefinitionOrgID,
+		DefinitionUID:   u.Instance.DefinitionUID,
+		Labels:          u.Instance.Labels,
+		LabelsHash:      u.Instance.LabelsHash,
+		PrevState:       u.PrevState,
+		State:           u.Instance.CurrentState,
+		Timestamp:       u.Instance.CurrentStateSince,
+	}
+	if u.Error != nil {
+		change.Error = u.Error.Error()
+	}
+	return change
+}
+
+// cleanUpStateHistory deletes the state changes that are older than the
+// retention of the state history until the context is cancelled.
+func (ng *AlertNG) cleanUpStateHistory(ctx context.Context) error {
+	maxAge := ng.Cfg.NGAlertStateHistoryMaxAge
+	if maxAge <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(stateHistoryCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cmd := deleteAlertInstanceStateChangesCommand{Before: timeNow().Add(-maxAge)}
+			if err := ng.deleteAlertInstanceStateChanges(&cmd); err != nil {
+				ng.log.Error("failed to delete expired alert instance state history", "error", err)
+				continue
+			}
+			ng.log.Debug("deleted expired alert instance state history", "count", cmd.Result)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ngalert
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// saveAlertInstanceStateChanges is a handler for appending state changes to
+// the state history of alert instances.
+func (ng *AlertNG) saveAlertInstanceStateChanges(cmd *saveAlertInstanceStateChangesCommand) error {
+	if len(cmd.Changes) == 0 {
+		return nil
+	}
+
+	return ng.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, change := range cmd.Changes {
+			labelTupleJSON, _, err := change.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+
+			if _, err := sess.Exec("INSERT INTO alert_instance_history (def_org_id, def_uid, labels, labels_hash, prev_state, state, error, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+				change.DefinitionOrgID, change.DefinitionUID, labelTupleJSON, change.LabelsHash, change.PrevState, change.State, change.Error, change.Timestamp.Unix()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// listAlertInstanceStateChanges is a handler for retrieving the state history
+// of the alert instances of an organisation based on various filters.
+func (ng *AlertNG) listAlertInstanceStateChanges(query *listAlertInstanceStateChangesQuery) error {
+	return ng.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		changes := make([]*AlertInstanceStateChange, 0)
+
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_instance_history WHERE def_org_id = ?", query.DefinitionOrgID)
+
+		if query.DefinitionUID != "" {
+			addToQuery(" AND def_uid = ?", query.DefinitionUID)
+		}
+
+		if query.LabelsHash != "" {
+			addToQuery(" AND labels_hash = ?", query.LabelsHash)
+		}
+
+		if query.State != "" {
+			addToQuery(" AND (state = ? OR prev_state = ?)", query.State, query.State)
+		}
+
+		if !query.From.IsZero() {
+			addToQuery(" AND timestamp >= ?", query.From.Unix())
+		}
+
+		if !query.To.IsZero() {
+			addToQuery(" AND timestamp <= ?", query.To.Unix())
+		}
+
+		limit := query.Limit
+		if limit <= 0 {
+			limit = defaultAlertInstanceHistoryLimit
+		}
+		addToQuery(" ORDER BY timestamp DESC, id DESC" + ng.SQLStore.Dialect.Limit(int64(limit)))
+
+		if err := sess.SQL(s.String(), params...).Find(&changes); err != nil {
+			return err
+		}
+
+		query.Result = changes
+		return nil
+	})
+}
+
+// deleteAlertInstanceStateChanges is a handler for deleting the state changes
+// that are older than the retention of the state history.
+func (ng *AlertNG) deleteAlertInstanceStateChanges(cmd *deleteAlertInstanceStateChangesCommand) error {
+	return ng.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_instance_history WHERE timestamp < ?", cmd.Before.Unix())
+		if err != nil {
+			return err
+		}
+
+		cmd.Result, err = res.RowsAffected()
+		return err
+	})
+}
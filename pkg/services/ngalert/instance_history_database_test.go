@@ -0,0 +1,108 @@
+// +build integration
+
+package ngalert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertInstanceStateChangeOperations(t *testing.T) {
+	ng := setupTestEnv(t)
+
+	alertDefinition := createTestAlertDefinition(t, ng, 60)
+	orgID := alertDefinition.OrgID
+
+	labels := InstanceLabels{"test": "testValue"}
+	_, hash, err := labels.StringAndHash()
+	require.NoError(t, err)
+
+	start := time.Unix(1000, 0)
+	change := func(prev, state InstanceStateType, ts time.Time) *AlertInstanceStateChange {
+		return &AlertInstanceStateChange{
+			DefinitionOrgID: orgID,
+			DefinitionUID:   alertDefinition.UID,
+			Labels:          labels,
+			LabelsHash:      hash,
+			PrevState:       prev,
+			State:           state,
+			Timestamp:       ts,
+		}
+	}
+
+	err = ng.saveAlertInstanceStateChanges(&saveAlertInstanceStateChangesCommand{Changes: []*AlertInstanceStateChange{
+		change(InstanceStateNormal, InstanceStatePending, start),
+		change(InstanceStatePending, InstanceStateFiring, start.Add(time.Minute)),
+		change(InstanceStateFiring, InstanceStateNormal, start.Add(2*time.Minute)),
+	}})
+	require.NoError(t, err)
+
+	t.Run("can list state changes most recent first", func(t *testing.T) {
+		q := &listAlertInstanceStateChangesQuery{DefinitionOrgID: orgID, DefinitionUID: alertDefinition.UID}
+		require.NoError(t, ng.listAlertInstanceStateChanges(q))
+		require.Len(t, q.Result, 3)
+
+		assert.Equal(t, InstanceStateNormal, q.Result[0].State)
+		assert.Equal(t, InstanceStateFiring, q.Result[0].PrevState)
+		assert.Equal(t, labels, q.Result[0].Labels)
+		assert.Equal(t, start.Add(2*time.Minute).Unix(), q.Result[0].Timestamp.Unix())
+	})
+
+	t.Run("can filter state changes by state and time", func(t *testing.T) {
+		q := &listAlertInstanceStateChangesQuery{DefinitionOrgID: orgID, State: InstanceStateFiring, From: start.Add(time.Minute)}
+		require.NoError(t, ng.listAlertInstanceStateChanges(q))
+		require.Len(t, q.Result, 2)
+
+		q = &listAlertInstanceStateChangesQuery{DefinitionOrgID: orgID, To: start, LabelsHash: hash}
+		require.NoError(t, ng.listAlertInstanceStateChanges(q))
+		require.Len(t, q.Result, 1)
+		assert.Equal(t, InstanceStatePending, q.Result[0].State)
+	})
+
+	t.Run("can limit state changes", func(t *testing.T) {
+		q := &listAlertInstanceStateChangesQuery{DefinitionOrgID: orgID, Limit: 1}
+		require.NoError(t, ng.listAlertInstanceStateChanges(q))
+		require.Len(t, q.Result, 1)
+	})
+
+	t.Run("can delete expired state changes", func(t *testing.T) {
+		cmd := &deleteAlertInstanceStateChangesCommand{Before: start.Add(time.Minute)}
+		require.NoError(t, ng.deleteAlertInstanceStateChanges(cmd))
+		assert.Equal(t, int64(1), cmd.Result)
+
+		q := &listAlertInstanceStateChangesQuery{DefinitionOrgID: orgID}
+		require.NoError(t, ng.listAlertInstanceStateChanges(q))
+		require.Len(t, q.Result, 2)
+	})
+}
+
+func TestGetAlertDefinitionInstances(t *testing.T) {
+	ng := setupTestEnv(t)
+
+	alertDefinition := createTestAlertDefinition(t, ng, 60)
+	now := time.Unix(1000, 0)
+
+	saveCmd := &saveAlertInstanceCommand{
+		DefinitionOrgID: alertDefinition.OrgID,
+		DefinitionUID:   alertDefinition.UID,
+		State:           InstanceStatePending,
+		StateSince:      now,
+		LastEvalTime:    now,
+		Labels:          InstanceLabels{"test": "testValue"},
+	}
+	require.NoError(t, ng.saveAlertInstance(saveCmd))
+
+	instances, err := ng.getAlertDefinitionInstances(alertDefinition.getKey())
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+
+	instance := instances[0]
+	assert.Equal(t, InstanceStatePending, instance.CurrentState)
+	assert.Equal(t, now.Unix(), instance.CurrentStateSince.Unix())
+	assert.Equal(t, saveCmd.Labels, instance.Labels)
+	assert.True(t, instance.StartsAt.IsZero())
+	assert.True(t, instance.EndsAt.IsZero())
+}
@@ -21,6 +21,54 @@ type AlertDefinition struct {
 	IntervalSeconds int64             `json:"intervalSeconds"`
 	Version         int64             `json:"version"`
 	UID             string            `xorm:"uid" json:"uid"`
+	// ForSeconds is how long the condition of an instance has to be true
+	// before the instance is alerting. Until then the instance is pending.
+	ForSeconds   int64               `json:"forSeconds"`
+	NoDataState  NoDataState         `json:"noDataState"`
+	ExecErrState ExecutionErrorState `json:"execErrState"`
+}
+
+// NoDataState is the state alert instances are set to when the
+// condition of their alert definition returns no data.
+type NoDataState string
+
+const (
+	// NoData sets the instances to the NoData state.
+	NoData NoDataState = "NoData"
+	// NoDataAlerting sets the instances to the Alerting state.
+	NoDataAlerting NoDataState = "Alerting"
+	// NoDataNormal sets the instances to the Normal state.
+	NoDataNormal NoDataState = "Normal"
+	// NoDataKeepLastState keeps the instances in their current state.
+	NoDataKeepLastState NoDataState = "KeepLastState"
+)
+
+// IsValid checks that the value of NoDataState is a valid string.
+func (s NoDataState) IsValid() bool {
+	return s == NoData ||
+		s == NoDataAlerting ||
+		s == NoDataNormal ||
+		s == NoDataKeepLastState
+}
+
+// ExecutionErrorState is the state alert instances are set to when
+// the condition of their alert definition fails to be evaluated.
+type ExecutionErrorState string
+
+const (
+	// ExecErrError sets the instances to the Error state.
+	ExecErrError ExecutionErrorState = "Error"
+	// ExecErrAlerting sets the instances to the Alerting state.
+	ExecErrAlerting ExecutionErrorState = "Alerting"
+	// ExecErrKeepLastState keeps the instances in their current state.
+	ExecErrKeepLastState ExecutionErrorState = "KeepLastState"
+)
+
+// IsValid checks that the value of ExecutionErrorState is a valid string.
+func (s ExecutionErrorState) IsValid() bool {
+	return s == ExecErrError ||
+		s == ExecErrAlerting ||
+		s == ExecErrKeepLastState
 }
 
 type alertDefinitionKey struct {
@@ -50,6 +98,9 @@ type AlertDefinitionVersion struct {
 	Condition       string
 	Data            []eval.AlertQuery
 	IntervalSeconds int64
+	ForSeconds      int64
+	NoDataState     NoDataState
+	ExecErrState    ExecutionErrorState
 }
 
 var (
@@ -72,21 +123,27 @@ type deleteAlertDefinitionByUIDCommand struct {
 
 // saveAlertDefinitionCommand is the query for saving a new alert definition.
 type saveAlertDefinitionCommand struct {
-	Title           string         `json:"title"`
-	OrgID           int64          `json:"-"`
-	Condition       eval.Condition `json:"condition"`
-	IntervalSeconds *int64         `json:"interval_seconds"`
+	Title           string              `json:"title"`
+	OrgID           int64               `json:"-"`
+	Condition       eval.Condition      `json:"condition"`
+	IntervalSeconds *int64              `json:"interval_seconds"`
+	ForSeconds      *int64              `json:"for_seconds"`
+	NoDataState     NoDataState         `json:"no_data_state"`
+	ExecErrState    ExecutionErrorState `json:"exec_err_state"`
 
 	Result *AlertDefinition
 }
 
 // updateAlertDefinitionCommand is the query for updating an existing alert definition.
 type updateAlertDefinitionCommand struct {
-	Title           string         `json:"title"`
-	OrgID           int64          `json:"-"`
-	Condition       eval.Condition `json:"condition"`
-	IntervalSeconds *int64         `json:"interval_seconds"`
-	UID             string         `json:"-"`
+	Title           string              `json:"title"`
+	OrgID           int64               `json:"-"`
+	Condition       eval.Condition      `json:"condition"`
+	IntervalSeconds *int64              `json:"interval_seconds"`
+	ForSeconds      *int64              `json:"for_seconds"`
+	NoDataState     NoDataState         `json:"no_data_state"`
+	ExecErrState    ExecutionErrorState `json:"exec_err_state"`
+	UID             string              `json:"-"`
 
 	Result *AlertDefinition
 }
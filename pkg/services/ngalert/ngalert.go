@@ -11,9 +11,11 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/setting"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -34,8 +36,11 @@ type AlertNG struct {
 	DatasourceCache datasources.CacheService `inject:""`
 	RouteRegister   routing.RouteRegister    `inject:""`
 	SQLStore        *sqlstore.SQLStore       `inject:""`
+	QuotaService    *quota.QuotaService      `inject:""`
 	log             log.Logger
 	schedule        *schedule
+	stateManager    *stateManager
+	dispatcher      *notificationDispatcher
 }
 
 func init() {
@@ -48,13 +53,43 @@ func (ng *AlertNG) Init() error {
 
 	ng.registerAPIEndpoints()
 	ng.schedule = newScheduler(clock.New(), baseIntervalSeconds*time.Second, ng.log, nil)
+	ng.stateManager = newStateManager(ng.log, ng.getAlertDefinitionInstances)
+	ng.dispatcher = newNotificationDispatcher(clock.New(), ng.log, ng.getRoute, ng.getActiveSilences, ng.sendGroupNotification)
 	return nil
 }
 
-// Run starts the scheduler
+// Run starts the scheduler, the notification dispatcher and the cleanup of
+// the state history.
 func (ng *AlertNG) Run(ctx context.Context) error {
 	ng.log.Debug("ngalert starting")
-	return ng.alertingTicker(ctx)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return ng.alertingTicker(ctx)
+	})
+	g.Go(func() error {
+		return ng.dispatcher.run(ctx)
+	})
+	g.Go(func() error {
+		return ng.cleanUpStateHistory(ctx)
+	})
+	return g.Wait()
+}
+
+func (ng *AlertNG) getRoute(orgID int64) (*Route, error) {
+	q := getNotificationPolicyQuery{OrgID: orgID}
+	if err := ng.getNotificationPolicy(&q); err != nil {
+		return nil, err
+	}
+	return q.Result, nil
+}
+
+func (ng *AlertNG) getActiveSilences(orgID int64, now time.Time) ([]*Silence, error) {
+	q := listSilencesQuery{OrgID: orgID, ActiveAt: now}
+	if err := ng.listSilences(&q); err != nil {
+		return nil, err
+	}
+	return q.Result, nil
 }
 
 // IsDisabled returns true if the alerting service is disable for this instance.
@@ -76,6 +111,10 @@ func (ng *AlertNG) AddMigration(mg *migrator.Migrator) {
 	addAlertDefinitionVersionMigrations(mg)
 	// Create alert_instance table
 	alertInstanceMigration(mg)
+	// Create alert_instance_history table
+	alertInstanceHistoryMigration(mg)
+	// Create alert_notification_policy and alert_silence tables
+	alertNotificationMigrations(mg)
 }
 
 // LoadAlertCondition returns a Condition object for the given alertDefinitionID.
@@ -0,0 +1,81 @@
+package ngalert
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// getNotificationPolicyEndpoint handles GET /api/alert-notification-policy.
+func (ng *AlertNG) getNotificationPolicyEndpoint(c *models.ReqContext) response.Response {
+	query := getNotificationPolicyQuery{OrgID: c.SignedInUser.OrgId}
+
+	if err := ng.getNotificationPolicy(&query); err != nil {
+		return response.Error(500, "Failed to get notification policy", err)
+	}
+
+	return response.JSON(200, query.Result)
+}
+
+// updateNotificationPolicyEndpoint handles PUT /api/alert-notification-policy.
+func (ng *AlertNG) updateNotificationPolicyEndpoint(c *models.ReqContext, route Route) response.Response {
+	cmd := saveNotificationPolicyCommand{OrgID: c.SignedInUser.OrgId, Route: &route}
+
+	if err := ng.saveNotificationPolicy(&cmd); err != nil {
+		if errors.Is(err, errInvalidNotificationPolicy) {
+			return response.Error(400, "Invalid notification policy", err)
+		}
+		return response.Error(500, "Failed to update notification policy", err)
+	}
+
+	if ng.dispatcher != nil {
+		ng.dispatcher.invalidateRoute(cmd.OrgID)
+	}
+
+	return response.Success("Notification policy updated")
+}
+
+// listSilencesEndpoint handles GET /api/alert-silences.
+func (ng *AlertNG) listSilencesEndpoint(c *models.ReqContext) response.Response {
+	query := listSilencesQuery{OrgID: c.SignedInUser.OrgId}
+	if c.QueryBool("active") {
+		query.ActiveAt = timeNow()
+	}
+
+	if err := ng.listSilences(&query); err != nil {
+		return response.Error(500, "Failed to list silences", err)
+	}
+
+	return response.JSON(200, query.Result)
+}
+
+// createSilenceEndpoint handles POST /api/alert-silences.
+func (ng *AlertNG) createSilenceEndpoint(c *models.ReqContext, cmd createSilenceCommand) response.Response {
+	cmd.OrgID = c.SignedInUser.OrgId
+	cmd.CreatedBy = c.SignedInUser.UserId
+
+	if err := cmd.validate(); err != nil {
+		return response.Error(400, "Invalid silence", err)
+	}
+
+	if err := ng.createSilence(&cmd); err != nil {
+		return response.Error(500, "Failed to create silence", err)
+	}
+
+	return response.JSON(200, cmd.Result)
+}
+
+// expireSilenceEndpoint handles DELETE /api/alert-silences/:silenceUID.
+func (ng *AlertNG) expireSilenceEndpoint(c *models.ReqContext) response.Response {
+	cmd := expireSilenceCommand{OrgID: c.SignedInUser.OrgId, UID: c.Params(":silenceUID")}
+
+	if err := ng.expireSilence(&cmd); err != nil {
+		if errors.Is(err, errSilenceNotFound) {
+			return response.Error(404, "Silence not found", err)
+		}
+		return response.Error(500, "Failed to expire silence", err)
+	}
+
+	return response.Success("Silence expired")
+}
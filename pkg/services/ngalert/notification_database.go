@@ -0,0 +1,205 @@
+package ngalert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	errSilenceNotFound                = errors.New("could not find silence")
+	errSilenceFailedGenerateUniqueUID = errors.New("failed to generate silence UID")
+	errInvalidNotificationPolicy      = errors.New("invalid notification policy")
+)
+
+// notificationPolicy is the notification routing tree of an organisation
+// as it is stored in the database.
+type notificationPolicy struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	Route   string `xorm:"route"`
+	Updated time.Time
+}
+
+// TableName returns the name of the database table of notification policies.
+func (p notificationPolicy) TableName() string {
+	return "alert_notification_policy"
+}
+
+// TableName returns the name of the database table of silences.
+func (s Silence) TableName() string {
+	return "alert_silence"
+}
+
+// getNotificationPolicyQuery is the query for the routing tree of an organisation.
+type getNotificationPolicyQuery struct {
+	OrgID int64
+
+	Result *Route
+}
+
+// saveNotificationPolicyCommand is the command for replacing the routing
+// tree of an organisation.
+type saveNotificationPolicyCommand struct {
+	OrgID int64
+	Route *Route
+}
+
+// getNotificationPolicy is a handler for retrieving the routing tree of an
+// organisation. It returns the default routing tree if the organisation has
+// none.
+func (ng *AlertNG) getNotificationPolicy(query *getNotificationPolicyQuery) error {
+	return ng.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		policy := notificationPolicy{}
+		has, err := sess.Where("org_id = ?", query.OrgID).Get(&policy)
+		if err != nil {
+			return err
+		}
+		if !has {
+			query.Result = defaultRoute()
+			return nil
+		}
+
+		route := &Route{}
+		if err := json.Unmarshal([]byte(policy.Route), route); err != nil {
+			return fmt.Errorf("failed to unmarshal the notification policy of organisation %d: %w", query.OrgID, err)
+		}
+		if err := route.init(); err != nil {
+			return fmt.Errorf("invalid notification policy of organisation %d: %w", query.OrgID, err)
+		}
+
+		query.Result = route
+		return nil
+	})
+}
+
+// saveNotificationPolicy is a handler for replacing the routing tree of an
+// organisation. The routing tree is stored as it is given, without the
+// inherited settings.
+func (ng *AlertNG) saveNotificationPolicy(cmd *saveNotificationPolicyCommand) error {
+	b, err := json.Marshal(cmd.Route)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Route.init(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidNotificationPolicy, err)
+	}
+
+	return ng.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		policy := notificationPolicy{OrgID: cmd.OrgID, Route: string(b), Updated: timeNow()}
+
+		existing := notificationPolicy{}
+		has, err := sess.Where("org_id = ?", cmd.OrgID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		if has {
+			_, err = sess.ID(existing.ID).Cols("route", "updated").Update(&policy)
+		} else {
+			_, err = sess.Insert(&policy)
+		}
+		return err
+	})
+}
+
+// createSilence is a handler for creating a silence.
+func (ng *AlertNG) createSilence(cmd *createSilenceCommand) error {
+	if err := cmd.validate(); err != nil {
+		return err
+	}
+
+	return ng.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		uid, err := generateNewSilenceUID(sess, cmd.OrgID)
+		if err != nil {
+			return err
+		}
+
+		silence := &Silence{
+			OrgID:     cmd.OrgID,
+			UID:       uid,
+			Matchers:  cmd.Matchers,
+			StartsAt:  cmd.StartsAt,
+			EndsAt:    cmd.EndsAt,
+			Comment:   cmd.Comment,
+			CreatedBy: cmd.CreatedBy,
+			Created:   timeNow(),
+		}
+
+		if _, err := sess.Insert(silence); err != nil {
+			return err
+		}
+
+		cmd.Result = silence
+		return nil
+	})
+}
+
+// listSilences is a handler for retrieving the silences of an organisation.
+func (ng *AlertNG) listSilences(query *listSilencesQuery) error {
+	return ng.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		silences := make([]*Silence, 0)
+
+		q := sess.Where("org_id = ?", query.OrgID)
+		if !query.ActiveAt.IsZero() {
+			q = q.And("starts_at <= ? AND ends_at > ?", query.ActiveAt, query.ActiveAt)
+		}
+
+		if err := q.Asc("ends_at").Find(&silences); err != nil {
+			return err
+		}
+
+		query.Result = silences
+		return nil
+	})
+}
+
+// expireSilence is a handler for ending a silence now. Silences that ended
+// already are left unchanged.
+func (ng *AlertNG) expireSilence(cmd *expireSilenceCommand) error {
+	return ng.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		silence := Silence{}
+		has, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Get(&silence)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return errSilenceNotFound
+		}
+
+		now := timeNow()
+		if !silence.EndsAt.After(now) {
+			return nil
+		}
+
+		silence.EndsAt = now
+		if silence.StartsAt.After(now) {
+			silence.StartsAt = now
+		}
+		_, err = sess.ID(silence.ID).Cols("starts_at", "ends_at").Update(&silence)
+		return err
+	})
+}
+
+func generateNewSilenceUID(sess *sqlstore.DBSession, orgID int64) (string, error) {
+	for i := 0; i < 3; i++ {
+		uid := util.GenerateShortUID()
+
+		exists, err := sess.Where("org_id=? AND uid=?", orgID, uid).Get(&Silence{})
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return uid, nil
+		}
+	}
+
+	return "", errSilenceFailedGenerateUniqueUID
+}
@@ -0,0 +1,98 @@
+// +build integration
+
+package ngalert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationPolicyOperations(t *testing.T) {
+	ng := setupTestEnv(t)
+
+	t.Run("organisation without policy gets the default policy", func(t *testing.T) {
+		q := &getNotificationPolicyQuery{OrgID: 1}
+		require.NoError(t, ng.getNotificationPolicy(q))
+		assert.Equal(t, []string{alertNameLabel}, q.Result.GroupBy)
+	})
+
+	t.Run("can save and update policy", func(t *testing.T) {
+		for _, receiver := range []string{"first", "second"} {
+			cmd := &saveNotificationPolicyCommand{
+				OrgID: 1,
+				Route: &Route{
+					Receiver: receiver,
+					Routes:   []*Route{{Matchers: Matchers{{Name: "team", Type: MatchEqual, Value: "a"}}}},
+				},
+			}
+			require.NoError(t, ng.saveNotificationPolicy(cmd))
+
+			q := &getNotificationPolicyQuery{OrgID: 1}
+			require.NoError(t, ng.getNotificationPolicy(q))
+			assert.Equal(t, receiver, q.Result.Receiver)
+			require.Len(t, q.Result.Routes, 1)
+			assert.Equal(t, receiver, q.Result.Routes[0].Receiver)
+		}
+	})
+
+	t.Run("invalid policy is rejected", func(t *testing.T) {
+		cmd := &saveNotificationPolicyCommand{
+			OrgID: 1,
+			Route: &Route{GroupWait: eval.Duration(-time.Second)},
+		}
+		err := ng.saveNotificationPolicy(cmd)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errInvalidNotificationPolicy)
+	})
+}
+
+func TestSilenceOperations(t *testing.T) {
+	ng := setupTestEnv(t)
+	now := timeNow()
+
+	matchers := Matchers{{Name: "team", Type: MatchRegexp, Value: "a|b"}}
+
+	active := &createSilenceCommand{OrgID: 1, Matchers: matchers, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}
+	require.NoError(t, ng.createSilence(active))
+	require.NotEmpty(t, active.Result.UID)
+
+	pending := &createSilenceCommand{OrgID: 1, Matchers: matchers, StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	require.NoError(t, ng.createSilence(pending))
+
+	t.Run("can list silences", func(t *testing.T) {
+		q := &listSilencesQuery{OrgID: 1}
+		require.NoError(t, ng.listSilences(q))
+		require.Len(t, q.Result, 2)
+		assert.Equal(t, active.Result.UID, q.Result[0].UID)
+		assert.True(t, q.Result[0].Matchers.Matches(InstanceLabels{"team": "b"}))
+	})
+
+	t.Run("can list active silences", func(t *testing.T) {
+		q := &listSilencesQuery{OrgID: 1, ActiveAt: now}
+		require.NoError(t, ng.listSilences(q))
+		require.Len(t, q.Result, 1)
+		assert.Equal(t, active.Result.UID, q.Result[0].UID)
+	})
+
+	t.Run("invalid silence is rejected", func(t *testing.T) {
+		cmd := &createSilenceCommand{OrgID: 1, Matchers: matchers, StartsAt: now, EndsAt: now}
+		require.Error(t, ng.createSilence(cmd))
+	})
+
+	t.Run("can expire silence", func(t *testing.T) {
+		require.NoError(t, ng.expireSilence(&expireSilenceCommand{OrgID: 1, UID: active.Result.UID}))
+
+		q := &listSilencesQuery{OrgID: 1, ActiveAt: timeNow().Add(time.Second)}
+		require.NoError(t, ng.listSilences(q))
+		assert.Empty(t, q.Result)
+	})
+
+	t.Run("expiring unknown silence fails", func(t *testing.T) {
+		err := ng.expireSilence(&expireSilenceCommand{OrgID: 1, UID: "unknown"})
+		assert.ErrorIs(t, err, errSilenceNotFound)
+	})
+}
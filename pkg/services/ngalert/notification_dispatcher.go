@@ -0,0 +1,322 @@
+package ngalert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	// dispatchInterval is how often the dispatcher checks for groups
+	// that are due to be notified.
+	dispatchInterval = time.Second
+	// alertTimeoutIntervals is the number of evaluation intervals after which
+	// an alert instance that is not updated anymore is considered resolved.
+	alertTimeoutIntervals = 3
+)
+
+// notificationAlert is the state of an alert instance handed over to the
+// notification dispatcher. An alert is resolved once its end time is past.
+type notificationAlert struct {
+	OrgID           int64
+	DefinitionUID   string
+	DefinitionTitle string
+	Labels          InstanceLabels
+	StartsAt        time.Time
+	EndsAt          time.Time
+	UpdatedAt       time.Time
+}
+
+func (a *notificationAlert) fingerprint() string {
+	_, hash, _ := a.Labels.StringAndHash()
+	return a.DefinitionUID + ":" + hash
+}
+
+func (a *notificationAlert) resolved(now time.Time) bool {
+	return !a.EndsAt.After(now)
+}
+
+// groupNotification is a notification about the alerts of a group.
+type groupNotification struct {
+	OrgID       int64
+	Receiver    string
+	GroupLabels InstanceLabels
+	Firing      []*notificationAlert
+	Resolved    []*notificationAlert
+}
+
+// title returns the titles of the alert definitions of the notification.
+func (n *groupNotification) title() string {
+	seen := map[string]struct{}{}
+	titles := make([]string, 0)
+	for _, alerts := range [][]*notificationAlert{n.Firing, n.Resolved} {
+		for _, a := range alerts {
+			if _, ok := seen[a.DefinitionTitle]; ok {
+				continue
+			}
+			seen[a.DefinitionTitle] = struct{}{}
+			titles = append(titles, a.DefinitionTitle)
+		}
+	}
+	sort.Strings(titles)
+	return strings.Join(titles, ", ")
+}
+
+// alertGroup holds the alerts of a route that have the same group labels,
+// and remembers which of them were notified to deduplicate notifications.
+type alertGroup struct {
+	mu sync.Mutex
+
+	orgID     int64
+	route     *Route
+	labels    InstanceLabels
+	alerts    map[string]*notificationAlert
+	nextFlush time.Time
+
+	lastNotified   time.Time
+	notifiedFiring map[string]struct{}
+}
+
+func newAlertGroup(orgID int64, route *Route, labels InstanceLabels, now time.Time) *alertGroup {
+	return &alertGroup{
+		orgID:          orgID,
+		route:          route,
+		labels:         labels,
+		alerts:         map[string]*notificationAlert{},
+		nextFlush:      now.Add(time.Duration(route.GroupWait)),
+		notifiedFiring: map[string]struct{}{},
+	}
+}
+
+func (g *alertGroup) put(alert *notificationAlert) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fp := alert.fingerprint()
+	a := *alert
+	if existing, ok := g.alerts[fp]; ok && !existing.resolved(alert.UpdatedAt) {
+		a.StartsAt = existing.StartsAt
+	}
+	g.alerts[fp] = &a
+}
+
+func (g *alertGroup) empty() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return len(g.alerts) == 0
+}
+
+// flush sends a notification about the group if its firing alerts changed
+// since the last notification, or if the repeat interval elapsed.
+// Silenced alerts are left out of notifications, and resolved alerts
+// are removed from the group once notified.
+func (g *alertGroup) flush(ctx context.Context, now time.Time, silences []*Silence, notify notifyFunc) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.nextFlush = now.Add(time.Duration(g.route.GroupInterval))
+
+	n := &groupNotification{OrgID: g.orgID, Receiver: g.route.Receiver, GroupLabels: g.labels}
+	firing := map[string]struct{}{}
+	for fp, a := range g.alerts {
+		switch {
+		case a.resolved(now):
+			if _, ok := g.notifiedFiring[fp]; ok {
+				n.Resolved = append(n.Resolved, a)
+			}
+		case !isSilenced(silences, a.Labels, now):
+			n.Firing = append(n.Firing, a)
+			firing[fp] = struct{}{}
+		}
+	}
+	sortAlerts(n.Firing)
+	sortAlerts(n.Resolved)
+
+	changed := len(firing) != len(g.notifiedFiring)
+	for fp := range firing {
+		if _, ok := g.notifiedFiring[fp]; !ok {
+			changed = true
+		}
+	}
+	repeat := len(n.Firing) > 0 && !now.Before(g.lastNotified.Add(time.Duration(g.route.RepeatInterval)))
+
+	if (changed || repeat) && (len(n.Firing) > 0 || len(n.Resolved) > 0) {
+		if err := notify(ctx, n); err != nil {
+			return err
+		}
+		g.lastNotified = now
+	}
+
+	g.notifiedFiring = firing
+	for fp, a := range g.alerts {
+		if a.resolved(now) {
+			delete(g.alerts, fp)
+		}
+	}
+
+	return nil
+}
+
+func sortAlerts(alerts []*notificationAlert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].fingerprint() < alerts[j].fingerprint()
+	})
+}
+
+type notifyFunc func(ctx context.Context, n *groupNotification) error
+
+// notificationDispatcher routes the alert instances through the notification
+// policies of their organisation into groups, and notifies the groups.
+type notificationDispatcher struct {
+	mu     sync.Mutex
+	groups map[string]*alertGroup
+	routes map[int64]*Route
+
+	getRoute    func(orgID int64) (*Route, error)
+	getSilences func(orgID int64, now time.Time) ([]*Silence, error)
+	notify      notifyFunc
+
+	clock clock.Clock
+	log   log.Logger
+}
+
+func newNotificationDispatcher(c clock.Clock, logger log.Logger, getRoute func(int64) (*Route, error), getSilences func(int64, time.Time) ([]*Silence, error), notify notifyFunc) *notificationDispatcher {
+	return &notificationDispatcher{
+		groups:      map[string]*alertGroup{},
+		routes:      map[int64]*Route{},
+		getRoute:    getRoute,
+		getSilences: getSilences,
+		notify:      notify,
+		clock:       c,
+		log:         logger,
+	}
+}
+
+// put routes the alert to its groups. Resolved alerts are only added to
+// existing groups.
+func (d *notificationDispatcher) put(alert *notificationAlert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	route, ok := d.routes[alert.OrgID]
+	if !ok {
+		var err error
+		route, err = d.getRoute(alert.OrgID)
+		if err != nil {
+			d.log.Error("failed to get notification policy", "org", alert.OrgID, "error", err)
+			return
+		}
+		d.routes[alert.OrgID] = route
+	}
+
+	now := d.clock.Now()
+	for _, r := range route.match(alert.Labels) {
+		groupLabels := r.groupLabels(alert.Labels)
+		_, hash, err := groupLabels.StringAndHash()
+		if err != nil {
+			d.log.Error("failed to group alert", "org", alert.OrgID, "definition", alert.DefinitionUID, "error", err)
+			continue
+		}
+
+		key := fmt.Sprintf("%d:%s:%s", alert.OrgID, r.id, hash)
+		g, ok := d.groups[key]
+		if !ok {
+			if alert.resolved(now) {
+				continue
+			}
+			g = newAlertGroup(alert.OrgID, r, groupLabels, now)
+			d.groups[key] = g
+		}
+		g.put(alert)
+	}
+}
+
+// invalidateRoute drops the cached notification policy of an organisation.
+// Alerts that do not reach their previous groups anymore resolve in them
+// when they time out.
+func (d *notificationDispatcher) invalidateRoute(orgID int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.routes, orgID)
+}
+
+// flush notifies the groups that are due.
+func (d *notificationDispatcher) flush(ctx context.Context) {
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	due := make(map[string]*alertGroup)
+	for key, g := range d.groups {
+		if !now.Before(g.nextFlush) {
+			due[key] = g
+		}
+	}
+	d.mu.Unlock()
+
+	silences := make(map[int64][]*Silence)
+	for key, g := range due {
+		s, ok := silences[g.orgID]
+		if !ok {
+			var err error
+			s, err = d.getSilences(g.orgID, now)
+			if err != nil {
+				d.log.Error("failed to get silences", "org", g.orgID, "error", err)
+				continue
+			}
+			silences[g.orgID] = s
+		}
+
+		if err := g.flush(ctx, now, s, d.notify); err != nil {
+			d.log.Error("failed to send notification", "org", g.orgID, "receiver", g.route.Receiver, "group", g.labels, "error", err)
+			continue
+		}
+
+		d.mu.Lock()
+		if g.empty() {
+			delete(d.groups, key)
+		}
+		d.mu.Unlock()
+	}
+}
+
+// run flushes the groups until the context is cancelled.
+func (d *notificationDispatcher) run(ctx context.Context) error {
+	ticker := d.clock.Ticker(dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.flush(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// newNotificationAlert returns the notification alert of an alert instance.
+// Instances that are not alerting are resolved.
+func newNotificationAlert(alertDefinition *AlertDefinition, instance *AlertInstance) *notificationAlert {
+	labels := InstanceLabels{alertNameLabel: alertDefinition.Title}
+	for k, v := range instance.Labels {
+		labels[k] = v
+	}
+
+	return &notificationAlert{
+		OrgID:           alertDefinition.OrgID,
+		DefinitionUID:   alertDefinition.UID,
+		DefinitionTitle: alertDefinition.Title,
+		Labels:          labels,
+		StartsAt:        instance.StartsAt,
+		EndsAt:          instance.EndsAt,
+		UpdatedAt:       instance.LastEvalTime,
+	}
+}
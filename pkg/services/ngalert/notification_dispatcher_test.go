@@ -0,0 +1,179 @@
+package ngalert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dispatcherTestEnv struct {
+	clock         *clock.Mock
+	dispatcher    *notificationDispatcher
+	silences      []*Silence
+	notifications []*groupNotification
+}
+
+func newDispatcherTestEnv(t *testing.T) *dispatcherTestEnv {
+	route := &Route{
+		GroupBy:        []string{alertNameLabel},
+		GroupWait:      eval.Duration(10 * time.Second),
+		GroupInterval:  eval.Duration(time.Minute),
+		RepeatInterval: eval.Duration(time.Hour),
+	}
+	require.NoError(t, route.init())
+
+	env := &dispatcherTestEnv{clock: clock.NewMock()}
+	env.dispatcher = newNotificationDispatcher(env.clock, log.New("ngalert.dispatcher.test"),
+		func(int64) (*Route, error) {
+			return route, nil
+		},
+		func(int64, time.Time) ([]*Silence, error) {
+			return env.silences, nil
+		},
+		func(ctx context.Context, n *groupNotification) error {
+			env.notifications = append(env.notifications, n)
+			return nil
+		},
+	)
+	return env
+}
+
+// evaluate puts the result of an evaluation of the alert definition.
+func (env *dispatcherTestEnv) evaluate(def *AlertDefinition, labels InstanceLabels, firing bool) {
+	now := env.clock.Now()
+	instance := &AlertInstance{Labels: labels, CurrentState: InstanceStateNormal, LastEvalTime: now, EndsAt: now}
+	if firing {
+		instance.CurrentState = InstanceStateFiring
+		instance.StartsAt = now
+		instance.EndsAt = now.Add(alertTimeoutIntervals * time.Duration(def.IntervalSeconds) * time.Second)
+	}
+	env.dispatcher.put(newNotificationAlert(def, instance))
+}
+
+// advance moves the clock forward and flushes the due groups.
+func (env *dispatcherTestEnv) advance(d time.Duration) {
+	env.clock.Add(d)
+	env.dispatcher.flush(context.Background())
+}
+
+func (env *dispatcherTestEnv) lastNotification(t *testing.T) *groupNotification {
+	require.NotEmpty(t, env.notifications)
+	return env.notifications[len(env.notifications)-1]
+}
+
+func TestNotificationDispatcher(t *testing.T) {
+	def := &AlertDefinition{OrgID: 1, UID: "uid", Title: "high cpu", IntervalSeconds: 60}
+
+	t.Run("group is notified after group wait", func(t *testing.T) {
+		env := newDispatcherTestEnv(t)
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+
+		env.advance(5 * time.Second)
+		require.Empty(t, env.notifications)
+
+		env.evaluate(def, InstanceLabels{"host": "b"}, true)
+		env.advance(5 * time.Second)
+		require.Len(t, env.notifications, 1)
+
+		n := env.lastNotification(t)
+		assert.Equal(t, InstanceLabels{alertNameLabel: "high cpu"}, n.GroupLabels)
+		assert.Len(t, n.Firing, 2)
+		assert.Empty(t, n.Resolved)
+	})
+
+	t.Run("unchanged group is only notified again after repeat interval", func(t *testing.T) {
+		env := newDispatcherTestEnv(t)
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+		env.advance(10 * time.Second)
+		require.Len(t, env.notifications, 1)
+
+		for i := 0; i < 59; i++ {
+			env.evaluate(def, InstanceLabels{"host": "a"}, true)
+			env.advance(time.Minute)
+		}
+		require.Len(t, env.notifications, 1)
+
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+		env.advance(time.Minute)
+		require.Len(t, env.notifications, 2)
+		assert.Len(t, env.lastNotification(t).Firing, 1)
+	})
+
+	t.Run("resolved alerts are notified once", func(t *testing.T) {
+		env := newDispatcherTestEnv(t)
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+		env.advance(10 * time.Second)
+		require.Len(t, env.notifications, 1)
+
+		env.evaluate(def, InstanceLabels{"host": "a"}, false)
+		env.advance(time.Minute)
+		require.Len(t, env.notifications, 2)
+
+		n := env.lastNotification(t)
+		assert.Empty(t, n.Firing)
+		require.Len(t, n.Resolved, 1)
+		assert.Equal(t, "a", n.Resolved[0].Labels["host"])
+
+		env.advance(time.Minute)
+		require.Len(t, env.notifications, 2)
+		assert.Empty(t, env.dispatcher.groups)
+	})
+
+	t.Run("alerts resolve when they are not evaluated anymore", func(t *testing.T) {
+		env := newDispatcherTestEnv(t)
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+		env.advance(10 * time.Second)
+		require.Len(t, env.notifications, 1)
+
+		env.advance(time.Duration(alertTimeoutIntervals) * time.Minute)
+		require.Len(t, env.notifications, 2)
+		assert.Len(t, env.lastNotification(t).Resolved, 1)
+	})
+
+	t.Run("alerts resolved before being notified are dropped", func(t *testing.T) {
+		env := newDispatcherTestEnv(t)
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+		env.evaluate(def, InstanceLabels{"host": "a"}, false)
+		env.advance(10 * time.Second)
+
+		assert.Empty(t, env.notifications)
+		assert.Empty(t, env.dispatcher.groups)
+	})
+
+	t.Run("resolved alerts without group are ignored", func(t *testing.T) {
+		env := newDispatcherTestEnv(t)
+		env.evaluate(def, InstanceLabels{"host": "a"}, false)
+
+		assert.Empty(t, env.dispatcher.groups)
+	})
+
+	t.Run("silenced alerts are not notified", func(t *testing.T) {
+		env := newDispatcherTestEnv(t)
+		env.silences = []*Silence{{
+			Matchers: Matchers{{Name: "host", Type: MatchEqual, Value: "a"}},
+			StartsAt: env.clock.Now(),
+			EndsAt:   env.clock.Now().Add(time.Minute),
+		}}
+		require.NoError(t, env.silences[0].Matchers.init())
+
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+		env.evaluate(def, InstanceLabels{"host": "b"}, true)
+		env.advance(10 * time.Second)
+		require.Len(t, env.notifications, 1)
+		require.Len(t, env.lastNotification(t).Firing, 1)
+		assert.Equal(t, "b", env.lastNotification(t).Firing[0].Labels["host"])
+
+		// the alert is notified once the silence ends
+		env.evaluate(def, InstanceLabels{"host": "a"}, true)
+		env.evaluate(def, InstanceLabels{"host": "b"}, true)
+		env.advance(time.Minute)
+		require.Len(t, env.notifications, 2)
+		assert.Len(t, env.lastNotification(t).Firing, 2)
+	})
+}
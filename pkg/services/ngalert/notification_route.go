@@ -0,0 +1,264 @@
+package ngalert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+const (
+	// alertNameLabel is the label holding the alert definition title
+	// that is added to the labels of the instances for routing.
+	alertNameLabel = "alertname"
+
+	defaultGroupWait      = 30 * time.Second
+	defaultGroupInterval  = 5 * time.Minute
+	defaultRepeatInterval = 4 * time.Hour
+)
+
+var errInvalidMatcher = errors.New("invalid matcher")
+
+// MatchType is the type of comparison of a Matcher.
+type MatchType string
+
+const (
+	// MatchEqual matches labels equal to the value.
+	MatchEqual MatchType = "="
+	// MatchNotEqual matches labels not equal to the value.
+	MatchNotEqual MatchType = "!="
+	// MatchRegexp matches labels matching the regular expression.
+	MatchRegexp MatchType = "=~"
+	// MatchNotRegexp matches labels not matching the regular expression.
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher matches the value of a label of alert instances.
+// Missing labels are treated as empty labels.
+type Matcher struct {
+	Name  string    `json:"name"`
+	Type  MatchType `json:"type"`
+	Value string    `json:"value"`
+
+	re *regexp.Regexp
+}
+
+// init validates the matcher and compiles its regular expression.
+func (m *Matcher) init() error {
+	if m.Name == "" {
+		return fmt.Errorf("%w: missing label name", errInvalidMatcher)
+	}
+
+	switch m.Type {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidMatcher, err)
+		}
+		m.re = re
+	default:
+		return fmt.Errorf("%w: unknown match type %q", errInvalidMatcher, m.Type)
+	}
+
+	return nil
+}
+
+// Matches returns true if the labels match.
+func (m *Matcher) Matches(labels InstanceLabels) bool {
+	v := labels[m.Name]
+
+	switch m.Type {
+	case MatchEqual:
+		return v == m.Value
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(v)
+	case MatchNotRegexp:
+		return !m.re.MatchString(v)
+	}
+
+	return false
+}
+
+// String returns the matcher in the Prometheus selector syntax.
+func (m *Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// Matchers is a list of matchers that all have to match.
+type Matchers []*Matcher
+
+func (ms Matchers) init() error {
+	for _, m := range ms {
+		if err := m.init(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches returns true if all matchers match the labels.
+func (ms Matchers) Matches(labels InstanceLabels) bool {
+	for _, m := range ms {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// FromDB loads matchers stored in the database as json.
+// FromDB is part of the xorm Conversion interface.
+func (ms *Matchers) FromDB(b []byte) error {
+	if err := json.Unmarshal(b, ms); err != nil {
+		return err
+	}
+	return ms.init()
+}
+
+// ToDB serializes the matchers as json for the database.
+// ToDB is part of the xorm Conversion interface.
+func (ms *Matchers) ToDB() ([]byte, error) {
+	return json.Marshal(ms)
+}
+
+// Route is a node of the notification routing tree. Alert instances are
+// routed to the deepest routes whose matchers match their labels, and are
+// grouped there by the labels of GroupBy before being sent to the receiver.
+// Settings that are not set on a route are inherited from its parent.
+type Route struct {
+	// Receiver is the uid of the notification channel. If the root route has
+	// no receiver, notifications are sent to the default notification channels.
+	Receiver string   `json:"receiver,omitempty"`
+	Matchers Matchers `json:"matchers,omitempty"`
+	GroupBy  []string `json:"groupBy,omitempty"`
+
+	// GroupWait is how long to wait before sending the first notification of a group.
+	GroupWait eval.Duration `json:"groupWait,omitempty"`
+	// GroupInterval is how long to wait before sending the notification about
+	// changes of a group.
+	GroupInterval eval.Duration `json:"groupInterval,omitempty"`
+	// RepeatInterval is how long to wait before sending a notification again
+	// if the group has not changed.
+	RepeatInterval eval.Duration `json:"repeatInterval,omitempty"`
+
+	// Continue is whether matching should continue with the next sibling
+	// routes once this route matched.
+	Continue bool `json:"continue,omitempty"`
+
+	Routes []*Route `json:"routes,omitempty"`
+
+	id string
+}
+
+// defaultRoute returns the route used by organisations without a
+// notification policy.
+func defaultRoute() *Route {
+	r := &Route{GroupBy: []string{alertNameLabel}}
+	_ = r.init()
+	return r
+}
+
+// init validates the routing tree and resolves the inherited settings of
+// the routes. It should be called on the root route.
+func (r *Route) init() error {
+	if len(r.Matchers) > 0 {
+		return fmt.Errorf("the root route must not have matchers")
+	}
+	if r.Continue {
+		return fmt.Errorf("the root route must not have continue set")
+	}
+
+	root := &Route{
+		GroupWait:      eval.Duration(defaultGroupWait),
+		GroupInterval:  eval.Duration(defaultGroupInterval),
+		RepeatInterval: eval.Duration(defaultRepeatInterval),
+	}
+	return r.inherit(root, "0")
+}
+
+func (r *Route) inherit(parent *Route, id string) error {
+	if err := r.Matchers.init(); err != nil {
+		return err
+	}
+
+	if r.GroupWait < 0 || r.GroupInterval < 0 || r.RepeatInterval < 0 {
+		return fmt.Errorf("route %v has negative intervals", id)
+	}
+
+	seen := map[string]struct{}{}
+	for _, l := range r.GroupBy {
+		if _, ok := seen[l]; ok {
+			return fmt.Errorf("route %v groups by the label %q more than once", id, l)
+		}
+		seen[l] = struct{}{}
+	}
+
+	r.id = id
+	if r.Receiver == "" {
+		r.Receiver = parent.Receiver
+	}
+	if r.GroupBy == nil {
+		r.GroupBy = parent.GroupBy
+	}
+	if r.GroupWait == 0 {
+		r.GroupWait = parent.GroupWait
+	}
+	if r.GroupInterval == 0 {
+		r.GroupInterval = parent.GroupInterval
+	}
+	if r.RepeatInterval == 0 {
+		r.RepeatInterval = parent.RepeatInterval
+	}
+
+	for i, child := range r.Routes {
+		if child == nil {
+			return fmt.Errorf("route %v has an empty child route", id)
+		}
+		if err := child.inherit(r, id+"/"+strconv.Itoa(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// match returns the deepest routes matching the labels.
+func (r *Route) match(labels InstanceLabels) []*Route {
+	if !r.Matchers.Matches(labels) {
+		return nil
+	}
+
+	var all []*Route
+	for _, child := range r.Routes {
+		matches := child.match(labels)
+		all = append(all, matches...)
+
+		if matches != nil && !child.Continue {
+			break
+		}
+	}
+
+	if len(all) == 0 {
+		all = append(all, r)
+	}
+
+	return all
+}
+
+// groupLabels returns the labels the alert instances are grouped by.
+func (r *Route) groupLabels(labels InstanceLabels) InstanceLabels {
+	groupLabels := InstanceLabels{}
+	for _, l := range r.GroupBy {
+		if v, ok := labels[l]; ok {
+			groupLabels[l] = v
+		}
+	}
+	return groupLabels
+}
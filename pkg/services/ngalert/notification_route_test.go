@@ -0,0 +1,160 @@
+package ngalert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		matcher  Matcher
+		labels   InstanceLabels
+		expected bool
+	}{
+		{
+			desc:     "equal matches",
+			matcher:  Matcher{Name: "team", Type: MatchEqual, Value: "a"},
+			labels:   InstanceLabels{"team": "a"},
+			expected: true,
+		},
+		{
+			desc:     "equal does not match missing label",
+			matcher:  Matcher{Name: "team", Type: MatchEqual, Value: "a"},
+			labels:   InstanceLabels{},
+			expected: false,
+		},
+		{
+			desc:     "equal to empty value matches missing label",
+			matcher:  Matcher{Name: "team", Type: MatchEqual, Value: ""},
+			labels:   InstanceLabels{},
+			expected: true,
+		},
+		{
+			desc:     "not equal matches",
+			matcher:  Matcher{Name: "team", Type: MatchNotEqual, Value: "a"},
+			labels:   InstanceLabels{"team": "b"},
+			expected: true,
+		},
+		{
+			desc:     "regexp is anchored",
+			matcher:  Matcher{Name: "team", Type: MatchRegexp, Value: "a|b"},
+			labels:   InstanceLabels{"team": "abc"},
+			expected: false,
+		},
+		{
+			desc:     "regexp matches",
+			matcher:  Matcher{Name: "team", Type: MatchRegexp, Value: "a.*"},
+			labels:   InstanceLabels{"team": "abc"},
+			expected: true,
+		},
+		{
+			desc:     "not regexp matches",
+			matcher:  Matcher{Name: "team", Type: MatchNotRegexp, Value: "a.*"},
+			labels:   InstanceLabels{"team": "b"},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			m := tc.matcher
+			require.NoError(t, m.init())
+			assert.Equal(t, tc.expected, m.Matches(tc.labels))
+		})
+	}
+}
+
+func TestMatcherInit(t *testing.T) {
+	for _, m := range []Matcher{
+		{Type: MatchEqual, Value: "a"},
+		{Name: "team", Type: "==", Value: "a"},
+		{Name: "team", Type: MatchRegexp, Value: "("},
+	} {
+		err := m.init()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errInvalidMatcher)
+	}
+}
+
+func TestRouteInit(t *testing.T) {
+	t.Run("settings are inherited", func(t *testing.T) {
+		r := &Route{
+			Receiver:  "root",
+			GroupWait: eval.Duration(time.Minute),
+			Routes: []*Route{
+				{
+					Matchers: Matchers{{Name: "team", Type: MatchEqual, Value: "a"}},
+					GroupBy:  []string{"team"},
+					Routes: []*Route{
+						{Receiver: "child", RepeatInterval: eval.Duration(time.Hour)},
+					},
+				},
+			},
+		}
+		require.NoError(t, r.init())
+
+		assert.Equal(t, "0", r.id)
+		assert.Equal(t, eval.Duration(defaultGroupInterval), r.GroupInterval)
+
+		child := r.Routes[0]
+		assert.Equal(t, "0/0", child.id)
+		assert.Equal(t, "root", child.Receiver)
+		assert.Equal(t, eval.Duration(time.Minute), child.GroupWait)
+		assert.Equal(t, eval.Duration(defaultRepeatInterval), child.RepeatInterval)
+
+		grandChild := child.Routes[0]
+		assert.Equal(t, "0/0/0", grandChild.id)
+		assert.Equal(t, "child", grandChild.Receiver)
+		assert.Equal(t, []string{"team"}, grandChild.GroupBy)
+		assert.Equal(t, eval.Duration(time.Hour), grandChild.RepeatInterval)
+	})
+
+	t.Run("invalid routes", func(t *testing.T) {
+		for desc, r := range map[string]*Route{
+			"root with matchers": {Matchers: Matchers{{Name: "team", Type: MatchEqual}}},
+			"root with continue": {Continue: true},
+			"negative interval":  {Routes: []*Route{{GroupWait: eval.Duration(-time.Second)}}},
+			"duplicate group by": {GroupBy: []string{"team", "team"}},
+			"empty child route":  {Routes: []*Route{nil}},
+			"invalid matcher":    {Routes: []*Route{{Matchers: Matchers{{Name: "team", Type: "?"}}}}},
+		} {
+			assert.Error(t, r.init(), desc)
+		}
+	})
+}
+
+func TestRouteMatch(t *testing.T) {
+	r := &Route{
+		Routes: []*Route{
+			{
+				Receiver: "a",
+				Matchers: Matchers{{Name: "team", Type: MatchEqual, Value: "a"}},
+				Continue: true,
+				Routes: []*Route{
+					{Receiver: "a-critical", Matchers: Matchers{{Name: "severity", Type: MatchEqual, Value: "critical"}}},
+				},
+			},
+			{Receiver: "a-or-b", Matchers: Matchers{{Name: "team", Type: MatchRegexp, Value: "a|b"}}},
+			{Receiver: "b", Matchers: Matchers{{Name: "team", Type: MatchEqual, Value: "b"}}},
+		},
+	}
+	require.NoError(t, r.init())
+
+	receivers := func(labels InstanceLabels) []string {
+		res := make([]string, 0)
+		for _, m := range r.match(labels) {
+			res = append(res, m.Receiver)
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"a", "a-or-b"}, receivers(InstanceLabels{"team": "a"}))
+	assert.Equal(t, []string{"a-critical", "a-or-b"}, receivers(InstanceLabels{"team": "a", "severity": "critical"}))
+	assert.Equal(t, []string{"a-or-b"}, receivers(InstanceLabels{"team": "b"}))
+	assert.Equal(t, []string{""}, receivers(InstanceLabels{"team": "c"}))
+}
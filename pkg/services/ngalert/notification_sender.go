@@ -0,0 +1,105 @@
+package ngalert
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+// sendGroupNotification delivers a group notification through the
+// notification channel of its receiver, or through the default
+// notification channels of the organisation if it has no receiver.
+func (ng *AlertNG) sendGroupNotification(ctx context.Context, n *groupNotification) error {
+	channels, err := getNotificationChannels(n.OrgID, n.Receiver)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, channel := range channels {
+		notifier, err := alerting.InitNotifier(channel)
+		if err != nil {
+			ng.log.Error("failed to create notifier", "org", n.OrgID, "receiver", channel.Uid, "error", err)
+			lastErr = err
+			continue
+		}
+
+		if len(n.Firing) == 0 && notifier.GetDisableResolveMessage() {
+			continue
+		}
+
+		if err := notifier.Notify(n.evalContext(ctx)); err != nil {
+			ng.log.Error("failed to send notification", "org", n.OrgID, "receiver", channel.Uid, "error", err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func getNotificationChannels(orgID int64, receiver string) ([]*models.AlertNotification, error) {
+	if receiver != "" {
+		query := &models.GetAlertNotificationsWithUidQuery{OrgId: orgID, Uid: receiver}
+		if err := bus.Dispatch(query); err != nil {
+			return nil, err
+		}
+		if query.Result == nil {
+			return nil, fmt.Errorf("notification channel %q not found", receiver)
+		}
+		return []*models.AlertNotification{query.Result}, nil
+	}
+
+	query := &models.GetAllAlertNotificationsQuery{OrgId: orgID}
+	if err := bus.Dispatch(query); err != nil {
+		return nil, err
+	}
+
+	channels := make([]*models.AlertNotification, 0)
+	for _, channel := range query.Result {
+		if channel.IsDefault {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+// evalContext returns the legacy alerting evaluation context for the
+// notification so that the existing notifiers can deliver it.
+func (n *groupNotification) evalContext(ctx context.Context) *alerting.EvalContext {
+	rule := &alerting.Rule{
+		OrgID:   n.OrgID,
+		Name:    n.title(),
+		Message: fmt.Sprintf("%d firing, %d resolved", len(n.Firing), len(n.Resolved)),
+		State:   models.AlertStateOK,
+	}
+	prevState := models.AlertStateAlerting
+	if len(n.Firing) > 0 {
+		rule.State = models.AlertStateAlerting
+		prevState = models.AlertStateOK
+	}
+
+	evalCtx := alerting.NewEvalContext(ctx, rule)
+	evalCtx.PrevAlertState = prevState
+	evalCtx.Firing = len(n.Firing) > 0
+
+	for _, a := range n.Firing {
+		tags := make(map[string]string, len(a.Labels))
+		for k, v := range a.Labels {
+			if k == alertNameLabel {
+				continue
+			}
+			tags[k] = v
+		}
+		evalCtx.EvalMatches = append(evalCtx.EvalMatches, &alerting.EvalMatch{
+			Metric: a.DefinitionTitle,
+			Value:  null.FloatFromPtr(nil),
+			Tags:   tags,
+		})
+	}
+
+	return evalCtx
+}
@@ -0,0 +1,85 @@
+package ngalert
+
+import (
+	"fmt"
+	"time"
+)
+
+// Silence mutes the notifications of the alert instances matching its
+// matchers between its start and end time.
+type Silence struct {
+	ID        int64     `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID     int64     `xorm:"org_id" json:"orgId"`
+	UID       string    `xorm:"uid" json:"uid"`
+	Matchers  Matchers  `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	Comment   string    `json:"comment"`
+	CreatedBy int64     `json:"createdBy"`
+	Created   time.Time `json:"created"`
+}
+
+// isActive returns true if the silence mutes alerts at the given time.
+func (s *Silence) isActive(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// mutes returns true if the silence mutes the labels at the given time.
+func (s *Silence) mutes(labels InstanceLabels, now time.Time) bool {
+	return s.isActive(now) && s.Matchers.Matches(labels)
+}
+
+// createSilenceCommand is the command for creating a silence.
+type createSilenceCommand struct {
+	OrgID     int64     `json:"-"`
+	CreatedBy int64     `json:"-"`
+	Matchers  Matchers  `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	Comment   string    `json:"comment"`
+
+	Result *Silence
+}
+
+// listSilencesQuery is the query for listing the silences of an organisation.
+type listSilencesQuery struct {
+	OrgID int64
+	// ActiveAt filters out the silences that are not active at that time
+	// when it is set.
+	ActiveAt time.Time
+
+	Result []*Silence
+}
+
+// expireSilenceCommand is the command for ending a silence now.
+type expireSilenceCommand struct {
+	OrgID int64
+	UID   string
+}
+
+// validate checks that the silence has valid matchers and time range.
+func (cmd *createSilenceCommand) validate() error {
+	if len(cmd.Matchers) == 0 {
+		return fmt.Errorf("silence has no matchers")
+	}
+	if err := cmd.Matchers.init(); err != nil {
+		return err
+	}
+	if cmd.StartsAt.IsZero() {
+		cmd.StartsAt = timeNow()
+	}
+	if !cmd.EndsAt.After(cmd.StartsAt) {
+		return fmt.Errorf("silence ends before it starts")
+	}
+	return nil
+}
+
+// isSilenced returns true if any of the silences mutes the labels at the given time.
+func isSilenced(silences []*Silence, labels InstanceLabels, now time.Time) bool {
+	for _, s := range silences {
+		if s.mutes(labels, now) {
+			return true
+		}
+	}
+	return false
+}
@@ -50,18 +50,28 @@ func (ng *AlertNG) definitionRoutine(grafanaCtx context.Context, key alertDefini
 				results, err := eval.ConditionEval(&condition, ctx.now)
 				end = timeNow()
 				if err != nil {
-					// consider saving alert instance on error
 					ng.schedule.log.Error("failed to evaluate alert definition", "title", alertDefinition.Title, "key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
+					// the instances are set to the error state after the last attempt
+					if attempt < ng.schedule.maxAttempts-1 {
+						return err
+					}
+					updates, stateErr := ng.stateManager.processError(alertDefinition, err, ctx.now)
+					if stateErr != nil {
+						ng.schedule.log.Error("failed to update alert instance states", "title", alertDefinition.Title, "key", key, "error", stateErr)
+						return err
+					}
+					ng.saveStateUpdates(alertDefinition, updates)
 					return err
 				}
 				for _, r := range results {
 					ng.schedule.log.Debug("alert definition result", "title", alertDefinition.Title, "key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "instance", r.Instance, "state", r.State.String())
-					cmd := saveAlertInstanceCommand{DefinitionOrgID: key.orgID, DefinitionUID: key.definitionUID, State: InstanceStateType(r.State.String()), Labels: InstanceLabels(r.Instance), LastEvalTime: ctx.now}
-					err := ng.saveAlertInstance(&cmd)
-					if err != nil {
-						ng.schedule.log.Error("failed saving alert instance", "title", alertDefinition.Title, "key", key, "attempt", attempt, "now", ctx.now, "instance", r.Instance, "state", r.State.String(), "error", err)
-					}
 				}
+				updates, err := ng.stateManager.process(alertDefinition, results, ctx.now)
+				if err != nil {
+					ng.schedule.log.Error("failed to update alert instance states", "title", alertDefinition.Title, "key", key, "error", err)
+					return err
+				}
+				ng.saveStateUpdates(alertDefinition, updates)
 				return nil
 			}
 
@@ -82,6 +92,7 @@ func (ng *AlertNG) definitionRoutine(grafanaCtx context.Context, key alertDefini
 				}
 			}()
 		case <-stopCh:
+			ng.stateManager.remove(key)
 			if ng.schedule.stopApplied != nil {
 				ng.schedule.stopApplied(key)
 			}
@@ -94,6 +105,40 @@ func (ng *AlertNG) definitionRoutine(grafanaCtx context.Context, key alertDefini
 	}
 }
 
+// saveStateUpdates persists the states of the alert instances, appends their
+// state changes to the state history, and hands the instances over to the
+// notification dispatcher.
+func (ng *AlertNG) saveStateUpdates(alertDefinition *AlertDefinition, updates []*stateUpdate) {
+	changes := make([]*AlertInstanceStateChange, 0)
+	for _, u := range updates {
+		cmd := saveAlertInstanceCommand{
+			DefinitionOrgID: u.Instance.DefinitionOrgID,
+			DefinitionUID:   u.Instance.DefinitionUID,
+			Labels:          u.Instance.Labels,
+			State:           u.Instance.CurrentState,
+			StateSince:      u.Instance.CurrentStateSince,
+			LastEvalTime:    u.Instance.LastEvalTime,
+			StartsAt:        u.Instance.StartsAt,
+			EndsAt:          u.Instance.EndsAt,
+		}
+		if err := ng.saveAlertInstance(&cmd); err != nil {
+			ng.schedule.log.Error("failed saving alert instance", "title", alertDefinition.Title, "key", alertDefinition.getKey(), "instance", u.Instance.Labels, "state", u.Instance.CurrentState, "error", err)
+		}
+
+		if u.changed() {
+			changes = append(changes, newAlertInstanceStateChange(u))
+		}
+
+		if ng.dispatcher != nil {
+			ng.dispatcher.put(newNotificationAlert(alertDefinition, u.Instance))
+		}
+	}
+
+	if err := ng.saveAlertInstanceStateChanges(&saveAlertInstanceStateChangesCommand{Changes: changes}); err != nil {
+		ng.schedule.log.Error("failed saving alert instance state history", "title", alertDefinition.Title, "key", alertDefinition.getKey(), "error", err)
+	}
+}
+
 type schedule struct {
 	// base tick rate (fastest possible configured check)
 	baseInterval time.Duration
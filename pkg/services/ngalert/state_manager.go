@@ -0,0 +1,222 @@
+package ngalert
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+// stateUpdate is the result of applying an evaluation to an alert instance.
+type stateUpdate struct {
+	// Instance is a copy of the instance after the evaluation.
+	Instance  *AlertInstance
+	PrevState InstanceStateType
+	// Error is the evaluation error if the evaluation failed.
+	Error error
+}
+
+// changed returns true if the evaluation changed the state of the instance.
+func (u *stateUpdate) changed() bool {
+	return u.PrevState != u.Instance.CurrentState
+}
+
+// stateManager keeps the state of the alert instances between evaluations
+// and applies the state transitions of the evaluation results.
+// The states of the instances of an alert definition are loaded from the
+// database on its first evaluation.
+type stateManager struct {
+	mu     sync.Mutex
+	states map[alertDefinitionKey]map[string]*AlertInstance
+
+	load func(key alertDefinitionKey) ([]*AlertInstance, error)
+	log  log.Logger
+}
+
+func newStateManager(logger log.Logger, load func(alertDefinitionKey) ([]*AlertInstance, error)) *stateManager {
+	return &stateManager{
+		states: map[alertDefinitionKey]map[string]*AlertInstance{},
+		load:   load,
+		log:    logger,
+	}
+}
+
+// process applies the evaluation results of an alert definition to the states
+// of its instances. Known instances that are missing from the results are
+// evaluated as normal, unless the condition returned no data at all, in which
+// case all known instances are evaluated as having no data.
+func (m *stateManager) process(alertDefinition *AlertDefinition, results eval.Results, now time.Time) ([]*stateUpdate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	instances, err := m.instances(alertDefinition.getKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instances) > 0 && len(results) == 1 && results[0].State == eval.NoData && len(results[0].Instance) == 0 {
+		updates := make([]*stateUpdate, 0, len(instances))
+		for _, instance := range instances {
+			updates = append(updates, instance.transition(alertDefinition, eval.NoData, now, nil))
+		}
+		return updates, nil
+	}
+
+	updates := make([]*stateUpdate, 0, len(results))
+	seen := make(map[string]struct{}, len(results))
+	for _, r := range results {
+		labels := InstanceLabels(r.Instance)
+		_, hash, err := labels.StringAndHash()
+		if err != nil {
+			return nil, err
+		}
+		seen[hash] = struct{}{}
+
+		instance, ok := instances[hash]
+		if !ok {
+			instance = newAlertInstance(alertDefinition, labels, hash, now)
+			instances[hash] = instance
+		}
+		updates = append(updates, instance.transition(alertDefinition, r.State, now, nil))
+	}
+
+	for hash, instance := range instances {
+		if _, ok := seen[hash]; ok || instance.CurrentState == InstanceStateNormal {
+			continue
+		}
+		updates = append(updates, instance.transition(alertDefinition, eval.Normal, now, nil))
+	}
+
+	return updates, nil
+}
+
+// processError applies a failed evaluation of an alert definition to the
+// states of all its instances. If the alert definition has no instances yet,
+// it is applied to an instance without labels.
+func (m *stateManager) processError(alertDefinition *AlertDefinition, evalErr error, now time.Time) ([]*stateUpdate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	instances, err := m.instances(alertDefinition.getKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instances) == 0 {
+		labels := InstanceLabels{}
+		_, hash, err := labels.StringAndHash()
+		if err != nil {
+			return nil, err
+		}
+		instances[hash] = newAlertInstance(alertDefinition, labels, hash, now)
+	}
+
+	updates := make([]*stateUpdate, 0, len(instances))
+	for _, instance := range instances {
+		updates = append(updates, instance.transition(alertDefinition, eval.Error, now, evalErr))
+	}
+	return updates, nil
+}
+
+// remove drops the states of the instances of an alert definition.
+func (m *stateManager) remove(key alertDefinitionKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.states, key)
+}
+
+func (m *stateManager) instances(key alertDefinitionKey) (map[string]*AlertInstance, error) {
+	if instances, ok := m.states[key]; ok {
+		return instances, nil
+	}
+
+	loaded, err := m.load(key)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make(map[string]*AlertInstance, len(loaded))
+	for _, instance := range loaded {
+		instances[instance.LabelsHash] = instance
+	}
+	m.states[key] = instances
+	m.log.Debug("alert instance states loaded", "key", key, "instances", len(instances))
+	return instances, nil
+}
+
+func newAlertInstance(alertDefinition *AlertDefinition, labels InstanceLabels, hash string, now time.Time) *AlertInstance {
+	return &AlertInstance{
+		DefinitionOrgID:   alertDefinition.OrgID,
+		DefinitionUID:     alertDefinition.UID,
+		Labels:            labels,
+		LabelsHash:        hash,
+		CurrentState:      InstanceStateNormal,
+		CurrentStateSince: now,
+	}
+}
+
+// transition applies the evaluation state to the instance. An instance whose
+// condition becomes true is pending until the condition has been true for the
+// for duration of the alert definition, and alerting after that.
+func (instance *AlertInstance) transition(alertDefinition *AlertDefinition, evalState eval.State, now time.Time, evalErr error) *stateUpdate {
+	prev := instance.CurrentState
+	next := alertDefinition.instanceState(prev, evalState)
+
+	if next == InstanceStateFiring && prev != InstanceStateFiring && alertDefinition.ForSeconds > 0 {
+		forDuration := time.Duration(alertDefinition.ForSeconds) * time.Second
+		if prev != InstanceStatePending || now.Sub(instance.CurrentStateSince) < forDuration {
+			next = InstanceStatePending
+		}
+	}
+
+	instance.LastEvalTime = now
+	if next != prev {
+		instance.CurrentState = next
+		instance.CurrentStateSince = now
+		if next == InstanceStateFiring {
+			instance.StartsAt = now
+		}
+		if prev == InstanceStateFiring {
+			instance.EndsAt = now
+		}
+	}
+	if next == InstanceStateFiring {
+		instance.EndsAt = now.Add(alertTimeoutIntervals * time.Duration(alertDefinition.IntervalSeconds) * time.Second)
+	}
+
+	updated := *instance
+	return &stateUpdate{Instance: &updated, PrevState: prev, Error: evalErr}
+}
+
+// instanceState returns the state an instance in the current state is set to
+// by the evaluation state, according to the no data and execution error
+// settings of the alert definition.
+func (alertDefinition *AlertDefinition) instanceState(current InstanceStateType, evalState eval.State) InstanceStateType {
+	switch evalState {
+	case eval.Alerting:
+		return InstanceStateFiring
+	case eval.NoData:
+		switch alertDefinition.NoDataState {
+		case NoDataAlerting:
+			return InstanceStateFiring
+		case NoDataNormal:
+			return InstanceStateNormal
+		case NoDataKeepLastState:
+			return current
+		default:
+			return InstanceStateNoData
+		}
+	case eval.Error:
+		switch alertDefinition.ExecErrState {
+		case ExecErrAlerting:
+			return InstanceStateFiring
+		case ExecErrKeepLastState:
+			return current
+		default:
+			return InstanceStateError
+		}
+	}
+	return InstanceStateNormal
+}
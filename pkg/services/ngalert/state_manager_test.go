@@ -0,0 +1,222 @@
+package ngalert
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertInstanceTransition(t *testing.T) {
+	now := time.Unix(1000, 0)
+	interval := time.Minute
+
+	testCases := []struct {
+		desc          string
+		definition    AlertDefinition
+		evalStates    []eval.State
+		expected      []InstanceStateType
+		expectedSince time.Time
+	}{
+		{
+			desc:       "alerting without for duration fires immediately",
+			definition: AlertDefinition{},
+			evalStates: []eval.State{eval.Alerting},
+			expected:   []InstanceStateType{InstanceStateFiring},
+		},
+		{
+			desc:       "alerting is pending for the for duration",
+			definition: AlertDefinition{ForSeconds: 120},
+			evalStates: []eval.State{eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting},
+			expected:   []InstanceStateType{InstanceStatePending, InstanceStatePending, InstanceStateFiring, InstanceStateFiring},
+		},
+		{
+			desc:       "pending instance that is normal again does not fire",
+			definition: AlertDefinition{ForSeconds: 120},
+			evalStates: []eval.State{eval.Alerting, eval.Normal, eval.Alerting, eval.Alerting},
+			expected:   []InstanceStateType{InstanceStatePending, InstanceStateNormal, InstanceStatePending, InstanceStatePending},
+		},
+		{
+			desc:       "no data",
+			definition: AlertDefinition{NoDataState: NoData},
+			evalStates: []eval.State{eval.Alerting, eval.NoData},
+			expected:   []InstanceStateType{InstanceStateFiring, InstanceStateNoData},
+		},
+		{
+			desc:       "no data as alerting",
+			definition: AlertDefinition{NoDataState: NoDataAlerting},
+			evalStates: []eval.State{eval.NoData},
+			expected:   []InstanceStateType{InstanceStateFiring},
+		},
+		{
+			desc:       "no data as normal",
+			definition: AlertDefinition{NoDataState: NoDataNormal},
+			evalStates: []eval.State{eval.Alerting, eval.NoData},
+			expected:   []InstanceStateType{InstanceStateFiring, InstanceStateNormal},
+		},
+		{
+			desc:       "no data keeps last state",
+			definition: AlertDefinition{NoDataState: NoDataKeepLastState},
+			evalStates: []eval.State{eval.Alerting, eval.NoData, eval.NoData},
+			expected:   []InstanceStateType{InstanceStateFiring, InstanceStateFiring, InstanceStateFiring},
+		},
+		{
+			desc:       "error",
+			definition: AlertDefinition{ExecErrState: ExecErrError},
+			evalStates: []eval.State{eval.Error, eval.Normal},
+			expected:   []InstanceStateType{InstanceStateError, InstanceStateNormal},
+		},
+		{
+			desc:       "error as alerting respects the for duration",
+			definition: AlertDefinition{ExecErrState: ExecErrAlerting, ForSeconds: 60},
+			evalStates: []eval.State{eval.Error, eval.Error},
+			expected:   []InstanceStateType{InstanceStatePending, InstanceStateFiring},
+		},
+		{
+			desc:       "error keeps last state",
+			definition: AlertDefinition{ExecErrState: ExecErrKeepLastState},
+			evalStates: []eval.State{eval.Error, eval.Alerting, eval.Error},
+			expected:   []InstanceStateType{InstanceStateNormal, InstanceStateFiring, InstanceStateFiring},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			def := tc.definition
+			def.IntervalSeconds = int64(interval.Seconds())
+			instance := newAlertInstance(&def, InstanceLabels{}, "", now)
+
+			evalTime := now
+			for i, s := range tc.evalStates {
+				instance.transition(&def, s, evalTime, nil)
+				require.Equal(t, tc.expected[i], instance.CurrentState, "evaluation %d", i)
+				evalTime = evalTime.Add(interval)
+			}
+		})
+	}
+}
+
+func TestAlertInstanceTransitionTimes(t *testing.T) {
+	def := &AlertDefinition{IntervalSeconds: 60}
+	now := time.Unix(1000, 0)
+	instance := newAlertInstance(def, InstanceLabels{}, "", now)
+
+	u := instance.transition(def, eval.Normal, now, nil)
+	assert.False(t, u.changed())
+	assert.True(t, instance.StartsAt.IsZero())
+
+	firing := now.Add(time.Minute)
+	u = instance.transition(def, eval.Alerting, firing, nil)
+	assert.True(t, u.changed())
+	assert.Equal(t, InstanceStateNormal, u.PrevState)
+	assert.Equal(t, firing, instance.StartsAt)
+	assert.Equal(t, firing, instance.CurrentStateSince)
+	assert.Equal(t, firing.Add(alertTimeoutIntervals*time.Minute), instance.EndsAt)
+
+	stillFiring := firing.Add(time.Minute)
+	u = instance.transition(def, eval.Alerting, stillFiring, nil)
+	assert.False(t, u.changed())
+	assert.Equal(t, firing, instance.StartsAt)
+	assert.Equal(t, firing, instance.CurrentStateSince)
+	assert.Equal(t, stillFiring, instance.LastEvalTime)
+	assert.Equal(t, stillFiring.Add(alertTimeoutIntervals*time.Minute), instance.EndsAt)
+
+	resolved := stillFiring.Add(time.Minute)
+	u = instance.transition(def, eval.Normal, resolved, nil)
+	assert.True(t, u.changed())
+	assert.Equal(t, InstanceStateFiring, u.PrevState)
+	assert.Equal(t, firing, instance.StartsAt)
+	assert.Equal(t, resolved, instance.EndsAt)
+}
+
+func TestStateManager(t *testing.T) {
+	def := &AlertDefinition{OrgID: 1, UID: "uid", IntervalSeconds: 60, NoDataState: NoData, ExecErrState: ExecErrError}
+	now := time.Unix(1000, 0)
+
+	stored := &AlertInstance{
+		DefinitionOrgID: 1,
+		DefinitionUID:   "uid",
+		Labels:          InstanceLabels{"host": "a"},
+		CurrentState:    InstanceStateFiring,
+	}
+	_, stored.LabelsHash, _ = stored.Labels.StringAndHash()
+
+	newManager := func() *stateManager {
+		loaded := *stored
+		return newStateManager(log.New("ngalert.state.test"), func(key alertDefinitionKey) ([]*AlertInstance, error) {
+			require.Equal(t, def.getKey(), key)
+			return []*AlertInstance{&loaded}, nil
+		})
+	}
+
+	states := func(updates []*stateUpdate) map[string]InstanceStateType {
+		res := map[string]InstanceStateType{}
+		for _, u := range updates {
+			res[u.Instance.Labels["host"]] = u.Instance.CurrentState
+		}
+		return res
+	}
+
+	t.Run("stored states are resumed", func(t *testing.T) {
+		m := newManager()
+		updates, err := m.process(def, eval.Results{
+			{Instance: data.Labels{"host": "a"}, State: eval.Alerting},
+			{Instance: data.Labels{"host": "b"}, State: eval.Normal},
+		}, now)
+		require.NoError(t, err)
+		require.Len(t, updates, 2)
+		for _, u := range updates {
+			assert.False(t, u.changed())
+		}
+		assert.Equal(t, map[string]InstanceStateType{"a": InstanceStateFiring, "b": InstanceStateNormal}, states(updates))
+	})
+
+	t.Run("missing instances are normal", func(t *testing.T) {
+		m := newManager()
+		updates, err := m.process(def, eval.Results{
+			{Instance: data.Labels{"host": "b"}, State: eval.Alerting},
+		}, now)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]InstanceStateType{"a": InstanceStateNormal, "b": InstanceStateFiring}, states(updates))
+	})
+
+	t.Run("no data applies to all instances", func(t *testing.T) {
+		m := newManager()
+		updates, err := m.process(def, eval.Results{
+			{Instance: data.Labels{}, State: eval.NoData},
+		}, now)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]InstanceStateType{"a": InstanceStateNoData}, states(updates))
+	})
+
+	t.Run("errors apply to all instances", func(t *testing.T) {
+		m := newManager()
+		evalErr := errors.New("datasource unavailable")
+		updates, err := m.processError(def, evalErr, now)
+		require.NoError(t, err)
+		require.Len(t, updates, 1)
+		assert.Equal(t, InstanceStateError, updates[0].Instance.CurrentState)
+		assert.Equal(t, evalErr, updates[0].Error)
+
+		change := newAlertInstanceStateChange(updates[0])
+		assert.Equal(t, InstanceStateFiring, change.PrevState)
+		assert.Equal(t, InstanceStateError, change.State)
+		assert.Equal(t, "datasource unavailable", change.Error)
+	})
+
+	t.Run("errors without instances apply to an instance without labels", func(t *testing.T) {
+		m := newStateManager(log.New("ngalert.state.test"), func(alertDefinitionKey) ([]*AlertInstance, error) {
+			return nil, nil
+		})
+		updates, err := m.processError(def, errors.New("failure"), now)
+		require.NoError(t, err)
+		require.Len(t, updates, 1)
+		assert.Empty(t, updates[0].Instance.Labels)
+		assert.Equal(t, InstanceStateError, updates[0].Instance.CurrentState)
+	})
+}
@@ -28,6 +28,18 @@ func (ng *AlertNG) validateAlertDefinition(alertDefinition *AlertDefinition, req
 		return fmt.Errorf("invalid interval: %v: interval should be divided exactly by scheduler interval: %v", time.Duration(alertDefinition.IntervalSeconds)*time.Second, ng.schedule.baseInterval)
 	}
 
+	if alertDefinition.ForSeconds < 0 {
+		return fmt.Errorf("invalid for: %v: it should not be negative", time.Duration(alertDefinition.ForSeconds)*time.Second)
+	}
+
+	if !alertDefinition.NoDataState.IsValid() {
+		return fmt.Errorf("invalid no data state: %q", alertDefinition.NoDataState)
+	}
+
+	if !alertDefinition.ExecErrState.IsValid() {
+		return fmt.Errorf("invalid execution error state: %q", alertDefinition.ExecErrState)
+	}
+
 	// enfore max name length in SQLite
 	if len(alertDefinition.Title) > alertDefinitionMaxTitleLength {
 		return fmt.Errorf("name length should not be greater than %d", alertDefinitionMaxTitleLength)
@@ -22,6 +22,7 @@ var mailTemplates *template.Template
 var tmplResetPassword = "reset_password.html"
 var tmplSignUpStarted = "signup_started.html"
 var tmplWelcomeOnSignUp = "welcome_on_signup.html"
+var tmplUserLockedOut = "user_locked_out.html"
 
 func init() {
 	registry.RegisterService(&NotificationService{})
@@ -42,6 +43,7 @@ func (ns *NotificationService) Init() error {
 	ns.webhookQueue = make(chan *Webhook, 10)
 
 	ns.Bus.AddHandler(ns.sendResetPasswordEmail)
+	ns.Bus.AddHandler(ns.sendUserLockedOutEmail)
 	ns.Bus.AddHandler(ns.validateResetPasswordCode)
 	ns.Bus.AddHandler(ns.sendEmailCommandHandler)
 
@@ -109,6 +111,7 @@ func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.
 		HttpMethod:  cmd.HttpMethod,
 		HttpHeader:  cmd.HttpHeader,
 		ContentType: cmd.ContentType,
+		Validation:  cmd.Validation,
 	})
 }
 
@@ -163,6 +166,17 @@ func (ns *NotificationService) sendResetPasswordEmail(cmd *models.SendResetPassw
 	})
 }
 
+func (ns *NotificationService) sendUserLockedOutEmail(cmd *models.SendUserLockedOutEmailCommand) error {
+	return ns.sendEmailCommandHandler(&models.SendEmailCommand{
+		To:       []string{cmd.User.Email},
+		Template: tmplUserLockedOut,
+		Data: map[string]interface{}{
+			"Name":        cmd.User.NameOrFallback(),
+			"LockedUntil": cmd.LockedUntil.UTC().Format("2006-01-02 15:04:05 MST"),
+		},
+	})
+}
+
 func (ns *NotificationService) validateResetPasswordCode(query *models.ValidateResetPasswordCodeQuery) error {
 	login := getLoginForEmailCode(query.Code)
 	if login == "" {
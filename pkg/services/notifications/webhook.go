@@ -24,6 +24,7 @@ type Webhook struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	Validation  func(body []byte, statusCode int) error
 }
 
 var netTransport = &http.Transport{
@@ -82,6 +83,18 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		}
 	}()
 
+	if webhook.Validation != nil {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if err := webhook.Validation(body, resp.StatusCode); err != nil {
+			ns.log.Debug("Webhook failed validation", "url", webhook.Url, "statuscode", resp.Status, "body", string(body))
+			return fmt.Errorf("webhook failed validation: %w", err)
+		}
+		return nil
+	}
+
 	if resp.StatusCode/100 == 2 {
 		ns.log.Debug("Webhook succeeded", "url", webhook.Url, "statuscode", resp.Status)
 		// flushing the body enables the transport to reuse the same connection
@@ -0,0 +1,54 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendWebRequestSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"id":"post1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	ns := &NotificationService{log: log.New("test")}
+
+	t.Run("should fail on a status code other than 2xx", func(t *testing.T) {
+		err := ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, Body: "{}"})
+		require.EqualError(t, err, "Webhook response status 400 Bad Request")
+	})
+
+	t.Run("should pass the response to the validation", func(t *testing.T) {
+		var body []byte
+		var statusCode int
+		err := ns.sendWebRequestSync(context.Background(), &Webhook{
+			Url:  server.URL,
+			Body: "{}",
+			Validation: func(b []byte, code int) error {
+				body, statusCode = b, code
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"id":"post1"}`, string(body))
+		require.Equal(t, http.StatusBadRequest, statusCode)
+	})
+
+	t.Run("should fail when the validation fails", func(t *testing.T) {
+		err := ns.sendWebRequestSync(context.Background(), &Webhook{
+			Url:  server.URL,
+			Body: "{}",
+			Validation: func(b []byte, code int) error {
+				return errors.New("missing post")
+			},
+		})
+		require.EqualError(t, err, "webhook failed validation: missing post")
+	})
+}
@@ -157,9 +157,10 @@ func validateNotifications(notifications []*notificationsAsConfig) error {
 		}
 
 		for _, notification := range notifications[i].Notifications {
+			settings := notification.SettingsToJSON()
 			_, err := alerting.InitNotifier(&models.AlertNotification{
 				Name:           notification.Name,
-				Settings:       notification.SettingsToJSON(),
+				Settings:       settings,
 				SecureSettings: securejsondata.GetEncryptedJsonData(notification.SecureSettings),
 				Type:           notification.Type,
 			})
@@ -167,6 +168,10 @@ func validateNotifications(notifications []*notificationsAsConfig) error {
 			if err != nil {
 				return err
 			}
+
+			if _, err := alerting.ParseNotificationTemplates(settings); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -24,6 +24,11 @@ var (
 	emptyFile                    = "./testdata/test-configs/empty"
 	twoNotificationsConfig       = "./testdata/test-configs/two-notifications"
 	unknownNotifier              = "./testdata/test-configs/unknown-notifier"
+	chatNotifiers                = "./testdata/test-configs/chat-notifiers"
+	incorrectChatSettings        = "./testdata/test-configs/incorrect-chat-settings"
+	snsHTTPNotifier              = "./testdata/test-configs/sns-http-notifier"
+	incorrectSNSHTTPSettings     = "./testdata/test-configs/incorrect-sns-http-settings"
+	incorrectTemplate            = "./testdata/test-configs/incorrect-template"
 )
 
 func TestNotificationAsConfig(t *testing.T) {
@@ -323,5 +328,95 @@ func TestNotificationAsConfig(t *testing.T) {
 			So(err, ShouldNotBeNil)
 			So(err.Error(), ShouldEqual, "alert validation error: Could not find url property in settings")
 		})
+
+		Convey("Read incorrect template", func() {
+			cfgProvider := &configReader{log: log.New("test logger")}
+			_, err := cfgProvider.readConfig(incorrectTemplate)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "alert validation error: invalid titleTemplate")
+		})
+
+		Convey("Chat notifiers", func() {
+			alerting.RegisterNotifier(&alerting.NotifierPlugin{
+				Type:    "mattermost",
+				Name:    "mattermost",
+				Factory: notifiers.NewMattermostNotifier,
+			})
+
+			alerting.RegisterNotifier(&alerting.NotifierPlugin{
+				Type:    "zulip",
+				Name:    "zulip",
+				Factory: notifiers.NewZulipNotifier,
+			})
+
+			alerting.RegisterNotifier(&alerting.NotifierPlugin{
+				Type:    "teams",
+				Name:    "teams",
+				Factory: notifiers.NewTeamsNotifier,
+			})
+
+			Convey("should be provisioned", func() {
+				dc := newNotificationProvisioner(logger)
+				err := dc.applyChanges(chatNotifiers)
+				if err != nil {
+					t.Fatalf("applyChanges return an error %v", err)
+				}
+
+				notificationsQuery := models.GetAllAlertNotificationsQuery{OrgId: 1}
+				err = sqlstore.GetAllAlertNotifications(&notificationsQuery)
+				So(err, ShouldBeNil)
+				So(len(notificationsQuery.Result), ShouldEqual, 4)
+
+				for _, notification := range notificationsQuery.Result {
+					_, err := alerting.InitNotifier(notification)
+					So(err, ShouldBeNil)
+				}
+
+				query := models.GetAlertNotificationsWithUidQuery{OrgId: 1, Uid: "zulip1"}
+				err = sqlstore.GetAlertNotificationsWithUid(&query)
+				So(err, ShouldBeNil)
+				So(query.Result.Settings.Get("stream").MustString(), ShouldEqual, "ops")
+				So(query.Result.DecryptedValue("apiKey", ""), ShouldEqual, "xxx")
+			})
+
+			Convey("with incorrect settings should return error", func() {
+				cfgProvider := &configReader{log: log.New("test logger")}
+				_, err := cfgProvider.readConfig(incorrectChatSettings)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "alert validation error: Could not find apiKey property in settings")
+			})
+		})
+
+		Convey("SNS-style HTTP notifier", func() {
+			alerting.RegisterNotifier(&alerting.NotifierPlugin{
+				Type:    "sns-http",
+				Name:    "sns-http",
+				Factory: notifiers.NewSNSHTTPNotifier,
+			})
+
+			Convey("should be provisioned", func() {
+				dc := newNotificationProvisioner(logger)
+				err := dc.applyChanges(snsHTTPNotifier)
+				if err != nil {
+					t.Fatalf("applyChanges return an error %v", err)
+				}
+
+				query := models.GetAlertNotificationsWithUidQuery{OrgId: 1, Uid: "snshttp1"}
+				err = sqlstore.GetAlertNotificationsWithUid(&query)
+				So(err, ShouldBeNil)
+				So(query.Result.Settings.Get("topic").MustString(), ShouldEqual, "ops-alerts")
+				So(query.Result.DecryptedValue("secret", ""), ShouldEqual, "xxx")
+
+				_, err = alerting.InitNotifier(query.Result)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("with incorrect settings should return error", func() {
+				cfgProvider := &configReader{log: log.New("test logger")}
+				_, err := cfgProvider.readConfig(incorrectSNSHTTPSettings)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "alert validation error: Could not find url property in settings")
+			})
+		})
 	})
 }
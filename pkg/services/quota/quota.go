@@ -1,26 +1,40 @@
 package quota
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
 )
 
 var ErrInvalidQuotaTarget = errors.New("invalid quota target")
 
+var getTime = time.Now
+
 func init() {
 	registry.RegisterService(&QuotaService{})
 }
 
 type QuotaService struct {
-	AuthTokenService models.UserTokenService `inject:""`
-	Cfg              *setting.Cfg            `inject:""`
+	AuthTokenService models.UserTokenService  `inject:""`
+	Cfg              *setting.Cfg             `inject:""`
+	RemoteCache      *remotecache.RemoteCache `inject:""`
+
+	log log.Logger
 }
 
 func (qs *QuotaService) Init() error {
+	qs.log = log.New("quota")
 	return nil
 }
 
@@ -58,9 +72,10 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 					return false, err
 				}
 
+				reportUsage(scope, 0, usedSessions, scope.DefaultLimit)
 				if usedSessions > scope.DefaultLimit {
 					c.Logger.Debug("Sessions limit reached", "active", usedSessions, "limit", scope.DefaultLimit)
-					return true, nil
+					return limitReached(scope), nil
 				}
 				continue
 			}
@@ -68,26 +83,35 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 			if err := bus.Dispatch(&query); err != nil {
 				return true, err
 			}
+			reportUsage(scope, 0, query.Result.Used, scope.DefaultLimit)
 			if query.Result.Used >= scope.DefaultLimit {
-				return true, nil
+				return limitReached(scope), nil
 			}
 		case "org":
 			if !c.IsSignedIn {
 				continue
 			}
+			if interval, ok := setting.RateQuotaIntervals[scope.Target]; ok {
+				reached, err := qs.orgRateQuotaReached(c, scope, interval)
+				if err != nil || reached {
+					return reached, err
+				}
+				continue
+			}
 			query := models.GetOrgQuotaByTargetQuery{OrgId: c.OrgId, Target: scope.Target, Default: scope.DefaultLimit}
 			if err := bus.Dispatch(&query); err != nil {
 				return true, err
 			}
+			reportUsage(scope, c.OrgId, query.Result.Used, query.Result.Limit)
 			if query.Result.Limit < 0 {
 				continue
 			}
 			if query.Result.Limit == 0 {
-				return true, nil
+				return limitReached(scope), nil
 			}
 
 			if query.Result.Used >= query.Result.Limit {
-				return true, nil
+				return limitReached(scope), nil
 			}
 		case "user":
 			if !c.IsSignedIn || c.UserId == 0 {
@@ -101,11 +125,11 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 				continue
 			}
 			if query.Result.Limit == 0 {
-				return true, nil
+				return limitReached(scope), nil
 			}
 
 			if query.Result.Used >= query.Result.Limit {
-				return true, nil
+				return limitReached(scope), nil
 			}
 		}
 	}
@@ -113,6 +137,97 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 	return false, nil
 }
 
+// orgRateQuotaReached counts the request in the fixed interval of the rate
+// quota. Requests are counted in the remote cache, so that the quota holds
+// across Grafana instances. Requests of unlimited quotas aren't counted.
+func (qs *QuotaService) orgRateQuotaReached(c *models.ReqContext, scope models.QuotaScope, interval time.Duration) (bool, error) {
+	query := models.GetOrgQuotaLimitQuery{OrgId: c.OrgId, Target: scope.Target, Default: scope.DefaultLimit}
+	if err := bus.Dispatch(&query); err != nil {
+		return true, err
+	}
+	if query.Result < 0 {
+		return false, nil
+	}
+
+	used, err := qs.RemoteCache.Increment(rateQuotaKey(scope.Target, c.OrgId, interval), 1, interval)
+	if err != nil {
+		// the rate quota is not enforced while the remote cache is unavailable
+		c.Logger.Warn("Failed to count rate quota usage", "target", scope.Target, "error", err)
+		return false, nil
+	}
+
+	reportUsage(scope, c.OrgId, used, query.Result)
+	if used <= query.Result {
+		return false, nil
+	}
+	c.Logger.Debug("Rate quota reached", "target", scope.Target, "used", used, "limit", query.Result)
+	return limitReached(scope), nil
+}
+
+// GetOrgQuotas returns the quotas of the organization, including the rate
+// quotas with the usage of their current interval.
+func (qs *QuotaService) GetOrgQuotas(ctx context.Context, orgID int64) ([]*models.OrgQuotaDTO, error) {
+	query := models.GetOrgQuotasQuery{OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		return nil, err
+	}
+	quotas := query.Result
+
+	for target, defaultLimit := range qs.Cfg.Quota.OrgRate.ToMap() {
+		interval := setting.RateQuotaIntervals[target]
+		limitQuery := models.GetOrgQuotaLimitQuery{OrgId: orgID, Target: target, Default: defaultLimit}
+		if err := bus.DispatchCtx(ctx, &limitQuery); err != nil {
+			return nil, err
+		}
+
+		// no request was counted in the interval if the counter is missing
+		used, err := qs.RemoteCache.GetCounter(rateQuotaKey(target, orgID, interval))
+		if err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			qs.log.Warn("Failed to get rate quota usage", "target", target, "orgId", orgID, "error", err)
+		}
+
+		quotas = append(quotas, &models.OrgQuotaDTO{
+			OrgId:    orgID,
+			Target:   target,
+			Limit:    limitQuery.Result,
+			Used:     used,
+			Interval: tsdb.FormatDuration(interval),
+		})
+	}
+
+	for _, quota := range quotas {
+		reportUsage(models.QuotaScope{Name: "org", Target: quota.Target}, orgID, quota.Used, quota.Limit)
+	}
+	return quotas, nil
+}
+
+// IsOrgQuotaTarget returns true if the target is a quota of organizations
+func (qs *QuotaService) IsOrgQuotaTarget(target string) bool {
+	if _, ok := qs.Cfg.Quota.Org.ToMap()[target]; ok {
+		return true
+	}
+	_, ok := qs.Cfg.Quota.OrgRate.ToMap()[target]
+	return ok
+}
+
+func rateQuotaKey(target string, orgID int64, interval time.Duration) string {
+	return fmt.Sprintf("quota:%s:org:%d:%d", target, orgID, getTime().Truncate(interval).Unix())
+}
+
+func reportUsage(scope models.QuotaScope, orgID int64, used, limit int64) {
+	orgLabel := ""
+	if orgID != 0 {
+		orgLabel = strconv.FormatInt(orgID, 10)
+	}
+	metrics.MQuotaUsed.WithLabelValues(scope.Name, scope.Target, orgLabel).Set(float64(used))
+	metrics.MQuotaLimit.WithLabelValues(scope.Name, scope.Target, orgLabel).Set(float64(limit))
+}
+
+func limitReached(scope models.QuotaScope) bool {
+	metrics.MQuotaReached.WithLabelValues(scope.Name, scope.Target).Inc()
+	return true
+}
+
 func (qs *QuotaService) getQuotaScopes(target string) ([]models.QuotaScope, error) {
 	scopes := make([]models.QuotaScope, 0)
 	switch target {
@@ -146,6 +261,19 @@ func (qs *QuotaService) getQuotaScopes(target string) ([]models.QuotaScope, erro
 			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.ApiKey},
 		)
 		return scopes, nil
+	case "library_panel", "alert_definition", "annotation", "short_url", "dashboard_snapshot":
+		globalLimits := qs.Cfg.Quota.Global.ToMap()
+		orgLimits := qs.Cfg.Quota.Org.ToMap()
+		scopes = append(scopes,
+			models.QuotaScope{Name: "global", Target: target, DefaultLimit: globalLimits[target]},
+			models.QuotaScope{Name: "org", Target: target, DefaultLimit: orgLimits[target]},
+		)
+		return scopes, nil
+	case "query", "render":
+		scopes = append(scopes,
+			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.OrgRate.ToMap()[target]},
+		)
+		return scopes, nil
 	case "session":
 		scopes = append(scopes,
 			models.QuotaScope{Name: "global", Target: target, DefaultLimit: qs.Cfg.Quota.Global.Session},
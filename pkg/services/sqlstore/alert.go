@@ -68,6 +68,14 @@ func deleteAlertByIdInternal(alertId int64, reason string, sess *DBSession) erro
 		return err
 	}
 
+	if _, err := sess.Exec("DELETE FROM alert_evaluation WHERE alert_id = ?", alertId); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE alert_id = ?", alertId); err != nil {
+		return err
+	}
+
 	return nil
 }
 
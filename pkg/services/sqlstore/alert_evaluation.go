@@ -0,0 +1,60 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandlerCtx("sql", SaveAlertEvaluation)
+	bus.AddHandlerCtx("sql", GetAlertEvaluations)
+}
+
+func SaveAlertEvaluation(ctx context.Context, cmd *models.SaveAlertEvaluationCommand) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
+		evaluation := &models.AlertEvaluation{
+			AlertId: cmd.AlertId,
+			OrgId:   cmd.OrgId,
+			State:   cmd.State,
+			Data:    cmd.Data,
+			Created: cmd.Created.UnixNano() / int64(time.Millisecond),
+		}
+		if _, err := sess.Insert(evaluation); err != nil || cmd.Limit <= 0 {
+			return err
+		}
+
+		// only keep the most recent evaluations, by deleting the ones older
+		// than the oldest evaluation to keep
+		var oldestIDs []int64
+		err := sess.Table("alert_evaluation").
+			Where("alert_id = ?", cmd.AlertId).
+			Desc("id").
+			Limit(1, cmd.Limit-1).
+			Cols("id").
+			Find(&oldestIDs)
+		if err != nil || len(oldestIDs) == 0 {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM alert_evaluation WHERE alert_id = ? AND id < ?", cmd.AlertId, oldestIDs[0])
+		return err
+	})
+}
+
+func GetAlertEvaluations(ctx context.Context, query *models.GetAlertEvaluationsQuery) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		evaluations := make([]*models.AlertEvaluation, 0)
+		err := sess.Where("alert_id = ? AND org_id = ?", query.AlertId, query.OrgId).
+			Desc("id").
+			Find(&evaluations)
+		if err != nil {
+			return err
+		}
+
+		query.Result = evaluations
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertEvaluations(t *testing.T) {
+	InitTestDB(t)
+	ctx := context.Background()
+
+	save := func(alertID int64, state models.AlertStateType, limit int) {
+		err := SaveAlertEvaluation(ctx, &models.SaveAlertEvaluationCommand{
+			AlertId: alertID,
+			OrgId:   1,
+			State:   state,
+			Data:    simplejson.NewFromAny(map[string]interface{}{"state": state}),
+			Created: time.Now(),
+			Limit:   limit,
+		})
+		require.NoError(t, err)
+	}
+
+	save(1, models.AlertStateOK, 3)
+	save(1, models.AlertStatePending, 3)
+	save(2, models.AlertStateOK, 3)
+	save(1, models.AlertStateAlerting, 3)
+	save(1, models.AlertStateOK, 3)
+
+	t.Run("should only keep the most recent evaluations of the alert", func(t *testing.T) {
+		query := &models.GetAlertEvaluationsQuery{AlertId: 1, OrgId: 1}
+		require.NoError(t, GetAlertEvaluations(ctx, query))
+
+		require.Len(t, query.Result, 3)
+		require.Equal(t, models.AlertStateOK, query.Result[0].State)
+		require.Equal(t, models.AlertStateAlerting, query.Result[1].State)
+		require.Equal(t, models.AlertStatePending, query.Result[2].State)
+		require.Equal(t, "alerting", query.Result[1].Data.Get("state").MustString())
+	})
+
+	t.Run("should not delete the evaluations of other alerts", func(t *testing.T) {
+		query := &models.GetAlertEvaluationsQuery{AlertId: 2, OrgId: 1}
+		require.NoError(t, GetAlertEvaluations(ctx, query))
+		require.Len(t, query.Result, 1)
+	})
+
+	t.Run("should not return the evaluations of another org", func(t *testing.T) {
+		query := &models.GetAlertEvaluationsQuery{AlertId: 1, OrgId: 2}
+		require.NoError(t, GetAlertEvaluations(ctx, query))
+		require.Empty(t, query.Result)
+	})
+}
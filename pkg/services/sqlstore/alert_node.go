@@ -0,0 +1,65 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandlerCtx("sql", HeartbeatAlertNode)
+	bus.AddHandlerCtx("sql", RemoveAlertNode)
+	bus.AddHandlerCtx("sql", DeleteExpiredAlertNodes)
+	bus.AddHandlerCtx("sql", GetAlertNodes)
+}
+
+func HeartbeatAlertNode(ctx context.Context, cmd *models.HeartbeatAlertNodeCommand) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
+		node := &models.AlertNode{
+			NodeId:    cmd.NodeId,
+			Address:   cmd.Address,
+			Started:   cmd.Started.Unix(),
+			Heartbeat: cmd.Now.Unix(),
+		}
+
+		affected, err := sess.Where("node_id = ?", cmd.NodeId).Cols("address", "heartbeat").Update(node)
+		if err != nil || affected > 0 {
+			return err
+		}
+
+		_, err = sess.Insert(node)
+		return err
+	})
+}
+
+func RemoveAlertNode(ctx context.Context, cmd *models.RemoveAlertNodeCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_node WHERE node_id = ?", cmd.NodeId)
+		return err
+	})
+}
+
+func DeleteExpiredAlertNodes(ctx context.Context, cmd *models.DeleteExpiredAlertNodesCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_node WHERE heartbeat < ?", cmd.HeartbeatBefore.Unix())
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = res.RowsAffected()
+		return err
+	})
+}
+
+func GetAlertNodes(ctx context.Context, query *models.GetAlertNodesQuery) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		nodes := make([]*models.AlertNode, 0)
+		if err := sess.Where("heartbeat >= ?", query.ActiveSince.Unix()).Asc("node_id").Find(&nodes); err != nil {
+			return err
+		}
+
+		query.Result = nodes
+		return nil
+	})
+}
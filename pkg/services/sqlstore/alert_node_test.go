@@ -0,0 +1,68 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertNodes(t *testing.T) {
+	InitTestDB(t)
+	ctx := context.Background()
+
+	started := time.Unix(1000, 0)
+	heartbeat := func(nodeID string, now time.Time) {
+		err := HeartbeatAlertNode(ctx, &models.HeartbeatAlertNodeCommand{
+			NodeId:  nodeID,
+			Address: nodeID + ".local",
+			Started: started,
+			Now:     now,
+		})
+		require.NoError(t, err)
+	}
+	activeNodes := func(since time.Time) []string {
+		query := &models.GetAlertNodesQuery{ActiveSince: since}
+		require.NoError(t, GetAlertNodes(ctx, query))
+
+		nodes := make([]string, 0, len(query.Result))
+		for _, node := range query.Result {
+			nodes = append(nodes, node.NodeId)
+		}
+		return nodes
+	}
+
+	heartbeat("b", time.Unix(2000, 0))
+	heartbeat("a", time.Unix(2000, 0))
+	heartbeat("c", time.Unix(1500, 0))
+
+	t.Run("should return the nodes active since a time ordered by node id", func(t *testing.T) {
+		require.Equal(t, []string{"a", "b", "c"}, activeNodes(time.Unix(1500, 0)))
+		require.Equal(t, []string{"a", "b"}, activeNodes(time.Unix(1800, 0)))
+	})
+
+	t.Run("should update the heartbeat of an existing node", func(t *testing.T) {
+		heartbeat("c", time.Unix(2100, 0))
+		require.Equal(t, []string{"c"}, activeNodes(time.Unix(2050, 0)))
+
+		query := &models.GetAlertNodesQuery{ActiveSince: time.Unix(2050, 0)}
+		require.NoError(t, GetAlertNodes(ctx, query))
+		require.Equal(t, started.Unix(), query.Result[0].Started)
+	})
+
+	t.Run("should delete the expired nodes", func(t *testing.T) {
+		cmd := &models.DeleteExpiredAlertNodesCommand{HeartbeatBefore: time.Unix(2050, 0)}
+		require.NoError(t, DeleteExpiredAlertNodes(ctx, cmd))
+		require.Equal(t, int64(2), cmd.DeletedRows)
+		require.Equal(t, []string{"c"}, activeNodes(time.Unix(0, 0)))
+	})
+
+	t.Run("should remove a node", func(t *testing.T) {
+		require.NoError(t, RemoveAlertNode(ctx, &models.RemoveAlertNodeCommand{NodeId: "c"}))
+		require.Empty(t, activeNodes(time.Unix(0, 0)))
+	})
+}
@@ -22,6 +22,7 @@ func init() {
 	bus.AddHandler("sql", GetAllAlertNotifications)
 	bus.AddHandlerCtx("sql", GetOrCreateAlertNotificationState)
 	bus.AddHandlerCtx("sql", SetAlertNotificationStateToCompleteCommand)
+	bus.AddHandlerCtx("sql", SetAlertNotificationThread)
 	bus.AddHandlerCtx("sql", SetAlertNotificationStateToPendingCommand)
 
 	bus.AddHandler("sql", GetAlertNotificationsWithUid)
@@ -50,6 +51,10 @@ func DeleteAlertNotification(cmd *models.DeleteAlertNotificationCommand) error {
 			return err
 		}
 
+		if _, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE org_id = ? AND notifier_id = ?", cmd.OrgId, cmd.Id); err != nil {
+			return err
+		}
+
 		return nil
 	})
 }
@@ -509,6 +514,13 @@ func SetAlertNotificationStateToCompleteCommand(ctx context.Context, cmd *models
 	})
 }
 
+func SetAlertNotificationThread(ctx context.Context, cmd *models.SetAlertNotificationThreadCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Exec("UPDATE alert_notification_state SET thread_id = ? WHERE id = ?", cmd.ThreadId, cmd.Id)
+		return err
+	})
+}
+
 func SetAlertNotificationStateToPendingCommand(ctx context.Context, cmd *models.SetAlertNotificationStateToPendingCommand) error {
 	return withDbSession(ctx, func(sess *DBSession) error {
 		newVersion := cmd.Version + 1
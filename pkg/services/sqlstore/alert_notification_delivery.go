@@ -0,0 +1,169 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandlerCtx("sql", EnqueueAlertNotificationDelivery)
+	bus.AddHandlerCtx("sql", ClaimAlertNotificationDelivery)
+	bus.AddHandlerCtx("sql", FailAlertNotificationDelivery)
+	bus.AddHandlerCtx("sql", ReplayAlertNotificationDelivery)
+	bus.AddHandlerCtx("sql", DeleteAlertNotificationDelivery)
+	bus.AddHandlerCtx("sql", GetDueAlertNotificationDeliveries)
+	bus.AddHandlerCtx("sql", GetAlertNotificationDeliveries)
+}
+
+func EnqueueAlertNotificationDelivery(ctx context.Context, cmd *models.EnqueueAlertNotificationDeliveryCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		delivery := &models.AlertNotificationDelivery{
+			OrgId:                    cmd.OrgId,
+			AlertId:                  cmd.AlertId,
+			NotifierId:               cmd.NotifierId,
+			NotifierUid:              cmd.NotifierUid,
+			NotifierType:             cmd.NotifierType,
+			NotificationStateId:      cmd.NotificationStateId,
+			NotificationStateVersion: cmd.NotificationStateVersion,
+			State:                    models.AlertNotificationDeliveryPending,
+			Attempts:                 cmd.Attempts,
+			NextAttempt:              cmd.NextAttempt.Unix(),
+			LastError:                cmd.LastError,
+			Payload:                  cmd.Payload,
+			Created:                  cmd.Created.Unix(),
+			Updated:                  cmd.Created.Unix(),
+		}
+		if _, err := sess.Insert(delivery); err != nil {
+			return err
+		}
+
+		cmd.Result = delivery
+		return nil
+	})
+}
+
+func ClaimAlertNotificationDelivery(ctx context.Context, cmd *models.ClaimAlertNotificationDeliveryCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		sql := `UPDATE alert_notification_delivery SET
+			attempts = ?,
+			next_attempt = ?,
+			updated = ?
+		WHERE
+			id = ? AND
+			attempts = ? AND
+			state = ?`
+
+		res, err := sess.Exec(sql, cmd.Attempts+1, cmd.Until.Unix(), timeNow().Unix(), cmd.Id, cmd.Attempts, models.AlertNotificationDeliveryPending)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		cmd.Claimed = affected == 1
+		return nil
+	})
+}
+
+func FailAlertNotificationDelivery(ctx context.Context, cmd *models.FailAlertNotificationDeliveryCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		state := models.AlertNotificationDeliveryPending
+		if cmd.Dead {
+			state = models.AlertNotificationDeliveryDead
+		}
+
+		_, err := sess.Exec("UPDATE alert_notification_delivery SET state = ?, next_attempt = ?, last_error = ?, updated = ? WHERE id = ?",
+			state, cmd.NextAttempt.Unix(), cmd.Error, timeNow().Unix(), cmd.Id)
+		return err
+	})
+}
+
+func ReplayAlertNotificationDelivery(ctx context.Context, cmd *models.ReplayAlertNotificationDeliveryCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		sql := `UPDATE alert_notification_delivery SET
+			state = ?,
+			attempts = 0,
+			next_attempt = ?,
+			notification_state_version = 0,
+			updated = ?
+		WHERE
+			id = ? AND
+			org_id = ? AND
+			state = ?`
+
+		res, err := sess.Exec(sql, models.AlertNotificationDeliveryPending, cmd.Now.Unix(), cmd.Now.Unix(), cmd.Id, cmd.OrgId, models.AlertNotificationDeliveryDead)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrAlertNotificationDeliveryNotFound
+		}
+
+		return nil
+	})
+}
+
+func DeleteAlertNotificationDelivery(ctx context.Context, cmd *models.DeleteAlertNotificationDeliveryCommand) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		sess.Where("id = ?", cmd.Id)
+		if cmd.OrgId != 0 {
+			sess.And("org_id = ?", cmd.OrgId)
+		}
+
+		affected, err := sess.Delete(&models.AlertNotificationDelivery{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrAlertNotificationDeliveryNotFound
+		}
+
+		return nil
+	})
+}
+
+func GetDueAlertNotificationDeliveries(ctx context.Context, query *models.GetDueAlertNotificationDeliveriesQuery) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		deliveries := make([]*models.AlertNotificationDelivery, 0)
+		err := sess.Where("state = ? AND next_attempt <= ?", models.AlertNotificationDeliveryPending, query.Now.Unix()).
+			Asc("next_attempt").
+			Limit(query.Limit).
+			Find(&deliveries)
+		if err != nil {
+			return err
+		}
+
+		query.Result = deliveries
+		return nil
+	})
+}
+
+func GetAlertNotificationDeliveries(ctx context.Context, query *models.GetAlertNotificationDeliveriesQuery) error {
+	return withDbSession(ctx, func(sess *DBSession) error {
+		sess.Where("org_id = ?", query.OrgId)
+		if query.State != "" {
+			sess.And("state = ?", query.State)
+		}
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+
+		deliveries := make([]*models.AlertNotificationDelivery, 0)
+		if err := sess.Desc("id").Find(&deliveries); err != nil {
+			return err
+		}
+
+		query.Result = deliveries
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertNotificationDeliveries(t *testing.T) {
+	InitTestDB(t)
+	ctx := context.Background()
+
+	enqueue := func(orgID int64, nextAttempt time.Time) *models.AlertNotificationDelivery {
+		cmd := &models.EnqueueAlertNotificationDeliveryCommand{
+			OrgId:                    orgID,
+			AlertId:                  1,
+			NotifierId:               2,
+			NotifierUid:              "slack",
+			NotifierType:             "slack",
+			NotificationStateId:      3,
+			NotificationStateVersion: 4,
+			Attempts:                 1,
+			NextAttempt:              nextAttempt,
+			LastError:                "slack is down",
+			Payload:                  "{}",
+			Created:                  time.Unix(1000, 0),
+		}
+		require.NoError(t, EnqueueAlertNotificationDelivery(ctx, cmd))
+		return cmd.Result
+	}
+	dueDeliveries := func(now time.Time) []int64 {
+		query := &models.GetDueAlertNotificationDeliveriesQuery{Now: now, Limit: 10}
+		require.NoError(t, GetDueAlertNotificationDeliveries(ctx, query))
+
+		ids := make([]int64, 0, len(query.Result))
+		for _, delivery := range query.Result {
+			ids = append(ids, delivery.Id)
+		}
+		return ids
+	}
+
+	first := enqueue(1, time.Unix(2000, 0))
+	second := enqueue(1, time.Unix(1500, 0))
+	other := enqueue(2, time.Unix(3000, 0))
+
+	t.Run("should return the due deliveries, oldest attempt first", func(t *testing.T) {
+		require.Equal(t, []int64{second.Id, first.Id}, dueDeliveries(time.Unix(2000, 0)))
+		require.Equal(t, []int64{second.Id}, dueDeliveries(time.Unix(1800, 0)))
+	})
+
+	t.Run("should only claim a delivery once", func(t *testing.T) {
+		claim := &models.ClaimAlertNotificationDeliveryCommand{Id: second.Id, Attempts: 1, Until: time.Unix(2500, 0)}
+		require.NoError(t, ClaimAlertNotificationDelivery(ctx, claim))
+		require.True(t, claim.Claimed)
+
+		claim = &models.ClaimAlertNotificationDeliveryCommand{Id: second.Id, Attempts: 1, Until: time.Unix(2500, 0)}
+		require.NoError(t, ClaimAlertNotificationDelivery(ctx, claim))
+		require.False(t, claim.Claimed)
+
+		require.Equal(t, []int64{first.Id}, dueDeliveries(time.Unix(2000, 0)))
+	})
+
+	t.Run("should keep a dead delivery out of the queue until replayed", func(t *testing.T) {
+		fail := &models.FailAlertNotificationDeliveryCommand{Id: second.Id, Error: "still down", NextAttempt: time.Unix(1500, 0), Dead: true}
+		require.NoError(t, FailAlertNotificationDelivery(ctx, fail))
+		require.Equal(t, []int64{first.Id}, dueDeliveries(time.Unix(2000, 0)))
+
+		query := &models.GetAlertNotificationDeliveriesQuery{OrgId: 1, State: models.AlertNotificationDeliveryDead}
+		require.NoError(t, GetAlertNotificationDeliveries(ctx, query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, "still down", query.Result[0].LastError)
+		require.Equal(t, 2, query.Result[0].Attempts)
+
+		err := ReplayAlertNotificationDelivery(ctx, &models.ReplayAlertNotificationDeliveryCommand{Id: second.Id, OrgId: 2, Now: time.Unix(2000, 0)})
+		require.ErrorIs(t, err, models.ErrAlertNotificationDeliveryNotFound)
+
+		err = ReplayAlertNotificationDelivery(ctx, &models.ReplayAlertNotificationDeliveryCommand{Id: second.Id, OrgId: 1, Now: time.Unix(2000, 0)})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int64{first.Id, second.Id}, dueDeliveries(time.Unix(2000, 0)))
+
+		query = &models.GetAlertNotificationDeliveriesQuery{OrgId: 1, State: models.AlertNotificationDeliveryPending}
+		require.NoError(t, GetAlertNotificationDeliveries(ctx, query))
+		require.Len(t, query.Result, 2)
+		require.Equal(t, second.Id, query.Result[0].Id)
+		require.Equal(t, 0, query.Result[0].Attempts)
+		require.Equal(t, int64(0), query.Result[0].NotificationStateVersion)
+	})
+
+	t.Run("should only delete the deliveries of the org", func(t *testing.T) {
+		err := DeleteAlertNotificationDelivery(ctx, &models.DeleteAlertNotificationDeliveryCommand{Id: other.Id, OrgId: 1})
+		require.ErrorIs(t, err, models.ErrAlertNotificationDeliveryNotFound)
+
+		require.NoError(t, DeleteAlertNotificationDelivery(ctx, &models.DeleteAlertNotificationDeliveryCommand{Id: other.Id, OrgId: 2}))
+		require.NoError(t, DeleteAlertNotificationDelivery(ctx, &models.DeleteAlertNotificationDeliveryCommand{Id: first.Id}))
+		require.Equal(t, []int64{second.Id}, dueDeliveries(time.Unix(5000, 0)))
+	})
+}
@@ -44,6 +44,20 @@ func TestAlertNotificationSQLAccess(t *testing.T) {
 					So(query2.Result.UpdatedAt, ShouldEqual, now.Unix())
 				})
 
+				Convey("Set thread should keep the version of the state", func() {
+					err := SetAlertNotificationThread(context.Background(), &models.SetAlertNotificationThreadCommand{
+						Id:       query.Result.Id,
+						ThreadId: "post1",
+					})
+					So(err, ShouldBeNil)
+
+					query2 := &models.GetOrCreateNotificationStateQuery{AlertId: alertID, OrgId: orgID, NotifierId: notifierID}
+					err = GetOrCreateAlertNotificationState(context.Background(), query2)
+					So(err, ShouldBeNil)
+					So(query2.Result.ThreadId, ShouldEqual, "post1")
+					So(query2.Result.Version, ShouldEqual, query.Result.Version)
+				})
+
 				Convey("Update existing state to pending with correct version should update database", func() {
 					s := *query.Result
 
@@ -3,6 +3,9 @@ package sqlstore
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -24,34 +27,78 @@ const (
 // CleanAnnotations deletes old annotations created by alert rules, API
 // requests and human made in the UI. It subsequently deletes orphaned rows
 // from the annotation_tag table. Cleanup actions are performed in batches
-// so that no query takes too long to complete.
+// so that no query takes too long to complete. The retention policies of
+// organizations in cfg.Retention override the default policies.
 //
 // Returns the number of annotation and annotation_tag rows deleted. If an
-// error occurs, it returns the number of rows affected so far.
+// error occurs, it returns the number of rows affected so far. In dry run
+// mode nothing is deleted, and the number of annotations that would be
+// deleted is returned instead.
 func (acs *AnnotationCleanupService) CleanAnnotations(ctx context.Context, cfg *setting.Cfg) (int64, int64, error) {
 	var totalCleanedAnnotations int64
-	affected, err := acs.cleanAnnotations(ctx, cfg.AlertingAnnotationCleanupSetting, alertAnnotationType)
+	affected, err := acs.cleanAnnotationType(ctx, cfg, setting.RetentionAlertAnnotations, cfg.AlertingAnnotationCleanupSetting, alertAnnotationType)
 	totalCleanedAnnotations += affected
 	if err != nil {
 		return totalCleanedAnnotations, 0, err
 	}
 
-	affected, err = acs.cleanAnnotations(ctx, cfg.APIAnnotationCleanupSettings, apiAnnotationType)
+	affected, err = acs.cleanAnnotationType(ctx, cfg, setting.RetentionAPIAnnotations, cfg.APIAnnotationCleanupSettings, apiAnnotationType)
 	totalCleanedAnnotations += affected
 	if err != nil {
 		return totalCleanedAnnotations, 0, err
 	}
 
-	affected, err = acs.cleanAnnotations(ctx, cfg.DashboardAnnotationCleanupSettings, dashboardAnnotationType)
+	affected, err = acs.cleanAnnotationType(ctx, cfg, setting.RetentionDashboardAnnotations, cfg.DashboardAnnotationCleanupSettings, dashboardAnnotationType)
 	totalCleanedAnnotations += affected
 	if err != nil {
 		return totalCleanedAnnotations, 0, err
 	}
 
+	if cfg.Retention.DryRun {
+		return totalCleanedAnnotations, 0, nil
+	}
+
 	affected, err = acs.cleanOrphanedAnnotationTags(ctx)
 	return totalCleanedAnnotations, affected, err
 }
 
+// cleanAnnotationType applies the retention policy of each organization with
+// its own policy to its annotations, and the default policy to the others.
+func (acs *AnnotationCleanupService) cleanAnnotationType(ctx context.Context, cfg *setting.Cfg, objectType string, defaultPolicy setting.RetentionPolicy, annotationType string) (int64, error) {
+	orgPolicies := cfg.Retention.Orgs[objectType]
+	orgIDs := make([]int64, 0, len(orgPolicies))
+	for orgID := range orgPolicies {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	clean := acs.cleanAnnotations
+	if cfg.Retention.DryRun {
+		clean = acs.countAnnotationsToClean
+	}
+
+	filter := annotationType
+	if len(orgIDs) > 0 {
+		filter = fmt.Sprintf("%s AND org_id NOT IN (%s)", annotationType, joinInt64s(orgIDs))
+	}
+
+	totalAffected, err := clean(ctx, defaultPolicy, filter)
+	if err != nil {
+		return totalAffected, err
+	}
+
+	for _, orgID := range orgIDs {
+		filter := fmt.Sprintf("%s AND org_id = %d", annotationType, orgID)
+		affected, err := clean(ctx, orgPolicies[orgID], filter)
+		totalAffected += affected
+		if err != nil {
+			return totalAffected, err
+		}
+	}
+
+	return totalAffected, nil
+}
+
 func (acs *AnnotationCleanupService) cleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error) {
 	var totalAffected int64
 	if cfg.MaxAge > 0 {
@@ -77,6 +124,45 @@ func (acs *AnnotationCleanupService) cleanAnnotations(ctx context.Context, cfg s
 	return totalAffected, nil
 }
 
+// countAnnotationsToClean returns the number of annotations cleanAnnotations would delete
+func (acs *AnnotationCleanupService) countAnnotationsToClean(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error) {
+	var total int64
+	err := withDbSession(ctx, func(session *DBSession) error {
+		remainingFilter := annotationType
+		if cfg.MaxAge > 0 {
+			cutoffDate := time.Now().Add(-cfg.MaxAge).UnixNano() / int64(time.Millisecond)
+			expired, err := session.Table("annotation").Where(fmt.Sprintf("%s AND created < %v", annotationType, cutoffDate)).Count()
+			if err != nil {
+				return err
+			}
+			total += expired
+			remainingFilter = fmt.Sprintf("%s AND created >= %v", annotationType, cutoffDate)
+		}
+
+		if cfg.MaxCount > 0 {
+			remaining, err := session.Table("annotation").Where(remainingFilter).Count()
+			if err != nil {
+				return err
+			}
+			if remaining > cfg.MaxCount {
+				total += remaining - cfg.MaxCount
+			}
+		}
+
+		return nil
+	})
+
+	return total, err
+}
+
+func joinInt64s(values []int64) string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, strconv.FormatInt(v, 10))
+	}
+	return strings.Join(strs, ",")
+}
+
 func (acs *AnnotationCleanupService) cleanOrphanedAnnotationTags(ctx context.Context) (int64, error) {
 	deleteQuery := `DELETE FROM annotation_tag WHERE id IN ( SELECT id FROM (SELECT id FROM annotation_tag WHERE NOT EXISTS (SELECT 1 FROM annotation a WHERE annotation_id = a.id) %s) a)`
 	sql := fmt.Sprintf(deleteQuery, dialect.Limit(acs.batchSize))
@@ -156,6 +156,61 @@ func TestOldAnnotationsAreDeletedFirst(t *testing.T) {
 	require.Equal(t, int64(0), countOld, "the two first annotations should have been deleted")
 }
 
+func TestAnnotationCleanUpWithOrgRetentionPolicies(t *testing.T) {
+	fakeSQL := InitTestDB(t)
+
+	t.Cleanup(func() {
+		err := fakeSQL.WithDbSession(context.Background(), func(session *DBSession) error {
+			_, err := session.Exec("DELETE FROM annotation")
+			return err
+		})
+		assert.NoError(t, err)
+	})
+
+	// six old annotations of which two are API annotations in org 1
+	createTestAnnotations(t, fakeSQL, 21, 6)
+
+	session := fakeSQL.NewSession()
+	defer session.Close()
+	for i := 0; i < 3; i++ {
+		_, err := session.Insert(&annotations.Item{
+			OrgId:   2,
+			Created: time.Now().AddDate(-10, 0, -10).UnixNano() / int64(time.Millisecond),
+		})
+		require.NoError(t, err)
+	}
+
+	cfg := &setting.Cfg{
+		APIAnnotationCleanupSettings: settingsFn(time.Hour*48, 0),
+		Retention: setting.RetentionSettings{
+			DryRun: true,
+			Orgs: map[string]map[int64]setting.RetentionPolicy{
+				setting.RetentionAPIAnnotations: {2: settingsFn(0, 0)},
+			},
+		},
+	}
+	cleaner := &AnnotationCleanupService{batchSize: 1, log: log.New("test-logger")}
+
+	t.Run("dry run should count the annotations without deleting them", func(t *testing.T) {
+		affected, affectedTags, err := cleaner.CleanAnnotations(context.Background(), cfg)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), affected)
+		assert.Equal(t, int64(0), affectedTags)
+		assertAnnotationCount(t, fakeSQL, apiAnnotationType, 10)
+	})
+
+	t.Run("org policy should override the default policy", func(t *testing.T) {
+		cfg.Retention.DryRun = false
+		affected, _, err := cleaner.CleanAnnotations(context.Background(), cfg)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), affected)
+		assertAnnotationCount(t, fakeSQL, apiAnnotationType+" AND org_id = 1", 5)
+		assertAnnotationCount(t, fakeSQL, apiAnnotationType+" AND org_id = 2", 3)
+	})
+}
+
 func assertAnnotationCount(t *testing.T, fakeSQL *SQLStore, sql string, expectedCount int64) {
 	t.Helper()
 
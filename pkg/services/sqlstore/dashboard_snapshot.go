@@ -28,6 +28,11 @@ func DeleteExpiredSnapshots(cmd *models.DeleteExpiredSnapshotsCommand) error {
 			return nil
 		}
 
+		if cmd.DryRun {
+			_, err := sess.SQL("SELECT COUNT(*) FROM dashboard_snapshot WHERE expires < ?", time.Now()).Get(&cmd.DeletedRows)
+			return err
+		}
+
 		deleteExpiredSQL := "DELETE FROM dashboard_snapshot WHERE expires < ?"
 		expiredResponse, err := sess.Exec(deleteExpiredSQL, time.Now())
 		if err != nil {
@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
@@ -75,31 +76,36 @@ func DeleteExpiredVersions(cmd *models.DeleteExpiredVersionsCommand) error {
 }
 
 func deleteExpiredVersions(cmd *models.DeleteExpiredVersionsCommand, perBatch int, maxBatches int) error {
-	versionsToKeep := setting.DashboardVersionsToKeep
-	if versionsToKeep < 1 {
-		versionsToKeep = 1
+	conditions, args := expiredVersionsConditions(cmd)
+	// Idea of this query is finding version IDs to delete based on formula:
+	// min_version_to_keep = min_version + (versions_count - versions_to_keep)
+	// where version stats is processed for each dashboard. This guarantees that we keep at least versions_to_keep
+	// versions, but in some cases (when versions are sparse) this number may be more.
+	// Versions older than the max age are deleted as well, except for the latest version.
+	expiredVersionsQuery := `FROM dashboard_version, (
+			SELECT dashboard_id, count(version) as count, min(version) as min, max(version) as max
+			FROM dashboard_version
+			GROUP BY dashboard_id
+		) AS vtd
+		WHERE ` + conditions
+
+	if cmd.DryRun {
+		return inTransaction(func(sess *DBSession) error {
+			var count int64
+			_, err := sess.SQL("SELECT COUNT(*) "+expiredVersionsQuery, args...).Get(&count)
+			cmd.DeletedRows = count
+			return err
+		})
 	}
 
 	for batch := 0; batch < maxBatches; batch++ {
 		deleted := int64(0)
 
 		batchErr := inTransaction(func(sess *DBSession) error {
-			// Idea of this query is finding version IDs to delete based on formula:
-			// min_version_to_keep = min_version + (versions_count - versions_to_keep)
-			// where version stats is processed for each dashboard. This guarantees that we keep at least versions_to_keep
-			// versions, but in some cases (when versions are sparse) this number may be more.
-			versionIdsToDeleteQuery := `SELECT id
-				FROM dashboard_version, (
-					SELECT dashboard_id, count(version) as count, min(version) as min
-					FROM dashboard_version
-					GROUP BY dashboard_id
-				) AS vtd
-				WHERE dashboard_version.dashboard_id=vtd.dashboard_id
-				AND version < vtd.min + vtd.count - ?
-				LIMIT ?`
+			versionIdsToDeleteQuery := `SELECT id ` + expiredVersionsQuery + ` LIMIT ?`
 
 			var versionIdsToDelete []interface{}
-			err := sess.SQL(versionIdsToDeleteQuery, versionsToKeep, perBatch).Find(&versionIdsToDelete)
+			err := sess.SQL(versionIdsToDeleteQuery, append(args, perBatch)...).Find(&versionIdsToDelete)
 			if err != nil {
 				return err
 			}
@@ -132,3 +138,45 @@ func deleteExpiredVersions(cmd *models.DeleteExpiredVersionsCommand, perBatch in
 
 	return nil
 }
+
+// expiredVersionsConditions returns the conditions matching the versions
+// to delete, and their arguments.
+func expiredVersionsConditions(cmd *models.DeleteExpiredVersionsCommand) (string, []interface{}) {
+	maxCount := cmd.MaxCount
+	if maxCount == 0 && cmd.MaxAge == 0 {
+		maxCount = int64(setting.DashboardVersionsToKeep)
+		if maxCount < 1 {
+			maxCount = 1
+		}
+	}
+
+	var expired []string
+	var args []interface{}
+	if maxCount > 0 {
+		expired = append(expired, "version < vtd.min + vtd.count - ?")
+		args = append(args, maxCount)
+	}
+	if cmd.MaxAge > 0 {
+		expired = append(expired, "dashboard_version.created < ?")
+		args = append(args, time.Now().Add(-cmd.MaxAge))
+	}
+
+	conditions := []string{
+		"dashboard_version.dashboard_id=vtd.dashboard_id",
+		"version < vtd.max",
+		"(" + strings.Join(expired, " OR ") + ")",
+	}
+
+	if cmd.OrgId > 0 {
+		conditions = append(conditions, "dashboard_version.dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ?)")
+		args = append(args, cmd.OrgId)
+	}
+	if len(cmd.ExcludeOrgIds) > 0 {
+		conditions = append(conditions, "dashboard_version.dashboard_id NOT IN (SELECT id FROM dashboard WHERE org_id IN (?"+strings.Repeat(",?", len(cmd.ExcludeOrgIds)-1)+"))")
+		for _, orgID := range cmd.ExcludeOrgIds {
+			args = append(args, orgID)
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
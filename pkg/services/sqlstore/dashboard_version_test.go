@@ -3,8 +3,10 @@
 package sqlstore
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 
@@ -171,3 +173,67 @@ func TestDeleteExpiredVersions(t *testing.T) {
 		})
 	})
 }
+
+func TestDeleteExpiredVersionsWithRetentionPolicy(t *testing.T) {
+	Convey("Testing dashboard versions clean up with retention policy", t, func() {
+		sqlStore := InitTestDB(t)
+
+		dashOrg1 := insertTestDashboard(t, "retention dash org 1", 1, 0, false, "retention")
+		dashOrg2 := insertTestDashboard(t, "retention dash org 2", 2, 0, false, "retention")
+		for i := 0; i < 9; i++ {
+			updateTestDashboard(dashOrg1, map[string]interface{}{"tags": "different-tag"})
+			updateTestDashboard(dashOrg2, map[string]interface{}{"tags": "different-tag"})
+		}
+
+		versionCount := func(dash *models.Dashboard) int {
+			query := models.GetDashboardVersionsQuery{DashboardId: dash.Id, OrgId: dash.OrgId}
+			err := GetDashboardVersions(&query)
+			So(err, ShouldBeNil)
+			return len(query.Result)
+		}
+
+		Convey("Dry run should count the versions without deleting them", func() {
+			cmd := models.DeleteExpiredVersionsCommand{MaxCount: 4, DryRun: true}
+			err := DeleteExpiredVersions(&cmd)
+			So(err, ShouldBeNil)
+
+			So(cmd.DeletedRows, ShouldEqual, 12)
+			So(versionCount(dashOrg1), ShouldEqual, 10)
+			So(versionCount(dashOrg2), ShouldEqual, 10)
+		})
+
+		Convey("Org policy should only delete versions of the org", func() {
+			cmd := models.DeleteExpiredVersionsCommand{MaxCount: 4, OrgId: 2}
+			err := DeleteExpiredVersions(&cmd)
+			So(err, ShouldBeNil)
+
+			So(cmd.DeletedRows, ShouldEqual, 6)
+			So(versionCount(dashOrg1), ShouldEqual, 10)
+			So(versionCount(dashOrg2), ShouldEqual, 4)
+		})
+
+		Convey("Excluded orgs should be left alone", func() {
+			cmd := models.DeleteExpiredVersionsCommand{MaxCount: 2, ExcludeOrgIds: []int64{2}}
+			err := DeleteExpiredVersions(&cmd)
+			So(err, ShouldBeNil)
+
+			So(versionCount(dashOrg1), ShouldEqual, 2)
+			So(versionCount(dashOrg2), ShouldEqual, 10)
+		})
+
+		Convey("Versions older than max age should be deleted except for the latest version", func() {
+			err := sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+				_, err := sess.Exec("UPDATE dashboard_version SET created = ?", time.Now().Add(-48*time.Hour))
+				return err
+			})
+			So(err, ShouldBeNil)
+
+			cmd := models.DeleteExpiredVersionsCommand{MaxAge: 24 * time.Hour}
+			err = DeleteExpiredVersions(&cmd)
+			So(err, ShouldBeNil)
+
+			So(versionCount(dashOrg1), ShouldEqual, 1)
+			So(versionCount(dashOrg2), ShouldEqual, 1)
+		})
+	})
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 )
 
@@ -109,30 +110,47 @@ func (ss *SQLStore) DeleteDataSource(uid string, id int64, name string, orgID in
 // DeleteDataSource removes a datasource by org_id as well as either uid (preferred), id, or name
 // and is added to the bus.
 func DeleteDataSource(cmd *models.DeleteDataSourceCommand) error {
-	params := make([]interface{}, 0)
-
-	makeQuery := func(sql string, p ...interface{}) {
-		params = append(params, sql)
-		params = append(params, p...)
-	}
+	var where string
+	var params []interface{}
 
 	switch {
 	case cmd.OrgID == 0:
 		return models.ErrDataSourceIdentifierNotSet
 	case cmd.UID != "":
-		makeQuery("DELETE FROM data_source WHERE uid=? and org_id=?", cmd.UID, cmd.OrgID)
+		where, params = "uid=? and org_id=?", []interface{}{cmd.UID, cmd.OrgID}
 	case cmd.ID != 0:
-		makeQuery("DELETE FROM data_source WHERE id=? and org_id=?", cmd.ID, cmd.OrgID)
+		where, params = "id=? and org_id=?", []interface{}{cmd.ID, cmd.OrgID}
 	case cmd.Name != "":
-		makeQuery("DELETE FROM data_source WHERE name=? and org_id=?", cmd.Name, cmd.OrgID)
+		where, params = "name=? and org_id=?", []interface{}{cmd.Name, cmd.OrgID}
 	default:
 		return models.ErrDataSourceIdentifierNotSet
 	}
 
 	return inTransaction(func(sess *DBSession) error {
-		result, err := sess.Exec(params...)
+		var ds models.DataSource
+		has, err := sess.Where(where, params...).Get(&ds)
+		if err != nil {
+			return err
+		}
+
+		result, err := sess.Exec(append([]interface{}{"DELETE FROM data_source WHERE " + where}, params...)...)
+		if err != nil {
+			return err
+		}
 		cmd.DeletedDatasourcesCount, _ = result.RowsAffected()
-		return err
+
+		if has && cmd.DeletedDatasourcesCount > 0 {
+			sess.publishAfterCommit(&events.DataSourceDeleted{
+				Timestamp: time.Now(),
+				Id:        ds.Id,
+				Uid:       ds.Uid,
+				OrgId:     ds.OrgId,
+				Name:      ds.Name,
+				Type:      ds.Type,
+			})
+		}
+
+		return nil
 	})
 }
 
@@ -267,6 +285,15 @@ func UpdateDataSource(cmd *models.UpdateDataSourceCommand) error {
 		err = updateIsDefaultFlag(ds, sess)
 
 		cmd.Result = ds
+		sess.publishAfterCommit(&events.DataSourceUpdated{
+			Timestamp: ds.Updated,
+			Id:        ds.Id,
+			Uid:       ds.Uid,
+			OrgId:     ds.OrgId,
+			Name:      ds.Name,
+			Type:      ds.Type,
+			Version:   ds.Version,
+		})
 		return err
 	})
 }
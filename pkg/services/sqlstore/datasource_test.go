@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -100,6 +102,27 @@ func TestDataAccess(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("publishes an updated event", func(t *testing.T) {
+			InitTestDB(t)
+			ds := initDatasource()
+
+			var updated *events.DataSourceUpdated
+			bus.AddEventListener(func(e *events.DataSourceUpdated) error {
+				updated = e
+				return nil
+			})
+
+			cmd := defaultUpdateDatasourceCommand
+			cmd.Id = ds.Id
+			cmd.Version = ds.Version
+			err := UpdateDataSource(&cmd)
+			require.NoError(t, err)
+
+			require.NotNil(t, updated)
+			require.Equal(t, ds.Id, updated.Id)
+			require.Equal(t, ds.Version+1, updated.Version)
+		})
+
 		t.Run("does not overwrite Uid if not specified", func(t *testing.T) {
 			InitTestDB(t)
 			ds := initDatasource()
@@ -190,6 +213,25 @@ func TestDataAccess(t *testing.T) {
 			require.Equal(t, 0, len(query.Result))
 		})
 
+		t.Run("publishes a deleted event", func(t *testing.T) {
+			InitTestDB(t)
+			ds := initDatasource()
+
+			var deleted *events.DataSourceDeleted
+			bus.AddEventListener(func(e *events.DataSourceDeleted) error {
+				deleted = e
+				return nil
+			})
+
+			err := DeleteDataSource(&models.DeleteDataSourceCommand{UID: ds.Uid, OrgID: ds.OrgId})
+			require.NoError(t, err)
+
+			require.NotNil(t, deleted)
+			require.Equal(t, ds.Id, deleted.Id)
+			require.Equal(t, ds.Uid, deleted.Uid)
+			require.Equal(t, ds.Type, deleted.Type)
+		})
+
 		t.Run("Can not delete datasource with wrong orgId", func(t *testing.T) {
 			InitTestDB(t)
 			ds := initDatasource()
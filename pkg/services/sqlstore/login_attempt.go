@@ -14,6 +14,11 @@ func init() {
 	bus.AddHandler("sql", CreateLoginAttempt)
 	bus.AddHandler("sql", DeleteOldLoginAttempts)
 	bus.AddHandler("sql", GetUserLoginAttemptCount)
+	bus.AddHandler("sql", GetIPLoginAttemptCount)
+	bus.AddHandler("sql", LockUser)
+	bus.AddHandler("sql", UnlockUser)
+	bus.AddHandler("sql", GetUserLockout)
+	bus.AddHandler("sql", DeleteExpiredLoginLockouts)
 }
 
 func CreateLoginAttempt(cmd *models.CreateLoginAttemptCommand) error {
@@ -36,6 +41,11 @@ func CreateLoginAttempt(cmd *models.CreateLoginAttemptCommand) error {
 
 func DeleteOldLoginAttempts(cmd *models.DeleteOldLoginAttemptsCommand) error {
 	return inTransaction(func(sess *DBSession) error {
+		if cmd.DryRun {
+			_, err := sess.SQL("SELECT COUNT(*) FROM login_attempt WHERE created < ?", cmd.OlderThan.Unix()).Get(&cmd.DeletedRows)
+			return err
+		}
+
 		var maxId int64
 		sql := "SELECT max(id) as id FROM login_attempt WHERE created < ?"
 		result, err := sess.Query(sql, cmd.OlderThan.Unix())
@@ -65,9 +75,32 @@ func DeleteOldLoginAttempts(cmd *models.DeleteOldLoginAttemptsCommand) error {
 }
 
 func GetUserLoginAttemptCount(query *models.GetUserLoginAttemptCountQuery) error {
+	sess := x.
+		Where("username = ?", query.Username).
+		And("created >= ?", query.Since.Unix())
+	if query.IpAddress != "" {
+		sess = sess.And("ip_address = ?", query.IpAddress)
+	}
+
+	var result struct {
+		Total       int64
+		LastAttempt int64
+	}
+	if _, err := sess.Table("login_attempt").Select("COUNT(*) AS total, COALESCE(MAX(created), 0) AS last_attempt").Get(&result); err != nil {
+		return err
+	}
+
+	query.Result = result.Total
+	if result.LastAttempt > 0 {
+		query.LastAttempt = time.Unix(result.LastAttempt, 0)
+	}
+	return nil
+}
+
+func GetIPLoginAttemptCount(query *models.GetIPLoginAttemptCountQuery) error {
 	loginAttempt := new(models.LoginAttempt)
 	total, err := x.
-		Where("username = ?", query.Username).
+		Where("ip_address = ?", query.IpAddress).
 		And("created >= ?", query.Since.Unix()).
 		Count(loginAttempt)
 
@@ -79,6 +112,74 @@ func GetUserLoginAttemptCount(query *models.GetUserLoginAttemptCountQuery) error
 	return nil
 }
 
+func LockUser(cmd *models.LockUserCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		lockout := models.LoginLockout{
+			UserId:      cmd.UserId,
+			LockedUntil: cmd.Until.Unix(),
+			Created:     getTimeNow().Unix(),
+		}
+
+		if _, err := sess.Exec("DELETE FROM login_lockout WHERE user_id = ?", cmd.UserId); err != nil {
+			return err
+		}
+
+		_, err := sess.Insert(&lockout)
+		return err
+	})
+}
+
+func UnlockUser(cmd *models.UnlockUserCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		user := models.User{}
+		has, err := sess.ID(cmd.UserId).Get(&user)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrUserNotFound
+		}
+
+		if _, err := sess.Exec("DELETE FROM login_lockout WHERE user_id = ?", cmd.UserId); err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM login_attempt WHERE username = ? OR username = ?", user.Login, user.Email)
+		return err
+	})
+}
+
+func DeleteExpiredLoginLockouts(cmd *models.DeleteExpiredLoginLockoutsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if cmd.DryRun {
+			_, err := sess.SQL("SELECT COUNT(*) FROM login_lockout WHERE locked_until <= ?", cmd.Now.Unix()).Get(&cmd.DeletedRows)
+			return err
+		}
+
+		result, err := sess.Exec("DELETE FROM login_lockout WHERE locked_until <= ?", cmd.Now.Unix())
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = result.RowsAffected()
+		return err
+	})
+}
+
+func GetUserLockout(query *models.GetUserLockoutQuery) error {
+	lockout := models.LoginLockout{}
+	has, err := x.Where("user_id = ?", query.UserId).Get(&lockout)
+	if err != nil {
+		return err
+	}
+
+	query.Result = time.Time{}
+	if has {
+		query.Result = time.Unix(lockout.LockedUntil, 0)
+	}
+	return nil
+}
+
 func toInt64(i interface{}) int64 {
 	switch i := i.(type) {
 	case []byte:
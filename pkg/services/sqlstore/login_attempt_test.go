@@ -3,11 +3,14 @@
 package sqlstore
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func mockTime(mock time.Time) time.Time {
@@ -114,6 +117,22 @@ func TestLoginAttempts(t *testing.T) {
 			So(cmd.DeletedRows, ShouldEqual, 2)
 		})
 
+		Convey("Should only count the rows to delete in dry run", func() {
+			cmd := models.DeleteOldLoginAttemptsCommand{
+				OlderThan: timePlusTwoMinutes.Add(time.Second * 1),
+				DryRun:    true,
+			}
+			err := DeleteOldLoginAttempts(&cmd)
+
+			So(err, ShouldBeNil)
+			So(cmd.DeletedRows, ShouldEqual, 3)
+
+			query := models.GetUserLoginAttemptCountQuery{Username: user, Since: beginningOfTime.Add(-time.Minute)}
+			err = GetUserLoginAttemptCount(&query)
+			So(err, ShouldBeNil)
+			So(query.Result, ShouldEqual, 3)
+		})
+
 		Convey("Should return deleted rows older than beginning of time + 2min and 1s", func() {
 			cmd := models.DeleteOldLoginAttemptsCommand{
 				OlderThan: timePlusTwoMinutes.Add(time.Second * 1),
@@ -125,3 +144,80 @@ func TestLoginAttempts(t *testing.T) {
 		})
 	})
 }
+
+func TestLoginAttemptsByIP(t *testing.T) {
+	InitTestDB(t)
+
+	now := mockTime(time.Date(2017, 10, 22, 8, 0, 0, 0, time.Local))
+	for _, attempt := range []models.CreateLoginAttemptCommand{
+		{Username: "user", IpAddress: "192.168.0.1"},
+		{Username: "admin", IpAddress: "192.168.0.1"},
+		{Username: "user", IpAddress: "192.168.0.2"},
+	} {
+		attempt := attempt
+		require.NoError(t, CreateLoginAttempt(&attempt))
+		now = mockTime(now.Add(time.Minute))
+	}
+
+	ipQuery := models.GetIPLoginAttemptCountQuery{IpAddress: "192.168.0.1", Since: now.Add(-time.Hour)}
+	require.NoError(t, GetIPLoginAttemptCount(&ipQuery))
+	assert.Equal(t, int64(2), ipQuery.Result)
+
+	userQuery := models.GetUserLoginAttemptCountQuery{Username: "user", IpAddress: "192.168.0.1", Since: now.Add(-time.Hour)}
+	require.NoError(t, GetUserLoginAttemptCount(&userQuery))
+	assert.Equal(t, int64(1), userQuery.Result)
+	assert.Equal(t, now.Add(-3*time.Minute).Unix(), userQuery.LastAttempt.Unix())
+
+	userQuery = models.GetUserLoginAttemptCountQuery{Username: "user", Since: now.Add(-time.Hour)}
+	require.NoError(t, GetUserLoginAttemptCount(&userQuery))
+	assert.Equal(t, int64(2), userQuery.Result)
+	assert.Equal(t, now.Add(-time.Minute).Unix(), userQuery.LastAttempt.Unix())
+
+	userQuery = models.GetUserLoginAttemptCountQuery{Username: "nobody", Since: now.Add(-time.Hour)}
+	require.NoError(t, GetUserLoginAttemptCount(&userQuery))
+	assert.Equal(t, int64(0), userQuery.Result)
+	assert.True(t, userQuery.LastAttempt.IsZero())
+}
+
+func TestLoginLockout(t *testing.T) {
+	InitTestDB(t)
+
+	now := mockTime(time.Date(2017, 10, 22, 8, 0, 0, 0, time.Local))
+	userCmd := models.CreateUserCommand{Login: "user", Email: "user@test.com"}
+	require.NoError(t, CreateUser(context.Background(), &userCmd))
+	userID := userCmd.Result.Id
+
+	query := models.GetUserLockoutQuery{UserId: userID}
+	require.NoError(t, GetUserLockout(&query))
+	assert.True(t, query.Result.IsZero())
+
+	require.NoError(t, LockUser(&models.LockUserCommand{UserId: userID, Until: now.Add(time.Minute)}))
+	require.NoError(t, LockUser(&models.LockUserCommand{UserId: userID, Until: now.Add(time.Hour)}))
+	require.NoError(t, GetUserLockout(&query))
+	assert.Equal(t, now.Add(time.Hour).Unix(), query.Result.Unix())
+
+	for _, username := range []string{"user", "user@test.com", "other"} {
+		require.NoError(t, CreateLoginAttempt(&models.CreateLoginAttemptCommand{Username: username, IpAddress: "192.168.0.1"}))
+	}
+
+	require.NoError(t, UnlockUser(&models.UnlockUserCommand{UserId: userID}))
+	require.NoError(t, GetUserLockout(&query))
+	assert.True(t, query.Result.IsZero())
+
+	ipQuery := models.GetIPLoginAttemptCountQuery{IpAddress: "192.168.0.1", Since: now.Add(-time.Hour)}
+	require.NoError(t, GetIPLoginAttemptCount(&ipQuery))
+	assert.Equal(t, int64(1), ipQuery.Result)
+
+	require.Equal(t, models.ErrUserNotFound, UnlockUser(&models.UnlockUserCommand{UserId: userID + 1}))
+
+	require.NoError(t, LockUser(&models.LockUserCommand{UserId: userID, Until: now.Add(time.Hour)}))
+	deleteCmd := models.DeleteExpiredLoginLockoutsCommand{Now: now.Add(time.Minute)}
+	require.NoError(t, DeleteExpiredLoginLockouts(&deleteCmd))
+	assert.Equal(t, int64(0), deleteCmd.DeletedRows)
+	deleteCmd = models.DeleteExpiredLoginLockoutsCommand{Now: now.Add(time.Hour), DryRun: true}
+	require.NoError(t, DeleteExpiredLoginLockouts(&deleteCmd))
+	assert.Equal(t, int64(1), deleteCmd.DeletedRows)
+	deleteCmd = models.DeleteExpiredLoginLockoutsCommand{Now: now.Add(time.Hour)}
+	require.NoError(t, DeleteExpiredLoginLockouts(&deleteCmd))
+	assert.Equal(t, int64(1), deleteCmd.DeletedRows)
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAlertEvaluationMigrations(mg *Migrator) {
+	alertEvaluationV1 := Table{
+		Name: "alert_evaluation",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "alert_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "data", Type: DB_MediumText, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"alert_id", "id"}},
+			{Cols: []string{"org_id", "alert_id"}},
+		},
+	}
+
+	mg.AddMigration("create alert_evaluation table v1", NewAddTableMigration(alertEvaluationV1))
+	addTableIndicesMigrations(mg, "v1", alertEvaluationV1)
+}
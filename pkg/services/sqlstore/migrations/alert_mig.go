@@ -212,4 +212,8 @@ func addAlertMigrations(mg *Migrator) {
 	mg.AddMigration("Add non-unique index alert_rule_tag_alert_id", NewAddIndexMigration(alertRuleTagTable, &Index{
 		Cols: []string{"alert_id"}, Type: IndexType,
 	}))
+
+	mg.AddMigration("Add column thread_id in alert_notification_state", NewAddColumnMigration(alert_notification_state, &Column{
+		Name: "thread_id", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
 }
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAlertNodeMigrations(mg *Migrator) {
+	alertNodeV1 := Table{
+		Name: "alert_node",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "node_id", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "address", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "started", Type: DB_BigInt, Nullable: false},
+			{Name: "heartbeat", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"node_id"}, Type: UniqueIndex},
+			{Cols: []string{"heartbeat"}},
+		},
+	}
+
+	mg.AddMigration("create alert_node table v1", NewAddTableMigration(alertNodeV1))
+	addTableIndicesMigrations(mg, "v1", alertNodeV1)
+}
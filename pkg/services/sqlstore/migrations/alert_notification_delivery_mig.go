@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAlertNotificationDeliveryMigrations(mg *Migrator) {
+	alertNotificationDeliveryV1 := Table{
+		Name: "alert_notification_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "alert_id", Type: DB_BigInt, Nullable: false},
+			{Name: "notifier_id", Type: DB_BigInt, Nullable: false},
+			{Name: "notifier_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "notifier_type", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "notification_state_id", Type: DB_BigInt, Nullable: false},
+			{Name: "notification_state_version", Type: DB_BigInt, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false},
+			{Name: "next_attempt", Type: DB_BigInt, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"state", "next_attempt"}},
+			{Cols: []string{"org_id", "state"}},
+			{Cols: []string{"alert_id"}},
+			{Cols: []string{"notifier_id"}},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table v1", NewAddTableMigration(alertNotificationDeliveryV1))
+	addTableIndicesMigrations(mg, "v1", alertNotificationDeliveryV1)
+}
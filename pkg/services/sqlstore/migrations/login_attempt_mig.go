@@ -39,4 +39,28 @@ func addLoginAttemptMigrations(mg *Migrator) {
 		"username":   "username",
 		"ip_address": "ip_address",
 	})
+
+	mg.AddMigration("alter login_attempt.ip_address to length 50", NewRawSQLMigration("").
+		Postgres("ALTER TABLE login_attempt ALTER COLUMN ip_address TYPE VARCHAR(50);").
+		Mysql("ALTER TABLE login_attempt MODIFY ip_address VARCHAR(50) NOT NULL;"))
+
+	mg.AddMigration("add index login_attempt.ip_address", NewAddIndexMigration(loginAttemptV2, &Index{
+		Cols: []string{"ip_address"},
+	}))
+
+	loginLockoutV1 := Table{
+		Name: "login_lockout",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "locked_until", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create login lockout table", NewAddTableMigration(loginLockoutV1))
+	mg.AddMigration("add unique index login_lockout.user_id", NewAddIndexMigration(loginLockoutV1, loginLockoutV1.Indices[0]))
 }
@@ -35,6 +35,11 @@ func AddMigrations(mg *Migrator) {
 	addUserAuthTokenMigrations(mg)
 	addCacheMigration(mg)
 	addShortURLMigrations(mg)
+	addServerLeaseMigrations(mg)
+	addAuditLogMigrations(mg)
+	addAlertNodeMigrations(mg)
+	addAlertEvaluationMigrations(mg)
+	addAlertNotificationDeliveryMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
@@ -20,3 +20,23 @@ func addServerlockMigrations(mg *migrator.Migrator) {
 
 	mg.AddMigration("add index server_lock.operation_uid", migrator.NewAddIndexMigration(serverLock, serverLock.Indices[0]))
 }
+
+func addServerLeaseMigrations(mg *migrator.Migrator) {
+	serverLease := migrator.Table{
+		Name: "server_lease",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "operation_uid", Type: migrator.DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "holder", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "fencing_token", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"operation_uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create server_lease table", migrator.NewAddTableMigration(serverLease))
+
+	mg.AddMigration("add index server_lease.operation_uid", migrator.NewAddIndexMigration(serverLease, serverLease.Indices[0]))
+}
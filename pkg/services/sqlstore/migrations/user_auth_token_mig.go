@@ -32,4 +32,8 @@ func addUserAuthTokenMigrations(mg *Migrator) {
 	mg.AddMigration("add unique index user_auth_token.prev_auth_token", NewAddIndexMigration(userAuthTokenV1, userAuthTokenV1.Indices[1]))
 
 	mg.AddMigration("add index user_auth_token.user_id", NewAddIndexMigration(userAuthTokenV1, userAuthTokenV1.Indices[2]))
+
+	mg.AddMigration("add label column to user_auth_token", NewAddColumnMigration(userAuthTokenV1, &Column{
+		Name: "label", Type: DB_NVarchar, Length: 100, Nullable: true,
+	}))
 }
@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -11,6 +12,7 @@ import (
 
 func init() {
 	bus.AddHandler("sql", GetOrgQuotaByTarget)
+	bus.AddHandler("sql", GetOrgQuotaLimit)
 	bus.AddHandler("sql", GetOrgQuotas)
 	bus.AddHandler("sql", UpdateOrgQuota)
 	bus.AddHandler("sql", GetUserQuotaByTarget)
@@ -36,9 +38,8 @@ func GetOrgQuotaByTarget(query *models.GetOrgQuotaByTargetQuery) error {
 	}
 
 	// get quota used.
-	rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s where org_id=?", dialect.Quote(query.Target))
-	resp := make([]*targetCount, 0)
-	if err := x.SQL(rawSQL, query.OrgId).Find(&resp); err != nil {
+	used, err := countQuotaTarget(query.Target, "org_id=?", query.OrgId)
+	if err != nil {
 		return err
 	}
 
@@ -46,12 +47,31 @@ func GetOrgQuotaByTarget(query *models.GetOrgQuotaByTargetQuery) error {
 		Target: query.Target,
 		Limit:  quota.Limit,
 		OrgId:  query.OrgId,
-		Used:   resp[0].Count,
+		Used:   used,
 	}
 
 	return nil
 }
 
+// GetOrgQuotaLimit returns the limit of the org quota without counting the
+// usage, for the rate quotas whose usage isn't stored in the database.
+func GetOrgQuotaLimit(query *models.GetOrgQuotaLimitQuery) error {
+	quota := models.Quota{
+		Target: query.Target,
+		OrgId:  query.OrgId,
+	}
+	has, err := x.Get(&quota)
+	if err != nil {
+		return err
+	}
+
+	query.Result = query.Default
+	if has {
+		query.Result = quota.Limit
+	}
+	return nil
+}
+
 func GetOrgQuotas(query *models.GetOrgQuotasQuery) error {
 	quotas := make([]*models.Quota, 0)
 	sess := x.Table("quota")
@@ -61,6 +81,15 @@ func GetOrgQuotas(query *models.GetOrgQuotasQuery) error {
 
 	defaultQuotas := setting.Quota.Org.ToMap()
 
+	// the usage of the rate quotas isn't stored in the database
+	countedQuotas := make([]*models.Quota, 0, len(quotas))
+	for _, q := range quotas {
+		if _, ok := defaultQuotas[q.Target]; ok {
+			countedQuotas = append(countedQuotas, q)
+		}
+	}
+	quotas = countedQuotas
+
 	seenTargets := make(map[string]bool)
 	for _, q := range quotas {
 		seenTargets[q.Target] = true
@@ -79,16 +108,15 @@ func GetOrgQuotas(query *models.GetOrgQuotasQuery) error {
 	result := make([]*models.OrgQuotaDTO, len(quotas))
 	for i, q := range quotas {
 		// get quota used.
-		rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s where org_id=?", dialect.Quote(q.Target))
-		resp := make([]*targetCount, 0)
-		if err := x.SQL(rawSQL, q.OrgId).Find(&resp); err != nil {
+		used, err := countQuotaTarget(q.Target, "org_id=?", q.OrgId)
+		if err != nil {
 			return err
 		}
 		result[i] = &models.OrgQuotaDTO{
 			Target: q.Target,
 			Limit:  q.Limit,
 			OrgId:  q.OrgId,
-			Used:   resp[0].Count,
+			Used:   used,
 		}
 	}
 	query.Result = result
@@ -231,17 +259,51 @@ func UpdateUserQuota(cmd *models.UpdateUserQuotaCmd) error {
 
 func GetGlobalQuotaByTarget(query *models.GetGlobalQuotaByTargetQuery) error {
 	// get quota used.
-	rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s", dialect.Quote(query.Target))
-	resp := make([]*targetCount, 0)
-	if err := x.SQL(rawSQL).Find(&resp); err != nil {
+	used, err := countQuotaTarget(query.Target, "")
+	if err != nil {
 		return err
 	}
 
 	query.Result = &models.GlobalQuotaDTO{
 		Target: query.Target,
 		Limit:  query.Default,
-		Used:   resp[0].Count,
+		Used:   used,
 	}
 
 	return nil
 }
+
+// quotaTargetConditions are the conditions of the rows counted for the quota
+// targets that don't count all the rows of their table.
+var quotaTargetConditions = map[string]string{
+	// the annotations of alerts are created by Grafana, not by the users
+	"annotation": "alert_id = 0",
+}
+
+// countQuotaTarget counts the rows of the table of the quota target matching
+// the condition. The tables of features that are disabled, like library panels,
+// may not exist, in which case nothing is counted.
+func countQuotaTarget(target string, condition string, args ...interface{}) (int64, error) {
+	exists, err := x.IsTableExist(target)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	conditions := make([]string, 0, 2)
+	if condition != "" {
+		conditions = append(conditions, condition)
+	}
+	if targetCondition, ok := quotaTargetConditions[target]; ok {
+		conditions = append(conditions, targetCondition)
+	}
+
+	rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s", dialect.Quote(target))
+	if len(conditions) > 0 {
+		rawSQL += " where " + strings.Join(conditions, " AND ")
+	}
+	resp := make([]*targetCount, 0)
+	if err := x.SQL(rawSQL, args...).Find(&resp); err != nil {
+		return 0, err
+	}
+	return resp[0].Count, nil
+}
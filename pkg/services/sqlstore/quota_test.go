@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/setting"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -24,6 +25,12 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 				Dashboard:  5,
 				DataSource: 5,
 				ApiKey:     5,
+
+				LibraryPanel:      5,
+				AlertDefinition:   5,
+				Annotation:        5,
+				ShortURL:          5,
+				DashboardSnapshot: 5,
 			},
 			User: &setting.UserQuota{
 				Org: 5,
@@ -92,7 +99,7 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 				err = GetOrgQuotas(&query)
 
 				So(err, ShouldBeNil)
-				So(len(query.Result), ShouldEqual, 4)
+				So(len(query.Result), ShouldEqual, 9)
 				for _, res := range query.Result {
 					limit := 5 // default quota limit
 					used := 0
@@ -105,6 +112,62 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 				}
 			})
 		})
+		Convey("Given saved org rate quota for queries", func() {
+			orgCmd := models.UpdateOrgQuotaCmd{
+				OrgId:  orgId,
+				Target: "query",
+				Limit:  100,
+			}
+			err := UpdateOrgQuota(&orgCmd)
+			So(err, ShouldBeNil)
+
+			Convey("Should be able to get saved quota limit by org id and target", func() {
+				query := models.GetOrgQuotaLimitQuery{OrgId: orgId, Target: "query", Default: 1}
+				err = GetOrgQuotaLimit(&query)
+
+				So(err, ShouldBeNil)
+				So(query.Result, ShouldEqual, 100)
+			})
+			Convey("Should be able to get default quota limit by org id and target", func() {
+				query := models.GetOrgQuotaLimitQuery{OrgId: orgId, Target: "render", Default: 11}
+				err = GetOrgQuotaLimit(&query)
+
+				So(err, ShouldBeNil)
+				So(query.Result, ShouldEqual, 11)
+			})
+			Convey("Should not count rate quota in quota list for org", func() {
+				query := models.GetOrgQuotasQuery{OrgId: orgId}
+				err = GetOrgQuotas(&query)
+
+				So(err, ShouldBeNil)
+				So(len(query.Result), ShouldEqual, 9)
+				for _, res := range query.Result {
+					So(res.Target, ShouldNotEqual, "query")
+				}
+			})
+		})
+
+		Convey("Should count org quota of tables of disabled features as unused", func() {
+			query := models.GetOrgQuotaByTargetQuery{OrgId: orgId, Target: "library_panel", Default: 5}
+			err = GetOrgQuotaByTarget(&query)
+
+			So(err, ShouldBeNil)
+			So(query.Result.Used, ShouldEqual, 0)
+		})
+
+		Convey("Should not count the annotations of alerts in org quota", func() {
+			for _, alertID := range []int64{0, 1} {
+				_, err := x.Table("annotation").Insert(&annotations.Item{OrgId: orgId, AlertId: alertID, Text: "annotation"})
+				So(err, ShouldBeNil)
+			}
+
+			query := models.GetOrgQuotaByTargetQuery{OrgId: orgId, Target: "annotation", Default: 5}
+			err = GetOrgQuotaByTarget(&query)
+
+			So(err, ShouldBeNil)
+			So(query.Result.Used, ShouldEqual, 1)
+		})
+
 		Convey("Given saved user quota for org", func() {
 			userQuotaCmd := models.UpdateUserQuotaCmd{
 				UserId: userId,
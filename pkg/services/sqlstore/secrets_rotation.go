@@ -0,0 +1,181 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/secrets"
+)
+
+// secretColumn is a database column that stores encrypted secrets.
+type secretColumn struct {
+	table  string
+	column string
+	// blob is true if the column is a binary column rather than a text column.
+	blob bool
+	// reencrypt re-encrypts the secrets of a column value with the current key.
+	reencrypt func(value []byte) ([]byte, bool, error)
+}
+
+var secretColumns = []secretColumn{
+	{table: "data_source", column: "secure_json_data", reencrypt: reencryptSecureJSONData},
+	{table: "plugin_setting", column: "secure_json_data", reencrypt: reencryptSecureJSONData},
+	{table: "alert_notification", column: "secure_settings", reencrypt: reencryptSecureJSONData},
+	{table: "user_auth", column: "o_auth_access_token", reencrypt: reencryptBase64},
+	{table: "user_auth", column: "o_auth_refresh_token", reencrypt: reencryptBase64},
+	{table: "user_auth", column: "o_auth_token_type", reencrypt: reencryptBase64},
+	{table: "dashboard_snapshot", column: "dashboard_encrypted", blob: true, reencrypt: secrets.Reencrypt},
+}
+
+// ReencryptedSecrets is the number of rows of a column whose secrets have
+// been re-encrypted.
+type ReencryptedSecrets struct {
+	Table  string
+	Column string
+	Rows   int64
+}
+
+// ReencryptSecrets re-encrypts all the secrets stored in the database with
+// the current encryption key. Rows are re-encrypted in batches of batchSize
+// rows, each in its own transaction, so that the secrets remain available
+// while they are re-encrypted. Rows that are modified while being
+// re-encrypted are left untouched. progress, if not nil, is called after
+// every batch.
+//
+// Returns the number of rows re-encrypted per column. If an error occurs, it
+// returns the rows re-encrypted so far.
+func (ss *SQLStore) ReencryptSecrets(ctx context.Context, batchSize int64, progress func(ReencryptedSecrets)) ([]ReencryptedSecrets, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d", batchSize)
+	}
+
+	result := make([]ReencryptedSecrets, 0, len(secretColumns))
+	for _, c := range secretColumns {
+		reencrypted := ReencryptedSecrets{Table: c.table, Column: c.column}
+		var lastID int64
+		for {
+			if err := ctx.Err(); err != nil {
+				return append(result, reencrypted), err
+			}
+
+			var rows int
+			var affected int64
+			err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+				var err error
+				rows, lastID, affected, err = reencryptBatch(sess, c, lastID, batchSize)
+				return err
+			})
+			reencrypted.Rows += affected
+			if err != nil {
+				return append(result, reencrypted), fmt.Errorf("failed to re-encrypt %s.%s: %w", c.table, c.column, err)
+			}
+			if progress != nil {
+				progress(reencrypted)
+			}
+			if int64(rows) < batchSize {
+				break
+			}
+		}
+		result = append(result, reencrypted)
+	}
+
+	return result, nil
+}
+
+// reencryptBatch re-encrypts the secrets of the batchSize rows following
+// lastID. Returns the number of rows read, the ID of the last one and the
+// number of rows updated.
+func reencryptBatch(sess *DBSession, c secretColumn, lastID int64, batchSize int64) (int, int64, int64, error) {
+	rawSQL := fmt.Sprintf("SELECT id, %s AS value FROM %s WHERE id > ? ORDER BY id%s",
+		dialect.Quote(c.column), dialect.Quote(c.table), dialect.Limit(batchSize))
+	rows, err := sess.Query(rawSQL, lastID)
+	if err != nil {
+		return 0, lastID, 0, err
+	}
+
+	var affected int64
+	for _, row := range rows {
+		id, err := strconv.ParseInt(string(row["id"]), 10, 64)
+		if err != nil {
+			return 0, lastID, affected, err
+		}
+		lastID = id
+
+		value := row["value"]
+		if len(value) == 0 {
+			continue
+		}
+
+		reencrypted, changed, err := c.reencrypt(value)
+		if err != nil {
+			return 0, lastID, affected, fmt.Errorf("row %d: %w", id, err)
+		}
+		if !changed {
+			continue
+		}
+
+		var newValue, oldValue interface{} = reencrypted, value
+		if !c.blob {
+			newValue, oldValue = string(reencrypted), string(value)
+		}
+		res, err := sess.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ? AND %s = ?",
+			dialect.Quote(c.table), dialect.Quote(c.column), dialect.Quote(c.column)), newValue, id, oldValue)
+		if err != nil {
+			return 0, lastID, affected, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, lastID, affected, err
+		}
+		affected += n
+	}
+
+	return len(rows), lastID, affected, nil
+}
+
+// reencryptSecureJSONData re-encrypts the values of a JSON encoded
+// securejsondata.SecureJsonData.
+func reencryptSecureJSONData(value []byte) ([]byte, bool, error) {
+	var data map[string][]byte
+	if err := json.Unmarshal(value, &data); err != nil {
+		return nil, false, err
+	}
+
+	changed := false
+	for key, encrypted := range data {
+		if len(encrypted) == 0 {
+			continue
+		}
+		reencrypted, ok, err := secrets.Reencrypt(encrypted)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", key, err)
+		}
+		if ok {
+			data[key] = reencrypted
+			changed = true
+		}
+	}
+	if !changed {
+		return value, false, nil
+	}
+
+	reencrypted, err := json.Marshal(data)
+	return reencrypted, err == nil, err
+}
+
+// reencryptBase64 re-encrypts a base64 encoded secret.
+func reencryptBase64(value []byte) ([]byte, bool, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(value))
+	if err != nil {
+		return nil, false, err
+	}
+
+	reencrypted, changed, err := secrets.Reencrypt(decoded)
+	if err != nil || !changed {
+		return value, false, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(reencrypted)), true, nil
+}
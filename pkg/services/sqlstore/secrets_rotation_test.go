@@ -0,0 +1,103 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/secrets"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencryptSecrets(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	v1, err := secrets.NewStaticKeyProvider("v1", map[string]string{"v1": "first secret"})
+	require.NoError(t, err)
+	v2, err := secrets.NewStaticKeyProvider("v2", map[string]string{"v1": "first secret", "v2": "second secret"})
+	require.NoError(t, err)
+	v2Only, err := secrets.NewStaticKeyProvider("v2", map[string]string{"v2": "second secret"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		secrets.SetKeyProvider(nil)
+	})
+
+	secrets.SetKeyProvider(v1)
+	for _, name := range []string{"first", "second", "legacy"} {
+		err := AddDataSource(&models.AddDataSourceCommand{
+			OrgId:          1,
+			Name:           name,
+			Type:           models.DS_GRAPHITE,
+			Access:         models.DS_ACCESS_PROXY,
+			Url:            "http://test",
+			SecureJsonData: map[string]string{"password": name + " password"},
+		})
+		require.NoError(t, err)
+	}
+
+	legacyPassword, err := util.Encrypt([]byte("legacy password"), setting.SecretKey)
+	require.NoError(t, err)
+	legacySecureJSONData, err := json.Marshal(map[string][]byte{"password": legacyPassword})
+	require.NoError(t, err)
+	_, err = x.Exec("UPDATE data_source SET secure_json_data = ? WHERE name = ?", string(legacySecureJSONData), "legacy")
+	require.NoError(t, err)
+
+	snapshotCmd := &models.CreateDashboardSnapshotCommand{
+		Key:       "key",
+		DeleteKey: "delete-key",
+		OrgId:     1,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "snapshot"}),
+	}
+	require.NoError(t, CreateDashboardSnapshot(snapshotCmd))
+
+	secrets.SetKeyProvider(v2)
+	var progress []ReencryptedSecrets
+	result, err := sqlStore.ReencryptSecrets(context.Background(), 2, func(r ReencryptedSecrets) {
+		progress = append(progress, r)
+	})
+	require.NoError(t, err)
+
+	rows := map[string]int64{}
+	for _, r := range result {
+		rows[r.Table+"."+r.Column] = r.Rows
+	}
+	assert.Equal(t, int64(3), rows["data_source.secure_json_data"])
+	assert.Equal(t, int64(1), rows["dashboard_snapshot.dashboard_encrypted"])
+	assert.Equal(t, int64(0), rows["user_auth.o_auth_access_token"])
+	assert.Contains(t, progress, ReencryptedSecrets{Table: "data_source", Column: "secure_json_data", Rows: 2})
+
+	t.Run("secrets can be decrypted with the new key only", func(t *testing.T) {
+		secrets.SetKeyProvider(v2Only)
+
+		for _, name := range []string{"first", "second", "legacy"} {
+			query := &models.GetDataSourceQuery{OrgId: 1, Name: name}
+			require.NoError(t, GetDataSource(query))
+			password, ok := query.Result.SecureJsonData.DecryptedValue("password")
+			require.True(t, ok)
+			assert.Equal(t, name+" password", password)
+		}
+
+		query := &models.GetDashboardSnapshotQuery{Key: "key"}
+		require.NoError(t, GetDashboardSnapshot(query))
+		dashboard, err := query.Result.DashboardEncrypted.Decrypt()
+		require.NoError(t, err)
+		assert.Contains(t, string(dashboard), "snapshot")
+	})
+
+	t.Run("secrets encrypted with the current key are not re-encrypted", func(t *testing.T) {
+		secrets.SetKeyProvider(v2)
+
+		result, err := sqlStore.ReencryptSecrets(context.Background(), 2, nil)
+		require.NoError(t, err)
+		for _, r := range result {
+			assert.Equal(t, int64(0), r.Rows, "%s.%s", r.Table, r.Column)
+		}
+	})
+}
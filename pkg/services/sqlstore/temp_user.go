@@ -136,6 +136,11 @@ func GetTempUserByCode(query *models.GetTempUserByCodeQuery) error {
 
 func ExpireOldUserInvites(cmd *models.ExpireTempUsersCommand) error {
 	return inTransaction(func(sess *DBSession) error {
+		if cmd.DryRun {
+			_, err := sess.SQL("SELECT COUNT(*) FROM temp_user WHERE created <= ? AND status in (?, ?)", cmd.OlderThan.Unix(), string(models.TmpUserSignUpStarted), string(models.TmpUserInvitePending)).Get(&cmd.NumExpired)
+			return err
+		}
+
 		var rawSQL = "UPDATE temp_user SET status = ?, updated = ? WHERE created <= ? AND status in (?, ?)"
 		if result, err := sess.Exec(rawSQL, string(models.TmpUserExpired), time.Now().Unix(), cmd.OlderThan.Unix(), string(models.TmpUserSignUpStarted), string(models.TmpUserInvitePending)); err != nil {
 			return err
@@ -86,6 +86,9 @@ var (
 
 	// Security settings.
 	SecretKey              string
+	EncryptionProvider     string
+	EncryptionKeysFile     string
+	EncryptionKeyID        string
 	DisableGravatar        bool
 	EmailCodeValidMinutes  int
 	DataProxyWhiteList     map[string]bool
@@ -168,6 +171,13 @@ var (
 	AlertingMaxAttempts         int
 	AlertingMinInterval         int64
 
+	AlertingEvaluationHistoryLimit int
+
+	AlertingNotificationMaxAttempts        int
+	AlertingNotificationRetryBackoff       time.Duration
+	AlertingNotificationRetryMaxBackoff    time.Duration
+	AlertingNotificationChannelConcurrency int
+
 	// Explore UI
 	ExploreEnabled bool
 
@@ -226,6 +236,7 @@ type Cfg struct {
 	// Security
 	DisableInitAdminCreation          bool
 	DisableBruteForceLoginProtection  bool
+	BruteForceLoginProtection         BruteForceLoginProtection
 	CookieSecure                      bool
 	CookieSameSiteDisabled            bool
 	CookieSameSiteMode                http.SameSite
@@ -265,6 +276,7 @@ type Cfg struct {
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
 	TokenRotationIntervalMinutes int
+	MaxConcurrentSessions        int
 	SigV4AuthEnabled             bool
 	BasicAuthEnabled             bool
 	AdminUser                    string
@@ -310,14 +322,31 @@ type Cfg struct {
 	UserInviteMaxLifetime time.Duration
 	HiddenUsers           map[string]struct{}
 
+	// Alerting HA
+	AlertingHAEnabled           bool
+	AlertingHAHeartbeatInterval time.Duration
+	AlertingHANodeTimeout       time.Duration
+
+	// Alerting NG
+	NGAlertStateHistoryMaxAge time.Duration
+
 	// Annotations
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
 
+	// Retention policies of the cleanup service
+	Retention RetentionSettings
+
+	// Audit log
+	Audit AuditSettings
+
 	// Sentry config
 	Sentry Sentry
 
+	// Rate limits of the route groups with rate limiting enabled
+	RateLimits map[string]RateLimitSettings
+
 	// Data sources
 	DataSourceLimit int
 
@@ -482,10 +511,7 @@ func (cfg *Cfg) readAnnotationSettings() {
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
 }
 
-type AnnotationCleanupSettings struct {
-	MaxAge   time.Duration
-	MaxCount int64
-}
+type AnnotationCleanupSettings = RetentionPolicy
 
 func envKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
@@ -814,6 +840,12 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	if err := readAlertingSettings(iniFile); err != nil {
 		return err
 	}
+	if err := cfg.readAlertingHASettings(iniFile); err != nil {
+		return err
+	}
+	if err := cfg.readNGAlertSettings(iniFile); err != nil {
+		return err
+	}
 
 	explore := iniFile.Section("explore")
 	ExploreEnabled = explore.Key("enabled").MustBool(true)
@@ -877,13 +909,27 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	connStr := valueAsString(cacheServer, "connstr", "")
 
 	cfg.RemoteCacheOptions = &RemoteCacheOptions{
-		Name:    dbName,
-		ConnStr: connStr,
+		Name:     dbName,
+		ConnStr:  connStr,
+		Prefix:   valueAsString(cacheServer, "prefix", ""),
+		Encoding: valueAsString(cacheServer, "encoding", "gob"),
 	}
 
 	cfg.readDateFormats()
 	cfg.readSentryConfig()
 
+	if err := cfg.readRateLimitSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readRetentionSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readAuditSettings(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -892,8 +938,10 @@ func valueAsString(section *ini.Section, keyName string, defaultValue string) st
 }
 
 type RemoteCacheOptions struct {
-	Name    string
-	ConnStr string
+	Name     string
+	ConnStr  string
+	Prefix   string
+	Encoding string
 }
 
 func (cfg *Cfg) readLDAPConfig() {
@@ -992,8 +1040,25 @@ func (cfg *Cfg) SectionWithEnvOverrides(s string) *DynamicSection {
 func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	security := iniFile.Section("security")
 	SecretKey = valueAsString(security, "secret_key", "")
+	EncryptionProvider = valueAsString(security, "encryption_provider", "secret_key")
+	EncryptionKeysFile = makeAbsolute(valueAsString(security, "encryption_keys_file", ""), HomePath)
+	EncryptionKeyID = valueAsString(security, "encryption_key_id", "")
+	switch EncryptionProvider {
+	case "secret_key":
+	case "file", "env":
+		if EncryptionKeyID == "" {
+			return fmt.Errorf("encryption_key_id in [security] is required by the %q encryption provider", EncryptionProvider)
+		}
+	default:
+		return fmt.Errorf("invalid encryption_provider in [security]: %q", EncryptionProvider)
+	}
 	DisableGravatar = security.Key("disable_gravatar").MustBool(true)
 	cfg.DisableBruteForceLoginProtection = security.Key("disable_brute_force_login_protection").MustBool(false)
+	bruteForceLoginProtection, err := readBruteForceLoginProtectionSettings(security)
+	if err != nil {
+		return err
+	}
+	cfg.BruteForceLoginProtection = bruteForceLoginProtection
 
 	CookieSecure = security.Key("cookie_secure").MustBool(false)
 	cfg.CookieSecure = CookieSecure
@@ -1081,6 +1146,7 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	if cfg.TokenRotationIntervalMinutes < 2 {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
+	cfg.MaxConcurrentSessions = auth.Key("max_concurrent_sessions_per_user").MustInt(0)
 
 	DisableLoginForm = auth.Key("disable_login_form").MustBool(false)
 	DisableSignoutMenu = auth.Key("disable_signout_menu").MustBool(false)
@@ -1225,6 +1291,62 @@ func readAlertingSettings(iniFile *ini.File) error {
 	AlertingNotificationTimeout = time.Second * time.Duration(notificationTimeoutSeconds)
 	AlertingMaxAttempts = alerting.Key("max_attempts").MustInt(3)
 	AlertingMinInterval = alerting.Key("min_interval_seconds").MustInt64(1)
+	AlertingEvaluationHistoryLimit = alerting.Key("evaluation_history_limit").MustInt(20)
+
+	AlertingNotificationMaxAttempts = alerting.Key("notification_max_attempts").MustInt(5)
+	retryBackoff, err := gtime.ParseDuration(valueAsString(alerting, "notification_retry_backoff", "30s"))
+	if err != nil {
+		return fmt.Errorf("invalid notification_retry_backoff in [alerting] configuration: %w", err)
+	}
+	retryMaxBackoff, err := gtime.ParseDuration(valueAsString(alerting, "notification_retry_max_backoff", "1h"))
+	if err != nil {
+		return fmt.Errorf("invalid notification_retry_max_backoff in [alerting] configuration: %w", err)
+	}
+	if retryBackoff <= 0 {
+		return fmt.Errorf("notification_retry_backoff in [alerting] configuration must be positive")
+	}
+	if retryMaxBackoff < retryBackoff {
+		return fmt.Errorf("notification_retry_max_backoff in [alerting] configuration must not be less than notification_retry_backoff")
+	}
+	AlertingNotificationRetryBackoff = retryBackoff
+	AlertingNotificationRetryMaxBackoff = retryMaxBackoff
+	AlertingNotificationChannelConcurrency = alerting.Key("notification_concurrency_per_channel").MustInt(4)
+
+	return nil
+}
+
+func (cfg *Cfg) readAlertingHASettings(iniFile *ini.File) error {
+	alerting := iniFile.Section("alerting")
+	cfg.AlertingHAEnabled = alerting.Key("ha_enabled").MustBool(false)
+
+	heartbeatInterval, err := gtime.ParseDuration(valueAsString(alerting, "ha_heartbeat_interval", "10s"))
+	if err != nil {
+		return fmt.Errorf("invalid ha_heartbeat_interval in [alerting] configuration: %w", err)
+	}
+	nodeTimeout, err := gtime.ParseDuration(valueAsString(alerting, "ha_node_timeout", "30s"))
+	if err != nil {
+		return fmt.Errorf("invalid ha_node_timeout in [alerting] configuration: %w", err)
+	}
+	if heartbeatInterval <= 0 {
+		return fmt.Errorf("ha_heartbeat_interval in [alerting] configuration must be positive")
+	}
+	if nodeTimeout <= heartbeatInterval {
+		return fmt.Errorf("ha_node_timeout in [alerting] configuration must be greater than ha_heartbeat_interval")
+	}
+	cfg.AlertingHAHeartbeatInterval = heartbeatInterval
+	cfg.AlertingHANodeTimeout = nodeTimeout
+
+	return nil
+}
+
+func (cfg *Cfg) readNGAlertSettings(iniFile *ini.File) error {
+	alerting := iniFile.Section("alerting")
+
+	maxAge, err := gtime.ParseDuration(valueAsString(alerting, "state_history_max_age", "30d"))
+	if err != nil {
+		return fmt.Errorf("invalid state_history_max_age in [alerting] configuration: %w", err)
+	}
+	cfg.NGAlertStateHistoryMaxAge = maxAge
 
 	return nil
 }
@@ -0,0 +1,81 @@
+package setting
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Audit log sinks
+const (
+	AuditSinkSQL     = "sql"
+	AuditSinkFile    = "file"
+	AuditSinkWebhook = "webhook"
+)
+
+// AuditSettings configures the audit log of administrative and data-changing actions.
+type AuditSettings struct {
+	Enabled bool
+	// Sinks are the destinations of the audit log entries
+	Sinks []string
+	// FilePath is the file the file sink appends JSON entries to
+	FilePath string
+	// WebhookURL is the URL the webhook sink posts JSON entries to
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	// QueueSize is the number of entries waiting to be written to the
+	// sinks, after which new entries are dropped
+	QueueSize int
+}
+
+// HasSink returns true if the audit log entries are written to the sink
+func (s AuditSettings) HasSink(sink string) bool {
+	for _, name := range s.Sinks {
+		if name == sink {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *Cfg) readAuditSettings() error {
+	section := cfg.Raw.Section("audit")
+
+	cfg.Audit = AuditSettings{
+		Enabled:    section.Key("enabled").MustBool(false),
+		Sinks:      util.SplitString(valueAsString(section, "sinks", AuditSinkSQL)),
+		WebhookURL: valueAsString(section, "webhook_url", ""),
+		QueueSize:  section.Key("queue_size").MustInt(1000),
+	}
+
+	for _, sink := range cfg.Audit.Sinks {
+		switch sink {
+		case AuditSinkSQL, AuditSinkFile, AuditSinkWebhook:
+		default:
+			return fmt.Errorf("unknown audit sink %q in [audit]", sink)
+		}
+	}
+
+	cfg.Audit.FilePath = valueAsString(section, "file_path", "audit.log")
+	if !filepath.IsAbs(cfg.Audit.FilePath) {
+		cfg.Audit.FilePath = filepath.Join(cfg.LogsPath, cfg.Audit.FilePath)
+	}
+
+	timeout, err := gtime.ParseDuration(valueAsString(section, "webhook_timeout", "10s"))
+	if err != nil {
+		return fmt.Errorf("invalid webhook_timeout in [audit]: %w", err)
+	}
+	cfg.Audit.WebhookTimeout = timeout
+
+	if cfg.Audit.Enabled && cfg.Audit.HasSink(AuditSinkWebhook) && cfg.Audit.WebhookURL == "" {
+		return fmt.Errorf("webhook_url is required in [audit] to use the webhook sink")
+	}
+	if cfg.Audit.QueueSize < 1 {
+		cfg.Audit.QueueSize = 1
+	}
+
+	return nil
+}
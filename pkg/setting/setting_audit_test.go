@@ -0,0 +1,69 @@
+package setting
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestAuditSettings(t *testing.T) {
+	loadAudit := func(t *testing.T, config string) (*Cfg, error) {
+		t.Helper()
+
+		raw, err := ini.Load([]byte(config))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		cfg.Raw = raw
+		cfg.LogsPath = "/var/log/grafana"
+		return cfg, cfg.readAuditSettings()
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := loadAudit(t, "")
+		require.NoError(t, err)
+		require.Equal(t, AuditSettings{
+			Sinks:          []string{AuditSinkSQL},
+			FilePath:       filepath.Join("/var/log/grafana", "audit.log"),
+			WebhookTimeout: 10 * time.Second,
+			QueueSize:      1000,
+		}, cfg.Audit)
+	})
+
+	t.Run("All sinks", func(t *testing.T) {
+		cfg, err := loadAudit(t, `
+[audit]
+enabled = true
+sinks = sql, file webhook
+file_path = /tmp/audit.log
+webhook_url = http://localhost:8080/audit
+webhook_timeout = 2s
+`)
+		require.NoError(t, err)
+		require.True(t, cfg.Audit.Enabled)
+		require.Equal(t, []string{AuditSinkSQL, AuditSinkFile, AuditSinkWebhook}, cfg.Audit.Sinks)
+		require.True(t, cfg.Audit.HasSink(AuditSinkWebhook))
+		require.Equal(t, "/tmp/audit.log", cfg.Audit.FilePath)
+		require.Equal(t, 2*time.Second, cfg.Audit.WebhookTimeout)
+	})
+
+	t.Run("Unknown sink", func(t *testing.T) {
+		_, err := loadAudit(t, `
+[audit]
+sinks = syslog
+`)
+		require.EqualError(t, err, `unknown audit sink "syslog" in [audit]`)
+	})
+
+	t.Run("Webhook sink without URL", func(t *testing.T) {
+		_, err := loadAudit(t, `
+[audit]
+enabled = true
+sinks = webhook
+`)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,60 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"gopkg.in/ini.v1"
+)
+
+// BruteForceLoginProtection configures the protection against brute-force
+// login attacks, in addition to the limit of failed login attempts per
+// username. Zero values disable the corresponding protection. The protections
+// keyed on the IP address are disabled by default, as the address is the one
+// of the connection, shared by all users behind a reverse proxy.
+type BruteForceLoginProtection struct {
+	// MaxAttemptsPerIP is the number of failed login attempts from the same IP
+	// address, across all usernames, after which logins from the IP address
+	// are blocked.
+	MaxAttemptsPerIP int64
+	// BackoffAttempts is the number of failed login attempts for the same
+	// username from the same IP address after which further attempts are
+	// delayed, starting with BackoffBase and doubling with every failed
+	// attempt up to BackoffMax.
+	BackoffAttempts int64
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+	// LockoutDuration is the duration user accounts are locked for once the
+	// limit of failed login attempts per username is reached.
+	LockoutDuration time.Duration
+	// LockoutNotification enables emailing users when their account is locked.
+	LockoutNotification bool
+}
+
+func readBruteForceLoginProtectionSettings(security *ini.Section) (BruteForceLoginProtection, error) {
+	settings := BruteForceLoginProtection{
+		MaxAttemptsPerIP:    security.Key("brute_force_max_attempts_per_ip").MustInt64(0),
+		BackoffAttempts:     security.Key("brute_force_backoff_attempts").MustInt64(0),
+		LockoutNotification: security.Key("brute_force_lockout_notification").MustBool(false),
+	}
+
+	durations := []struct {
+		key          string
+		defaultValue string
+		value        *time.Duration
+	}{
+		{key: "brute_force_backoff_base", defaultValue: "1s", value: &settings.BackoffBase},
+		{key: "brute_force_backoff_max", defaultValue: "1m", value: &settings.BackoffMax},
+		{key: "brute_force_lockout_duration", defaultValue: "0", value: &settings.LockoutDuration},
+	}
+	for _, d := range durations {
+		value, err := gtime.ParseDuration(valueAsString(security, d.key, d.defaultValue))
+		if err != nil {
+			return settings, fmt.Errorf("invalid %s in [security]: %w", d.key, err)
+		}
+		*d.value = value
+	}
+
+	return settings, nil
+}
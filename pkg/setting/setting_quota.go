@@ -2,13 +2,34 @@ package setting
 
 import (
 	"reflect"
+	"time"
 )
 
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
+	User              int64 `target:"org_user"`
+	DataSource        int64 `target:"data_source"`
+	Dashboard         int64 `target:"dashboard"`
+	ApiKey            int64 `target:"api_key"`
+	LibraryPanel      int64 `target:"library_panel"`
+	AlertDefinition   int64 `target:"alert_definition"`
+	Annotation        int64 `target:"annotation"`
+	ShortURL          int64 `target:"short_url"`
+	DashboardSnapshot int64 `target:"dashboard_snapshot"`
+}
+
+// OrgRateQuota limits the number of requests per organization in a fixed
+// interval, as opposed to OrgQuota limiting the number of stored objects.
+type OrgRateQuota struct {
+	// Query is the number of data source queries per minute
+	Query int64 `target:"query"`
+	// Render is the number of render requests per hour
+	Render int64 `target:"render"`
+}
+
+// RateQuotaIntervals are the intervals the requests of the rate quotas are counted in, by target
+var RateQuotaIntervals = map[string]time.Duration{
+	"query":  time.Minute,
+	"render": time.Hour,
 }
 
 type UserQuota struct {
@@ -16,18 +37,31 @@ type UserQuota struct {
 }
 
 type GlobalQuota struct {
-	Org        int64 `target:"org"`
-	User       int64 `target:"user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	Session    int64 `target:"-"`
+	Org               int64 `target:"org"`
+	User              int64 `target:"user"`
+	DataSource        int64 `target:"data_source"`
+	Dashboard         int64 `target:"dashboard"`
+	ApiKey            int64 `target:"api_key"`
+	Session           int64 `target:"-"`
+	LibraryPanel      int64 `target:"library_panel"`
+	AlertDefinition   int64 `target:"alert_definition"`
+	Annotation        int64 `target:"annotation"`
+	ShortURL          int64 `target:"short_url"`
+	DashboardSnapshot int64 `target:"dashboard_snapshot"`
 }
 
 func (q *OrgQuota) ToMap() map[string]int64 {
 	return quotaToMap(*q)
 }
 
+func (q *GlobalQuota) ToMap() map[string]int64 {
+	return quotaToMap(*q)
+}
+
+func (q *OrgRateQuota) ToMap() map[string]int64 {
+	return quotaToMap(*q)
+}
+
 func (q *UserQuota) ToMap() map[string]int64 {
 	return quotaToMap(*q)
 }
@@ -55,6 +89,7 @@ func quotaToMap(q interface{}) map[string]int64 {
 type QuotaSettings struct {
 	Enabled bool
 	Org     *OrgQuota
+	OrgRate *OrgRateQuota
 	User    *UserQuota
 	Global  *GlobalQuota
 }
@@ -70,6 +105,18 @@ func (cfg *Cfg) readQuotaSettings() {
 		DataSource: quota.Key("org_data_source").MustInt64(10),
 		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
 		ApiKey:     quota.Key("org_api_key").MustInt64(10),
+
+		LibraryPanel:      quota.Key("org_library_panel").MustInt64(-1),
+		AlertDefinition:   quota.Key("org_alert_definition").MustInt64(-1),
+		Annotation:        quota.Key("org_annotation").MustInt64(-1),
+		ShortURL:          quota.Key("org_short_url").MustInt64(-1),
+		DashboardSnapshot: quota.Key("org_dashboard_snapshot").MustInt64(-1),
+	}
+
+	// per ORG rate limits
+	Quota.OrgRate = &OrgRateQuota{
+		Query:  quota.Key("org_query_per_minute").MustInt64(-1),
+		Render: quota.Key("org_render_per_hour").MustInt64(-1),
 	}
 
 	// per User limits
@@ -85,6 +132,12 @@ func (cfg *Cfg) readQuotaSettings() {
 		Dashboard:  quota.Key("global_dashboard").MustInt64(-1),
 		ApiKey:     quota.Key("global_api_key").MustInt64(-1),
 		Session:    quota.Key("global_session").MustInt64(-1),
+
+		LibraryPanel:      quota.Key("global_library_panel").MustInt64(-1),
+		AlertDefinition:   quota.Key("global_alert_definition").MustInt64(-1),
+		Annotation:        quota.Key("global_annotation").MustInt64(-1),
+		ShortURL:          quota.Key("global_short_url").MustInt64(-1),
+		DashboardSnapshot: quota.Key("global_dashboard_snapshot").MustInt64(-1),
 	}
 
 	cfg.Quota = Quota
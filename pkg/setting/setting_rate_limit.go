@@ -0,0 +1,71 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+)
+
+// Rate limit keys identify the clients whose requests are counted together.
+const (
+	RateLimitKeyUser   = "user"
+	RateLimitKeyOrg    = "org"
+	RateLimitKeyAPIKey = "api_key"
+	RateLimitKeyIP     = "ip"
+)
+
+// RateLimitGroups are the route groups that can be rate limited, along with
+// the default key their requests are counted by.
+var RateLimitGroups = map[string]string{
+	"login":    RateLimitKeyIP,
+	"query":    RateLimitKeyUser,
+	"render":   RateLimitKeyUser,
+	"api_keys": RateLimitKeyUser,
+}
+
+// RateLimitSettings is the rate limit of a route group. At most Limit
+// requests with the same key are allowed per Interval.
+type RateLimitSettings struct {
+	Limit    int64
+	Interval time.Duration
+	KeyBy    string
+}
+
+func (cfg *Cfg) readRateLimitSettings() error {
+	cfg.RateLimits = map[string]RateLimitSettings{}
+	for group, defaultKey := range RateLimitGroups {
+		sectionName := "rate_limiting." + group
+		section := cfg.Raw.Section(sectionName)
+		if !section.Key("enabled").MustBool(false) {
+			continue
+		}
+
+		limit := section.Key("limit").MustInt64(0)
+		if limit <= 0 {
+			return fmt.Errorf("limit in [%s] should be positive", sectionName)
+		}
+
+		interval, err := gtime.ParseDuration(valueAsString(section, "interval", "1m"))
+		if err != nil {
+			return fmt.Errorf("invalid interval in [%s]: %w", sectionName, err)
+		}
+		if interval < time.Second {
+			return fmt.Errorf("interval in [%s] should be at least 1s", sectionName)
+		}
+
+		keyBy := valueAsString(section, "key_by", defaultKey)
+		switch keyBy {
+		case RateLimitKeyUser, RateLimitKeyOrg, RateLimitKeyAPIKey, RateLimitKeyIP:
+		default:
+			return fmt.Errorf("invalid key_by in [%s]: %q", sectionName, keyBy)
+		}
+
+		cfg.RateLimits[group] = RateLimitSettings{
+			Limit:    limit,
+			Interval: interval,
+			KeyBy:    keyBy,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"gopkg.in/ini.v1"
+)
+
+// Object types with a retention policy.
+const (
+	RetentionAlertAnnotations     = "alert_annotations"
+	RetentionDashboardAnnotations = "dashboard_annotations"
+	RetentionAPIAnnotations       = "api_annotations"
+	RetentionDashboardVersions    = "dashboard_versions"
+)
+
+// RetentionPolicy defines which objects of a type are kept by the cleanup
+// service. Objects older than MaxAge are deleted, as well as the oldest
+// objects exceeding MaxCount. Zero values mean no limit.
+type RetentionPolicy struct {
+	MaxAge   time.Duration
+	MaxCount int64
+}
+
+// IsEnabled returns true if the policy limits the objects kept
+func (p RetentionPolicy) IsEnabled() bool {
+	return p.MaxAge > 0 || p.MaxCount > 0
+}
+
+// RetentionSettings configures the retention policies of the cleanup service.
+type RetentionSettings struct {
+	// DryRun makes the cleanup service report the objects it would delete
+	// in all of its jobs, including the retention policies, instead of deleting them.
+	DryRun bool
+	// DashboardVersions is the default retention policy of dashboard
+	// versions, with MaxCount applying per dashboard.
+	DashboardVersions RetentionPolicy
+	// Orgs are the retention policies overriding the default policy of an
+	// object type for an organization, by object type and org ID.
+	Orgs map[string]map[int64]RetentionPolicy
+}
+
+// readRetentionSettings reads the [retention] sections. The default policies
+// of annotations and dashboard versions in [retention.<type>] override the
+// [annotations.*], [alerting] and [dashboards] settings, and are overridden
+// per organization in [retention.<type>.org.<org id>].
+func (cfg *Cfg) readRetentionSettings() error {
+	versionsToKeep := int64(DashboardVersionsToKeep)
+	if versionsToKeep < 1 {
+		versionsToKeep = 1
+	}
+
+	cfg.Retention = RetentionSettings{
+		DryRun:            cfg.Raw.Section("retention").Key("dry_run").MustBool(false),
+		DashboardVersions: RetentionPolicy{MaxCount: versionsToKeep},
+		Orgs:              map[string]map[int64]RetentionPolicy{},
+	}
+
+	defaults := map[string]*RetentionPolicy{
+		RetentionAlertAnnotations:     &cfg.AlertingAnnotationCleanupSetting,
+		RetentionDashboardAnnotations: &cfg.DashboardAnnotationCleanupSettings,
+		RetentionAPIAnnotations:       &cfg.APIAnnotationCleanupSettings,
+		RetentionDashboardVersions:    &cfg.Retention.DashboardVersions,
+	}
+
+	for objectType, policy := range defaults {
+		section, err := cfg.Raw.GetSection("retention." + objectType)
+		if err != nil {
+			continue
+		}
+
+		if *policy, err = readRetentionPolicy(section, *policy); err != nil {
+			return err
+		}
+	}
+
+	for _, section := range cfg.Raw.Sections() {
+		name := section.Name()
+		if !strings.HasPrefix(name, "retention.") || !strings.Contains(name, ".org.") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(name, "retention."), ".org.", 2)
+		objectType := parts[0]
+		defaultPolicy, ok := defaults[objectType]
+		if !ok {
+			return fmt.Errorf("unknown object type in [%s]: %q", name, objectType)
+		}
+
+		orgID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || orgID <= 0 {
+			return fmt.Errorf("invalid org ID in [%s]: %q", name, parts[1])
+		}
+
+		policy, err := readRetentionPolicy(section, *defaultPolicy)
+		if err != nil {
+			return err
+		}
+
+		if cfg.Retention.Orgs[objectType] == nil {
+			cfg.Retention.Orgs[objectType] = map[int64]RetentionPolicy{}
+		}
+		cfg.Retention.Orgs[objectType][orgID] = policy
+	}
+
+	return nil
+}
+
+// readRetentionPolicy reads the max_age and max_count of a retention policy
+// section, keeping the values of defaultPolicy for keys that are not set.
+func readRetentionPolicy(section *ini.Section, defaultPolicy RetentionPolicy) (RetentionPolicy, error) {
+	policy := defaultPolicy
+
+	if section.HasKey("max_age") {
+		policy.MaxAge = 0
+		if value := section.Key("max_age").String(); value != "" {
+			maxAge, err := gtime.ParseDuration(value)
+			if err != nil {
+				return policy, fmt.Errorf("invalid max_age in [%s]: %w", section.Name(), err)
+			}
+			policy.MaxAge = maxAge
+		}
+	}
+
+	if section.HasKey("max_count") {
+		maxCount, err := section.Key("max_count").Int64()
+		if err != nil || maxCount < 0 {
+			return policy, fmt.Errorf("invalid max_count in [%s]: %q", section.Name(), section.Key("max_count").String())
+		}
+		policy.MaxCount = maxCount
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,67 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestRetentionSettings(t *testing.T) {
+	loadRetention := func(t *testing.T, config string) (*Cfg, error) {
+		t.Helper()
+
+		raw, err := ini.Load([]byte(config))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		cfg.Raw = raw
+		cfg.APIAnnotationCleanupSettings = AnnotationCleanupSettings{MaxCount: 100}
+		return cfg, cfg.readRetentionSettings()
+	}
+
+	t.Run("Default policies override legacy settings", func(t *testing.T) {
+		cfg, err := loadRetention(t, `
+[retention]
+dry_run = true
+
+[retention.api_annotations]
+max_age = 10d
+
+[retention.dashboard_versions]
+max_count = 5
+`)
+		require.NoError(t, err)
+		require.True(t, cfg.Retention.DryRun)
+		require.Equal(t, RetentionPolicy{MaxAge: 10 * 24 * time.Hour, MaxCount: 100}, cfg.APIAnnotationCleanupSettings)
+		require.Equal(t, RetentionPolicy{MaxCount: 5}, cfg.Retention.DashboardVersions)
+		require.Empty(t, cfg.Retention.Orgs)
+	})
+
+	t.Run("Org policies inherit the default policy", func(t *testing.T) {
+		cfg, err := loadRetention(t, `
+[retention.api_annotations.org.2]
+max_age = 1h
+
+[retention.dashboard_versions.org.3]
+max_count = 0
+max_age = 30d
+`)
+		require.NoError(t, err)
+		require.Equal(t, RetentionPolicy{MaxAge: time.Hour, MaxCount: 100}, cfg.Retention.Orgs[RetentionAPIAnnotations][2])
+		require.Equal(t, RetentionPolicy{MaxAge: 30 * 24 * time.Hour}, cfg.Retention.Orgs[RetentionDashboardVersions][3])
+	})
+
+	t.Run("Invalid sections return an error", func(t *testing.T) {
+		for _, config := range []string{
+			"[retention.users.org.1]\nmax_age = 1h",
+			"[retention.api_annotations.org.main]\nmax_age = 1h",
+			"[retention.api_annotations]\nmax_age = forever",
+			"[retention.dashboard_versions]\nmax_count = -1",
+		} {
+			_, err := loadRetention(t, config)
+			require.Error(t, err, config)
+		}
+	})
+}
@@ -389,3 +389,38 @@ func TestAuthDurationSettings(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, maxLifetimeDurationTest, cfg.LoginMaxLifetime)
 }
+
+func TestAlertingHASettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	err := cfg.readAlertingHASettings(f)
+	require.NoError(t, err)
+	require.False(t, cfg.AlertingHAEnabled)
+	require.Equal(t, 10*time.Second, cfg.AlertingHAHeartbeatInterval)
+	require.Equal(t, 30*time.Second, cfg.AlertingHANodeTimeout)
+
+	f = ini.Empty()
+	sec, err := f.NewSection("alerting")
+	require.NoError(t, err)
+	_, err = sec.NewKey("ha_enabled", "true")
+	require.NoError(t, err)
+	_, err = sec.NewKey("ha_heartbeat_interval", "5s")
+	require.NoError(t, err)
+	_, err = sec.NewKey("ha_node_timeout", "1m")
+	require.NoError(t, err)
+	err = cfg.readAlertingHASettings(f)
+	require.NoError(t, err)
+	require.True(t, cfg.AlertingHAEnabled)
+	require.Equal(t, 5*time.Second, cfg.AlertingHAHeartbeatInterval)
+	require.Equal(t, time.Minute, cfg.AlertingHANodeTimeout)
+
+	f = ini.Empty()
+	sec, err = f.NewSection("alerting")
+	require.NoError(t, err)
+	_, err = sec.NewKey("ha_heartbeat_interval", "30s")
+	require.NoError(t, err)
+	_, err = sec.NewKey("ha_node_timeout", "30s")
+	require.NoError(t, err)
+	err = cfg.readAlertingHASettings(f)
+	require.Error(t, err)
+}
@@ -63,6 +63,23 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(root)
 }
 
+// SortOrder represents the order of a sort in a search request
+type SortOrder string
+
+const (
+	// SortOrderAsc sorts in ascending order
+	SortOrderAsc SortOrder = "asc"
+	// SortOrderDesc sorts in descending order
+	SortOrderDesc SortOrder = "desc"
+)
+
+const (
+	// HighlightPreTag is the tag inserted before highlighted terms
+	HighlightPreTag = "@HIGHLIGHT@"
+	// HighlightPostTag is the tag inserted after highlighted terms
+	HighlightPostTag = "@/HIGHLIGHT@"
+)
+
 // SearchResponseHits represents search response hits
 type SearchResponseHits struct {
 	Hits []map[string]interface{}
@@ -281,8 +298,12 @@ type MetricAggregation struct {
 
 // MarshalJSON returns the JSON encoding of the metric aggregation
 func (a *MetricAggregation) MarshalJSON() ([]byte, error) {
-	root := map[string]interface{}{
-		"field": a.Field,
+	root := map[string]interface{}{}
+
+	// Some metric aggregations, like top_metrics or a script based rate,
+	// don't have a field and elasticsearch rejects an empty one
+	if a.Field != "" {
+		root["field"] = a.Field
 	}
 
 	for k, v := range a.Settings {
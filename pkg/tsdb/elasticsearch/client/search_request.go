@@ -69,10 +69,15 @@ func (b *SearchRequestBuilder) Size(size int) *SearchRequestBuilder {
 	return b
 }
 
-// SortDesc adds a sort to the search request
+// SortDesc adds a descending sort to the search request
 func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchRequestBuilder {
+	return b.Sort(SortOrderDesc, field, unmappedType)
+}
+
+// Sort adds a sort with the given order to the search request
+func (b *SearchRequestBuilder) Sort(order SortOrder, field, unmappedType string) *SearchRequestBuilder {
 	props := map[string]string{
-		"order": "desc",
+		"order": string(order),
 	}
 
 	if unmappedType != "" {
@@ -102,6 +107,31 @@ func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuil
 	return b
 }
 
+// AddHighlight adds a highlight of all fields to the search request, using
+// the highlight tags the frontend expects
+func (b *SearchRequestBuilder) AddHighlight() *SearchRequestBuilder {
+	b.customProps["highlight"] = map[string]interface{}{
+		"fields": map[string]interface{}{
+			"*": map[string]interface{}{},
+		},
+		"pre_tags":      []string{HighlightPreTag},
+		"post_tags":     []string{HighlightPostTag},
+		"fragment_size": 2147483647,
+	}
+
+	return b
+}
+
+// AddSearchAfter sets the sort values of the last document of a previous
+// page, so that the search request returns the page that follows it
+func (b *SearchRequestBuilder) AddSearchAfter(values []interface{}) *SearchRequestBuilder {
+	if len(values) > 0 {
+		b.customProps["search_after"] = values
+	}
+
+	return b
+}
+
 // Query creates and return a query builder
 func (b *SearchRequestBuilder) Query() *QueryBuilder {
 	if b.queryBuilder == nil {
@@ -44,6 +44,8 @@ var metricAggType = map[string]string{
 	"extended_stats": "Extended Stats",
 	"percentiles":    "Percentiles",
 	"cardinality":    "Unique Count",
+	"top_metrics":    "Top Metrics",
+	"rate":           "Rate",
 	"moving_avg":     "Moving Average",
 	"moving_fn":      "Moving Function",
 	"cumulative_sum": "Cumulative Sum",
@@ -51,6 +53,8 @@ var metricAggType = map[string]string{
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
 }
 
 var extendedStats = map[string]string{
@@ -77,6 +81,21 @@ var pipelineAggWithMultipleBucketPathsType = map[string]string{
 	"bucket_script": "bucket_script",
 }
 
+var documentQueryType = map[string]string{
+	"raw_data":     "raw_data",
+	"raw_document": "raw_document",
+	"logs":         "logs",
+}
+
+// isDocumentQuery returns whether the metric type fetches documents instead of
+// aggregating them
+func isDocumentQuery(metricType string) bool {
+	if _, ok := documentQueryType[metricType]; ok {
+		return true
+	}
+	return false
+}
+
 func isPipelineAgg(metricType string) bool {
 	if _, ok := pipelineAggType[metricType]; ok {
 		return true
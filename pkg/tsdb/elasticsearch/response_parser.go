@@ -1,12 +1,15 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -18,12 +21,17 @@ const (
 	countType         = "count"
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
+	topMetricsType    = "top_metrics"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
 	filtersType     = "filters"
 	termsType       = "terms"
 	geohashGridType = "geohash_grid"
+
+	defaultDocumentSize = 500
 )
 
 type responseParser struct {
@@ -64,6 +72,17 @@ func (rp *responseParser) getTimeSeries() (*tsdb.Response, error) {
 
 		queryRes := tsdb.NewQueryResult()
 		queryRes.Meta = debugInfo
+
+		if len(target.BucketAggs) == 0 && len(target.Metrics) > 0 && isDocumentQuery(target.Metrics[0].Type) {
+			frames, err := rp.processDocuments(res, target)
+			if err != nil {
+				return nil, err
+			}
+			queryRes.Dataframes = tsdb.NewDecodedDataFrames(frames)
+			result.Results[target.RefID] = queryRes
+			continue
+		}
+
 		props := make(map[string]string)
 		table := tsdb.Table{
 			Columns: make([]tsdb.TableColumn, 0),
@@ -194,13 +213,7 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 			}
 
 			firstBucket := simplejson.NewFromAny(buckets[0])
-			percentiles := firstBucket.GetPath(metric.ID, "values").MustMap()
-
-			percentileKeys := make([]string, 0)
-			for k := range percentiles {
-				percentileKeys = append(percentileKeys, k)
-			}
-			sort.Strings(percentileKeys)
+			percentileKeys := getPercentileKeys(firstBucket.GetPath(metric.ID, "values"))
 			for _, percentileName := range percentileKeys {
 				newSeries := tsdb.TimeSeries{
 					Tags: make(map[string]string),
@@ -212,7 +225,26 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 				newSeries.Tags["field"] = metric.Field
 				for _, v := range buckets {
 					bucket := simplejson.NewFromAny(v)
-					value := castToNullFloat(bucket.GetPath(metric.ID, "values", percentileName))
+					value := getPercentileValue(bucket.GetPath(metric.ID, "values"), percentileName)
+					key := castToNullFloat(bucket.Get("key"))
+					newSeries.Points = append(newSeries.Points, tsdb.TimePoint{value, key})
+				}
+				*series = append(*series, &newSeries)
+			}
+		case topMetricsType:
+			buckets := esAgg.Get("buckets").MustArray()
+			for _, field := range metric.Settings.Get("metrics").MustStringArray() {
+				newSeries := tsdb.TimeSeries{
+					Tags: make(map[string]string),
+				}
+				for k, v := range props {
+					newSeries.Tags[k] = v
+				}
+				newSeries.Tags["metric"] = topMetricsType
+				newSeries.Tags["field"] = field
+				for _, v := range buckets {
+					bucket := simplejson.NewFromAny(v)
+					value := getTopMetricValue(bucket.Get(metric.ID), field)
 					key := castToNullFloat(bucket.Get("key"))
 					newSeries.Points = append(newSeries.Points, tsdb.TimePoint{value, key})
 				}
@@ -362,6 +394,15 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 					addMetricValue(&values, rp.getMetricName(metric.Type), value)
 					break
 				}
+			case percentilesType:
+				percentiles := bucket.GetPath(metric.ID, "values")
+				for _, percentileName := range getPercentileKeys(percentiles) {
+					addMetricValue(&values, "p"+percentileName+" "+metric.Field, getPercentileValue(percentiles, percentileName))
+				}
+			case topMetricsType:
+				for _, field := range metric.Settings.Get("metrics").MustStringArray() {
+					addMetricValue(&values, rp.getMetricName(metric.Type)+" "+field, getTopMetricValue(bucket.Get(metric.ID), field))
+				}
 			default:
 				metricName := rp.getMetricName(metric.Type)
 				otherMetrics := make([]*MetricAgg, 0)
@@ -555,6 +596,64 @@ func castToNullFloat(j *simplejson.Json) null.Float {
 	return null.NewFloat(0, false)
 }
 
+// getPercentileKeys returns the sorted names of the percentiles of a
+// percentiles aggregation, for both the default keyed response, an object
+// keyed by percentile, and the response with keyed set to false, an array of
+// key/value objects.
+func getPercentileKeys(values *simplejson.Json) []string {
+	keys := make([]string, 0)
+	if arr, err := values.Array(); err == nil {
+		for i := range arr {
+			if key, err := values.GetIndex(i).Get("key").Float64(); err == nil {
+				keys = append(keys, formatPercentileKey(key))
+			}
+		}
+	} else {
+		for k := range values.MustMap() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getPercentileValue returns the value of a percentile for both the keyed and
+// non-keyed percentiles response.
+func getPercentileValue(values *simplejson.Json, percentileName string) null.Float {
+	arr, err := values.Array()
+	if err != nil {
+		return castToNullFloat(values.Get(percentileName))
+	}
+
+	for i := range arr {
+		item := values.GetIndex(i)
+		if key, err := item.Get("key").Float64(); err == nil && formatPercentileKey(key) == percentileName {
+			return castToNullFloat(item.Get("value"))
+		}
+	}
+	return null.NewFloat(0, false)
+}
+
+// formatPercentileKey formats a percentile the way elasticsearch formats the
+// keys of a keyed percentiles response, e.g. "99.0".
+func formatPercentileKey(key float64) string {
+	s := strconv.FormatFloat(key, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// getTopMetricValue returns the value of field in the first result of a
+// top_metrics aggregation.
+func getTopMetricValue(topMetrics *simplejson.Json, field string) null.Float {
+	top := topMetrics.Get("top").MustArray()
+	if len(top) == 0 {
+		return null.NewFloat(0, false)
+	}
+	return castToNullFloat(simplejson.NewFromAny(top[0]).GetPath("metrics", field))
+}
+
 func findAgg(target *Query, aggID string) (*BucketAgg, error) {
 	for _, v := range target.BucketAggs {
 		if aggID == v.ID {
@@ -581,3 +680,244 @@ func getErrorFromElasticResponse(response *es.SearchResponse) *tsdb.QueryResult
 
 	return result
 }
+
+// processDocuments converts the hits of a raw_data, raw_document or logs query
+// into a data frame with one row per document. Nested source fields are
+// flattened into dot separated field names.
+func (rp *responseParser) processDocuments(res *es.SearchResponse, target *Query) (data.Frames, error) {
+	metric := target.Metrics[0]
+
+	var hits []map[string]interface{}
+	if res.Hits != nil {
+		hits = res.Hits.Hits
+	}
+
+	timeValues := make([]*time.Time, 0, len(hits))
+	docs := make([]map[string]interface{}, 0, len(hits))
+	sources := make([]*string, 0, len(hits))
+	fieldNames := map[string]struct{}{}
+	searchWords := map[string]struct{}{}
+	var lastSort []interface{}
+
+	for _, hit := range hits {
+		doc := map[string]interface{}{
+			"_id":    hit["_id"],
+			"_type":  hit["_type"],
+			"_index": hit["_index"],
+		}
+
+		source, _ := hit["_source"].(map[string]interface{})
+		flattenDocument("", source, doc)
+
+		timeValues = append(timeValues, getDocumentTimestamp(hit, doc, target.TimeField))
+		delete(doc, target.TimeField)
+
+		if metric.Type != rawDataType {
+			b, err := json.Marshal(source)
+			if err != nil {
+				return nil, err
+			}
+			s := string(b)
+			sources = append(sources, &s)
+		}
+
+		if highlight, ok := hit["highlight"].(map[string]interface{}); ok {
+			for _, fragments := range highlight {
+				for _, fragment := range toStringSlice(fragments) {
+					for _, word := range extractHighlightedWords(fragment) {
+						searchWords[word] = struct{}{}
+					}
+				}
+			}
+		}
+
+		if sortValues, ok := hit["sort"].([]interface{}); ok {
+			lastSort = sortValues
+		}
+
+		for k := range doc {
+			fieldNames[k] = struct{}{}
+		}
+		docs = append(docs, doc)
+	}
+
+	fields := []*data.Field{data.NewField(target.TimeField, nil, timeValues)}
+	if metric.Type != rawDataType {
+		fields = append(fields, data.NewField("_source", nil, sources))
+	}
+
+	names := make([]string, 0, len(fieldNames))
+	for k := range fieldNames {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := make([]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			values = append(values, doc[name])
+		}
+		fields = append(fields, newDocumentField(name, values))
+	}
+
+	frame := data.NewFrame(target.RefID, fields...)
+
+	custom := map[string]interface{}{}
+	if len(lastSort) > 0 {
+		custom["searchAfter"] = lastSort
+	}
+	if len(searchWords) > 0 {
+		words := make([]string, 0, len(searchWords))
+		for w := range searchWords {
+			words = append(words, w)
+		}
+		sort.Strings(words)
+		custom["searchWords"] = words
+	}
+
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+		Custom:                 custom,
+	}
+	if metric.Type == logsType {
+		frame.Meta.PreferredVisualization = data.VisTypeLogs
+	}
+
+	return data.Frames{frame}, nil
+}
+
+// flattenDocument copies the values of a document source into target,
+// joining the keys of nested objects with a dot.
+func flattenDocument(prefix string, source map[string]interface{}, target map[string]interface{}) {
+	for k, v := range source {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		if nested, ok := v.(map[string]interface{}); ok {
+			flattenDocument(key, nested, target)
+			continue
+		}
+		target[key] = v
+	}
+}
+
+// getDocumentTimestamp returns the time of a document, preferring the doc
+// value field requested in the search over the value in the source.
+func getDocumentTimestamp(hit map[string]interface{}, doc map[string]interface{}, timeField string) *time.Time {
+	if fields, ok := hit["fields"].(map[string]interface{}); ok {
+		if values, ok := fields[timeField].([]interface{}); ok && len(values) > 0 {
+			if t := parseTimestamp(values[0]); t != nil {
+				return t
+			}
+		}
+	}
+
+	return parseTimestamp(doc[timeField])
+}
+
+// parseTimestamp parses a time value as returned by elasticsearch, which is
+// either epoch milliseconds or a formatted date.
+func parseTimestamp(v interface{}) *time.Time {
+	var t time.Time
+	switch value := v.(type) {
+	case float64:
+		t = time.Unix(0, int64(value)*int64(time.Millisecond)).UTC()
+	case string:
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			t = time.Unix(0, ms*int64(time.Millisecond)).UTC()
+			break
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil
+		}
+		t = parsed.UTC()
+	default:
+		return nil
+	}
+
+	return &t
+}
+
+// newDocumentField creates a frame field from document values, using a
+// numeric or boolean field when all values have that type and a string field
+// otherwise. Values that aren't strings are JSON encoded in string fields.
+func newDocumentField(name string, values []interface{}) *data.Field {
+	allFloats, allBools := true, true
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+		case float64:
+			allBools = false
+		case bool:
+			allFloats = false
+		default:
+			allFloats, allBools = false, false
+		}
+	}
+
+	switch {
+	case allFloats:
+		vec := make([]*float64, len(values))
+		for i, v := range values {
+			if f, ok := v.(float64); ok {
+				vec[i] = &f
+			}
+		}
+		return data.NewField(name, nil, vec)
+	case allBools:
+		vec := make([]*bool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				vec[i] = &b
+			}
+		}
+		return data.NewField(name, nil, vec)
+	}
+
+	vec := make([]*string, len(values))
+	for i, v := range values {
+		switch value := v.(type) {
+		case nil:
+		case string:
+			vec[i] = &value
+		default:
+			b, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			s := string(b)
+			vec[i] = &s
+		}
+	}
+	return data.NewField(name, nil, vec)
+}
+
+func toStringSlice(v interface{}) []string {
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+var highlightRegex = regexp.MustCompile(regexp.QuoteMeta(es.HighlightPreTag) + `(.*?)` + regexp.QuoteMeta(es.HighlightPostTag))
+
+// extractHighlightedWords returns the words elasticsearch marked as matching
+// the query in a highlighted fragment.
+func extractHighlightedWords(fragment string) []string {
+	matches := highlightRegex.FindAllStringSubmatch(fragment, -1)
+	words := make([]string, 0, len(matches))
+	for _, m := range matches {
+		words = append(words, m[1])
+	}
+	return words
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
@@ -322,6 +323,189 @@ func TestResponseParser(t *testing.T) {
 			So(seriesTwo.Points[1][1].Float64, ShouldEqual, 2000)
 		})
 
+		Convey("With percentiles and keyed response disabled", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "percentiles", "settings": { "percents": [75, 90], "keyed": false }, "id": "1" }],
+          "bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "3" }]
+				}`,
+			}
+			response := `{
+        "responses": [
+          {
+            "aggregations": {
+              "3": {
+                "buckets": [
+                  {
+                    "1": { "values": [{ "key": 75, "value": 3.3 }, { "key": 90, "value": 5.5 }] },
+                    "doc_count": 10,
+                    "key": 1000
+                  },
+                  {
+                    "1": { "values": [{ "key": 75, "value": 2.3 }, { "key": 90, "value": null }] },
+                    "doc_count": 15,
+                    "key": 2000
+                  }
+                ]
+              }
+            }
+          }
+        ]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes.Series, ShouldHaveLength, 2)
+			seriesOne := queryRes.Series[0]
+			So(seriesOne.Name, ShouldEqual, "p75.0")
+			So(seriesOne.Points, ShouldHaveLength, 2)
+			So(seriesOne.Points[0][0].Float64, ShouldEqual, 3.3)
+			So(seriesOne.Points[1][0].Float64, ShouldEqual, 2.3)
+
+			seriesTwo := queryRes.Series[1]
+			So(seriesTwo.Name, ShouldEqual, "p90.0")
+			So(seriesTwo.Points[0][0].Float64, ShouldEqual, 5.5)
+			So(seriesTwo.Points[1][0].Valid, ShouldBeFalse)
+		})
+
+		Convey("With top_metrics", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "top_metrics", "settings": { "order": "desc", "orderBy": "@timestamp", "metrics": ["@value", "@anotherValue"] }, "id": "1" }],
+          "bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "3" }]
+				}`,
+			}
+			response := `{
+        "responses": [
+          {
+            "aggregations": {
+              "3": {
+                "buckets": [
+                  {
+                    "1": { "top": [{ "sort": ["2021-01-01T00:00:00.000Z"], "metrics": { "@value": 1, "@anotherValue": 2 } }] },
+                    "doc_count": 10,
+                    "key": 1000
+                  },
+                  {
+                    "1": { "top": [] },
+                    "doc_count": 0,
+                    "key": 2000
+                  }
+                ]
+              }
+            }
+          }
+        ]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes.Series, ShouldHaveLength, 2)
+			seriesOne := queryRes.Series[0]
+			So(seriesOne.Name, ShouldEqual, "Top Metrics @value")
+			So(seriesOne.Points, ShouldHaveLength, 2)
+			So(seriesOne.Points[0][0].Float64, ShouldEqual, 1)
+			So(seriesOne.Points[0][1].Float64, ShouldEqual, 1000)
+			So(seriesOne.Points[1][0].Valid, ShouldBeFalse)
+
+			seriesTwo := queryRes.Series[1]
+			So(seriesTwo.Name, ShouldEqual, "Top Metrics @anotherValue")
+			So(seriesTwo.Points[0][0].Float64, ShouldEqual, 2)
+		})
+
+		Convey("With rate and moving function", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [
+						{ "type": "rate", "field": "@value", "id": "1" },
+						{ "type": "moving_fn", "field": "1", "pipelineAgg": "1", "id": "2" }
+					],
+          "bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "3" }]
+				}`,
+			}
+			response := `{
+        "responses": [
+          {
+            "aggregations": {
+              "3": {
+                "buckets": [
+                  { "1": { "value": 10 }, "2": { "value": null }, "doc_count": 10, "key": 1000 },
+                  { "1": { "value": 20 }, "2": { "value": 15 }, "doc_count": 15, "key": 2000 }
+                ]
+              }
+            }
+          }
+        ]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes.Series, ShouldHaveLength, 2)
+			So(queryRes.Series[0].Name, ShouldEqual, "Rate @value")
+			So(queryRes.Series[0].Points[1][0].Float64, ShouldEqual, 20)
+			So(queryRes.Series[1].Name, ShouldEqual, "Moving Function Rate 1")
+			So(queryRes.Series[1].Points[0][0].Valid, ShouldBeFalse)
+			So(queryRes.Series[1].Points[1][0].Float64, ShouldEqual, 15)
+		})
+
+		Convey("With percentiles and top_metrics in a table", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [
+						{ "type": "percentiles", "field": "@load", "settings": { "percents": [50] }, "id": "1" },
+						{ "type": "top_metrics", "settings": { "metrics": ["@value"] }, "id": "2" }
+					],
+          "bucketAggs": [{ "type": "terms", "field": "host", "id": "3" }]
+				}`,
+			}
+			response := `{
+        "responses": [
+          {
+            "aggregations": {
+              "3": {
+                "buckets": [
+                  {
+                    "1": { "values": { "50.0": 3 } },
+                    "2": { "top": [{ "sort": [1], "metrics": { "@value": 7 } }] },
+                    "doc_count": 10,
+                    "key": "server-1"
+                  }
+                ]
+              }
+            }
+          }
+        ]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes.Tables, ShouldHaveLength, 1)
+			table := queryRes.Tables[0]
+			So(table.Columns, ShouldHaveLength, 3)
+			So(table.Columns[0].Text, ShouldEqual, "host")
+			So(table.Columns[1].Text, ShouldEqual, "p50.0 @load")
+			So(table.Columns[2].Text, ShouldEqual, "Top Metrics @value")
+			So(table.Rows[0][0], ShouldEqual, "server-1")
+			So(table.Rows[0][1].(null.Float).Float64, ShouldEqual, 3)
+			So(table.Rows[0][2].(null.Float).Float64, ShouldEqual, 7)
+		})
+
 		Convey("With extended stats", func() {
 			targets := map[string]string{
 				"A": `{
@@ -994,6 +1178,159 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestResponseParserDocuments(t *testing.T) {
+	Convey("Elasticsearch response parser document queries", t, func() {
+		response := `{
+			"responses": [
+				{
+					"hits": {
+						"total": 2,
+						"hits": [
+							{
+								"_id": "fdsfs",
+								"_type": "_doc",
+								"_index": "mock-index",
+								"_source": {
+									"@timestamp": "2019-06-24T09:51:19.765Z",
+									"host": "djisaodjsoad",
+									"number": 1,
+									"line": "hello, i am a message",
+									"level": "debug",
+									"fields": { "lvl": "debug" }
+								},
+								"fields": { "@timestamp": ["2019-06-24T09:51:19.765Z"] },
+								"highlight": { "line": ["@HIGHLIGHT@hello@/HIGHLIGHT@, i am a message"] },
+								"sort": [1561369879765]
+							},
+							{
+								"_id": "kdospaidopa",
+								"_type": "_doc",
+								"_index": "mock-index",
+								"_source": {
+									"@timestamp": "2019-06-24T09:52:19.765Z",
+									"host": "dsalkdakdop",
+									"line": "hello, i am also message",
+									"level": "error",
+									"fields": { "lvl": "info" }
+								},
+								"fields": { "@timestamp": ["2019-06-24T09:52:19.765Z"] },
+								"highlight": { "line": ["@HIGHLIGHT@hello@/HIGHLIGHT@, i am also a message"] },
+								"sort": [1561369939765]
+							}
+						]
+					}
+				}
+			]
+		}`
+
+		Convey("Raw data query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "raw_data", "id": "1", "settings": { "size": 500 } }],
+					"bucketAggs": []
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes, ShouldNotBeNil)
+			So(queryRes.Series, ShouldHaveLength, 0)
+			frames, err := queryRes.Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+
+			frame := frames[0]
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Meta.PreferredVisualization, ShouldEqual, data.VisTypeTable)
+
+			fieldNames := []string{}
+			for _, f := range frame.Fields {
+				fieldNames = append(fieldNames, f.Name)
+			}
+			So(fieldNames, ShouldResemble, []string{"@timestamp", "_id", "_index", "_type", "fields.lvl", "host", "level", "line", "number"})
+
+			So(*frame.Fields[0].At(0).(*time.Time), ShouldEqual, time.Date(2019, 6, 24, 9, 51, 19, 765000000, time.UTC))
+			So(*frame.Fields[1].At(1).(*string), ShouldEqual, "kdospaidopa")
+			So(*frame.Fields[4].At(0).(*string), ShouldEqual, "debug")
+			So(*frame.Fields[8].At(0).(*float64), ShouldEqual, 1)
+			So(frame.Fields[8].At(1).(*float64), ShouldBeNil)
+		})
+
+		Convey("Raw document query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "raw_document", "id": "1" }],
+					"bucketAggs": []
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			frames, err := result.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			frame := frames[0]
+			So(frame.Fields[1].Name, ShouldEqual, "_source")
+
+			source := simplejson.New()
+			err = source.UnmarshalJSON([]byte(*frame.Fields[1].At(0).(*string)))
+			So(err, ShouldBeNil)
+			So(source.Get("host").MustString(), ShouldEqual, "djisaodjsoad")
+			So(source.GetPath("fields", "lvl").MustString(), ShouldEqual, "debug")
+		})
+
+		Convey("Logs query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "logs", "id": "1" }],
+					"bucketAggs": []
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			frames, err := result.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			frame := frames[0]
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Meta.PreferredVisualization, ShouldEqual, data.VisTypeLogs)
+
+			custom, ok := frame.Meta.Custom.(map[string]interface{})
+			So(ok, ShouldBeTrue)
+			So(custom["searchWords"], ShouldResemble, []string{"hello"})
+			So(custom["searchAfter"], ShouldResemble, []interface{}{float64(1561369939765)})
+		})
+
+		Convey("Query without hits", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "raw_data", "id": "1" }],
+					"bucketAggs": []
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, `{ "responses": [{ "hits": { "total": 0, "hits": [] } }] }`)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			frames, err := result.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			So(frames[0].Rows(), ShouldEqual, 0)
+		})
+	})
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
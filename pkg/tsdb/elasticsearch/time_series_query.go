@@ -76,7 +76,7 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	}
 
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+		if len(q.Metrics) == 0 || !isDocumentQuery(q.Metrics[0].Type) {
 			result.Results[q.RefID] = &tsdb.QueryResult{
 				RefId:       q.RefID,
 				Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
@@ -84,10 +84,10 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 			}
 			return nil
 		}
-		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
+		if q.TimeField == "" {
+			q.TimeField = e.client.GetTimeField()
+		}
+		addDocumentQuery(b, q)
 		return nil
 	}
 
@@ -138,8 +138,13 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 						}
 					}
 
+					settings, err := getPipelineAggSettings(m)
+					if err != nil {
+						return err
+					}
+
 					aggBuilder.Pipeline(m.ID, m.Type, bucketPaths, func(a *es.PipelineAggregation) {
-						a.Settings = m.Settings.MustMap()
+						a.Settings = settings
 					})
 				} else {
 					continue
@@ -159,8 +164,13 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 							bucketPath = "_count"
 						}
 
+						settings, err := getPipelineAggSettings(m)
+						if err != nil {
+							return err
+						}
+
 						aggBuilder.Pipeline(m.ID, m.Type, bucketPath, func(a *es.PipelineAggregation) {
-							a.Settings = m.Settings.MustMap()
+							a.Settings = settings
 						})
 					}
 				} else {
@@ -169,7 +179,7 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 			}
 		} else {
 			aggBuilder.Metric(m.ID, m.Type, m.Field, func(a *es.MetricAggregation) {
-				a.Settings = m.Settings.MustMap()
+				a.Settings = getMetricAggSettings(m)
 			})
 		}
 	}
@@ -177,6 +187,114 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+// getMetricAggSettings converts the settings of a metric of the query model
+// into the settings of the elasticsearch metric aggregation
+func getMetricAggSettings(m *MetricAgg) map[string]interface{} {
+	if m.Type != topMetricsType {
+		return m.Settings.MustMap()
+	}
+
+	metrics := make([]map[string]interface{}, 0)
+	for _, field := range m.Settings.Get("metrics").MustStringArray() {
+		metrics = append(metrics, map[string]interface{}{"field": field})
+	}
+
+	settings := map[string]interface{}{
+		"metrics": metrics,
+		"size":    1,
+	}
+	if orderBy := m.Settings.Get("orderBy").MustString(); orderBy != "" {
+		settings["sort"] = []map[string]interface{}{
+			{orderBy: m.Settings.Get("order").MustString("desc")},
+		}
+	}
+
+	return settings
+}
+
+// getPipelineAggSettings converts the settings of a pipeline metric of the
+// query model into the settings of the elasticsearch pipeline aggregation.
+// The query editor stores numbers as strings, which elasticsearch rejects for
+// integer parameters, so other values are rejected.
+func getPipelineAggSettings(m *MetricAgg) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	for k, v := range m.Settings.MustMap() {
+		settings[k] = v
+	}
+
+	var intSettings []string
+	switch m.Type {
+	case "moving_fn":
+		intSettings = []string{"window", "shift"}
+	case "serial_diff":
+		intSettings = []string{"lag"}
+	}
+
+	for _, key := range intSettings {
+		v, err := m.Settings.Get(key).String()
+		if err != nil {
+			continue
+		}
+		if v == "" {
+			delete(settings, key)
+			continue
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s setting %q of %s metric %s, must be an integer", key, v, m.Type, m.ID)
+		}
+		settings[key] = i
+	}
+
+	if m.Type == "moving_fn" {
+		// moving_fn only supports the script as a string
+		if script, err := m.Settings.GetPath("script", "inline").String(); err == nil {
+			settings["script"] = script
+		}
+	}
+
+	return settings, nil
+}
+
+// addDocumentQuery sets up a search request returning the documents themselves
+// rather than aggregations, as used by the raw_data, raw_document and logs
+// metric types.
+func addDocumentQuery(b *es.SearchRequestBuilder, q *Query) {
+	metric := q.Metrics[0]
+
+	sizeSetting := "size"
+	if metric.Type == logsType {
+		sizeSetting = "limit"
+	}
+	b.Size(getIntSetting(metric.Settings, sizeSetting, defaultDocumentSize))
+
+	order := es.SortOrderDesc
+	if metric.Settings.Get("order").MustString() == string(es.SortOrderAsc) {
+		order = es.SortOrderAsc
+	}
+	b.Sort(order, q.TimeField, "boolean")
+	b.AddDocValueField(q.TimeField)
+	b.AddSearchAfter(metric.Settings.Get("searchAfter").MustArray())
+
+	if metric.Type == logsType || metric.Settings.Get("highlight").MustBool(false) {
+		b.AddHighlight()
+	}
+}
+
+// getIntSetting returns a setting that may be stored either as a number or as
+// a numeric string, falling back to defaultValue when missing or zero.
+func getIntSetting(settings *simplejson.Json, key string, defaultValue int) int {
+	if v, err := settings.Get(key).Int(); err == nil && v > 0 {
+		return v
+	}
+	if s, err := settings.Get(key).String(); err == nil {
+		if v, err := strconv.Atoi(s); err == nil && v > 0 {
+			return v
+		}
+	}
+	return defaultValue
+}
+
 func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFrom, timeTo string) es.AggBuilder {
 	aggBuilder.DateHistogram(bucketAgg.ID, bucketAgg.Field, func(a *es.DateHistogramAgg, b es.AggBuilder) {
 		a.Interval = bucketAgg.Settings.Get("interval").MustString("auto")
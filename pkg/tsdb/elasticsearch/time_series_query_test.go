@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -376,6 +377,58 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(sr.Size, ShouldEqual, 1337)
 		})
 
+		Convey("With raw document metric sorts on the time field", func() {
+			c := newFakeClient(5)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_document", "settings": { "size": "100", "order": "asc" } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 100)
+			sort, ok := sr.Sort["timestamp"].(map[string]string)
+			So(ok, ShouldBeTrue)
+			So(sort["order"], ShouldEqual, "asc")
+			So(sr.CustomProps["docvalue_fields"], ShouldResemble, []string{"timestamp"})
+			So(sr.CustomProps["highlight"], ShouldBeNil)
+		})
+
+		Convey("With raw data metric", func() {
+			c := newFakeClient(5)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": 1337, "searchAfter": [1561369939765] } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 1337)
+			sort, ok := sr.Sort["@timestamp"].(map[string]string)
+			So(ok, ShouldBeTrue)
+			So(sort["order"], ShouldEqual, "desc")
+			So(sr.CustomProps["search_after"], ShouldHaveLength, 1)
+		})
+
+		Convey("With logs metric", func() {
+			c := newFakeClient(5)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": 10 } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 10)
+			highlight, ok := sr.CustomProps["highlight"].(map[string]interface{})
+			So(ok, ShouldBeTrue)
+			So(highlight["pre_tags"], ShouldResemble, []string{es.HighlightPreTag})
+			So(highlight["post_tags"], ShouldResemble, []string{es.HighlightPostTag})
+		})
+
 		Convey("With date histogram agg", func() {
 			c := newFakeClient(5)
 			_, err := executeTsdbQuery(c, `{
@@ -454,6 +507,135 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(ghGridAgg.Precision, ShouldEqual, 3)
 		})
 
+		Convey("With moving function", func() {
+			c := newFakeClient(70)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+				],
+				"metrics": [
+					{ "id": "3", "type": "sum", "field": "@value" },
+					{
+						"id": "2",
+						"type": "moving_fn",
+						"field": "3",
+						"pipelineAgg": "3",
+						"settings": { "window": "5", "shift": "1", "script": { "inline": "MovingFunctions.unweightedAvg(values)" } }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			firstLevel := sr.Aggs[0]
+			So(firstLevel.Aggregation.Aggs, ShouldHaveLength, 2)
+
+			movingFnAgg := firstLevel.Aggregation.Aggs[1]
+			So(movingFnAgg.Key, ShouldEqual, "2")
+			So(movingFnAgg.Aggregation.Type, ShouldEqual, "moving_fn")
+			pl := movingFnAgg.Aggregation.Aggregation.(*es.PipelineAggregation)
+			So(pl.BucketPath, ShouldEqual, "3")
+			So(pl.Settings["window"], ShouldEqual, 5)
+			So(pl.Settings["shift"], ShouldEqual, 1)
+			So(pl.Settings["script"], ShouldEqual, "MovingFunctions.unweightedAvg(values)")
+		})
+
+		Convey("With moving function window not a number", func() {
+			c := newFakeClient(70)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+				],
+				"metrics": [
+					{ "id": "3", "type": "sum", "field": "@value" },
+					{
+						"id": "2",
+						"type": "moving_fn",
+						"field": "3",
+						"pipelineAgg": "3",
+						"settings": { "window": "5m", "script": { "inline": "MovingFunctions.unweightedAvg(values)" } }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "window")
+		})
+
+		Convey("With top_metrics", func() {
+			c := newFakeClient(70)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "3" }
+				],
+				"metrics": [
+					{
+						"id": "2",
+						"type": "top_metrics",
+						"settings": { "order": "desc", "orderBy": "@timestamp", "metrics": ["@value", "@anotherValue"] }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			firstLevel := sr.Aggs[0]
+			So(firstLevel.Aggregation.Aggs, ShouldHaveLength, 1)
+
+			topMetricsAgg := firstLevel.Aggregation.Aggs[0]
+			So(topMetricsAgg.Key, ShouldEqual, "2")
+			So(topMetricsAgg.Aggregation.Type, ShouldEqual, "top_metrics")
+
+			body, err := json.Marshal(topMetricsAgg.Aggregation.Aggregation)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldEqual, `{"metrics":[{"field":"@value"},{"field":"@anotherValue"}],"size":1,"sort":[{"@timestamp":"desc"}]}`)
+		})
+
+		Convey("With rate", func() {
+			c := newFakeClient(70)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "3", "settings": { "interval": "1m" } }
+				],
+				"metrics": [
+					{ "id": "2", "type": "rate", "field": "@value", "settings": { "unit": "second", "mode": "sum" } }
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			rateAgg := sr.Aggs[0].Aggregation.Aggs[0]
+			So(rateAgg.Key, ShouldEqual, "2")
+			So(rateAgg.Aggregation.Type, ShouldEqual, "rate")
+			mAgg := rateAgg.Aggregation.Aggregation.(*es.MetricAggregation)
+			So(mAgg.Field, ShouldEqual, "@value")
+			So(mAgg.Settings["unit"], ShouldEqual, "second")
+			So(mAgg.Settings["mode"], ShouldEqual, "sum")
+		})
+
+		Convey("With percentiles and keyed response disabled", func() {
+			c := newFakeClient(70)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "3" }
+				],
+				"metrics": [
+					{ "id": "1", "type": "percentiles", "field": "@load_time", "settings": { "percents": [ "1", "2", "3", "4" ], "keyed": false } }
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			percentilesAgg := sr.Aggs[0].Aggregation.Aggs[0]
+			So(percentilesAgg.Aggregation.Type, ShouldEqual, "percentiles")
+			mAgg := percentilesAgg.Aggregation.Aggregation.(*es.MetricAggregation)
+			So(mAgg.Settings["keyed"], ShouldEqual, false)
+		})
+
 		Convey("With moving average", func() {
 			c := newFakeClient(5)
 			_, err := executeTsdbQuery(c, `{
@@ -753,6 +935,53 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(plAgg.BucketPath, ShouldEqual, "3")
 		})
 
+		Convey("With serial_diff lag as string", func() {
+			c := newFakeClient(5)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "3" }
+				],
+				"metrics": [
+					{ "id": "3", "type": "max", "field": "@value" },
+					{
+						"id": "2",
+						"type": "serial_diff",
+						"pipelineAgg": "3",
+						"settings": { "lag": "5" }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			serialDiffAgg := sr.Aggs[0].Aggregation.Aggs[1]
+			pl := serialDiffAgg.Aggregation.Aggregation.(*es.PipelineAggregation)
+			So(pl.Settings["lag"], ShouldEqual, 5)
+		})
+
+		Convey("With serial_diff lag not a number", func() {
+			c := newFakeClient(5)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "3" }
+				],
+				"metrics": [
+					{ "id": "3", "type": "max", "field": "@value" },
+					{
+						"id": "2",
+						"type": "serial_diff",
+						"pipelineAgg": "3",
+						"settings": { "lag": "five" }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "lag")
+			So(c.multisearchRequests, ShouldBeEmpty)
+		})
+
 		Convey("With serial_diff doc count", func() {
 			c := newFakeClient(5)
 			_, err := executeTsdbQuery(c, `{
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -138,38 +139,38 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(err, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				column := rowsFromResult(queryResult)[0]
 
-				So(column[0].(bool), ShouldEqual, true)
+				So(*column[0].(*bool), ShouldEqual, true)
 
-				So(column[1].(int64), ShouldEqual, 5)
-				So(column[2].(int64), ShouldEqual, 20020)
-				So(column[3].(int64), ShouldEqual, 980300)
-				So(column[4].(int64), ShouldEqual, 1420070400)
+				So(*column[1].(*int64), ShouldEqual, 5)
+				So(*column[2].(*int64), ShouldEqual, 20020)
+				So(*column[3].(*int64), ShouldEqual, 980300)
+				So(*column[4].(*int64), ShouldEqual, 1420070400)
 
-				So(column[5].(float64), ShouldEqual, 20000.15)
-				So(column[6].(float64), ShouldEqual, 2.15)
-				So(column[7].(float64), ShouldEqual, 12345.12)
-				So(column[8].(float64), ShouldEqual, 1.1100000143051147)
-				So(column[9].(float64), ShouldEqual, 2.22)
-				So(column[10].(float64), ShouldEqual, 3.33)
+				So(*column[5].(*float64), ShouldEqual, 20000.15)
+				So(*column[6].(*float64), ShouldEqual, 2.15)
+				So(*column[7].(*float64), ShouldEqual, 12345.12)
+				So(*column[8].(*float64), ShouldEqual, 1.1100000143051147)
+				So(*column[9].(*float64), ShouldEqual, 2.22)
+				So(*column[10].(*float64), ShouldEqual, 3.33)
 
-				So(column[11].(string), ShouldEqual, "char10    ")
-				So(column[12].(string), ShouldEqual, "varchar10")
-				So(column[13].(string), ShouldEqual, "text")
+				So(*column[11].(*string), ShouldEqual, "char10    ")
+				So(*column[12].(*string), ShouldEqual, "varchar10")
+				So(*column[13].(*string), ShouldEqual, "text")
 
-				So(column[14].(string), ShouldEqual, "☺nchar12☺   ")
-				So(column[15].(string), ShouldEqual, "☺nvarchar12☺")
-				So(column[16].(string), ShouldEqual, "☺text☺")
+				So(*column[14].(*string), ShouldEqual, "☺nchar12☺   ")
+				So(*column[15].(*string), ShouldEqual, "☺nvarchar12☺")
+				So(*column[16].(*string), ShouldEqual, "☺text☺")
 
-				So(column[17].(time.Time), ShouldEqual, dt)
-				So(column[18].(time.Time), ShouldEqual, dt2)
-				So(column[19].(time.Time), ShouldEqual, dt.Truncate(time.Minute))
-				So(column[20].(time.Time), ShouldEqual, dt.Truncate(24*time.Hour))
-				So(column[21].(time.Time), ShouldEqual, time.Date(1, 1, 1, dt.Hour(), dt.Minute(), dt.Second(), dt.Nanosecond(), time.UTC))
-				So(column[22].(time.Time), ShouldEqual, dt2.In(time.FixedZone("UTC-7", int(-7*60*60))))
+				So(*column[17].(*time.Time), ShouldEqual, dt)
+				So(*column[18].(*time.Time), ShouldEqual, dt2)
+				So(*column[19].(*time.Time), ShouldEqual, dt.Truncate(time.Minute))
+				So(*column[20].(*time.Time), ShouldEqual, dt.Truncate(24*time.Hour))
+				So(*column[21].(*time.Time), ShouldEqual, time.Date(1, 1, 1, dt.Hour(), dt.Minute(), dt.Second(), dt.Nanosecond(), time.UTC))
+				So(*column[22].(*time.Time), ShouldEqual, dt2.In(time.FixedZone("UTC-7", int(-7*60*60))))
 
-				So(column[23].(string), ShouldEqual, uuid)
+				So(*column[23].(*string), ShouldEqual, uuid)
 			})
 		})
 
@@ -231,7 +232,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := seriesFromResult(queryResult)[0].Points
 				// without fill this should result in 4 buckets
 				So(len(points), ShouldEqual, 4)
 
@@ -278,7 +279,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := seriesFromResult(queryResult)[0].Points
 				So(len(points), ShouldEqual, 7)
 
 				dt := fromStart
@@ -364,7 +365,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := seriesFromResult(queryResult)[0].Points
 				So(points[3][0].Float64, ShouldEqual, 1.5)
 			})
 		})
@@ -454,8 +455,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable) should return metric with time in milliseconds", func() {
@@ -476,8 +477,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64) as time column and value column (float64) should return metric with time in milliseconds", func() {
@@ -498,8 +499,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable) should return metric with time in milliseconds", func() {
@@ -520,8 +521,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32) as time column and value column (int32) should return metric with time in milliseconds", func() {
@@ -542,8 +543,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable) should return metric with time in milliseconds", func() {
@@ -564,8 +565,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float32) as time column and value column (float32) should return metric with time in milliseconds", func() {
@@ -586,8 +587,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable) should return metric with time in milliseconds", func() {
@@ -608,8 +609,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query grouping by time and select metric column should return correct series", func() {
@@ -630,9 +631,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A - value one")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric B - value one")
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 2)
+				So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "Metric A - value one")
+				So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "Metric B - value one")
 			})
 
 			Convey("When doing a metric query grouping by time should return correct series", func() {
@@ -653,9 +654,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "valueTwo")
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 2)
+				So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "valueOne")
+				So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "valueTwo")
 			})
 
 			Convey("When doing a metric query with metric column and multiple value columns", func() {
@@ -676,11 +677,11 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 4)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-				So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-				So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 4)
+				So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+				So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+				So(seriesFromResult(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+				So(seriesFromResult(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 			})
 
 			Convey("When doing a query with timeFrom,timeTo,unixEpochFrom,unixEpochTo macros", func() {
@@ -777,11 +778,11 @@ func TestMSSQL(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(queryResult.Error, ShouldBeNil)
 
-					So(len(queryResult.Series), ShouldEqual, 4)
-					So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-					So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-					So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-					So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+					So(len(seriesFromResult(queryResult)), ShouldEqual, 4)
+					So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+					So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+					So(seriesFromResult(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+					So(seriesFromResult(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 				})
 			})
 
@@ -856,11 +857,11 @@ func TestMSSQL(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(queryResult.Error, ShouldBeNil)
 
-					So(len(queryResult.Series), ShouldEqual, 4)
-					So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-					So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-					So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-					So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+					So(len(seriesFromResult(queryResult)), ShouldEqual, 4)
+					So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+					So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+					So(seriesFromResult(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+					So(seriesFromResult(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 				})
 			})
 		})
@@ -930,7 +931,7 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -953,7 +954,7 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -980,11 +981,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				// Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.UnixNano()/1e6))
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.UnixNano()/1e6)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format should return ms", func() {
@@ -1010,11 +1011,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				// Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format (int) should return ms", func() {
@@ -1040,11 +1041,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				// Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch millisecond format should return ms", func() {
@@ -1070,11 +1071,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				// Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.Unix()*1000))
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column holding a bigint null value should return nil", func() {
@@ -1098,8 +1099,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				// Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1126,8 +1127,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				// Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1164,3 +1165,44 @@ func genTimeRangeByInterval(from time.Time, duration time.Duration, interval tim
 
 	return timeRange
 }
+
+// seriesFromResult converts the data frames of a time series query result
+// into time series.
+func seriesFromResult(queryResult *tsdb.QueryResult) tsdb.TimeSeriesSlice {
+	frames, err := queryResult.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+
+	series := make(tsdb.TimeSeriesSlice, 0, len(frames))
+	for _, frame := range frames {
+		valueField := frame.Fields[1]
+		s := &tsdb.TimeSeries{
+			Name:   valueField.Config.DisplayNameFromDS,
+			Points: make(tsdb.TimeSeriesPoints, frame.Rows()),
+		}
+		for i := 0; i < frame.Rows(); i++ {
+			timestamp, err := frame.FloatAt(0, i)
+			So(err, ShouldBeNil)
+
+			value := null.Float{}
+			if v := valueField.At(i).(*float64); v != nil {
+				value = null.FloatFrom(*v)
+			}
+			s.Points[i] = tsdb.TimePoint{value, null.FloatFrom(timestamp)}
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// rowsFromResult returns the rows of the data frame of a table query result.
+func rowsFromResult(queryResult *tsdb.QueryResult) [][]interface{} {
+	frames, err := queryResult.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+	So(len(frames), ShouldEqual, 1)
+
+	rows := make([][]interface{}, frames[0].Rows())
+	for i := range rows {
+		rows[i] = frames[0].RowCopy(i)
+	}
+	return rows
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -219,27 +220,27 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
-				So(column[0].(int64), ShouldEqual, 1)
-				So(column[1].(int64), ShouldEqual, 2)
-				So(column[2].(int64), ShouldEqual, 3)
+				column := rowsFromResult(queryResult)[0]
+				So(*column[0].(*int64), ShouldEqual, 1)
+				So(*column[1].(*int64), ShouldEqual, 2)
+				So(*column[2].(*int64), ShouldEqual, 3)
 
-				So(column[3].(float64), ShouldEqual, 4.5)
-				So(column[4].(float64), ShouldEqual, 6.7)
-				So(column[5].(float64), ShouldEqual, 1.1)
-				So(column[6].(float64), ShouldEqual, 1.2)
+				So(*column[3].(*float64), ShouldEqual, 4.5)
+				So(*column[4].(*float64), ShouldEqual, 6.7)
+				So(*column[5].(*float64), ShouldEqual, 1.1)
+				So(*column[6].(*float64), ShouldEqual, 1.2)
 
-				So(column[7].(string), ShouldEqual, "char10    ")
-				So(column[8].(string), ShouldEqual, "varchar10")
-				So(column[9].(string), ShouldEqual, "text")
+				So(*column[7].(*string), ShouldEqual, "char10    ")
+				So(*column[8].(*string), ShouldEqual, "varchar10")
+				So(*column[9].(*string), ShouldEqual, "text")
 
-				So(column[10].(time.Time), ShouldHaveSameTypeAs, time.Now())
-				So(column[11].(time.Time), ShouldHaveSameTypeAs, time.Now())
-				So(column[12].(time.Time), ShouldHaveSameTypeAs, time.Now())
-				So(column[13].(time.Time), ShouldHaveSameTypeAs, time.Now())
-				So(column[14].(time.Time), ShouldHaveSameTypeAs, time.Now())
+				So(*column[10].(*time.Time), ShouldHaveSameTypeAs, time.Now())
+				So(*column[11].(*time.Time), ShouldHaveSameTypeAs, time.Now())
+				So(*column[12].(*time.Time), ShouldHaveSameTypeAs, time.Now())
+				So(*column[13].(*time.Time), ShouldHaveSameTypeAs, time.Now())
+				So(*column[14].(*time.Time), ShouldHaveSameTypeAs, time.Now())
 
-				So(column[15].(string), ShouldEqual, "00:15:00")
+				So(*column[15].(*string), ShouldEqual, "00:15:00")
 			})
 		})
 
@@ -299,7 +300,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := seriesFromResult(queryResult)[0].Points
 				// without fill this should result in 4 buckets
 				So(len(points), ShouldEqual, 4)
 
@@ -381,7 +382,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := seriesFromResult(queryResult)[0].Points
 				So(len(points), ShouldEqual, 7)
 
 				dt := fromStart
@@ -434,7 +435,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := seriesFromResult(queryResult)[0].Points
 				So(points[3][0].Float64, ShouldEqual, 1.5)
 			})
 		})
@@ -461,7 +462,7 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			So(queryResult.Error, ShouldBeNil)
 
-			points := queryResult.Series[0].Points
+			points := seriesFromResult(queryResult)[0].Points
 			So(points[2][0].Float64, ShouldEqual, 15.0)
 			So(points[3][0].Float64, ShouldEqual, 15.0)
 			So(points[6][0].Float64, ShouldEqual, 20.0)
@@ -552,8 +553,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable) should return metric with time in milliseconds", func() {
@@ -574,8 +575,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64) as time column and value column (float64) should return metric with time in milliseconds", func() {
@@ -596,8 +597,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable) should return metric with time in milliseconds", func() {
@@ -618,8 +619,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32) as time column and value column (int32) should return metric with time in milliseconds", func() {
@@ -640,8 +641,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable) should return metric with time in milliseconds", func() {
@@ -662,8 +663,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float32) as time column and value column (float32) should return metric with time in milliseconds", func() {
@@ -684,8 +685,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable) should return metric with time in milliseconds", func() {
@@ -706,8 +707,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 1)
+				So(seriesFromResult(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query grouping by time and select metric column should return correct series", func() {
@@ -728,9 +729,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A - value one")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric B - value one")
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 2)
+				So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "Metric A - value one")
+				So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "Metric B - value one")
 			})
 
 			Convey("When doing a metric query with metric column and multiple value columns", func() {
@@ -751,11 +752,11 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 4)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-				So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-				So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 4)
+				So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+				So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+				So(seriesFromResult(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+				So(seriesFromResult(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 			})
 
 			Convey("When doing a metric query grouping by time should return correct series", func() {
@@ -776,9 +777,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "valueTwo")
+				So(len(seriesFromResult(queryResult)), ShouldEqual, 2)
+				So(seriesFromResult(queryResult)[0].Name, ShouldEqual, "valueOne")
+				So(seriesFromResult(queryResult)[1].Name, ShouldEqual, "valueTwo")
 			})
 
 			Convey("When doing a query with timeFrom,timeTo,unixEpochFrom,unixEpochTo macros", func() {
@@ -859,7 +860,7 @@ func TestPostgres(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -882,7 +883,7 @@ func TestPostgres(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -909,11 +910,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				//Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.UnixNano()/1e6))
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.UnixNano()/1e6)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format should return ms", func() {
@@ -939,11 +940,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format (int) should return ms", func() {
@@ -969,11 +970,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch millisecond format should return ms", func() {
@@ -999,11 +1000,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column holding a bigint null value should return nil", func() {
@@ -1027,8 +1028,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1055,8 +1056,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(rowsFromResult(queryResult)), ShouldEqual, 1)
+				columns := rowsFromResult(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1093,3 +1094,44 @@ func genTimeRangeByInterval(from time.Time, duration time.Duration, interval tim
 
 	return timeRange
 }
+
+// seriesFromResult converts the data frames of a time series query result
+// into time series.
+func seriesFromResult(queryResult *tsdb.QueryResult) tsdb.TimeSeriesSlice {
+	frames, err := queryResult.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+
+	series := make(tsdb.TimeSeriesSlice, 0, len(frames))
+	for _, frame := range frames {
+		valueField := frame.Fields[1]
+		s := &tsdb.TimeSeries{
+			Name:   valueField.Config.DisplayNameFromDS,
+			Points: make(tsdb.TimeSeriesPoints, frame.Rows()),
+		}
+		for i := 0; i < frame.Rows(); i++ {
+			timestamp, err := frame.FloatAt(0, i)
+			So(err, ShouldBeNil)
+
+			value := null.Float{}
+			if v := valueField.At(i).(*float64); v != nil {
+				value = null.FloatFrom(*v)
+			}
+			s.Points[i] = tsdb.TimePoint{value, null.FloatFrom(timestamp)}
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// rowsFromResult returns the rows of the data frame of a table query result.
+func rowsFromResult(queryResult *tsdb.QueryResult) [][]interface{} {
+	frames, err := queryResult.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+	So(len(frames), ShouldEqual, 1)
+
+	rows := make([][]interface{}, frames[0].Rows())
+	for i := range rows {
+		rows[i] = frames[0].RowCopy(i)
+	}
+	return rows
+}
@@ -0,0 +1,115 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/api"
+)
+
+const exemplarsEndpoint = "/api/v1/query_exemplars"
+
+// queryExemplars fetches the exemplars of the series selected by query and
+// converts them into a single data frame with one row per exemplar.
+func queryExemplars(ctx context.Context, client api.Client, query *PrometheusQuery) (data.Frames, error) {
+	u := client.URL(exemplarsEndpoint, nil)
+	q := u.Query()
+	q.Set("query", query.Expr)
+	q.Set("start", formatTime(query.Start))
+	q.Set("end", formatTime(query.End))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, body, err := client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res exemplarResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("failed to decode exemplar response: %w", err)
+	}
+	if res.Status != "success" {
+		return nil, fmt.Errorf("%s: %s", res.ErrorType, res.Error)
+	}
+
+	return exemplarsToDataFrames(res.Data, query)
+}
+
+// exemplarsToDataFrames converts exemplar query results into a frame with a
+// time and value field followed by one string field per label found on either
+// the exemplars or the series they belong to.
+func exemplarsToDataFrames(results []exemplarQueryResult, query *PrometheusQuery) (data.Frames, error) {
+	labelSet := map[string]struct{}{}
+	for _, r := range results {
+		for k := range r.SeriesLabels {
+			labelSet[k] = struct{}{}
+		}
+		for _, e := range r.Exemplars {
+			for k := range e.Labels {
+				labelSet[k] = struct{}{}
+			}
+		}
+	}
+
+	labelNames := make([]string, 0, len(labelSet))
+	for k := range labelSet {
+		labelNames = append(labelNames, k)
+	}
+	sort.Strings(labelNames)
+
+	timeField := data.NewField("Time", nil, []time.Time{})
+	valueField := data.NewField("Value", nil, []float64{})
+	labelFields := make([]*data.Field, 0, len(labelNames))
+	for _, name := range labelNames {
+		labelFields = append(labelFields, data.NewField(name, nil, []string{}))
+	}
+
+	for _, r := range results {
+		for _, e := range r.Exemplars {
+			value, err := strconv.ParseFloat(e.Value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse exemplar value %q: %w", e.Value, err)
+			}
+
+			sec := int64(e.Timestamp)
+			nsec := int64((e.Timestamp - float64(sec)) * float64(time.Second))
+			timeField.Append(time.Unix(sec, nsec).UTC())
+			valueField.Append(value)
+
+			for i, name := range labelNames {
+				// Exemplar labels take precedence over series labels.
+				v, ok := e.Labels[name]
+				if !ok {
+					v = r.SeriesLabels[name]
+				}
+				labelFields[i].Append(v)
+			}
+		}
+	}
+
+	fields := append([]*data.Field{timeField, valueField}, labelFields...)
+	frame := data.NewFrame("exemplar", fields...)
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString: query.Expr,
+		Custom: map[string]interface{}{
+			"resultType": "exemplar",
+		},
+	}
+
+	return data.Frames{frame}, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.Unix())+float64(t.Nanosecond())/1e9, 'f', -1, 64)
+}
@@ -12,7 +12,7 @@ import (
 
 	"net/http"
 
-	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -48,6 +48,11 @@ func NewPrometheusExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, e
 	}, nil
 }
 
+const (
+	queryTypeRange   = "range"
+	queryTypeInstant = "instant"
+)
+
 var (
 	plog               log.Logger
 	legendFormat       *regexp.Regexp
@@ -61,7 +66,7 @@ func init() {
 	intervalCalculator = tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: time.Second * 1})
 }
 
-func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, error) {
+func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (api.Client, error) {
 	cfg := api.Config{
 		Address:      dsInfo.Url,
 		RoundTripper: e.Transport,
@@ -75,12 +80,7 @@ func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, er
 		}
 	}
 
-	client, err := api.NewClient(cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	return apiv1.NewAPI(client), nil
+	return api.NewClient(cfg)
 }
 
 func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
@@ -92,6 +92,7 @@ func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 	if err != nil {
 		return nil, err
 	}
+	apiClient := apiv1.NewAPI(client)
 
 	queries, err := parseQuery(dsInfo, tsdbQuery.Queries, tsdbQuery)
 	if err != nil {
@@ -99,13 +100,8 @@ func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 	}
 
 	for _, query := range queries {
-		timeRange := apiv1.Range{
-			Start: query.Start,
-			End:   query.End,
-			Step:  query.Step,
-		}
-
-		plog.Debug("Sending query", "start", timeRange.Start, "end", timeRange.End, "step", timeRange.Step, "query", query.Expr)
+		plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr,
+			"range", query.RangeQuery, "instant", query.InstantQuery, "exemplar", query.ExemplarQuery)
 
 		span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
 		span.SetTag("expr", query.Expr)
@@ -113,17 +109,52 @@ func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		value, _, err := client.QueryRange(ctx, query.Expr, timeRange)
+		frames := data.Frames{}
+
+		if query.RangeQuery {
+			timeRange := apiv1.Range{
+				Start: query.Start,
+				End:   query.End,
+				Step:  query.Step,
+			}
+			value, _, err := apiClient.QueryRange(ctx, query.Expr, timeRange)
+			if err != nil {
+				return nil, err
+			}
+			rangeFrames, err := parseResponse(value, query)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, rangeFrames...)
+		}
 
-		if err != nil {
-			return nil, err
+		if query.InstantQuery {
+			value, _, err := apiClient.Query(ctx, query.Expr, query.End)
+			if err != nil {
+				return nil, err
+			}
+			instantFrames, err := parseResponse(value, query)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, instantFrames...)
 		}
 
-		queryResult, err := parseResponse(value, query)
-		if err != nil {
-			return nil, err
+		if query.ExemplarQuery {
+			exemplarFrames, err := queryExemplars(ctx, client, query)
+			if err != nil {
+				// Exemplars are an optional addition to the result, so a server
+				// without exemplar storage shouldn't fail the whole query.
+				plog.Warn("Failed to query exemplars", "query", query.Expr, "error", err)
+			} else {
+				frames = append(frames, exemplarFrames...)
+			}
+		}
+
+		result.Results[query.RefId] = &tsdb.QueryResult{
+			RefId:      query.RefId,
+			Dataframes: tsdb.NewDecodedDataFrames(frames),
 		}
-		result.Results[query.RefId] = queryResult
 	}
 
 	return result, nil
@@ -176,46 +207,139 @@ func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *
 		interval := intervalCalculator.Calculate(queryContext.TimeRange, dsInterval)
 		step := time.Duration(int64(interval.Value) * intervalFactor)
 
+		// Legacy alerting reduces the whole range of the series and can't
+		// convert exemplar frames into series, so alert queries are always
+		// range queries only.
+		rangeQuery, instantQuery, exemplarQuery := true, false, false
+		if _, fromAlert := queryContext.Headers["FromAlert"]; !fromAlert {
+			rangeQuery, instantQuery = parseQueryType(queryModel)
+			exemplarQuery = queryModel.Model.Get("exemplar").MustBool(false)
+		}
+
 		qs = append(qs, &PrometheusQuery{
-			Expr:         expr,
-			Step:         step,
-			LegendFormat: format,
-			Start:        start,
-			End:          end,
-			RefId:        queryModel.RefId,
+			Expr:          expr,
+			Step:          step,
+			LegendFormat:  format,
+			Start:         start,
+			End:           end,
+			RefId:         queryModel.RefId,
+			RangeQuery:    rangeQuery,
+			InstantQuery:  instantQuery,
+			ExemplarQuery: exemplarQuery,
 		})
 	}
 
 	return qs, nil
 }
 
-func parseResponse(value model.Value, query *PrometheusQuery) (*tsdb.QueryResult, error) {
-	queryRes := tsdb.NewQueryResult()
+// parseQueryType works out whether a query should be run as a range query,
+// an instant query or both. The query type can be set either through the
+// query's queryType ("range" or "instant") or through the "range" and
+// "instant" flags of the query model. Queries that don't specify anything
+// are range queries.
+func parseQueryType(query *tsdb.Query) (rangeQuery bool, instantQuery bool) {
+	switch query.QueryType {
+	case queryTypeRange:
+		return true, false
+	case queryTypeInstant:
+		return false, true
+	}
 
-	data, ok := value.(model.Matrix)
-	if !ok {
-		return queryRes, fmt.Errorf("unsupported result format: %q", value.Type().String())
+	rangeQuery = query.Model.Get("range").MustBool(false)
+	instantQuery = query.Model.Get("instant").MustBool(false)
+	if !rangeQuery && !instantQuery {
+		rangeQuery = true
 	}
 
-	for _, v := range data {
-		series := tsdb.TimeSeries{
-			Name:   formatLegend(v.Metric, query),
-			Tags:   make(map[string]string, len(v.Metric)),
-			Points: make([]tsdb.TimePoint, 0, len(v.Values)),
-		}
+	return rangeQuery, instantQuery
+}
 
-		for k, v := range v.Metric {
-			series.Tags[string(k)] = string(v)
-		}
+func parseResponse(value model.Value, query *PrometheusQuery) (data.Frames, error) {
+	switch v := value.(type) {
+	case model.Matrix:
+		return matrixToDataFrames(v, query), nil
+	case model.Vector:
+		return vectorToDataFrames(v, query), nil
+	case *model.Scalar:
+		return scalarToDataFrames(v, query), nil
+	default:
+		return nil, fmt.Errorf("unsupported result format: %q", value.Type().String())
+	}
+}
+
+func matrixToDataFrames(matrix model.Matrix, query *PrometheusQuery) data.Frames {
+	frames := make(data.Frames, 0, len(matrix))
+
+	for _, v := range matrix {
+		timeVector := make([]time.Time, 0, len(v.Values))
+		values := make([]float64, 0, len(v.Values))
 
 		for _, k := range v.Values {
-			series.Points = append(series.Points, tsdb.NewTimePoint(null.FloatFrom(float64(k.Value)), float64(k.Timestamp.Unix()*1000)))
+			timeVector = append(timeVector, k.Timestamp.Time().UTC())
+			values = append(values, float64(k.Value))
 		}
 
-		queryRes.Series = append(queryRes.Series, &series)
+		name := formatLegend(v.Metric, query)
+		frame := data.NewFrame(name,
+			data.NewField("Time", nil, timeVector),
+			data.NewField("Value", metricToLabels(v.Metric), values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name}),
+		)
+		frame.Meta = newFrameMeta(query, model.ValMatrix)
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+func vectorToDataFrames(vector model.Vector, query *PrometheusQuery) data.Frames {
+	frames := make(data.Frames, 0, len(vector))
+
+	for _, v := range vector {
+		name := formatLegend(v.Metric, query)
+		frame := data.NewFrame(name,
+			data.NewField("Time", nil, []time.Time{v.Timestamp.Time().UTC()}),
+			data.NewField("Value", metricToLabels(v.Metric), []float64{float64(v.Value)}).SetConfig(&data.FieldConfig{DisplayNameFromDS: name}),
+		)
+		frame.Meta = newFrameMeta(query, model.ValVector)
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+func scalarToDataFrames(scalar *model.Scalar, query *PrometheusQuery) data.Frames {
+	frame := data.NewFrame(query.Expr,
+		data.NewField("Time", nil, []time.Time{scalar.Timestamp.Time().UTC()}),
+		data.NewField("Value", nil, []float64{float64(scalar.Value)}),
+	)
+	frame.Meta = newFrameMeta(query, model.ValScalar)
+
+	return data.Frames{frame}
+}
+
+// newFrameMeta returns the frame metadata for a query result. The custom
+// metadata carries the Prometheus result type and, for range queries, the
+// step used, which clients can use as a hint for the spacing of the points.
+func newFrameMeta(query *PrometheusQuery, resultType model.ValueType) *data.FrameMeta {
+	custom := map[string]interface{}{
+		"resultType": resultType.String(),
+	}
+	if resultType == model.ValMatrix {
+		custom["stepMs"] = query.Step.Milliseconds()
 	}
 
-	return queryRes, nil
+	return &data.FrameMeta{
+		ExecutedQueryString: query.Expr,
+		Custom:              custom,
+	}
+}
+
+func metricToLabels(metric model.Metric) data.Labels {
+	labels := make(data.Labels, len(metric))
+	for k, v := range metric {
+		labels[string(k)] = string(v)
+	}
+	return labels
 }
 
 // IsAPIError returns whether err is or wraps a Prometheus error.
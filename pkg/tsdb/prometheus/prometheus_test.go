@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -125,4 +126,184 @@ func TestPrometheus(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, time.Minute*2, models[0].Step)
 	})
+
+	t.Run("parsing query model defaults to a range query", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "go_goroutines", "refId": "A"}`))
+		queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+		require.NoError(t, err)
+		require.True(t, models[0].RangeQuery)
+		require.False(t, models[0].InstantQuery)
+		require.False(t, models[0].ExemplarQuery)
+	})
+
+	t.Run("parsing query model with instant and exemplar flags", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{
+			"expr": "go_goroutines",
+			"instant": true,
+			"range": true,
+			"exemplar": true,
+			"refId": "A"
+		}`))
+		queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+		require.NoError(t, err)
+		require.True(t, models[0].RangeQuery)
+		require.True(t, models[0].InstantQuery)
+		require.True(t, models[0].ExemplarQuery)
+	})
+
+	t.Run("parsing query model with exemplar flag from alerting", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "go_goroutines", "exemplar": true, "refId": "A"}`))
+		queryContext := &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Headers:   map[string]string{"FromAlert": "true"},
+		}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+		require.NoError(t, err)
+		require.True(t, models[0].RangeQuery)
+		require.False(t, models[0].ExemplarQuery)
+	})
+
+	t.Run("parsing instant query model from alerting", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "go_goroutines", "instant": true, "refId": "A"}`))
+		queryContext := &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Headers:   map[string]string{"FromAlert": "true"},
+		}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel, QueryType: "instant"}}, queryContext)
+		require.NoError(t, err)
+		require.True(t, models[0].RangeQuery)
+		require.False(t, models[0].InstantQuery)
+	})
+
+	t.Run("parsing range and instant query model from alerting", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "go_goroutines", "instant": true, "range": true, "refId": "A"}`))
+		queryContext := &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Headers:   map[string]string{"FromAlert": "true"},
+		}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+		require.NoError(t, err)
+		require.True(t, models[0].RangeQuery)
+		require.False(t, models[0].InstantQuery)
+	})
+
+	t.Run("parsing query model with instant query type", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "go_goroutines", "range": true, "refId": "A"}`))
+		queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel, QueryType: "instant"}}, queryContext)
+		require.NoError(t, err)
+		require.False(t, models[0].RangeQuery)
+		require.True(t, models[0].InstantQuery)
+	})
+}
+
+func TestParseResponse(t *testing.T) {
+	t.Run("matrix response should be parsed into frames with labels and step", func(t *testing.T) {
+		values := []p.SamplePair{
+			{Value: 1, Timestamp: 1000},
+			{Value: 2, Timestamp: 2000},
+			{Value: 3, Timestamp: 3000},
+		}
+		value := p.Matrix{
+			&p.SampleStream{
+				Metric: p.Metric{"app": "Application", "tag2": "tag2"},
+				Values: values,
+			},
+		}
+		query := &PrometheusQuery{
+			LegendFormat: "legend {{app}}",
+			Step:         time.Second,
+		}
+
+		frames, err := parseResponse(value, query)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		require.Equal(t, "legend Application", frame.Name)
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, 3, frame.Fields[0].Len())
+		require.Equal(t, time.Unix(1, 0).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, float64(3), frame.Fields[1].At(2))
+		require.Equal(t, data.Labels{"app": "Application", "tag2": "tag2"}, frame.Fields[1].Labels)
+		require.Equal(t, "legend Application", frame.Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, map[string]interface{}{"resultType": "matrix", "stepMs": int64(1000)}, frame.Meta.Custom)
+	})
+
+	t.Run("vector response should be parsed into single point frames", func(t *testing.T) {
+		value := p.Vector{
+			&p.Sample{
+				Metric:    p.Metric{"app": "Application"},
+				Value:     1,
+				Timestamp: 123,
+			},
+		}
+		query := &PrometheusQuery{LegendFormat: "{{app}}"}
+
+		frames, err := parseResponse(value, query)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, "Application", frames[0].Name)
+		require.Equal(t, 1, frames[0].Fields[0].Len())
+		require.Equal(t, time.Unix(0, 123e6).UTC(), frames[0].Fields[0].At(0))
+		require.Equal(t, float64(1), frames[0].Fields[1].At(0))
+		require.Equal(t, data.Labels{"app": "Application"}, frames[0].Fields[1].Labels)
+		require.Equal(t, map[string]interface{}{"resultType": "vector"}, frames[0].Meta.Custom)
+	})
+
+	t.Run("scalar response should be parsed into a single frame", func(t *testing.T) {
+		value := &p.Scalar{Value: 1, Timestamp: 123}
+		query := &PrometheusQuery{Expr: "1"}
+
+		frames, err := parseResponse(value, query)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, float64(1), frames[0].Fields[1].At(0))
+	})
+
+	t.Run("string response should return an error", func(t *testing.T) {
+		_, err := parseResponse(&p.String{Value: "foo"}, &PrometheusQuery{})
+		require.Error(t, err)
+	})
+}
+
+func TestExemplarsToDataFrames(t *testing.T) {
+	results := []exemplarQueryResult{
+		{
+			SeriesLabels: map[string]string{"__name__": "request_duration_bucket", "job": "api"},
+			Exemplars: []exemplar{
+				{Labels: map[string]string{"traceID": "abc"}, Value: "6", Timestamp: 1600096945.5},
+				{Labels: map[string]string{"traceID": "def", "job": "worker"}, Value: "19", Timestamp: 1600096955},
+			},
+		},
+	}
+
+	frames, err := exemplarsToDataFrames(results, &PrometheusQuery{Expr: "request_duration_bucket"})
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+
+	frame := frames[0]
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, []string{"Time", "Value", "__name__", "job", "traceID"}, fieldNames(frame))
+	require.Equal(t, time.Unix(1600096945, 5e8).UTC(), frame.Fields[0].At(0))
+	require.Equal(t, float64(19), frame.Fields[1].At(1))
+	require.Equal(t, "api", frame.Fields[3].At(0))
+	require.Equal(t, "worker", frame.Fields[3].At(1))
+	require.Equal(t, "def", frame.Fields[4].At(1))
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
 }
@@ -3,10 +3,34 @@ package prometheus
 import "time"
 
 type PrometheusQuery struct {
-	Expr         string
-	Step         time.Duration
-	LegendFormat string
-	Start        time.Time
-	End          time.Time
-	RefId        string
+	Expr          string
+	Step          time.Duration
+	LegendFormat  string
+	Start         time.Time
+	End           time.Time
+	RefId         string
+	RangeQuery    bool
+	InstantQuery  bool
+	ExemplarQuery bool
+}
+
+// exemplarResponse is the payload of the /api/v1/query_exemplars endpoint.
+// The Prometheus client library doesn't yet support exemplars, so we decode
+// the response ourselves.
+type exemplarResponse struct {
+	Status    string                `json:"status"`
+	Data      []exemplarQueryResult `json:"data"`
+	ErrorType string                `json:"errorType"`
+	Error     string                `json:"error"`
+}
+
+type exemplarQueryResult struct {
+	SeriesLabels map[string]string `json:"seriesLabels"`
+	Exemplars    []exemplar        `json:"exemplars"`
+}
+
+type exemplar struct {
+	Labels    map[string]string `json:"labels"`
+	Value     string            `json:"value"`
+	Timestamp float64           `json:"timestamp"`
 }
@@ -0,0 +1,186 @@
+package sqleng
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/registry"
+	"xorm.io/xorm"
+)
+
+// poolMetricsInterval is how often the connection pool metrics are updated.
+const poolMetricsInterval = 10 * time.Second
+
+var engineLog = log.New("tsdb.sqleng")
+
+// errEngineClosed is returned when querying a data source whose connection
+// pool was closed since the query started, because the data source was
+// updated or deleted.
+var errEngineClosed = errors.New("the connection pool of the data source was closed, the data source was updated or deleted")
+
+type engineCacheEntry struct {
+	engine   *xorm.Engine
+	version  int
+	pluginID string
+	// users is the number of queries in progress using the engine
+	users int
+	// evicted is set once the entry is removed from the cache, the engine
+	// being closed once no query uses it anymore
+	evicted bool
+	closed  bool
+	// waitCount is the number of waits for a connection already counted in
+	// the metrics
+	waitCount int64
+}
+
+// engineCacheType holds an engine, and with it a connection pool, per SQL
+// data source. Engines are replaced when a new version of the data source
+// is queried.
+type engineCacheType struct {
+	cache map[int64]*engineCacheEntry
+	sync.Mutex
+}
+
+var engineCache = engineCacheType{
+	cache: make(map[int64]*engineCacheEntry),
+}
+
+// evict removes the engine of a data source from the cache, together with
+// its metrics. The engine is closed once the queries in progress using it
+// finish. The caller must hold the lock.
+func (c *engineCacheType) evict(dsID int64) {
+	entry, ok := c.cache[dsID]
+	if !ok {
+		return
+	}
+	delete(c.cache, dsID)
+
+	labels := poolMetricLabels(dsID, entry)
+	metrics.MDataSourceConnectionsInUse.DeleteLabelValues(labels...)
+	metrics.MDataSourceConnectionsIdle.DeleteLabelValues(labels...)
+	metrics.MDataSourceConnectionsWaitCount.DeleteLabelValues(labels...)
+
+	entry.evicted = true
+	c.closeUnused(dsID, entry)
+}
+
+// acquire marks the engine of the entry as used by a query, so that it isn't
+// closed until the query releases it. Returns false if the engine is closed.
+func (c *engineCacheType) acquire(entry *engineCacheEntry) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if entry.closed {
+		return false
+	}
+	entry.users++
+	return true
+}
+
+// release marks the engine of the entry as no longer used by a query, and
+// closes it if it was evicted and no other query uses it.
+func (c *engineCacheType) release(dsID int64, entry *engineCacheEntry) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry.users--
+	c.closeUnused(dsID, entry)
+}
+
+// closeUnused closes the engine of an evicted entry once no query uses it.
+// The caller must hold the lock.
+func (c *engineCacheType) closeUnused(dsID int64, entry *engineCacheEntry) {
+	if !entry.evicted || entry.closed || entry.users > 0 {
+		return
+	}
+
+	entry.closed = true
+	if err := entry.engine.Close(); err != nil {
+		engineLog.Warn("Failed to close connection pool", "datasourceId", dsID, "err", err)
+	}
+}
+
+func (c *engineCacheType) evictAll() {
+	c.Lock()
+	defer c.Unlock()
+
+	for dsID := range c.cache {
+		c.evict(dsID)
+	}
+}
+
+// updateMetrics publishes the statistics of the connection pools.
+func (c *engineCacheType) updateMetrics() {
+	c.Lock()
+	defer c.Unlock()
+
+	for dsID, entry := range c.cache {
+		stats := entry.engine.DB().Stats()
+		labels := poolMetricLabels(dsID, entry)
+		metrics.MDataSourceConnectionsInUse.WithLabelValues(labels...).Set(float64(stats.InUse))
+		metrics.MDataSourceConnectionsIdle.WithLabelValues(labels...).Set(float64(stats.Idle))
+		// the wait count of the pool is cumulative
+		metrics.MDataSourceConnectionsWaitCount.WithLabelValues(labels...).Add(float64(stats.WaitCount - entry.waitCount))
+		entry.waitCount = stats.WaitCount
+	}
+}
+
+func poolMetricLabels(dsID int64, entry *engineCacheEntry) []string {
+	return []string{strconv.FormatInt(dsID, 10), entry.pluginID}
+}
+
+func init() {
+	registry.RegisterService(&ConnectionPoolService{})
+}
+
+// ConnectionPoolService closes the connection pools of SQL data sources when
+// they are updated or deleted, and publishes their statistics as metrics.
+type ConnectionPoolService struct {
+	Bus bus.Bus `inject:""`
+}
+
+func (s *ConnectionPoolService) Init() error {
+	s.Bus.AddEventListener(s.handleDataSourceUpdated)
+	s.Bus.AddEventListener(s.handleDataSourceDeleted)
+	return nil
+}
+
+func (s *ConnectionPoolService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(poolMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			engineCache.updateMetrics()
+		case <-ctx.Done():
+			engineCache.evictAll()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *ConnectionPoolService) handleDataSourceUpdated(e *events.DataSourceUpdated) error {
+	engineCache.Lock()
+	defer engineCache.Unlock()
+
+	if entry, ok := engineCache.cache[e.Id]; ok && entry.version != e.Version {
+		engineCache.evict(e.Id)
+	}
+	return nil
+}
+
+func (s *ConnectionPoolService) handleDataSourceDeleted(e *events.DataSourceDeleted) error {
+	engineCache.Lock()
+	defer engineCache.Unlock()
+
+	engineCache.evict(e.Id)
+	return nil
+}
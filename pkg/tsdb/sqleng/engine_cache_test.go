@@ -0,0 +1,105 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestEngineCache(t *testing.T) {
+	origXormEngine := NewXormEngine
+	t.Cleanup(func() {
+		NewXormEngine = origXormEngine
+		engineCache.evictAll()
+	})
+	NewXormEngine = func(driverName string, connectionString string) (*xorm.Engine, error) {
+		return xorm.NewEngine("sqlite3", ":memory:")
+	}
+
+	newEndpoint := func(t *testing.T, ds *models.DataSource) *sqlQueryEndpoint {
+		t.Helper()
+
+		endpoint, err := NewSqlQueryEndpoint(&SqlQueryEndpointConfiguration{
+			DriverName: "sqlite3",
+			Datasource: ds,
+		}, nil, nil, log.New("test"))
+		require.NoError(t, err)
+		return endpoint.(*sqlQueryEndpoint)
+	}
+
+	newDataSource := func(id int64, version int) *models.DataSource {
+		return &models.DataSource{
+			Id:      id,
+			Version: version,
+			Type:    "mysql",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"maxOpenConns": 5,
+			}),
+		}
+	}
+
+	service := &ConnectionPoolService{}
+
+	t.Run("Should reuse the engine of the same data source version", func(t *testing.T) {
+		first := newEndpoint(t, newDataSource(1, 1))
+		second := newEndpoint(t, newDataSource(1, 1))
+		require.Same(t, first.engine, second.engine)
+		require.Equal(t, 5, first.engine.DB().Stats().MaxOpenConnections)
+	})
+
+	t.Run("Should close the engine of a previous data source version", func(t *testing.T) {
+		first := newEndpoint(t, newDataSource(2, 1))
+		second := newEndpoint(t, newDataSource(2, 2))
+		require.NotSame(t, first.engine, second.engine)
+		require.Error(t, first.engine.Ping())
+		require.NoError(t, second.engine.Ping())
+	})
+
+	t.Run("Should close the engine when the data source is updated", func(t *testing.T) {
+		endpoint := newEndpoint(t, newDataSource(3, 1))
+
+		err := service.handleDataSourceUpdated(&events.DataSourceUpdated{Id: 3, Version: 1})
+		require.NoError(t, err)
+		require.NoError(t, endpoint.engine.Ping())
+
+		err = service.handleDataSourceUpdated(&events.DataSourceUpdated{Id: 3, Version: 2})
+		require.NoError(t, err)
+		require.Error(t, endpoint.engine.Ping())
+		require.NotContains(t, engineCache.cache, int64(3))
+	})
+
+	t.Run("Should close the engine when the data source is deleted", func(t *testing.T) {
+		endpoint := newEndpoint(t, newDataSource(4, 1))
+
+		err := service.handleDataSourceDeleted(&events.DataSourceDeleted{Id: 4})
+		require.NoError(t, err)
+		require.Error(t, endpoint.engine.Ping())
+		require.NotContains(t, engineCache.cache, int64(4))
+	})
+
+	t.Run("Should close the engine once the queries using it finish", func(t *testing.T) {
+		endpoint := newEndpoint(t, newDataSource(6, 1))
+		require.True(t, engineCache.acquire(endpoint.cacheEntry))
+
+		err := service.handleDataSourceDeleted(&events.DataSourceDeleted{Id: 6})
+		require.NoError(t, err)
+		require.NotContains(t, engineCache.cache, int64(6))
+		require.NoError(t, endpoint.engine.Ping())
+
+		engineCache.release(endpoint.dsID, endpoint.cacheEntry)
+		require.Error(t, endpoint.engine.Ping())
+		require.False(t, engineCache.acquire(endpoint.cacheEntry))
+	})
+
+	t.Run("Should publish pool metrics", func(t *testing.T) {
+		newEndpoint(t, newDataSource(5, 1))
+		require.NotPanics(t, engineCache.updateMetrics)
+	})
+}
@@ -0,0 +1,266 @@
+package sqleng
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// frameBuilder builds a data frame from the rows of a SQL query one row at a
+// time, so that results don't have to be held twice in memory. The type of
+// each field is decided by the first non-null value of its column, as
+// returned by the SqlQueryResultTransformer of the data source, and widened
+// when a later value of the column has another type. All fields are nullable.
+type frameBuilder struct {
+	columnNames []string
+	timeIndices map[int]bool
+	fields      []*data.Field
+	rowCount    int
+	byteCount   int64
+}
+
+func newFrameBuilder(columnNames []string, timeIndices ...int) *frameBuilder {
+	b := &frameBuilder{
+		columnNames: columnNames,
+		timeIndices: make(map[int]bool),
+		fields:      make([]*data.Field, len(columnNames)),
+	}
+
+	for _, i := range timeIndices {
+		if i >= 0 {
+			b.timeIndices[i] = true
+		}
+	}
+
+	return b
+}
+
+// append adds a row to the frame.
+func (b *frameBuilder) append(values tsdb.RowValues) error {
+	if len(values) != len(b.columnNames) {
+		return fmt.Errorf("expected %d values in row, got %d", len(b.columnNames), len(values))
+	}
+
+	for i, v := range values {
+		if b.timeIndices[i] {
+			v = toTime(v)
+		}
+
+		value, fieldType, size := normalizeValue(v)
+		b.byteCount += size
+
+		field := b.fields[i]
+		if field == nil {
+			if value == nil {
+				// the field is created with the preceding nulls once the
+				// column's type is known
+				continue
+			}
+			field = data.NewFieldFromFieldType(fieldType, b.rowCount)
+			field.Name = b.columnNames[i]
+			b.fields[i] = field
+		}
+
+		if value != nil && field.Type() != fieldType {
+			if widened := widenedType(field.Type(), fieldType); widened != field.Type() {
+				field = widenField(field, widened)
+				b.fields[i] = field
+			}
+		}
+
+		if value != nil && field.Type() != fieldType {
+			converted, err := convertValue(value, field.Type())
+			if err != nil {
+				return fmt.Errorf("column %q: %w", b.columnNames[i], err)
+			}
+			value = converted
+		}
+
+		if value == nil {
+			field.Append(nil)
+			continue
+		}
+		field.Append(value)
+	}
+
+	b.rowCount++
+	return nil
+}
+
+// frame returns the data frame built so far. Columns that only had null
+// values become nullable string fields.
+func (b *frameBuilder) frame(name string) *data.Frame {
+	fields := make([]*data.Field, len(b.fields))
+	for i, field := range b.fields {
+		if field == nil {
+			field = data.NewFieldFromFieldType(data.FieldTypeNullableString, b.rowCount)
+			field.Name = b.columnNames[i]
+		}
+		fields[i] = field
+	}
+
+	return data.NewFrame(name, fields...)
+}
+
+// normalizeValue converts a value returned by a SqlQueryResultTransformer into
+// a pointer of one of the types supported by nullable frame fields, and
+// returns an estimate of the value's size in bytes. A nil value is returned
+// for null values.
+//nolint: gocyclo
+func normalizeValue(v interface{}) (interface{}, data.FieldType, int64) {
+	if v == nil {
+		return nil, data.FieldTypeNullableString, 0
+	}
+
+	// transformers return pointers for nullable columns
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, data.FieldTypeNullableString, 0
+		}
+		return normalizeValue(rv.Elem().Interface())
+	}
+
+	var i int64
+	var f float64
+
+	switch value := v.(type) {
+	case string:
+		return &value, data.FieldTypeNullableString, int64(len(value))
+	case []byte:
+		s := string(value)
+		return &s, data.FieldTypeNullableString, int64(len(s))
+	case bool:
+		return &value, data.FieldTypeNullableBool, 1
+	case time.Time:
+		return &value, data.FieldTypeNullableTime, 24
+	case int:
+		i = int64(value)
+	case int8:
+		i = int64(value)
+	case int16:
+		i = int64(value)
+	case int32:
+		i = int64(value)
+	case int64:
+		i = value
+	case uint:
+		i = int64(value)
+	case uint8:
+		i = int64(value)
+	case uint16:
+		i = int64(value)
+	case uint32:
+		i = int64(value)
+	case uint64:
+		i = int64(value)
+	case float32:
+		f = float64(value)
+		return &f, data.FieldTypeNullableFloat64, 8
+	case float64:
+		f = value
+		return &f, data.FieldTypeNullableFloat64, 8
+	default:
+		s := fmt.Sprintf("%v", value)
+		return &s, data.FieldTypeNullableString, int64(len(s))
+	}
+
+	return &i, data.FieldTypeNullableInt64, 8
+}
+
+// widenedType returns the type of a field able to hold the values of both
+// types: integers and floats are widened to floats, any other mix of types to
+// strings.
+func widenedType(fieldType, valueType data.FieldType) data.FieldType {
+	if fieldType == valueType || fieldType == data.FieldTypeNullableString {
+		return fieldType
+	}
+
+	numeric := func(t data.FieldType) bool {
+		return t == data.FieldTypeNullableInt64 || t == data.FieldTypeNullableFloat64
+	}
+	if numeric(fieldType) && numeric(valueType) {
+		return data.FieldTypeNullableFloat64
+	}
+
+	return data.FieldTypeNullableString
+}
+
+// widenField returns a copy of the field with the given type, the values
+// already appended being converted to it.
+func widenField(field *data.Field, fieldType data.FieldType) *data.Field {
+	widened := data.NewFieldFromFieldType(fieldType, field.Len())
+	widened.Name = field.Name
+
+	for i := 0; i < field.Len(); i++ {
+		value := field.At(i)
+		if rv := reflect.ValueOf(value); value == nil || rv.IsNil() {
+			continue
+		}
+		// widenedType only returns types every value can be converted to
+		converted, _ := convertValue(value, fieldType)
+		widened.Set(i, converted)
+	}
+
+	return widened
+}
+
+// convertValue converts a normalized value to the type of an existing field,
+// which happens when the type of a column changes from a row to another.
+func convertValue(value interface{}, fieldType data.FieldType) (interface{}, error) {
+	if v, ok := value.(*int64); ok && fieldType == data.FieldTypeNullableFloat64 {
+		f := float64(*v)
+		return &f, nil
+	}
+
+	if fieldType == data.FieldTypeNullableString {
+		s := fmt.Sprintf("%v", derefValue(value))
+		return &s, nil
+	}
+
+	return nil, fmt.Errorf("can't convert %T to %s", value, fieldType)
+}
+
+func derefValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *int64:
+		return *v
+	case *float64:
+		return *v
+	case *bool:
+		return *v
+	case *string:
+		return *v
+	case *time.Time:
+		return *v
+	}
+	return value
+}
+
+// toTime converts the value of a time column to a time.Time. Numeric values
+// are treated as epoch timestamps, with their precision detected the same way
+// as in ConvertSqlTimeColumnToEpochMs.
+func toTime(v interface{}) interface{} {
+	switch value := v.(type) {
+	case time.Time:
+		return value.UTC()
+	case *time.Time:
+		if value != nil {
+			return value.UTC()
+		}
+		return nil
+	}
+
+	values := tsdb.RowValues{v}
+	ConvertSqlTimeColumnToEpochMs(values, 0)
+
+	switch value := values[0].(type) {
+	case int64:
+		return time.Unix(0, value*int64(time.Millisecond)).UTC()
+	case float64:
+		return time.Unix(0, int64(value*float64(time.Millisecond))).UTC()
+	}
+	return v
+}
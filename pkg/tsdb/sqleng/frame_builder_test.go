@@ -0,0 +1,117 @@
+package sqleng
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameBuilder(t *testing.T) {
+	dt := time.Date(2018, 3, 14, 21, 20, 6, 0, time.UTC)
+
+	t.Run("Given rows with typed values", func(t *testing.T) {
+		b := newFrameBuilder([]string{"time", "text", "value", "enabled"}, 0)
+		value := int32(42)
+		require.NoError(t, b.append(tsdb.RowValues{dt, "a", &value, true}))
+		require.NoError(t, b.append(tsdb.RowValues{dt.Unix(), []byte("b"), nil, false}))
+
+		frame := b.frame("A")
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[2].Type())
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[3].Type())
+
+		require.Equal(t, dt, *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, dt, *frame.Fields[0].At(1).(*time.Time))
+		require.Equal(t, "b", *frame.Fields[1].At(1).(*string))
+		require.Equal(t, int64(42), *frame.Fields[2].At(0).(*int64))
+		require.Nil(t, frame.Fields[2].At(1))
+	})
+
+	t.Run("Given a column with leading null values", func(t *testing.T) {
+		b := newFrameBuilder([]string{"value"})
+		require.NoError(t, b.append(tsdb.RowValues{nil}))
+		require.NoError(t, b.append(tsdb.RowValues{1.5}))
+
+		frame := b.frame("A")
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[0].Type())
+		require.Nil(t, frame.Fields[0].At(0))
+		require.Equal(t, 1.5, *frame.Fields[0].At(1).(*float64))
+	})
+
+	t.Run("Given a column with only null values", func(t *testing.T) {
+		b := newFrameBuilder([]string{"value"})
+		require.NoError(t, b.append(tsdb.RowValues{nil}))
+
+		frame := b.frame("A")
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		require.Equal(t, 1, frame.Rows())
+	})
+
+	t.Run("Given a column with both integers and floats", func(t *testing.T) {
+		b := newFrameBuilder([]string{"value"})
+		require.NoError(t, b.append(tsdb.RowValues{1.5}))
+		require.NoError(t, b.append(tsdb.RowValues{int64(2)}))
+
+		frame := b.frame("A")
+		require.Equal(t, 2.0, *frame.Fields[0].At(1).(*float64))
+	})
+
+	t.Run("Given a column of integers followed by floats", func(t *testing.T) {
+		b := newFrameBuilder([]string{"value"})
+		require.NoError(t, b.append(tsdb.RowValues{int64(1)}))
+		require.NoError(t, b.append(tsdb.RowValues{nil}))
+		require.NoError(t, b.append(tsdb.RowValues{2.5}))
+
+		frame := b.frame("A")
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[0].Type())
+		require.Equal(t, 1.0, *frame.Fields[0].At(0).(*float64))
+		require.Nil(t, frame.Fields[0].At(1))
+		require.Equal(t, 2.5, *frame.Fields[0].At(2).(*float64))
+	})
+
+	t.Run("Given a column of integers followed by text", func(t *testing.T) {
+		b := newFrameBuilder([]string{"value"})
+		require.NoError(t, b.append(tsdb.RowValues{int64(1)}))
+		require.NoError(t, b.append(tsdb.RowValues{"a"}))
+
+		frame := b.frame("A")
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		require.Equal(t, "1", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, "a", *frame.Fields[0].At(1).(*string))
+	})
+
+	t.Run("Given a row with the wrong number of values", func(t *testing.T) {
+		b := newFrameBuilder([]string{"time", "value"})
+		require.Error(t, b.append(tsdb.RowValues{dt}))
+	})
+
+	t.Run("Should count the bytes of the values", func(t *testing.T) {
+		b := newFrameBuilder([]string{"text", "value"})
+		require.NoError(t, b.append(tsdb.RowValues{"abcd", 1.0}))
+		require.Equal(t, int64(12), b.byteCount)
+	})
+}
+
+func TestResultLimits(t *testing.T) {
+	t.Run("Should use the default limits", func(t *testing.T) {
+		limits := newResultLimits(&models.DataSource{JsonData: simplejson.New()})
+		require.Equal(t, int64(defaultRowLimit), limits.maxRows)
+		require.Equal(t, int64(defaultByteLimit), limits.maxBytes)
+	})
+
+	t.Run("Should use the limits of the data source", func(t *testing.T) {
+		limits := newResultLimits(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"maxRows":  100,
+			"maxBytes": 1024,
+		})})
+		require.Equal(t, int64(100), limits.maxRows)
+		require.Equal(t, int64(1024), limits.maxBytes)
+	})
+}
@@ -1,7 +1,6 @@
 package sqleng
 
 import (
-	"container/list"
 	"context"
 	"database/sql"
 	"fmt"
@@ -12,7 +11,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -42,17 +41,6 @@ type SqlQueryResultTransformer interface {
 	TransformQueryError(err error) error
 }
 
-type engineCacheType struct {
-	cache    map[int64]*xorm.Engine
-	versions map[int64]int
-	sync.Mutex
-}
-
-var engineCache = engineCacheType{
-	cache:    make(map[int64]*xorm.Engine),
-	versions: make(map[int64]int),
-}
-
 var sqlIntervalCalculator = tsdb.NewIntervalCalculator(nil)
 
 // NewXormEngine is an xorm.Engine factory, that can be stubbed by tests.
@@ -67,6 +55,8 @@ type sqlQueryEndpoint struct {
 	macroEngine            SqlMacroEngine
 	queryResultTransformer SqlQueryResultTransformer
 	engine                 *xorm.Engine
+	dsID                   int64
+	cacheEntry             *engineCacheEntry
 	timeColumnNames        []string
 	metricColumnTypes      []string
 	log                    log.Logger
@@ -99,11 +89,16 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 	engineCache.Lock()
 	defer engineCache.Unlock()
 
-	if engine, present := engineCache.cache[config.Datasource.Id]; present {
-		if version := engineCache.versions[config.Datasource.Id]; version == config.Datasource.Version {
-			queryEndpoint.engine = engine
+	if entry, present := engineCache.cache[config.Datasource.Id]; present {
+		if entry.version == config.Datasource.Version {
+			queryEndpoint.engine = entry.engine
+			queryEndpoint.dsID = config.Datasource.Id
+			queryEndpoint.cacheEntry = entry
 			return &queryEndpoint, nil
 		}
+
+		// the data source was updated on another instance
+		engineCache.evict(config.Datasource.Id)
 	}
 
 	engine, err := NewXormEngine(config.DriverName, config.ConnectionString)
@@ -118,14 +113,27 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 	connMaxLifetime := config.Datasource.JsonData.Get("connMaxLifetime").MustInt(14400)
 	engine.SetConnMaxLifetime(time.Duration(connMaxLifetime) * time.Second)
 
-	engineCache.versions[config.Datasource.Id] = config.Datasource.Version
-	engineCache.cache[config.Datasource.Id] = engine
+	entry := &engineCacheEntry{
+		engine:   engine,
+		version:  config.Datasource.Version,
+		pluginID: config.Datasource.Type,
+	}
+	engineCache.cache[config.Datasource.Id] = entry
 	queryEndpoint.engine = engine
+	queryEndpoint.dsID = config.Datasource.Id
+	queryEndpoint.cacheEntry = entry
 
 	return &queryEndpoint, nil
 }
 
-const rowLimit = 1000000
+const (
+	// defaultRowLimit is the maximum number of rows read for a query, unless
+	// the data source sets maxRows
+	defaultRowLimit = 1000000
+	// defaultByteLimit is the maximum estimated size in bytes of the rows read
+	// for a query, unless the data source sets maxBytes. 0 means no limit.
+	defaultByteLimit = 0
+)
 
 // Query is the main function for the SqlQueryEndpoint
 func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
@@ -133,6 +141,15 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 		Results: make(map[string]*tsdb.QueryResult),
 	}
 
+	// the engine isn't closed while the queries are in progress, even if the
+	// data source is updated in the meantime
+	if !engineCache.acquire(e.cacheEntry) {
+		return nil, errEngineClosed
+	}
+	defer engineCache.release(e.dsID, e.cacheEntry)
+
+	limits := newResultLimits(dsInfo)
+
 	var wg sync.WaitGroup
 
 	for _, query := range tsdbQuery.Queries {
@@ -144,6 +161,13 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 		queryResult := &tsdb.QueryResult{Meta: simplejson.New(), RefId: query.RefId}
 		result.Results[query.RefId] = queryResult
 
+		// the rows of other formats would be read without being returned
+		format := query.Model.Get("format").MustString("time_series")
+		if format != "time_series" && format != "table" {
+			queryResult.Error = fmt.Errorf("unsupported format %q, must be time_series or table", format)
+			continue
+		}
+
 		// global substitutions
 		rawSQL, err := Interpolate(query, tsdbQuery.TimeRange, rawSQL)
 		if err != nil {
@@ -162,13 +186,18 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 
 		wg.Add(1)
 
-		go func(rawSQL string, query *tsdb.Query, queryResult *tsdb.QueryResult) {
+		go func(rawSQL, format string, query *tsdb.Query, queryResult *tsdb.QueryResult) {
 			defer wg.Done()
 			session := e.engine.NewSession()
 			defer session.Close()
 			db := session.DB()
 
-			rows, err := db.Query(rawSQL)
+			// The query is cancelled when the request is, or when reading
+			// the rows stops early because a result limit was reached.
+			queryCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			rows, err := db.QueryContext(queryCtx, rawSQL)
 			if err != nil {
 				queryResult.Error = e.queryResultTransformer.TransformQueryError(err)
 				return
@@ -179,29 +208,64 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 				}
 			}()
 
-			format := query.Model.Get("format").MustString("time_series")
-
-			switch format {
-			case "time_series":
-				err := e.transformToTimeSeries(query, rows, queryResult, tsdbQuery)
-				if err != nil {
-					queryResult.Error = err
-					return
+			var frames data.Frames
+			var rowCount int
+			if format == "time_series" {
+				frames, rowCount, err = e.readTimeSeries(query, tsdbQuery, rows, limits, cancel)
+			} else {
+				var frame *data.Frame
+				frame, err = e.readFrame(query, rows, limits, cancel)
+				if frame != nil {
+					rowCount = frame.Rows()
+					frames = data.Frames{frame}
 				}
-			case "table":
-				err := e.transformToTable(query, rows, queryResult, tsdbQuery)
-				if err != nil {
-					queryResult.Error = err
-					return
+			}
+			if err != nil {
+				queryResult.Error = err
+				return
+			}
+			queryResult.Meta.Set("rowCount", rowCount)
+
+			for _, frame := range frames {
+				if frame.Meta != nil && len(frame.Meta.Notices) > 0 {
+					queryResult.Meta.Set("notices", frame.Meta.Notices)
+					break
 				}
 			}
-		}(rawSQL, query, queryResult)
+			queryResult.Dataframes = tsdb.NewDecodedDataFrames(frames)
+		}(rawSQL, format, query, queryResult)
 	}
 	wg.Wait()
 
 	return result, nil
 }
 
+// resultLimits are the limits on the size of the result of a single query.
+type resultLimits struct {
+	maxRows  int64
+	maxBytes int64
+}
+
+func newResultLimits(dsInfo *models.DataSource) resultLimits {
+	limits := resultLimits{
+		maxRows:  defaultRowLimit,
+		maxBytes: defaultByteLimit,
+	}
+
+	if dsInfo == nil || dsInfo.JsonData == nil {
+		return limits
+	}
+
+	if maxRows := dsInfo.JsonData.Get("maxRows").MustInt64(0); maxRows > 0 {
+		limits.maxRows = maxRows
+	}
+	if maxBytes := dsInfo.JsonData.Get("maxBytes").MustInt64(0); maxBytes > 0 {
+		limits.maxBytes = maxBytes
+	}
+
+	return limits
+}
+
 // Interpolate provides global macros/substitutions for all sql datasources.
 var Interpolate = func(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string) (string, error) {
 	minInterval, err := tsdb.GetIntervalFrom(query.DataSource, query.Model, time.Second*60)
@@ -218,26 +282,28 @@ var Interpolate = func(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string)
 	return sql, nil
 }
 
-func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult, tsdbQuery *tsdb.TsdbQuery) error {
+// readFrame reads the rows of a query into a data frame. Columns named time
+// (or one of the data source's time column names) and timeend are converted
+// to time fields, which makes native datetime types and epoch dates work in
+// annotation and table queries.
+//
+// Reading stops when the result reaches one of the limits. In that case the
+// frame is truncated, a notice is added to its metadata and cancel is called
+// so that the database stops executing the query.
+func (e *sqlQueryEndpoint) readFrame(query *tsdb.Query, rows *core.Rows, limits resultLimits, cancel context.CancelFunc) (*data.Frame, error) {
 	columnNames, err := rows.Columns()
-	columnCount := len(columnNames)
+	if err != nil {
+		return nil, err
+	}
 
+	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rowCount := 0
 	timeIndex := -1
 	timeEndIndex := -1
-
-	table := &tsdb.Table{
-		Columns: make([]tsdb.TableColumn, columnCount),
-		Rows:    make([]tsdb.RowValues, 0),
-	}
-
 	for i, name := range columnNames {
-		table.Columns[i].Text = name
-
 		for _, tc := range e.timeColumnNames {
 			if name == tc {
 				timeIndex = i
@@ -251,88 +317,152 @@ func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows,
 		}
 	}
 
-	columnTypes, err := rows.ColumnTypes()
-	if err != nil {
-		return err
-	}
+	builder := newFrameBuilder(columnNames, timeIndex, timeEndIndex)
 
-	for ; rows.Next(); rowCount++ {
-		if rowCount > rowLimit {
-			return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
+	var notice *data.Notice
+	for rows.Next() {
+		if int64(builder.rowCount) >= limits.maxRows {
+			notice = &data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %d rows because the row limit of the data source was reached", limits.maxRows),
+			}
+			break
 		}
 
 		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// converts column named time and timeend to unix timestamp in milliseconds
-		// to make native mssql datetime types and epoch dates work in
-		// annotation and table queries.
-		ConvertSqlTimeColumnToEpochMs(values, timeIndex)
-		ConvertSqlTimeColumnToEpochMs(values, timeEndIndex)
-		table.Rows = append(table.Rows, values)
+		if err := builder.append(values); err != nil {
+			return nil, err
+		}
+
+		if limits.maxBytes > 0 && builder.byteCount >= limits.maxBytes {
+			notice = &data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %d rows because the size limit of %d bytes of the data source was reached", builder.rowCount, limits.maxBytes),
+			}
+			break
+		}
 	}
 
-	result.Tables = append(result.Tables, table)
-	result.Meta.Set("rowCount", rowCount)
-	return nil
+	if notice != nil {
+		e.log.Debug("Query result truncated", "refId", query.RefId, "rows", builder.rowCount, "bytes", builder.byteCount)
+		cancel()
+	} else if err := rows.Err(); err != nil {
+		return nil, e.queryResultTransformer.TransformQueryError(err)
+	}
+
+	frame := builder.frame(query.RefId)
+	if notice != nil {
+		frame.AppendNotices(*notice)
+	}
+
+	return frame, nil
 }
 
-func newProcessCfg(query *tsdb.Query, tsdbQuery *tsdb.TsdbQuery, rows *core.Rows) (*processCfg, error) {
+// readTimeSeries reads the rows of a time series query straight into a data
+// frame per series. A column named time (or one of the data source's time
+// column names) is mandatory. The first column of one of the metric column
+// types is used as the name of the series, unless a column named metric is
+// present. Other columns are the values of the series.
+//
+// Reading stops when the result reaches one of the limits, the same way as in
+// readFrame. The number of points of the series is returned along with them.
+func (e *sqlQueryEndpoint) readTimeSeries(query *tsdb.Query, tsdbQuery *tsdb.TsdbQuery, rows *core.Rows, limits resultLimits, cancel context.CancelFunc) (data.Frames, int, error) {
 	columnNames, err := rows.Columns()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+
 	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	fillMissing := query.Model.Get("fill").MustBool(false)
+	builder, err := e.newTimeSeriesBuilder(query, tsdbQuery, columnNames, columnTypes)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	cfg := &processCfg{
-		rowCount:           0,
-		columnTypes:        columnTypes,
-		columnNames:        columnNames,
-		rows:               rows,
-		timeIndex:          -1,
-		metricIndex:        -1,
-		metricPrefix:       false,
-		fillMissing:        fillMissing,
-		seriesByQueryOrder: list.New(),
-		pointsBySeries:     make(map[string]*tsdb.TimeSeries),
-		tsdbQuery:          tsdbQuery,
+	var notice *data.Notice
+	rowCount := 0
+	for rows.Next() {
+		if int64(rowCount) >= limits.maxRows {
+			notice = &data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %d rows because the row limit of the data source was reached", limits.maxRows),
+			}
+			break
+		}
+
+		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if err := builder.append(values); err != nil {
+			return nil, 0, err
+		}
+		rowCount++
+
+		if limits.maxBytes > 0 && builder.byteCount >= limits.maxBytes {
+			notice = &data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %d rows because the size limit of %d bytes of the data source was reached", rowCount, limits.maxBytes),
+			}
+			break
+		}
+	}
+
+	if notice != nil {
+		e.log.Debug("Query result truncated", "refId", query.RefId, "rows", rowCount, "bytes", builder.byteCount)
+		cancel()
+	} else if err := rows.Err(); err != nil {
+		return nil, 0, e.queryResultTransformer.TransformQueryError(err)
+	}
+
+	meta := &data.FrameMeta{}
+	if notice != nil {
+		meta.Notices = []data.Notice{*notice}
 	}
-	return cfg, nil
+
+	frames := builder.frames(query.RefId, meta)
+	return frames, builder.pointCount, nil
 }
 
-func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult,
-	tsdbQuery *tsdb.TsdbQuery) error {
-	cfg, err := newProcessCfg(query, tsdbQuery, rows)
-	if err != nil {
-		return err
+func (e *sqlQueryEndpoint) newTimeSeriesBuilder(query *tsdb.Query, tsdbQuery *tsdb.TsdbQuery, columnNames []string, columnTypes []*sql.ColumnType) (*timeSeriesBuilder, error) {
+	b := &timeSeriesBuilder{
+		columnNames:       columnNames,
+		columnTypes:       columnTypes,
+		metricColumnTypes: e.metricColumnTypes,
+		timeIndex:         -1,
+		metricIndex:       -1,
+		fillMissing:       query.Model.Get("fill").MustBool(false),
+		timeRange:         tsdbQuery.TimeRange,
+		seriesByName:      make(map[string]*seriesBuilder),
 	}
 
 	// check columns of resultset: a column named time is mandatory
 	// the first text column is treated as metric name unless a column named metric is present
-	for i, col := range cfg.columnNames {
+	for i, col := range columnNames {
 		for _, tc := range e.timeColumnNames {
 			if col == tc {
-				cfg.timeIndex = i
+				b.timeIndex = i
 				continue
 			}
 		}
 		switch col {
 		case "metric":
-			cfg.metricIndex = i
+			b.metricIndex = i
 		default:
-			if cfg.metricIndex == -1 {
-				columnType := cfg.columnTypes[i].DatabaseTypeName()
+			if b.metricIndex == -1 {
+				columnType := columnTypes[i].DatabaseTypeName()
 
 				for _, mct := range e.metricColumnTypes {
 					if columnType == mct {
-						cfg.metricIndex = i
+						b.metricIndex = i
 						continue
 					}
 				}
@@ -341,102 +471,92 @@ func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.R
 	}
 
 	// use metric column as prefix with multiple value columns
-	if cfg.metricIndex != -1 && len(cfg.columnNames) > 3 {
-		cfg.metricPrefix = true
+	if b.metricIndex != -1 && len(columnNames) > 3 {
+		b.metricPrefix = true
 	}
 
-	if cfg.timeIndex == -1 {
-		return fmt.Errorf("found no column named %q", strings.Join(e.timeColumnNames, " or "))
+	if b.timeIndex == -1 {
+		return nil, fmt.Errorf("found no column named %q", strings.Join(e.timeColumnNames, " or "))
 	}
 
-	if cfg.fillMissing {
-		cfg.fillInterval = query.Model.Get("fillInterval").MustFloat64() * 1000
+	if b.fillMissing {
+		b.fillInterval = query.Model.Get("fillInterval").MustFloat64() * 1000
 		switch query.Model.Get("fillMode").MustString() {
 		case "null":
 		case "previous":
-			cfg.fillPrevious = true
+			b.fillPrevious = true
 		case "value":
-			cfg.fillValue.Float64 = query.Model.Get("fillValue").MustFloat64()
-			cfg.fillValue.Valid = true
+			b.fillValue.Float64 = query.Model.Get("fillValue").MustFloat64()
+			b.fillValue.Valid = true
 		}
 	}
 
-	for rows.Next() {
-		if err := e.processRow(cfg); err != nil {
-			return err
-		}
-	}
+	return b, nil
+}
 
-	for elem := cfg.seriesByQueryOrder.Front(); elem != nil; elem = elem.Next() {
-		key := elem.Value.(string)
-		result.Series = append(result.Series, cfg.pointsBySeries[key])
-		if !cfg.fillMissing {
-			continue
-		}
+// timeSeriesBuilder builds the series of a time series query from the rows of
+// the query one row at a time, filling in missing points when requested.
+type timeSeriesBuilder struct {
+	columnNames       []string
+	columnTypes       []*sql.ColumnType
+	metricColumnTypes []string
+	timeIndex         int
+	metricIndex       int
+	metricPrefix      bool
+	fillMissing       bool
+	fillInterval      float64
+	fillPrevious      bool
+	fillValue         null.Float
+	timeRange         *tsdb.TimeRange
+	seriesByName      map[string]*seriesBuilder
+	series            []*seriesBuilder
+	pointCount        int
+	byteCount         int64
+}
 
-		series := cfg.pointsBySeries[key]
-		// fill in values from last fetched value till interval end
-		intervalStart := series.Points[len(series.Points)-1][1].Float64
-		intervalEnd := float64(tsdbQuery.TimeRange.MustGetTo().UnixNano() / 1e6)
+// seriesBuilder holds the points of a series. Timestamps are in milliseconds.
+type seriesBuilder struct {
+	name          string
+	times         []time.Time
+	values        []*float64
+	lastTimestamp float64
+	lastValue     null.Float
+}
 
-		if cfg.fillPrevious {
-			if len(series.Points) > 0 {
-				cfg.fillValue = series.Points[len(series.Points)-1][0]
-			} else {
-				cfg.fillValue.Valid = false
-			}
-		}
+// pointSize is the estimated size in bytes of a point of a series
+const pointSize = 16
 
-		// align interval start
-		intervalStart = math.Floor(intervalStart/cfg.fillInterval) * cfg.fillInterval
-		for i := intervalStart + cfg.fillInterval; i < intervalEnd; i += cfg.fillInterval {
-			series.Points = append(series.Points, tsdb.TimePoint{cfg.fillValue, null.FloatFrom(i)})
-			cfg.rowCount++
-		}
+func (b *timeSeriesBuilder) addPoint(series *seriesBuilder, value null.Float, timestamp float64) {
+	ms := int64(timestamp)
+	series.times = append(series.times, time.Unix(ms/1e3, (ms%1e3)*1e6))
+	if value.Valid {
+		v := value.Float64
+		series.values = append(series.values, &v)
+	} else {
+		series.values = append(series.values, nil)
 	}
+	series.lastTimestamp = timestamp
+	series.lastValue = value
 
-	result.Meta.Set("rowCount", cfg.rowCount)
-	return nil
-}
-
-type processCfg struct {
-	rowCount           int
-	columnTypes        []*sql.ColumnType
-	columnNames        []string
-	rows               *core.Rows
-	timeIndex          int
-	metricIndex        int
-	metricPrefix       bool
-	metricPrefixValue  string
-	fillMissing        bool
-	pointsBySeries     map[string]*tsdb.TimeSeries
-	seriesByQueryOrder *list.List
-	fillValue          null.Float
-	tsdbQuery          *tsdb.TsdbQuery
-	fillInterval       float64
-	fillPrevious       bool
+	b.pointCount++
+	b.byteCount += pointSize
 }
 
-func (e *sqlQueryEndpoint) processRow(cfg *processCfg) error {
-	var timestamp float64
-	var value null.Float
-	var metric string
-
-	if cfg.rowCount > rowLimit {
-		return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
+// append adds the values of a row to the series.
+func (b *timeSeriesBuilder) append(values tsdb.RowValues) error {
+	if len(values) != len(b.columnNames) {
+		return fmt.Errorf("expected %d values in row, got %d", len(b.columnNames), len(values))
 	}
 
-	values, err := e.queryResultTransformer.TransformQueryResult(cfg.columnTypes, cfg.rows)
-	if err != nil {
-		return err
-	}
+	var timestamp float64
+	var metric, metricPrefix string
 
 	// converts column named time to unix timestamp in milliseconds to make
 	// native mysql datetime types and epoch dates work in
 	// annotation and table queries.
-	ConvertSqlTimeColumnToEpochMs(values, cfg.timeIndex)
+	ConvertSqlTimeColumnToEpochMs(values, b.timeIndex)
 
-	switch columnValue := values[cfg.timeIndex].(type) {
+	switch columnValue := values[b.timeIndex].(type) {
 	case int64:
 		timestamp = float64(columnValue)
 	case float64:
@@ -446,75 +566,118 @@ func (e *sqlQueryEndpoint) processRow(cfg *processCfg) error {
 			columnValue, columnValue)
 	}
 
-	if cfg.metricIndex >= 0 {
-		if columnValue, ok := values[cfg.metricIndex].(string); ok {
-			if cfg.metricPrefix {
-				cfg.metricPrefixValue = columnValue
-			} else {
-				metric = columnValue
-			}
-		} else {
+	if b.metricIndex >= 0 {
+		columnValue, ok := metricValue(values[b.metricIndex])
+		if !ok {
 			return fmt.Errorf("column metric must be of type %s. metric column name: %s type: %s but datatype is %T",
-				strings.Join(e.metricColumnTypes, ", "), cfg.columnNames[cfg.metricIndex],
-				cfg.columnTypes[cfg.metricIndex].DatabaseTypeName(), values[cfg.metricIndex])
+				strings.Join(b.metricColumnTypes, ", "), b.columnNames[b.metricIndex],
+				b.columnTypes[b.metricIndex].DatabaseTypeName(), values[b.metricIndex])
+		}
+		if b.metricPrefix {
+			metricPrefix = columnValue
+		} else {
+			metric = columnValue
 		}
 	}
 
-	for i, col := range cfg.columnNames {
-		if i == cfg.timeIndex || i == cfg.metricIndex {
+	for i, col := range b.columnNames {
+		if i == b.timeIndex || i == b.metricIndex {
 			continue
 		}
 
-		if value, err = ConvertSqlValueColumnToFloat(col, values[i]); err != nil {
+		value, err := ConvertSqlValueColumnToFloat(col, values[i])
+		if err != nil {
 			return err
 		}
 
-		if cfg.metricIndex == -1 {
+		if b.metricIndex == -1 {
 			metric = col
-		} else if cfg.metricPrefix {
-			metric = cfg.metricPrefixValue + " " + col
+		} else if b.metricPrefix {
+			metric = metricPrefix + " " + col
 		}
 
-		series, exist := cfg.pointsBySeries[metric]
+		series, exist := b.seriesByName[metric]
 		if !exist {
-			series = &tsdb.TimeSeries{Name: metric}
-			cfg.pointsBySeries[metric] = series
-			cfg.seriesByQueryOrder.PushBack(metric)
+			series = &seriesBuilder{name: metric}
+			b.seriesByName[metric] = series
+			b.series = append(b.series, series)
+			b.byteCount += int64(len(metric))
 		}
 
-		if cfg.fillMissing {
+		if b.fillMissing {
 			var intervalStart float64
 			if !exist {
-				intervalStart = float64(cfg.tsdbQuery.TimeRange.MustGetFrom().UnixNano() / 1e6)
+				intervalStart = float64(b.timeRange.MustGetFrom().UnixNano() / 1e6)
 			} else {
-				intervalStart = series.Points[len(series.Points)-1][1].Float64 + cfg.fillInterval
+				intervalStart = series.lastTimestamp + b.fillInterval
 			}
 
-			if cfg.fillPrevious {
-				if len(series.Points) > 0 {
-					cfg.fillValue = series.Points[len(series.Points)-1][0]
-				} else {
-					cfg.fillValue.Valid = false
-				}
+			fillValue := b.fillValue
+			if b.fillPrevious {
+				fillValue = series.lastValue
 			}
 
 			// align interval start
-			intervalStart = math.Floor(intervalStart/cfg.fillInterval) * cfg.fillInterval
+			intervalStart = math.Floor(intervalStart/b.fillInterval) * b.fillInterval
 
-			for i := intervalStart; i < timestamp; i += cfg.fillInterval {
-				series.Points = append(series.Points, tsdb.TimePoint{cfg.fillValue, null.FloatFrom(i)})
-				cfg.rowCount++
+			for i := intervalStart; i < timestamp; i += b.fillInterval {
+				b.addPoint(series, fillValue, i)
 			}
 		}
 
-		series.Points = append(series.Points, tsdb.TimePoint{value, null.FloatFrom(timestamp)})
+		b.addPoint(series, value, timestamp)
+	}
+
+	return nil
+}
 
-		if setting.Env == setting.Dev {
-			e.log.Debug("Rows", "metric", metric, "time", timestamp, "value", value)
+// frames fills in the missing points at the end of the series when requested,
+// and returns a data frame per series in the order of the query.
+func (b *timeSeriesBuilder) frames(refID string, meta *data.FrameMeta) data.Frames {
+	frames := make(data.Frames, 0, len(b.series))
+	for _, series := range b.series {
+		if b.fillMissing {
+			// fill in values from last fetched value till interval end
+			intervalStart := series.lastTimestamp
+			intervalEnd := float64(b.timeRange.MustGetTo().UnixNano() / 1e6)
+
+			fillValue := b.fillValue
+			if b.fillPrevious {
+				fillValue = series.lastValue
+			}
+
+			// align interval start
+			intervalStart = math.Floor(intervalStart/b.fillInterval) * b.fillInterval
+			for i := intervalStart + b.fillInterval; i < intervalEnd; i += b.fillInterval {
+				b.addPoint(series, fillValue, i)
+			}
 		}
+
+		valueField := data.NewField(series.name, nil, series.values)
+		valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: series.name})
+
+		frame := data.NewFrame(series.name, data.NewField("time", nil, series.times), valueField)
+		frame.RefID = refID
+		frame.Meta = meta
+		frames = append(frames, frame)
 	}
 
-	return nil
+	return frames
+}
+
+// metricValue returns the value of the metric column, which must be text.
+func metricValue(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case *string:
+		if value != nil {
+			return *value, true
+		}
+	case []byte:
+		return string(value), true
+	}
+	return "", false
 }
 
 // ConvertSqlTimeColumnToEpochMs converts column named time to unix timestamp in milliseconds
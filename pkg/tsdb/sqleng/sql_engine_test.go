@@ -1,15 +1,18 @@
 package sqleng
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
 )
 
 func TestSqlEngine(t *testing.T) {
@@ -310,3 +313,126 @@ func TestSqlEngine(t *testing.T) {
 		}
 	})
 }
+
+func TestTimeSeriesBuilder(t *testing.T) {
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	timeRange := tsdb.NewTimeRange(fmt.Sprint(from.UnixNano()/1e6), fmt.Sprint(from.Add(time.Minute).UnixNano()/1e6))
+	ms := func(d time.Duration) int64 {
+		return from.Add(d).UnixNano() / 1e6
+	}
+
+	newBuilder := func(columnNames []string, metricIndex int) *timeSeriesBuilder {
+		return &timeSeriesBuilder{
+			columnNames:  columnNames,
+			timeIndex:    0,
+			metricIndex:  metricIndex,
+			metricPrefix: metricIndex != -1 && len(columnNames) > 3,
+			timeRange:    timeRange,
+			seriesByName: make(map[string]*seriesBuilder),
+		}
+	}
+
+	t.Run("Should build a series per value column", func(t *testing.T) {
+		b := newBuilder([]string{"time", "a", "b"}, -1)
+		require.NoError(t, b.append(tsdb.RowValues{from, int64(1), 1.5}))
+		require.NoError(t, b.append(tsdb.RowValues{ms(time.Second), nil, 2.5}))
+
+		frames := b.frames("A", nil)
+		require.Len(t, frames, 2)
+		require.Equal(t, 4, b.pointCount)
+
+		require.Equal(t, "A", frames[0].RefID)
+		require.Equal(t, "a", frames[0].Fields[1].Name)
+		require.Equal(t, "a", frames[0].Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, from, frames[0].Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, 1.0, *frames[0].Fields[1].At(0).(*float64))
+		require.Nil(t, frames[0].Fields[1].At(1))
+		require.Equal(t, from.Add(time.Second), frames[1].Fields[0].At(1).(time.Time).UTC())
+		require.Equal(t, 2.5, *frames[1].Fields[1].At(1).(*float64))
+	})
+
+	t.Run("Should build a series per metric", func(t *testing.T) {
+		b := newBuilder([]string{"time", "metric", "value"}, 1)
+		metric := "b"
+		require.NoError(t, b.append(tsdb.RowValues{from, "a", 1.0}))
+		require.NoError(t, b.append(tsdb.RowValues{from, &metric, 2.0}))
+		require.NoError(t, b.append(tsdb.RowValues{from, []byte("a"), 3.0}))
+
+		frames := b.frames("A", nil)
+		require.Len(t, frames, 2)
+		require.Equal(t, "a", frames[0].Fields[1].Name)
+		require.Equal(t, 2, frames[0].Rows())
+		require.Equal(t, "b", frames[1].Fields[1].Name)
+	})
+
+	t.Run("Should prefix the value columns with the metric", func(t *testing.T) {
+		b := newBuilder([]string{"time", "metric", "a", "b"}, 1)
+		require.NoError(t, b.append(tsdb.RowValues{from, "host", 1.0, 2.0}))
+
+		frames := b.frames("A", nil)
+		require.Len(t, frames, 2)
+		require.Equal(t, "host a", frames[0].Fields[1].Name)
+		require.Equal(t, "host b", frames[1].Fields[1].Name)
+	})
+
+	t.Run("Should fill in missing points with a value", func(t *testing.T) {
+		b := newBuilder([]string{"time", "value"}, -1)
+		b.fillMissing = true
+		b.fillInterval = 20000
+		b.fillValue = null.FloatFrom(0)
+		require.NoError(t, b.append(tsdb.RowValues{ms(20 * time.Second), 1.0}))
+
+		frames := b.frames("A", nil)
+		require.Equal(t, 3, frames[0].Rows())
+		require.Equal(t, 3, b.pointCount)
+		require.Equal(t, 0.0, *frames[0].Fields[1].At(0).(*float64))
+		require.Equal(t, 1.0, *frames[0].Fields[1].At(1).(*float64))
+		require.Equal(t, 0.0, *frames[0].Fields[1].At(2).(*float64))
+		require.Equal(t, from.Add(40*time.Second), frames[0].Fields[0].At(2).(time.Time).UTC())
+	})
+
+	t.Run("Should fill in missing points with the previous value", func(t *testing.T) {
+		b := newBuilder([]string{"time", "value"}, -1)
+		b.fillMissing = true
+		b.fillInterval = 20000
+		b.fillPrevious = true
+		require.NoError(t, b.append(tsdb.RowValues{ms(20 * time.Second), 1.0}))
+
+		frames := b.frames("A", nil)
+		require.Equal(t, 3, frames[0].Rows())
+		require.Nil(t, frames[0].Fields[1].At(0))
+		require.Equal(t, 1.0, *frames[0].Fields[1].At(2).(*float64))
+	})
+}
+
+func TestSqlQueryEndpointUnsupportedFormat(t *testing.T) {
+	origXormEngine := NewXormEngine
+	t.Cleanup(func() {
+		NewXormEngine = origXormEngine
+		engineCache.evictAll()
+	})
+	NewXormEngine = func(driverName string, connectionString string) (*xorm.Engine, error) {
+		return xorm.NewEngine("sqlite3", ":memory:")
+	}
+
+	ds := &models.DataSource{Id: 1, Version: 1, Type: "mysql", JsonData: simplejson.New()}
+	endpoint, err := NewSqlQueryEndpoint(&SqlQueryEndpointConfiguration{
+		DriverName: "sqlite3",
+		Datasource: ds,
+	}, nil, nil, log.New("test"))
+	require.NoError(t, err)
+
+	res, err := endpoint.Query(context.Background(), ds, &tsdb.TsdbQuery{
+		TimeRange: tsdb.NewTimeRange("1h", "now"),
+		Queries: []*tsdb.Query{{
+			RefId: "A",
+			Model: simplejson.NewFromAny(map[string]interface{}{
+				"rawSql": "SELECT 1",
+				"format": "logs",
+			}),
+		}},
+	})
+	require.NoError(t, err)
+	require.EqualError(t, res.Results["A"].Error, `unsupported format "logs", must be time_series or table`)
+	require.Nil(t, res.Results["A"].Dataframes)
+}
@@ -6,7 +6,7 @@ import { InlineField, InlineFieldRow, Input, Select, TextArea } from '@grafana/u
 import { SelectableValue, ReducerID, QueryEditorProps } from '@grafana/data';
 
 // Types
-import { ExpressionQuery, GELQueryType } from './types';
+import { ExpressionQuery, GELQueryType, ThresholdCondition } from './types';
 import { ExpressionDatasourceApi } from './ExpressionDatasource';
 
 type Props = QueryEditorProps<ExpressionDatasourceApi, ExpressionQuery>;
@@ -17,14 +17,34 @@ const gelTypes: Array<SelectableValue<GELQueryType>> = [
   { value: GELQueryType.math, label: 'Math' },
   { value: GELQueryType.reduce, label: 'Reduce' },
   { value: GELQueryType.resample, label: 'Resample' },
+  { value: GELQueryType.threshold, label: 'Threshold' },
 ];
 
+const thresholdTypes: Array<SelectableValue<string>> = [
+  { value: 'gt', label: 'Is above' },
+  { value: 'lt', label: 'Is below' },
+  { value: 'within_range', label: 'Is within range' },
+  { value: 'outside_range', label: 'Is outside range' },
+];
+
+const defaultThresholdCondition: ThresholdCondition = { evaluator: { type: 'gt', params: [0] } };
+
+function isRangeThreshold(type: string) {
+  return type === 'within_range' || type === 'outside_range';
+}
+
 const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.min, label: 'Min', description: 'Get the minimum value' },
   { value: ReducerID.max, label: 'Max', description: 'Get the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Get the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
+  { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'percentile', label: 'Percentile', description: 'Get the nth percentile value' },
+  { value: 'stddev', label: 'StdDev', description: 'Get the standard deviation of all values' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and first value' },
 ];
 
 const downsamplingTypes: Array<SelectableValue<string>> = [
@@ -43,7 +63,8 @@ const upsamplingTypes: Array<SelectableValue<string>> = [
 const mathPlaceholder =
   'Math operations on one more queries, you reference the query by ${refId} ie. $A, $B, $C etc\n' +
   'Example: $A + $B\n' +
-  'Available functions: abs(), log(), nan(), inf(), null()';
+  'Available functions: abs(), log(), exp(), sqrt(), round(), ceil(), floor(), clamp_min(), clamp_max(), ' +
+  'is_nan(), is_null(), nan(), inf(), null()';
 
 export class ExpressionQueryEditor extends PureComponent<Props, State> {
   state = {};
@@ -68,6 +89,11 @@ export class ExpressionQueryEditor extends PureComponent<Props, State> {
         q.upsampler = 'fillna';
       }
       q.reducer = undefined;
+    } else if (q.type === GELQueryType.threshold) {
+      if (!q.conditions) {
+        q.conditions = [defaultThresholdCondition];
+      }
+      q.reducer = undefined;
     } else {
       q.reducer = undefined;
     }
@@ -83,6 +109,44 @@ export class ExpressionQueryEditor extends PureComponent<Props, State> {
     });
   };
 
+  onPercentileChange = (evt: ChangeEvent<HTMLInputElement>) => {
+    const { query, onChange } = this.props;
+    onChange({
+      ...query,
+      percentile: parseFloat(evt.target.value),
+    });
+  };
+
+  onThresholdChange = (condition: Partial<ThresholdCondition>) => {
+    const { query, onChange } = this.props;
+    const current = (query.conditions && query.conditions[0]) || defaultThresholdCondition;
+    onChange({
+      ...query,
+      conditions: [{ ...current, ...condition }],
+    });
+  };
+
+  onSelectThresholdType = (item: SelectableValue<string>) => {
+    const { query } = this.props;
+    const current = (query.conditions && query.conditions[0]) || defaultThresholdCondition;
+    const params = isRangeThreshold(item.value!)
+      ? [current.evaluator.params[0], current.evaluator.params[1] ?? current.evaluator.params[0]]
+      : [current.evaluator.params[0]];
+    this.onThresholdChange({ evaluator: { type: item.value!, params } });
+  };
+
+  onThresholdParamChange = (index: number) => (evt: ChangeEvent<HTMLInputElement>) => {
+    const { query } = this.props;
+    const current = (query.conditions && query.conditions[0]) || defaultThresholdCondition;
+    const params = [...current.evaluator.params];
+    params[index] = parseFloat(evt.target.value);
+    this.onThresholdChange({ evaluator: { ...current.evaluator, params } });
+  };
+
+  onHysteresisChange = (evt: ChangeEvent<HTMLInputElement>) => {
+    this.onThresholdChange({ hysteresis: parseFloat(evt.target.value) });
+  };
+
   onSelectUpsampler = (item: SelectableValue<string>) => {
     const { query, onChange } = this.props;
     onChange({
@@ -138,6 +202,8 @@ export class ExpressionQueryEditor extends PureComponent<Props, State> {
     const downsampler = downsamplingTypes.find((o) => o.value === query.downsampler);
     const upsampler = upsamplingTypes.find((o) => o.value === query.upsampler);
     const labelWidth = 14;
+    const condition = (query.conditions && query.conditions[0]) || defaultThresholdCondition;
+    const thresholdType = thresholdTypes.find((o) => o.value === condition.evaluator.type);
 
     const refIds = queries!.filter((q) => query.refId !== q.refId).map((q) => ({ value: q.refId, label: q.refId }));
 
@@ -170,6 +236,18 @@ export class ExpressionQueryEditor extends PureComponent<Props, State> {
             <InlineField label="Input" labelWidth={labelWidth}>
               <Select onChange={this.onRefIdChange} options={refIds} value={query.expression} width={20} />
             </InlineField>
+            {query.reducer === 'percentile' && (
+              <InlineField label="Percentile" tooltip="0 to 100">
+                <Input
+                  type="number"
+                  min={0}
+                  max={100}
+                  onChange={this.onPercentileChange}
+                  value={query.percentile}
+                  width={10}
+                />
+              </InlineField>
+            )}
           </InlineFieldRow>
         )}
         {query.type === GELQueryType.resample && (
@@ -197,6 +275,40 @@ export class ExpressionQueryEditor extends PureComponent<Props, State> {
             </InlineFieldRow>
           </>
         )}
+        {query.type === GELQueryType.threshold && (
+          <InlineFieldRow>
+            <InlineField label="Input" labelWidth={labelWidth}>
+              <Select onChange={this.onRefIdChange} options={refIds} value={query.expression} width={20} />
+            </InlineField>
+            <InlineField label="Condition">
+              <Select options={thresholdTypes} value={thresholdType} onChange={this.onSelectThresholdType} width={20} />
+            </InlineField>
+            <InlineField>
+              <Input
+                type="number"
+                onChange={this.onThresholdParamChange(0)}
+                value={condition.evaluator.params[0]}
+                width={10}
+              />
+            </InlineField>
+            {isRangeThreshold(condition.evaluator.type) && (
+              <InlineField label="to">
+                <Input
+                  type="number"
+                  onChange={this.onThresholdParamChange(1)}
+                  value={condition.evaluator.params[1]}
+                  width={10}
+                />
+              </InlineField>
+            )}
+            <InlineField
+              label="Hysteresis"
+              tooltip="Once a value fired, it keeps firing until it comes back from the threshold by this margin"
+            >
+              <Input type="number" min={0} onChange={this.onHysteresisChange} value={condition.hysteresis} width={10} />
+            </InlineField>
+          </InlineFieldRow>
+        )}
       </div>
     );
   }
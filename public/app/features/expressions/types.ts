@@ -4,6 +4,15 @@ export enum GELQueryType {
   math = 'math',
   reduce = 'reduce',
   resample = 'resample',
+  threshold = 'threshold',
+}
+
+export interface ThresholdCondition {
+  evaluator: {
+    type: string;
+    params: number[];
+  };
+  hysteresis?: number;
 }
 
 /**
@@ -13,8 +22,10 @@ export enum GELQueryType {
 export interface ExpressionQuery extends DataQuery {
   type: GELQueryType;
   reducer?: string;
+  percentile?: number;
   expression?: string;
   window?: string;
   downsampler?: string;
   upsampler?: string;
+  conditions?: ThresholdCondition[];
 }
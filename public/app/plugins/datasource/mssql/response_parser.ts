@@ -1,5 +1,32 @@
 import _ from 'lodash';
-import { MetricFindValue } from '@grafana/data';
+import { arrowTableToDataFrame, base64StringToArrowTable, DataFrame, MetricFindValue } from '@grafana/data';
+
+// decodeFrames decodes the data frames of a query result, which the backend
+// returns as base64 encoded Arrow tables.
+function decodeFrames(queryRes: any): DataFrame[] {
+  return (queryRes.dataframes || []).map((b64: string) => {
+    const frame = arrowTableToDataFrame(base64StringToArrowTable(b64));
+    frame.refId = queryRes.refId;
+    frame.meta = { ...queryRes.meta, ...frame.meta };
+    return frame;
+  });
+}
+
+// getTable returns the table of a query result, converting the data frame
+// returned by the backend into the legacy table format.
+function getTable(queryRes: any): { columns: Array<{ text: string }>; rows: any[][] } {
+  if (!queryRes.dataframes) {
+    return queryRes.tables[0];
+  }
+
+  const [frame] = decodeFrames(queryRes);
+  const rows: any[][] = [];
+  for (let i = 0; i < frame.length; i++) {
+    rows.push(frame.fields.map((field) => field.values.get(i)));
+  }
+
+  return { columns: frame.fields.map((field) => ({ text: field.name })), rows };
+}
 
 interface TableResponse extends Record<string, any> {
   type: string;
@@ -15,7 +42,7 @@ interface SeriesResponse extends Record<string, any> {
 }
 
 export interface MssqlResponse {
-  data: Array<TableResponse | SeriesResponse>;
+  data: Array<TableResponse | SeriesResponse | DataFrame>;
 }
 
 export default class ResponseParser {
@@ -48,6 +75,10 @@ export default class ResponseParser {
           data.push(table);
         }
       }
+
+      if (queryRes.dataframes) {
+        data.push(...decodeFrames(queryRes));
+      }
     }
 
     return { data: data };
@@ -58,8 +89,7 @@ export default class ResponseParser {
       return [];
     }
 
-    const columns = results.data.results[refId].tables[0].columns;
-    const rows = results.data.results[refId].tables[0].rows;
+    const { columns, rows } = getTable(results.data.results[refId]);
     const textColIndex = this.findColIndex(columns, '__text');
     const valueColIndex = this.findColIndex(columns, '__value');
 
@@ -118,7 +148,7 @@ export default class ResponseParser {
   }
 
   transformAnnotationResponse(options: any, data: any) {
-    const table = data.data.results[options.annotation.name].tables[0];
+    const table = getTable(data.data.results[options.annotation.name]);
 
     let timeColumnIndex = -1;
     let timeEndColumnIndex = -1;